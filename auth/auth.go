@@ -0,0 +1,234 @@
+// Package auth verifies the bearer tokens issued by the auth service. Tokens
+// are RS256 (or RS384/RS512) signed with keys published as a JWKS, which is
+// cached and refreshed as the auth service rotates keys; an HS256 shared
+// secret is still accepted for local development. Issuer, audience and
+// expiry are checked, and the claims holding the user ID and role are
+// configurable.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultUserIDClaim = "user_id"
+	DefaultRoleClaim   = "role"
+	DefaultOrgClaim    = "org_id"
+	DefaultRefresh     = 15 * time.Minute
+	DefaultLeeway      = 30 * time.Second
+)
+
+var (
+	ErrNoKey          = errors.New("no token verification key configured: set JWT_JWKS_URL, JWT_JWKS_FILE or JWT_SECRET")
+	ErrUnknownKey     = errors.New("token signed with an unknown key")
+	ErrMissingSubject = errors.New("token is missing the user ID or role claim")
+)
+
+// Config says how tokens are verified and where the caller's identity is
+// read from. The claim names may be dotted paths into nested claims, e.g.
+// "app_metadata.role".
+type Config struct {
+	JWKSURL  string // http(s) URL of the auth service's JWKS
+	JWKSFile string // or a JWKS file on disk, re-read on refresh
+	Secret   string // HS256 shared secret (local development)
+
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+
+	UserIDClaim string
+	RoleClaim   string
+	OrgClaim    string // optional organization the token acts for
+
+	Refresh time.Duration // how long fetched keys are trusted before refetching
+}
+
+// ConfigFromEnv reads the JWT_* environment variables
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		JWKSURL:     os.Getenv("JWT_JWKS_URL"),
+		JWKSFile:    os.Getenv("JWT_JWKS_FILE"),
+		Secret:      os.Getenv("JWT_SECRET"),
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+		UserIDClaim: os.Getenv("JWT_USER_ID_CLAIM"),
+		RoleClaim:   os.Getenv("JWT_ROLE_CLAIM"),
+		OrgClaim:    os.Getenv("JWT_ORG_CLAIM"),
+		Leeway:      DefaultLeeway,
+		Refresh:     DefaultRefresh,
+	}
+
+	for name, dst := range map[string]*time.Duration{"JWT_LEEWAY": &cfg.Leeway, "JWT_JWKS_REFRESH": &cfg.Refresh} {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return Config{}, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = d
+		}
+	}
+	return cfg, nil
+}
+
+// Verifier checks tokens against one configuration
+type Verifier struct {
+	cfg    Config
+	keys   *KeySet // nil when only a shared secret is configured
+	parser *jwt.Parser
+}
+
+// NewVerifier builds a verifier, loading the JWKS once so that a bad URL or
+// file stops the server at startup rather than on the first request.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if cfg.UserIDClaim == "" {
+		cfg.UserIDClaim = DefaultUserIDClaim
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = DefaultRoleClaim
+	}
+	if cfg.OrgClaim == "" {
+		cfg.OrgClaim = DefaultOrgClaim
+	}
+	if cfg.Refresh == 0 {
+		cfg.Refresh = DefaultRefresh
+	}
+
+	v := &Verifier{cfg: cfg}
+	var methods []string
+
+	switch {
+	case cfg.JWKSURL != "" && cfg.JWKSFile != "":
+		return nil, errors.New("set only one of JWT_JWKS_URL and JWT_JWKS_FILE")
+	case cfg.JWKSURL != "" || cfg.JWKSFile != "":
+		source := cfg.JWKSURL
+		if source == "" {
+			source = cfg.JWKSFile
+		}
+		keys, err := NewKeySet(source, cfg.Refresh)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		methods = append(methods, "RS256", "RS384", "RS512")
+	}
+	if cfg.Secret != "" {
+		methods = append(methods, "HS256")
+	}
+	if len(methods) == 0 {
+		return nil, ErrNoKey
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Parse verifies a raw token and returns it with its claims as
+// *jwt.MapClaims. The token must carry the configured user ID and role.
+func (v *Verifier) Parse(raw string) (*jwt.Token, error) {
+	if v.parser == nil {
+		return nil, ErrNoKey
+	}
+	token, err := v.parser.ParseWithClaims(raw, new(jwt.MapClaims), v.key)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := v.Subject(*token.Claims.(*jwt.MapClaims)); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// key picks the verification key by algorithm, so a token can never make
+// an RSA public key double as an HMAC secret
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(v.cfg.Secret), nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// Subject reads the user ID and role from verified claims. IDs may be JSON
+// numbers or numeric strings (as "sub" usually is).
+func (v *Verifier) Subject(claims jwt.MapClaims) (uint, string, error) {
+	role, okRole := lookup(claims, v.cfg.RoleClaim).(string)
+
+	var id uint64
+	var err error
+	switch raw := lookup(claims, v.cfg.UserIDClaim).(type) {
+	case float64:
+		id = uint64(raw)
+		if float64(id) != raw {
+			err = ErrMissingSubject
+		}
+	case string:
+		id, err = strconv.ParseUint(raw, 10, 0)
+	default:
+		err = ErrMissingSubject
+	}
+
+	if err != nil || id == 0 || !okRole || role == "" {
+		return 0, "", ErrMissingSubject
+	}
+	return uint(id), role, nil
+}
+
+// Organization reads the organization ID claim, if the token carries one
+func (v *Verifier) Organization(claims jwt.MapClaims) string {
+	org, _ := lookup(claims, v.cfg.OrgClaim).(string)
+	return org
+}
+
+// lookup follows a dotted path into nested claims
+func lookup(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return value
+}
+
+// Active verifies every request. Until Configure runs it only knows the
+// default claim names, which is all handler tests need.
+var Active = &Verifier{cfg: Config{UserIDClaim: DefaultUserIDClaim, RoleClaim: DefaultRoleClaim, OrgClaim: DefaultOrgClaim}}
+
+// Configure builds Active from the environment. It fails when no key is
+// configured or the JWKS cannot be loaded; the server must not start
+// without a way to verify tokens.
+func Configure() error {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	v, err := NewVerifier(cfg)
+	if err != nil {
+		return err
+	}
+	Active = v
+	return nil
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	keyOnce sync.Once
+	keys    [2]*rsa.PrivateKey
+)
+
+// testKeys generates two RSA keys once for the whole package
+func testKeys(t *testing.T) [2]*rsa.PrivateKey {
+	t.Helper()
+	keyOnce.Do(func() {
+		for i := range keys {
+			k, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			keys[i] = k
+		}
+	})
+	return keys
+}
+
+// jwksJSON publishes the public halves of the given keys
+func jwksJSON(kids []string, privs ...*rsa.PrivateKey) []byte {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	for i, k := range privs {
+		doc.Keys = append(doc.Keys, jwk{
+			Kty: "RSA", Kid: kids[i], Use: "sig", Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	raw, _ := json.Marshal(doc)
+	return raw
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":     "https://auth.example.in",
+		"aud":     "vishwakarma-setu",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"user_id": float64(42),
+		"role":    "seller",
+	}
+}
+
+func writeJWKS(t *testing.T, raw []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	k := testKeys(t)
+	v, err := NewVerifier(Config{
+		JWKSFile: writeJWKS(t, jwksJSON([]string{"k1"}, k[0])),
+		Issuer:   "https://auth.example.in",
+		Audience: "vishwakarma-setu",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := v.Parse(sign(t, k[0], "k1", validClaims()))
+	if err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+	id, role, _ := v.Subject(*token.Claims.(*jwt.MapClaims))
+	if id != 42 || role != "seller" {
+		t.Errorf("expected seller 42, got %s %d", role, id)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		key    *rsa.PrivateKey
+		kid    string
+	}{
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, k[0], "k1"},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }, k[0], "k1"},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, k[0], "k1"},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }, k[0], "k1"},
+		{"no role", func(c jwt.MapClaims) { delete(c, "role") }, k[0], "k1"},
+		{"unknown key", func(c jwt.MapClaims) {}, k[1], "k2"},
+		{"wrong key for kid", func(c jwt.MapClaims) {}, k[1], "k1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := validClaims()
+			tc.mutate(claims)
+			if _, err := v.Parse(sign(t, tc.key, tc.kid, claims)); err == nil {
+				t.Error("expected the token to be rejected")
+			}
+		})
+	}
+}
+
+func TestVerifier_RejectsHS256WithoutSecret(t *testing.T) {
+	k := testKeys(t)
+	v, err := NewVerifier(Config{JWKSFile: writeJWKS(t, jwksJSON([]string{"k1"}, k[0]))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An HMAC token keyed with the public modulus must not pass
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	s, _ := token.SignedString(k[0].N.Bytes())
+	if _, err := v.Parse(s); err == nil {
+		t.Error("expected HS256 to be refused when no secret is configured")
+	}
+}
+
+func TestVerifier_ClaimMapping(t *testing.T) {
+	v, err := NewVerifier(Config{Secret: "test-secret", UserIDClaim: "sub", RoleClaim: "app_metadata.role"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.MapClaims{
+		"sub":          "17",
+		"app_metadata": map[string]interface{}{"role": "inspector"},
+		"exp":          time.Now().Add(time.Hour).Unix(),
+	}
+	s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+
+	token, err := v.Parse(s)
+	if err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+	id, role, _ := v.Subject(*token.Claims.(*jwt.MapClaims))
+	if id != 17 || role != "inspector" {
+		t.Errorf("expected inspector 17, got %s %d", role, id)
+	}
+
+	claims["sub"] = "not-a-number"
+	s, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	if _, err := v.Parse(s); !errors.Is(err, ErrMissingSubject) {
+		t.Errorf("expected ErrMissingSubject, got %v", err)
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	k := testKeys(t)
+
+	var mu sync.Mutex
+	published := jwksJSON([]string{"k1"}, k[0])
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		w.Write(published)
+	}))
+	defer srv.Close()
+
+	set, err := NewKeySet(srv.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	set.now = func() time.Time { return now }
+
+	if _, err := set.Key("k1"); err != nil {
+		t.Fatalf("expected k1, got %v", err)
+	}
+
+	// The auth service rotates to k2. Within a minute of the last fetch an
+	// unknown kid is not refetched
+	mu.Lock()
+	published = jwksJSON([]string{"k2"}, k[1])
+	mu.Unlock()
+	if _, err := set.Key("k2"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey while throttled, got %v", err)
+	}
+
+	now = now.Add(2 * minRefetch)
+	if _, err := set.Key("k2"); err != nil {
+		t.Errorf("expected k2 after rotation, got %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+
+	// Once the set goes stale it refetches even for a known kid, and keeps
+	// serving the old keys if the auth service is down
+	srv.Close()
+	now = now.Add(2 * time.Hour)
+	if _, err := set.Key("k2"); err != nil {
+		t.Errorf("expected the cached key while the JWKS is unreachable, got %v", err)
+	}
+}
+
+func TestNewVerifier_NoKey(t *testing.T) {
+	if _, err := NewVerifier(Config{}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected ErrNoKey, got %v", err)
+	}
+	if _, err := NewVerifier(Config{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected a missing JWKS file to fail")
+	}
+	if _, err := NewVerifier(Config{JWKSFile: writeJWKS(t, []byte(`{"keys":[{"kty":"EC","kid":"e1"}]}`))}); err == nil {
+		t.Error("expected a JWKS without RSA keys to fail")
+	}
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefetch limits how often the set is refetched, so neither a stream of
+// forged kids nor an unreachable auth service turns every request into a
+// fetch
+const minRefetch = time.Minute
+
+// KeySet caches the RSA keys of a JWKS by key ID. Keys are refetched once
+// they are older than the refresh interval, and early when a token names a
+// key we have not seen, which is how a rotation shows up. A failed refresh
+// keeps serving the keys already held.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	now     func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetched     time.Time
+	lastAttempt time.Time
+}
+
+// NewKeySet loads the JWKS at source, an http(s) URL or a file path
+func NewKeySet(source string, refresh time.Duration) (*KeySet, error) {
+	s := &KeySet{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+	}
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Key returns the key with the given ID. An empty kid is accepted only
+// while the set holds exactly one key.
+func (s *KeySet) Key(kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	stale := s.now().Sub(s.fetched) > s.refresh
+	key, ok := s.find(kid)
+	s.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+	if !s.tryFetch() {
+		if ok {
+			return key, nil
+		}
+		return nil, ErrUnknownKey
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.find(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// find looks a key up; callers hold mu
+func (s *KeySet) find(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// tryFetch refreshes the set unless another attempt was made within
+// minRefetch. It reports whether the keys were reloaded.
+func (s *KeySet) tryFetch() bool {
+	s.mu.Lock()
+	if s.now().Sub(s.lastAttempt) < minRefetch {
+		s.mu.Unlock()
+		return false
+	}
+	s.lastAttempt = s.now()
+	s.mu.Unlock()
+
+	if err := s.fetch(); err != nil {
+		log.Printf("auth: refreshing JWKS from %s: %v", s.source, err)
+		return false
+	}
+	return true
+}
+
+func (s *KeySet) fetch() error {
+	raw, err := s.read()
+	if err != nil {
+		return fmt.Errorf("loading JWKS from %s: %w", s.source, err)
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return fmt.Errorf("parsing JWKS from %s: %w", s.source, err)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetched = s.now()
+	s.lastAttempt = s.fetched
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *KeySet) read() ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(s.source, "file://"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk is the subset of RFC 7517 fields needed for RSA signature keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// parseJWKS keeps the RSA signing keys of a JWKS document. Keys of other
+// types or uses are skipped; a document with no usable key is an error.
+func parseJWKS(raw []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := k.rsaKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("modulus shorter than 2048 bits")
+	}
+	return key, nil
+}
@@ -0,0 +1,92 @@
+// Package certificate renders inspection certificates: a PDF summary of a
+// machine's inspection report that buyers can share, carrying a
+// verification code and a QR code linking to the public verification
+// endpoint, so anyone holding a copy can confirm it is genuine and current.
+package certificate
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"image"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+// MaxPhotos is how many of the report's photos a certificate shows
+const MaxPhotos = 6
+
+// alphabet is Crockford's base 32: no I, L, O or U, so codes survive
+// being read aloud or typed from paper
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// codeLength is the number of characters in a code, 60 random bits
+const codeLength = 12
+
+var ErrInvalidCode = errors.New("invalid verification code")
+
+// VerifyURL is where certificates send readers to verify them; the code is
+// appended. Empty means the API's own GET /api/certificates/{code}, built
+// from the request. Configure sets it.
+var VerifyURL = ""
+
+// Configure reads CERTIFICATE_VERIFY_URL, e.g. a page of the web app at
+// https://vishwakarmasetu.in/verify/
+func Configure() error {
+	if v := os.Getenv("CERTIFICATE_VERIFY_URL"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid CERTIFICATE_VERIFY_URL %q", v)
+		}
+		VerifyURL = v
+	}
+	return nil
+}
+
+// NewCode returns a random verification code such as "7KQ2-M9XD-4TRA"
+func NewCode() string {
+	b := make([]byte, codeLength)
+	rand.Read(b)
+	for i := range b {
+		b[i] = alphabet[b[i]&31]
+	}
+	return format(string(b))
+}
+
+// NormalizeCode accepts a code as a person might type it: in any case,
+// with or without dashes or spaces, and with O, I and L for 0, 1 and 1
+func NormalizeCode(code string) (string, error) {
+	code = strings.NewReplacer("-", "", " ", "", "O", "0", "I", "1", "L", "1").Replace(strings.ToUpper(code))
+	if len(code) != codeLength {
+		return "", ErrInvalidCode
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(alphabet, r) {
+			return "", ErrInvalidCode
+		}
+	}
+	return format(code), nil
+}
+
+func format(code string) string {
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
+
+// Certificate is everything printed on one certificate
+type Certificate struct {
+	Code      string
+	VerifyURL string // with the code, as encoded in the QR code
+	IssuedAt  time.Time
+
+	Machine models.Machine
+	Report  models.InspectionReport
+	// Template is the checklist the report was scored against, if any
+	Template *models.InspectionTemplate
+	// Photos are the first MaxPhotos of the report's media that could be
+	// loaded, already scaled down
+	Photos []image.Image
+}
@@ -0,0 +1,138 @@
+package certificate
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vishwakarma-setu-backend/checklist"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestCodes(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code := NewCode()
+		if !pattern.MatchString(code) {
+			t.Fatalf("NewCode() = %q", code)
+		}
+		if seen[code] {
+			t.Fatalf("NewCode() repeated %q", code)
+		}
+		seen[code] = true
+		if got, err := NormalizeCode(code); err != nil || got != code {
+			t.Errorf("NormalizeCode(%q) = %q, %v", code, got, err)
+		}
+	}
+
+	cases := map[string]string{
+		"7kq2-m9xd-4tra":   "7KQ2-M9XD-4TRA",
+		"7KQ2 M9XD 4TRA":   "7KQ2-M9XD-4TRA",
+		"7KQ2M9XD4TRA":     "7KQ2-M9XD-4TRA",
+		"OIL0-0000-0000":   "0110-0000-0000",
+		" 7kq2-m9xd-4tra ": "7KQ2-M9XD-4TRA",
+	}
+	for in, want := range cases {
+		if got, err := NormalizeCode(in); err != nil || got != want {
+			t.Errorf("NormalizeCode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "7KQ2-M9XD-4TR", "7KQ2-M9XD-4TRAB", "7KQ2-M9XD-4TRU", "../../etc"} {
+		if _, err := NormalizeCode(in); err != ErrInvalidCode {
+			t.Errorf("NormalizeCode(%q) error = %v, want ErrInvalidCode", in, err)
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func() { VerifyURL = "" }()
+	t.Setenv("CERTIFICATE_VERIFY_URL", "https://vishwakarmasetu.in/verify/")
+	if err := Configure(); err != nil || VerifyURL != "https://vishwakarmasetu.in/verify/" {
+		t.Fatalf("Configure() = %v, VerifyURL %q", err, VerifyURL)
+	}
+	t.Setenv("CERTIFICATE_VERIFY_URL", "vishwakarmasetu.in/verify")
+	if err := Configure(); err == nil {
+		t.Error("expected a URL without a scheme to be rejected")
+	}
+}
+
+func TestRender(t *testing.T) {
+	maxRunout := 0.01
+	score := 75.0
+	cert := &Certificate{
+		Code:      "7KQ2-M9XD-4TRA",
+		VerifyURL: "https://api.example.com/api/certificates/7KQ2-M9XD-4TRA",
+		IssuedAt:  time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		Machine: models.Machine{
+			ID: uuid.New(), Title: "Haas VF-2 (2015)", Manufacturer: "Haas", ModelNumber: "VF-2",
+			YearOfManufacture: 2015, Category: "CNC Mill", Location: "Faridabad, Haryana",
+			Specs: []byte(`{"spindle_speed_rpm": 8100}`),
+		},
+		Report: models.InspectionReport{
+			ID: uuid.New(), InspectorID: 7, InspectionDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Verdict: "Pass", Summary: strings.Repeat("Well maintained machine with full service history. ", 10),
+			ReportData:     []byte(`{"runout": 0.02, "leaks": "pass"}`),
+			ConditionScore: &score, ConditionGrade: "B",
+			SectionScores: []byte(`[{"key": "spindle", "score": 75}]`),
+		},
+		Template: &models.InspectionTemplate{Version: 2, Sections: checklist.Sections{{
+			Key: "spindle", Label: "Spindle", Weight: 1, Items: []checklist.Item{
+				{Key: "runout", Label: "Spindle runout", Type: checklist.TypeMeasurement, Unit: "mm", Max: &maxRunout, Weight: 1},
+				{Key: "leaks", Label: "No coolant leaks", Type: checklist.TypePassFail, Weight: 3},
+				{Key: "noise", Label: "Bearing noise", Type: checklist.TypePassFail, Weight: 1},
+			},
+		}}},
+	}
+	for i := 0; i < 4; i++ {
+		cert.Photos = append(cert.Photos, image.NewGray(image.Rect(0, 0, 40, 30)))
+	}
+
+	out, err := Render(cert)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF")
+	}
+	if n := bytes.Count(out, []byte("/Subtype /Image")); n != 4 {
+		t.Errorf("embedded %d photos, want 4", n)
+	}
+
+	text := pageText(t, out)
+	for _, want := range []string{
+		"Inspection Certificate", "7KQ2-M9XD-4TRA", "Haas VF-2 \\(2015\\)", "Spindle speed rpm", "8100",
+		"Inspector #7", "75 / 100 \\(grade B\\)", "CNC Mill checklist, version 2",
+		"Spindle runout", "0.02 mm", "max 0.01 mm", "FAIL", "PASS", "Not checked", "Page 1 of",
+	} {
+		if !strings.Contains(text, "("+want) && !strings.Contains(text, want+")") {
+			t.Errorf("expected %q in the certificate", want)
+		}
+	}
+}
+
+// pageText decompresses and joins every content stream
+func pageText(t *testing.T, out []byte) string {
+	var text strings.Builder
+	for rest := out; ; {
+		i := bytes.Index(rest, []byte("/FlateDecode"))
+		if i < 0 {
+			break
+		}
+		rest = rest[i:]
+		rest = rest[bytes.Index(rest, []byte("stream\n"))+len("stream\n"):]
+		zr, err := zlib.NewReader(bytes.NewReader(rest))
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		content, _ := io.ReadAll(zr)
+		text.Write(content)
+	}
+	return text.String()
+}
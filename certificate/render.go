@@ -0,0 +1,407 @@
+package certificate
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vishwakarma-setu-backend/checklist"
+	"github.com/vishwakarma-setu-backend/pdf"
+	"github.com/vishwakarma-setu-backend/qr"
+)
+
+const (
+	margin       = 48.0
+	contentWidth = pdf.PageWidth - 2*margin
+	// bottom is the lowest baseline content may use, above the footer
+	bottom = pdf.PageHeight - 64
+)
+
+var (
+	navy  = pdf.Color{R: 22, G: 48, B: 84}
+	green = pdf.Color{R: 22, G: 128, B: 61}
+	red   = pdf.Color{R: 185, G: 28, B: 28}
+	gray  = pdf.Color{R: 100, G: 108, B: 118}
+	rule  = pdf.Color{R: 210, G: 214, B: 220}
+)
+
+// Render lays the certificate out on as many A4 pages as it needs
+func Render(cert *Certificate) ([]byte, error) {
+	code, err := qr.Encode(cert.VerifyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &layout{doc: pdf.New("Inspection Certificate " + cert.Code), cert: cert}
+	l.doc.Created = cert.IssuedAt
+	l.firstPage(code)
+	l.machine()
+	l.inspection()
+	l.summary()
+	l.checklist()
+	if err := l.photos(); err != nil {
+		return nil, err
+	}
+	l.footers()
+	return l.doc.Bytes()
+}
+
+// layout tracks the current page and how far down it content has reached
+type layout struct {
+	doc   *pdf.Document
+	cert  *Certificate
+	pages []*pdf.Page
+	page  *pdf.Page
+	y     float64
+}
+
+func (l *layout) newPage() {
+	l.page = l.doc.AddPage()
+	l.pages = append(l.pages, l.page)
+	l.y = margin
+}
+
+// need starts a new page unless h more points fit on this one
+func (l *layout) need(h float64) {
+	if l.y+h > bottom {
+		l.newPage()
+		l.page.Text(margin, l.y, pdf.HelveticaBold, 9, gray, "Inspection Certificate "+l.cert.Code+" (continued)")
+		l.y += 24
+	}
+}
+
+// firstPage draws the header band with the title, code and QR code
+func (l *layout) firstPage(code *qr.Code) {
+	l.newPage()
+	const band = 136.0
+	l.page.Rect(0, 0, pdf.PageWidth, band, navy)
+	l.page.Text(margin, 44, pdf.HelveticaBold, 9, pdf.White, "VISHWAKARMA SETU")
+	l.page.Text(margin, 72, pdf.HelveticaBold, 24, pdf.White, "Inspection Certificate")
+	l.page.Text(margin, 96, pdf.Helvetica, 11, pdf.White, "Verification code  "+l.cert.Code)
+	l.page.Text(margin, 114, pdf.Helvetica, 9, pdf.White, "Issued "+l.cert.IssuedAt.Format("02 Jan 2006 15:04 MST"))
+
+	// The QR code with its four-module quiet zone, on white
+	const box = 112.0
+	x, y := pdf.PageWidth-margin-box, (band-box)/2
+	l.page.Rect(x, y, box, box, pdf.White)
+	module := box / float64(code.Size+8)
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if code.Dark(col, row) {
+				// A hair of overlap keeps viewers from drawing seams
+				l.page.Rect(x+float64(col+4)*module, y+float64(row+4)*module, module+0.1, module+0.1, pdf.Black)
+			}
+		}
+	}
+
+	l.y = band + 24
+	for _, line := range pdf.Wrap(pdf.Helvetica, 9, "Scan the code or visit "+l.cert.VerifyURL+" to confirm this certificate is genuine and still current. It reflects the machine's condition on the inspection date only.", contentWidth) {
+		l.page.Text(margin, l.y, pdf.Helvetica, 9, gray, line)
+		l.y += 12
+	}
+}
+
+func (l *layout) heading(title string) {
+	l.need(48)
+	l.y += 18
+	l.page.Text(margin, l.y, pdf.HelveticaBold, 13, navy, title)
+	l.y += 6
+	l.page.Line(margin, l.y, margin+contentWidth, l.y, 0.75, navy)
+	l.y += 16
+}
+
+// field prints a label and its value, wrapping long values
+func (l *layout) field(label, value string, c pdf.Color) {
+	const valueX = margin + 140
+	lines := pdf.Wrap(pdf.Helvetica, 10, value, contentWidth-140)
+	l.need(14 * float64(len(lines)))
+	l.page.Text(margin, l.y, pdf.Helvetica, 10, gray, label)
+	for _, line := range lines {
+		l.page.Text(valueX, l.y, pdf.Helvetica, 10, c, line)
+		l.y += 14
+	}
+}
+
+func (l *layout) machine() {
+	m := &l.cert.Machine
+	l.heading("Machine")
+	l.field("Title", m.Title, pdf.Black)
+	l.field("Make and model", strings.TrimSpace(m.Manufacturer+" "+m.ModelNumber), pdf.Black)
+	if m.YearOfManufacture > 0 {
+		l.field("Year of manufacture", strconv.Itoa(m.YearOfManufacture), pdf.Black)
+	}
+	if m.Category != "" {
+		l.field("Category", m.Category, pdf.Black)
+	}
+	if m.Location != "" {
+		l.field("Location", m.Location, pdf.Black)
+	}
+	l.field("Machine ID", m.ID.String(), pdf.Black)
+
+	var specs map[string]interface{}
+	if json.Unmarshal(m.Specs, &specs) == nil {
+		for _, key := range sortedKeys(specs) {
+			l.field(label(key), value(specs[key]), pdf.Black)
+		}
+	}
+}
+
+func (l *layout) inspection() {
+	r := &l.cert.Report
+	l.heading("Inspection")
+	l.field("Report ID", r.ID.String(), pdf.Black)
+	l.field("Inspection date", r.InspectionDate.Format("02 Jan 2006"), pdf.Black)
+	l.field("Inspector", fmt.Sprintf("Inspector #%d", r.InspectorID), pdf.Black)
+	l.field("Verdict", r.Verdict, verdictColor(r.Verdict))
+	if r.ConditionScore != nil {
+		l.field("Condition", fmt.Sprintf("%s / 100 (grade %s)", number(*r.ConditionScore), r.ConditionGrade), gradeColor(r.ConditionGrade))
+	}
+	if l.cert.Template != nil {
+		l.field("Checklist", fmt.Sprintf("%s checklist, version %d", l.cert.Machine.Category, l.cert.Template.Version), pdf.Black)
+	}
+}
+
+func (l *layout) summary() {
+	if strings.TrimSpace(l.cert.Report.Summary) == "" {
+		return
+	}
+	l.heading("Summary")
+	for _, line := range pdf.Wrap(pdf.Helvetica, 10, l.cert.Report.Summary, contentWidth) {
+		l.need(14)
+		l.page.Text(margin, l.y, pdf.Helvetica, 10, pdf.Black, line)
+		l.y += 14
+	}
+}
+
+// Checklist table columns
+const (
+	colAnswer     = margin + 250
+	colAcceptable = margin + 350
+	colResultEnd  = margin + contentWidth
+)
+
+func (l *layout) checklist() {
+	var answers map[string]interface{}
+	json.Unmarshal(l.cert.Report.ReportData, &answers)
+
+	if l.cert.Template == nil {
+		// No checklist to lay the answers out by: list them as recorded
+		if len(answers) == 0 {
+			return
+		}
+		l.heading("Findings")
+		for _, key := range sortedKeys(answers) {
+			l.field(label(key), value(answers[key]), pdf.Black)
+		}
+		return
+	}
+
+	l.heading("Checklist results")
+	scores := map[string]float64{}
+	var sectionScores []checklist.SectionScore
+	if json.Unmarshal(l.cert.Report.SectionScores, &sectionScores) == nil {
+		for _, s := range sectionScores {
+			scores[s.Key] = s.Score
+		}
+	}
+
+	for _, section := range l.cert.Template.Sections {
+		l.need(44)
+		l.y += 4
+		l.page.Rect(margin, l.y-12, contentWidth, 18, pdf.Color{R: 236, G: 240, B: 245})
+		l.page.Text(margin+6, l.y, pdf.HelveticaBold, 10, navy, section.Label)
+		if score, ok := scores[section.Key]; ok {
+			l.rightText(colResultEnd-6, l.y, pdf.HelveticaBold, 10, navy, number(score)+" / 100")
+		}
+		l.y += 20
+
+		for i := range section.Items {
+			item := &section.Items[i]
+			answer, answered := answers[item.Key]
+			result, c := "Not checked", gray
+			switch {
+			case answered && !item.Scored():
+				result, c = "Recorded", gray
+			case answered && item.Passes(answer):
+				result, c = "PASS", green
+			case answered:
+				result, c = "FAIL", red
+			}
+
+			lines := pdf.Wrap(pdf.Helvetica, 9, item.Label, colAnswer-margin-16)
+			l.need(13 * float64(len(lines)))
+			top := l.y
+			for _, line := range lines {
+				l.page.Text(margin+6, l.y, pdf.Helvetica, 9, pdf.Black, line)
+				l.y += 13
+			}
+			if answered {
+				l.page.Text(colAnswer, top, pdf.Helvetica, 9, pdf.Black, answerText(item, answer))
+			} else {
+				l.page.Text(colAnswer, top, pdf.Helvetica, 9, gray, "—")
+			}
+			l.page.Text(colAcceptable, top, pdf.Helvetica, 9, gray, acceptable(item))
+			l.rightText(colResultEnd-6, top, pdf.HelveticaBold, 9, c, result)
+			l.page.Line(margin, l.y-8, margin+contentWidth, l.y-8, 0.25, rule)
+		}
+		l.y += 6
+	}
+}
+
+// photos lays the photos out three to a row, each fitted into its cell
+func (l *layout) photos() error {
+	if len(l.cert.Photos) == 0 {
+		return nil
+	}
+	l.heading("Photos")
+	const columns, gap = 3, 12.0
+	cell := (contentWidth - gap*(columns-1)) / columns
+	cellHeight := cell * 3 / 4
+
+	for i, photo := range l.cert.Photos {
+		col := i % columns
+		if col == 0 {
+			if i > 0 {
+				l.y += cellHeight + gap
+			}
+			l.need(cellHeight)
+		}
+		im, err := l.doc.AddImage(photo, 80)
+		if err != nil {
+			return err
+		}
+		w, h := fit(photo.Bounds(), cell, cellHeight)
+		x := margin + float64(col)*(cell+gap)
+		l.page.Rect(x, l.y-10, cell, cellHeight, pdf.Color{R: 243, G: 244, B: 246})
+		l.page.Image(im, x+(cell-w)/2, l.y-10+(cellHeight-h)/2, w, h)
+		l.page.StrokeRect(x, l.y-10, cell, cellHeight, 0.5, rule)
+	}
+	l.y += cellHeight + gap
+	return nil
+}
+
+// footers numbers the pages once their count is known
+func (l *layout) footers() {
+	for i, p := range l.pages {
+		y := pdf.PageHeight - 32
+		p.Line(margin, y-14, margin+contentWidth, y-14, 0.5, rule)
+		p.Text(margin, y, pdf.Helvetica, 8, gray, "Certificate "+l.cert.Code+" - verify at "+l.cert.VerifyURL)
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(l.pages))
+		p.Text(margin+contentWidth-pdf.Width(pdf.Helvetica, 8, footer), y, pdf.Helvetica, 8, gray, footer)
+	}
+}
+
+func (l *layout) rightText(right, y float64, font pdf.Font, size float64, c pdf.Color, s string) {
+	l.page.Text(right-pdf.Width(font, size, s), y, font, size, c, s)
+}
+
+// fit scales bounds to fit within w by h, keeping the aspect ratio
+func fit(bounds image.Rectangle, w, h float64) (float64, float64) {
+	iw, ih := float64(bounds.Dx()), float64(bounds.Dy())
+	if iw == 0 || ih == 0 {
+		return w, h
+	}
+	scale := min(w/iw, h/ih)
+	return iw * scale, ih * scale
+}
+
+func answerText(item *checklist.Item, v interface{}) string {
+	switch v {
+	case checklist.Pass:
+		return "Pass"
+	case checklist.Fail:
+		return "Fail"
+	}
+	s := value(v)
+	if item.Unit != "" {
+		s += " " + item.Unit
+	}
+	return s
+}
+
+// acceptable describes a measurement's range, e.g. "max 0.01 mm"
+func acceptable(item *checklist.Item) string {
+	unit := ""
+	if item.Unit != "" {
+		unit = " " + item.Unit
+	}
+	switch {
+	case item.Min != nil && item.Max != nil:
+		return number(*item.Min) + " - " + number(*item.Max) + unit
+	case item.Min != nil:
+		return "min " + number(*item.Min) + unit
+	case item.Max != nil:
+		return "max " + number(*item.Max) + unit
+	}
+	return ""
+}
+
+func verdictColor(verdict string) pdf.Color {
+	switch strings.ToLower(verdict) {
+	case "pass":
+		return green
+	case "fail":
+		return red
+	}
+	return pdf.Black
+}
+
+func gradeColor(grade string) pdf.Color {
+	switch grade {
+	case "A", "B":
+		return green
+	case "D", "F":
+		return red
+	}
+	return pdf.Black
+}
+
+// label turns a JSON key such as "spindle_speed_rpm" into "Spindle speed rpm"
+func label(key string) string {
+	s := strings.ReplaceAll(key, "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// value formats a decoded JSON value for print
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "—"
+	case string:
+		return v
+	case float64:
+		return number(v)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = value(e)
+		}
+		return strings.Join(parts, ", ")
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
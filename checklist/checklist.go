@@ -0,0 +1,166 @@
+// Package checklist describes the inspection templates of each machine
+// category: sections of checklist items, each a pass/fail check or a
+// measurement with an acceptable range. It validates submitted reports
+// against a template and scores them. Templates are stored per category.
+package checklist
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Item types
+const (
+	TypePassFail    = "pass_fail"
+	TypeMeasurement = "measurement"
+)
+
+// Answers to pass/fail items
+const (
+	Pass = "pass"
+	Fail = "fail"
+)
+
+// Item is one check in a section. A measurement passes when it lies within
+// Min and Max; one without either is recorded but not scored.
+type Item struct {
+	Key      string   `json:"key" example:"spindle_runout_mm"`
+	Label    string   `json:"label" example:"Spindle runout"`
+	Type     string   `json:"type" example:"measurement"`
+	Unit     string   `json:"unit,omitempty" example:"mm"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty" example:"0.01"`
+	Weight   float64  `json:"weight" example:"2"`
+	Required bool     `json:"required"`
+}
+
+// Scored reports whether the item counts towards the score
+func (i *Item) Scored() bool {
+	return i.Weight > 0 && (i.Type == TypePassFail || i.Min != nil || i.Max != nil)
+}
+
+// Section groups related items, e.g. "spindle" or "hydraulics"
+type Section struct {
+	Key    string  `json:"key" example:"spindle"`
+	Label  string  `json:"label" example:"Spindle"`
+	Weight float64 `json:"weight" example:"3"`
+	Items  []Item  `json:"items"`
+}
+
+// Sections is a template stored as a JSON column
+type Sections []Section
+
+// Value implements driver.Valuer
+func (s Sections) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (s *Sections) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil:
+		*s = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into checklist.Sections", value)
+}
+
+// item looks up an item by key across all sections
+func (s Sections) item(key string) (*Section, *Item, bool) {
+	for i := range s {
+		for j := range s[i].Items {
+			if s[i].Items[j].Key == key {
+				return &s[i], &s[i].Items[j], true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// SectionScore is how a report did on one section
+type SectionScore struct {
+	Key   string  `json:"key" example:"spindle"`
+	Score float64 `json:"score" example:"83.3"`
+}
+
+// Result is a report's overall condition
+type Result struct {
+	Score    float64        `json:"score" example:"87.5"`
+	Grade    string         `json:"grade" example:"B"`
+	Sections []SectionScore `json:"sections"`
+}
+
+// Score rates validated answers from 0 to 100. Each section scores the
+// weighted share of its answered, scored items that passed; the overall
+// score weighs the sections that had any. Optional items left out do not
+// count against the machine. ok is false when nothing could be scored.
+func (s Sections) Score(answers map[string]interface{}) (Result, bool) {
+	var result Result
+	var total, weights float64
+	for _, section := range s {
+		var passed, possible float64
+		for i := range section.Items {
+			item := &section.Items[i]
+			v, answered := answers[item.Key]
+			if !answered || !item.Scored() {
+				continue
+			}
+			possible += item.Weight
+			if item.Passes(v) {
+				passed += item.Weight
+			}
+		}
+		if possible == 0 || section.Weight <= 0 {
+			continue
+		}
+		score := 100 * passed / possible
+		result.Sections = append(result.Sections, SectionScore{Key: section.Key, Score: round1(score)})
+		total += score * section.Weight
+		weights += section.Weight
+	}
+	if weights == 0 {
+		return Result{}, false
+	}
+	result.Score = round1(total / weights)
+	result.Grade = Grade(result.Score)
+	return result, true
+}
+
+// Passes reports whether a validated answer is a pass
+func (i *Item) Passes(v interface{}) bool {
+	if i.Type == TypePassFail {
+		return v == Pass
+	}
+	n, _ := v.(float64)
+	return (i.Min == nil || n >= *i.Min) && (i.Max == nil || n <= *i.Max)
+}
+
+// Grade turns a score into a letter: A from 90, B from 75, C from 60, D
+// from 40 and F below
+func Grade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	}
+	return "F"
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,138 @@
+package checklist
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vishwakarma-setu-backend/validation"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+var testMill = Sections{
+	{Key: "spindle", Label: "Spindle", Weight: 3, Items: []Item{
+		{Key: "spindle_noise", Label: "Runs quietly", Type: TypePassFail, Weight: 1, Required: true},
+		{Key: "spindle_runout_mm", Label: "Runout", Type: TypeMeasurement, Unit: "mm", Max: ptr(0.01), Weight: 2},
+	}},
+	{Key: "hydraulics", Label: "Hydraulics", Weight: 1, Items: []Item{
+		{Key: "hydraulic_leaks", Label: "No leaks", Type: TypePassFail, Weight: 1},
+		{Key: "hydraulic_pressure_bar", Label: "Pressure", Type: TypeMeasurement, Unit: "bar"},
+	}},
+}
+
+func TestValidate(t *testing.T) {
+	clean, err := testMill.Validate(map[string]interface{}{
+		"spindle_noise":          " Pass",
+		"spindle_runout_mm":      0.004,
+		"hydraulic_pressure_bar": 65.0,
+		"hydraulic_leaks":        nil,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{"spindle_noise": "pass", "spindle_runout_mm": 0.004, "hydraulic_pressure_bar": 65.0}
+	if !reflect.DeepEqual(clean, expected) {
+		t.Errorf("expected %v, got %v", expected, clean)
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	_, err := testMill.Validate(map[string]interface{}{
+		"spindle_runout_mm": "tight",
+		"hydraulic_leaks":   "maybe",
+		"paint":             "pass",
+	})
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation.Error, got %v", err)
+	}
+	expected := map[string]string{
+		"spindle_noise":     "is required",
+		"spindle_runout_mm": "must be a number (in mm)",
+		"hydraulic_leaks":   `must be "pass" or "fail"`,
+		"paint":             "is not on the inspection checklist",
+	}
+	if !reflect.DeepEqual(verr.Fields, expected) {
+		t.Errorf("expected %v, got %v", expected, verr.Fields)
+	}
+}
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		name    string
+		answers map[string]interface{}
+		score   float64
+		grade   string
+	}{
+		{"all pass", map[string]interface{}{"spindle_noise": Pass, "spindle_runout_mm": 0.005, "hydraulic_leaks": Pass}, 100, "A"},
+		// Spindle 1/3 (runout out of range), hydraulics 1/1: (33.3*3 + 100) / 4
+		{"worn spindle", map[string]interface{}{"spindle_noise": Pass, "spindle_runout_mm": 0.02, "hydraulic_leaks": Pass}, 50, "D"},
+		// Unanswered optional items and unscored measurements do not count
+		{"partial", map[string]interface{}{"spindle_noise": Pass, "hydraulic_pressure_bar": 10.0}, 100, "A"},
+		{"failing", map[string]interface{}{"spindle_noise": Fail, "hydraulic_leaks": Fail}, 0, "F"},
+	}
+	for _, tc := range cases {
+		result, ok := testMill.Score(tc.answers)
+		if !ok || result.Score != tc.score || result.Grade != tc.grade {
+			t.Errorf("%s: expected %v %s, got %+v (%v)", tc.name, tc.score, tc.grade, result, ok)
+		}
+	}
+
+	result, _ := testMill.Score(map[string]interface{}{"spindle_noise": Pass, "spindle_runout_mm": 0.02, "hydraulic_leaks": Pass})
+	expected := []SectionScore{{Key: "spindle", Score: 33.3}, {Key: "hydraulics", Score: 100}}
+	if !reflect.DeepEqual(result.Sections, expected) {
+		t.Errorf("expected section scores %v, got %v", expected, result.Sections)
+	}
+
+	if _, ok := testMill.Score(map[string]interface{}{"hydraulic_pressure_bar": 10.0}); ok {
+		t.Error("expected no score when only unscored items are answered")
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for score, grade := range map[float64]string{100: "A", 90: "A", 89.9: "B", 75: "B", 60: "C", 40: "D", 39.9: "F", 0: "F"} {
+		if got := Grade(score); got != grade {
+			t.Errorf("expected %s for %v, got %s", grade, score, got)
+		}
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	if err := ValidateTemplate(testMill); err != nil {
+		t.Fatalf("expected valid template, got %v", err)
+	}
+
+	bad := Sections{
+		{Key: "Spindle", Weight: 1, Items: []Item{
+			{Key: "noise", Label: "Noise", Type: TypePassFail, Weight: 1, Unit: "dB"},
+			{Key: "noise", Label: "Noise again", Type: TypePassFail},
+			{Key: "runout", Label: "Runout", Type: TypeMeasurement, Weight: 1},
+			{Key: "travel", Label: "Travel", Type: TypeMeasurement, Min: ptr(5), Max: ptr(1)},
+			{Key: "paint", Type: "rating"},
+		}},
+		{Key: "empty", Weight: -1},
+	}
+	var verr *validation.Error
+	if !errors.As(ValidateTemplate(bad), &verr) {
+		t.Fatal("expected a validation.Error")
+	}
+	expected := map[string]string{
+		"sections[0].key":             "must be snake_case, e.g. hydraulics",
+		"sections[0].items[0].type":   "only measurements have a unit or range",
+		"sections[0].items[1].key":    `"noise" is defined twice`,
+		"sections[0].items[2].weight": "a measurement needs a min or max to be weighted",
+		"sections[0].items[3].min":    "must not be above max",
+		"sections[0].items[4].label":  "is required",
+		"sections[0].items[4].type":   "must be pass_fail or measurement",
+		"sections[1].weight":          "must not be negative",
+		"sections[1].items":           "needs at least one item",
+	}
+	if !reflect.DeepEqual(verr.Fields, expected) {
+		t.Errorf("expected %v, got %v", expected, verr.Fields)
+	}
+
+	if err := ValidateTemplate(nil); err == nil {
+		t.Error("expected an empty template to be rejected")
+	}
+}
@@ -0,0 +1,140 @@
+package checklist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vishwakarma-setu-backend/validation"
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Validate checks a report's answers, keyed by item, against the template.
+// Required items must be answered, unknown keys are rejected, pass/fail
+// items take "pass" or "fail" and measurements a number. It returns the
+// answers with pass/fail in lower case.
+func (s Sections) Validate(answers map[string]interface{}) (map[string]interface{}, error) {
+	verr := validation.New("checklist")
+	clean := make(map[string]interface{}, len(answers))
+
+	for key, v := range answers {
+		_, item, ok := s.item(key)
+		if !ok {
+			verr.Add(key, "is not on the inspection checklist")
+			continue
+		}
+		if v == nil {
+			continue // null is the same as leaving it out
+		}
+		norm, msg := item.check(v)
+		if msg != "" {
+			verr.Add(key, msg)
+			continue
+		}
+		clean[key] = norm
+	}
+
+	for _, section := range s {
+		for _, item := range section.Items {
+			if _, ok := clean[item.Key]; !ok && item.Required {
+				if _, reported := verr.Fields[item.Key]; !reported {
+					verr.Add(item.Key, "is required")
+				}
+			}
+		}
+	}
+
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+// check validates a decoded JSON answer and returns it normalised
+func (i *Item) check(v interface{}) (interface{}, string) {
+	if i.Type == TypePassFail {
+		if str, ok := v.(string); ok {
+			switch answer := strings.ToLower(strings.TrimSpace(str)); answer {
+			case Pass, Fail:
+				return answer, ""
+			}
+		}
+		return nil, `must be "pass" or "fail"`
+	}
+	if n, ok := v.(float64); ok {
+		return n, ""
+	}
+	if i.Unit != "" {
+		return nil, "must be a number (in " + i.Unit + ")"
+	}
+	return nil, "must be a number"
+}
+
+// ValidateTemplate checks a template before it is saved. Item keys are
+// unique across the whole template, since reports answer them by key.
+func ValidateTemplate(s Sections) error {
+	verr := validation.New("checklist")
+	if len(s) == 0 {
+		verr.Add("sections", "needs at least one section")
+	}
+
+	sectionKeys := map[string]bool{}
+	itemKeys := map[string]bool{}
+	scored := false
+	for i, section := range s {
+		name := fmt.Sprintf("sections[%d]", i)
+		switch {
+		case !keyPattern.MatchString(section.Key):
+			verr.Add(name+".key", "must be snake_case, e.g. hydraulics")
+		case sectionKeys[section.Key]:
+			verr.Add(name+".key", fmt.Sprintf("%q is defined twice", section.Key))
+		}
+		sectionKeys[section.Key] = true
+		if section.Weight < 0 {
+			verr.Add(name+".weight", "must not be negative")
+		}
+		if len(section.Items) == 0 {
+			verr.Add(name+".items", "needs at least one item")
+		}
+
+		for j, item := range section.Items {
+			itemName := fmt.Sprintf("%s.items[%d]", name, j)
+			switch {
+			case !keyPattern.MatchString(item.Key):
+				verr.Add(itemName+".key", "must be snake_case, e.g. spindle_runout_mm")
+			case itemKeys[item.Key]:
+				verr.Add(itemName+".key", fmt.Sprintf("%q is defined twice", item.Key))
+			}
+			itemKeys[item.Key] = true
+			if strings.TrimSpace(item.Label) == "" {
+				verr.Add(itemName+".label", "is required")
+			}
+			if item.Weight < 0 {
+				verr.Add(itemName+".weight", "must not be negative")
+			}
+
+			switch item.Type {
+			case TypePassFail:
+				if item.Min != nil || item.Max != nil || item.Unit != "" {
+					verr.Add(itemName+".type", "only measurements have a unit or range")
+				}
+			case TypeMeasurement:
+				if item.Min != nil && item.Max != nil && *item.Min > *item.Max {
+					verr.Add(itemName+".min", "must not be above max")
+				}
+				if item.Weight > 0 && item.Min == nil && item.Max == nil {
+					verr.Add(itemName+".weight", "a measurement needs a min or max to be weighted")
+				}
+			default:
+				verr.Add(itemName+".type", "must be pass_fail or measurement")
+			}
+			scored = scored || (section.Weight > 0 && item.Scored())
+		}
+	}
+	if len(s) > 0 && !scored {
+		verr.Add("sections", "at least one weighted section needs a weighted item")
+	}
+
+	return verr.OrNil()
+}
@@ -6,17 +6,19 @@ import (
 	"os"
 
 	_ "github.com/joho/godotenv/autoload"
-	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func ConnectDatabase() *gorm.DB {
+// OpenDatabase connects to Postgres without checking or touching the schema.
+// Used by the migrate subcommand.
+func OpenDatabase() *gorm.DB {
 	dsn := os.Getenv("DATABASE_DSN")
 	if dsn == "" {
-		log.Fatal("❌ DATABASE_URL is not set")
+		log.Fatal("❌ DATABASE_DSN is not set")
 	}
 
 	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
@@ -26,11 +28,22 @@ func ConnectDatabase() *gorm.DB {
 
 	DB = database
 	fmt.Println("✅ Connected to Database")
-	DB.Migrator().DropTable(&models.Machine{})
-	// fmt.Println("🔄 Running Migrations...")
-	err = DB.AutoMigrate(&models.Machine{}, &models.Rental{}, &models.InspectionReport{}, &models.MaintenanceRecord{})
+
+	return DB
+}
+
+// ConnectDatabase connects to Postgres and refuses to continue if any
+// migration has not been applied yet.
+func ConnectDatabase() *gorm.DB {
+	OpenDatabase()
+
+	pending, err := migrations.Pending(DB)
 	if err != nil {
-		log.Fatalf("❌ AutoMigrate failed: %v", err)
+		log.Fatalf("❌ Could not read schema version: %v", err)
+	}
+	if len(pending) > 0 {
+		next := pending[0]
+		log.Fatalf("❌ Database schema is %d migration(s) behind (next: %04d_%s). Run `migrate up` first.", len(pending), next.Version, next.Name)
 	}
 
 	return DB
@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/documents"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/storage"
+)
+
+// attachmentAccess is what the caller may do with the attachments of one
+// record
+type attachmentAccess struct {
+	public bool // the record itself is public, so its public attachments are too
+	party  bool // owner or party to the record: sees private attachments
+	manage bool // may attach and remove documents
+}
+
+// visible reports whether the caller may see a
+func (acc attachmentAccess) visible(a *models.Attachment) bool {
+	return acc.party || (acc.public && a.Visibility == models.VisibilityPublic)
+}
+
+// attachmentAccessFor loads the record an attachment belongs to and works
+// out the caller's access to it.
+//
+// Machines, maintenance records and inspection reports are public, so
+// their public attachments are visible to everyone; rentals are not. The
+// machine owner is a party to all three, the inspector (and for check-out
+// and check-in reports, the renter) to an inspection, and every party to
+// a rental.
+func attachmentAccessFor(parentType, parentID string, user *UserClaims) (*attachmentAccess, *echo.HTTPError) {
+	id, err := uuid.Parse(parentID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid parent_id")
+	}
+	notFound := echo.NewHTTPError(http.StatusNotFound, "Record not found")
+
+	switch parentType {
+	case models.AttachToMachine:
+		var machine models.Machine
+		if err := config.DB.First(&machine, "id = ?", id).Error; err != nil {
+			return nil, notFound
+		}
+		owner := machineOwner(&machine)
+		return &attachmentAccess{
+			public: true,
+			party:  user.Owns(owner) || user.Can(policy.Any(policy.MachineUpdate)),
+			manage: user.Allows(policy.MachineUpdate, owner),
+		}, nil
+
+	case models.AttachToMaintenance:
+		var record models.MaintenanceRecord
+		if err := config.DB.First(&record, "id = ?", id).Error; err != nil {
+			return nil, notFound
+		}
+		var machine models.Machine
+		if err := config.DB.First(&machine, "id = ?", record.MachineID).Error; err != nil {
+			return nil, notFound
+		}
+		owner := machineOwner(&machine)
+		return &attachmentAccess{
+			public: true,
+			party:  user.Owns(owner) || user.Can(policy.Any(policy.MaintenanceCreate)),
+			manage: user.Allows(policy.MaintenanceCreate, owner),
+		}, nil
+
+	case models.AttachToInspection:
+		var report models.InspectionReport
+		if err := config.DB.First(&report, "id = ?", id).Error; err != nil {
+			return nil, notFound
+		}
+		var machine models.Machine
+		if err := config.DB.First(&machine, "id = ?", report.MachineID).Error; err != nil {
+			return nil, notFound
+		}
+		inspector := user.ID == report.InspectorID && user.Can(policy.InspectionSubmit)
+		acc := &attachmentAccess{
+			public: true,
+			party:  inspector || user.Owns(machineOwner(&machine)) || user.Can(policy.Any(policy.InspectionSubmit)),
+			manage: inspector || user.Can(policy.Any(policy.InspectionSubmit)),
+		}
+		if report.RentalID != nil && !acc.party {
+			var rental models.Rental
+			if err := config.DB.First(&rental, "id = ?", *report.RentalID).Error; err == nil {
+				acc.party = user.Owns(rentalRenter(&rental))
+			}
+		}
+		return acc, nil
+
+	case models.AttachToRental:
+		var rental models.Rental
+		if err := config.DB.Preload("Machine").First(&rental, "id = ?", id).Error; err != nil {
+			return nil, notFound
+		}
+		party := len(rentalParties(&rental, user)) > 0
+		return &attachmentAccess{party: party, manage: party}, nil
+	}
+	return nil, echo.NewHTTPError(http.StatusBadRequest, "parent_type must be machine, maintenance, inspection or rental")
+}
+
+// withURL fills in where a can be downloaded from: its public URL, or a
+// short-lived presigned one when it is private
+func withURL(c echo.Context, a *models.Attachment) error {
+	if !storage.IsPrivate(a.StorageKey) {
+		a.URL = storage.Active.URL(a.StorageKey)
+		return nil
+	}
+	url, err := storage.Active.PresignGet(c.Request().Context(), a.StorageKey, presignExpiry)
+	if err != nil {
+		return err
+	}
+	expires := time.Now().Add(presignExpiry)
+	a.URL, a.URLExpiresAt = url, &expires
+	return nil
+}
+
+// cleanFilename keeps the base name of an uploaded file, without control
+// characters, for display
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	return name
+}
+
+// CreateAttachment godoc
+//
+//	@Summary		Attach a document
+//	@Description	Upload a PDF, Word, Excel, PowerPoint, OpenDocument or CSV file and attach it to a machine, maintenance record, inspection report or rental. The type is detected from the file's content. Limits: PDF, PPT(X) and ODP 20MB; DOC(X), XLS(X), ODT and ODS 10MB; CSV 5MB. Files with macros are rejected. Machine owners attach to their machines and maintenance records, inspectors to their reports, and any party to a rental. Attachments are private unless `visibility` is `public`; rental attachments are always private.
+//	@Tags			Attachments
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			file		formData	file	true	"Document"
+//	@Param			parent_type	formData	string	true	"machine, maintenance, inspection or rental"
+//	@Param			parent_id	formData	string	true	"ID of the record"
+//	@Param			kind		formData	string	false	"invoice, manual, certificate, report or other (default)"
+//	@Param			visibility	formData	string	false	"private (default) or public"
+//	@Success		201			{object}	models.Attachment
+//	@Failure		400			{object}	map[string]string	"Invalid file or parent"
+//	@Failure		403			{object}	policy.Denial		"Not allowed to attach here"
+//	@Failure		404			{object}	map[string]string	"Record not found"
+//	@Failure		413			{object}	map[string]string	"File too large for its type"
+//	@Router			/attachments [post]
+func CreateAttachment(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.FileUpload) {
+		return policy.Forbid(c, policy.FileUpload, "")
+	}
+
+	parentType := c.FormValue("parent_type")
+	kind := c.FormValue("kind")
+	if kind == "" {
+		kind = models.DocumentOther
+	}
+	if !models.IsDocumentKind(kind) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "kind must be invoice, manual, certificate, report or other"})
+	}
+	visibility := c.FormValue("visibility")
+	if visibility == "" || parentType == models.AttachToRental {
+		visibility = models.VisibilityPrivate
+	}
+	if visibility != models.VisibilityPrivate && visibility != models.VisibilityPublic {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "visibility must be private or public"})
+	}
+
+	access, herr := attachmentAccessFor(parentType, c.FormValue("parent_id"), user)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	if !access.manage {
+		return policy.Deny(c, "You cannot attach documents to this record")
+	}
+
+	// Read the file, stopping at the largest size any type allows
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "No file uploaded"})
+	}
+	if file.Size > documents.MaxSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "File too large (Max 20MB)"})
+	}
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not open file"})
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, documents.MaxSize+1))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not read file"})
+	}
+
+	if len(data) > documents.MaxSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "File too large (Max 20MB)"})
+	}
+
+	docType, err := documents.Detect(data, file.Filename)
+	var sizeErr *documents.SizeError
+	if errors.As(err, &sizeErr) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	key := "attachments/" + uuid.New().String() + docType.Ext
+	if visibility == models.VisibilityPrivate {
+		key = storage.PrivatePrefix + key
+	}
+	ctx := c.Request().Context()
+	if err := storage.Active.Put(ctx, key, bytes.NewReader(data), int64(len(data)), docType.ContentType); err != nil {
+		c.Logger().Errorf("storing attachment %s: %v", key, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
+	}
+
+	attachment := models.Attachment{
+		ParentType:  parentType,
+		ParentID:    uuid.MustParse(c.FormValue("parent_id")), // validated above
+		UploaderID:  user.ID,
+		Kind:        kind,
+		Visibility:  visibility,
+		Filename:    cleanFilename(file.Filename),
+		ContentType: docType.ContentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	}
+	if err := config.DB.Create(&attachment).Error; err != nil {
+		storage.Active.Delete(ctx, key)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save attachment"})
+	}
+
+	if err := withURL(c, &attachment); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not sign download URL"})
+	}
+	return c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachments godoc
+//
+//	@Summary		List a record's attachments
+//	@Description	List the documents attached to a machine, maintenance record, inspection report or rental, newest first, a page at a time. Private attachments are only listed for the record's owner and parties, with download URLs that expire after 15 minutes.
+//	@Tags			Attachments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			parent_type		query		string	true	"machine, maintenance, inspection or rental"
+//	@Param			parent_id		query		string	true	"ID of the record"
+//	@Param			kind			query		string	false	"Only this kind"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching attachments"
+//	@Success		200				{object}	pagination.Page[models.Attachment]
+//	@Failure		400				{object}	map[string]string	"Invalid parent or cursor"
+//	@Failure		403				{object}	policy.Denial		"Not a party to the rental"
+//	@Failure		404				{object}	map[string]string	"Record not found"
+//	@Router			/attachments [get]
+func GetAttachments(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	parentType, parentID := c.QueryParam("parent_type"), c.QueryParam("parent_id")
+	access, herr := attachmentAccessFor(parentType, parentID, user)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	if !access.public && !access.party {
+		return policy.Deny(c, "You are not a party to this record")
+	}
+
+	query := config.DB.Model(&models.Attachment{}).Where("parent_type = ? AND parent_id = ?", parentType, parentID)
+	if !access.party {
+		query = query.Where("visibility = ?", models.VisibilityPublic)
+	}
+	if kind := c.QueryParam("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	page, err := pagination.Paginate(query, pagination.FromQuery(c.QueryParams()), attachmentOrder)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch attachments"})
+	}
+	for i := range page.Data {
+		if err := withURL(c, &page.Data[i]); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not sign download URL"})
+		}
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// attachmentOrder lists attachments newest first
+var attachmentOrder = pagination.Order[models.Attachment]{
+	{Expr: "created_at", Type: "timestamptz", Desc: true, Value: func(a *models.Attachment) interface{} { return a.CreatedAt }},
+	{Expr: "id", Type: "uuid", Desc: true, Value: func(a *models.Attachment) interface{} { return a.ID }},
+}
+
+// GetAttachment godoc
+//
+//	@Summary		Get an attachment
+//	@Description	Fetch one attachment with a fresh download URL.
+//	@Tags			Attachments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Attachment ID"
+//	@Success		200	{object}	models.Attachment
+//	@Failure		404	{object}	map[string]string	"Attachment not found"
+//	@Router			/attachments/{id} [get]
+func GetAttachment(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var attachment models.Attachment
+	if err := config.DB.First(&attachment, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attachment not found"})
+	}
+	access, herr := attachmentAccessFor(attachment.ParentType, attachment.ParentID.String(), user)
+	// Hidden attachments are reported missing rather than forbidden
+	if herr != nil || !access.visible(&attachment) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attachment not found"})
+	}
+
+	if err := withURL(c, &attachment); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not sign download URL"})
+	}
+	return c.JSON(http.StatusOK, attachment)
+}
+
+// DeleteAttachment godoc
+//
+//	@Summary		Delete an attachment
+//	@Description	Remove a document and its stored file. Allowed to whoever may attach documents to the record.
+//	@Tags			Attachments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Attachment ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		403	{object}	policy.Denial		"Not allowed"
+//	@Failure		404	{object}	map[string]string	"Attachment not found"
+//	@Router			/attachments/{id} [delete]
+func DeleteAttachment(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var attachment models.Attachment
+	if err := config.DB.First(&attachment, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attachment not found"})
+	}
+	access, herr := attachmentAccessFor(attachment.ParentType, attachment.ParentID.String(), user)
+	if herr != nil || !access.visible(&attachment) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Attachment not found"})
+	}
+	if !access.manage {
+		return policy.Deny(c, "You cannot remove documents from this record")
+	}
+
+	if err := config.DB.Delete(&attachment).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete attachment"})
+	}
+	if err := storage.Active.Delete(c.Request().Context(), attachment.StorageKey); err != nil {
+		c.Logger().Errorf("deleting attachment file %s: %v", attachment.StorageKey, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Attachment deleted successfully"})
+}
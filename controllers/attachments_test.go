@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/storage"
+)
+
+// attachmentCtx builds a multipart attachment upload from userID
+func attachmentCtx(e *echo.Echo, userID uint, role string, fields map[string]string, filename string, content []byte) (echo.Context, *httptest.ResponseRecorder) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/attachments", body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	token, _ := jwt.ParseWithClaims(createTestToken(userID, role), new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	c.Set("user", token)
+	return c, rec
+}
+
+// listAttachments lists a record's attachments as userID
+func listAttachments(t *testing.T, e *echo.Echo, userID uint, role, parentType, parentID string) (int, []models.Attachment) {
+	t.Helper()
+	c, rec := paymentCtx(e, http.MethodGet, "", userID, role, "", "")
+	c.Request().URL.RawQuery = "parent_type=" + parentType + "&parent_id=" + parentID
+	GetAttachments(c)
+	var page pagination.Page[models.Attachment]
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	return rec.Code, page.Data
+}
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+
+func TestCreateAttachment_UnknownRole(t *testing.T) {
+	c, rec := attachmentCtx(echo.New(), 1, "guest", map[string]string{"parent_type": "machine"}, "a.pdf", testPDF)
+	CreateAttachment(c)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without file:upload, got %d", rec.Code)
+	}
+}
+
+func TestMachineAttachments(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	dir := t.TempDir()
+	storage.Active = storage.NewLocal(dir, "/uploads", "secret")
+
+	e := echo.New()
+	machine, _ := seedRentableMachine(t)
+	machineID := machine.ID.String()
+
+	// The owner attaches a private invoice and a public manual
+	c1, rec1 := attachmentCtx(e, 1, "seller", map[string]string{"parent_type": "machine", "parent_id": machineID, "kind": "invoice"}, "invoice.pdf", testPDF)
+	CreateAttachment(c1)
+	if rec1.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec1.Code, rec1.Body.String())
+	}
+	var invoice models.Attachment
+	json.Unmarshal(rec1.Body.Bytes(), &invoice)
+	if invoice.Visibility != models.VisibilityPrivate || !strings.HasPrefix(invoice.URL, storage.SignedPath+storage.PrivatePrefix) || invoice.URLExpiresAt == nil {
+		t.Errorf("expected a private attachment with a presigned url, got %+v", invoice)
+	}
+
+	c2, rec2 := attachmentCtx(e, 1, "seller", map[string]string{"parent_type": "machine", "parent_id": machineID, "kind": "manual", "visibility": "public"}, "manual.pdf", testPDF)
+	CreateAttachment(c2)
+	if rec2.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+	var manual models.Attachment
+	json.Unmarshal(rec2.Body.Bytes(), &manual)
+	if !strings.HasPrefix(manual.URL, "/uploads/attachments/") {
+		t.Errorf("expected a public url, got %s", manual.URL)
+	}
+
+	// Renamed executables and other sellers are turned away
+	c3, rec3 := attachmentCtx(e, 1, "seller", map[string]string{"parent_type": "machine", "parent_id": machineID}, "invoice.pdf", []byte("MZ\x90\x00\x03"))
+	CreateAttachment(c3)
+	if rec3.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-document, got %d", rec3.Code)
+	}
+	c4, rec4 := attachmentCtx(e, 3, "seller", map[string]string{"parent_type": "machine", "parent_id": machineID}, "spam.pdf", testPDF)
+	CreateAttachment(c4)
+	if rec4.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for another seller, got %d", rec4.Code)
+	}
+
+	// The owner sees both; anyone else only the public manual
+	if code, list := listAttachments(t, e, 1, "seller", "machine", machineID); code != http.StatusOK || len(list) != 2 {
+		t.Errorf("expected the owner to see 2 attachments, got %d %d", code, len(list))
+	}
+	if code, list := listAttachments(t, e, 2, "buyer", "machine", machineID); code != http.StatusOK || len(list) != 1 || list[0].ID != manual.ID {
+		t.Errorf("expected a buyer to see only the manual, got %d %+v", code, list)
+	}
+	c5, rec5 := paymentCtx(e, http.MethodGet, "", 2, "buyer", "id", invoice.ID.String())
+	GetAttachment(c5)
+	if rec5.Code != http.StatusNotFound {
+		t.Errorf("expected a private attachment to be hidden, got %d", rec5.Code)
+	}
+
+	// Only the owner deletes, which removes the file too
+	c6, rec6 := paymentCtx(e, http.MethodDelete, "", 2, "buyer", "id", manual.ID.String())
+	DeleteAttachment(c6)
+	if rec6.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a buyer deleting, got %d", rec6.Code)
+	}
+	c7, rec7 := paymentCtx(e, http.MethodDelete, "", 1, "seller", "id", invoice.ID.String())
+	DeleteAttachment(c7)
+	if rec7.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec7.Code, rec7.Body.String())
+	}
+	if _, err := storage.Active.Get(c7.Request().Context(), invoice.StorageKey); err == nil {
+		t.Error("expected the stored file to be removed")
+	}
+}
+
+func TestRentalAttachments(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	storage.Active = storage.NewLocal(t.TempDir(), "/uploads", "secret")
+
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+	rentalID := rental.ID.String()
+
+	// The renter attaches a document; asking for public has no effect
+	c1, rec1 := attachmentCtx(e, 2, "buyer", map[string]string{"parent_type": "rental", "parent_id": rentalID, "visibility": "public"}, "po.pdf", testPDF)
+	CreateAttachment(c1)
+	if rec1.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec1.Code, rec1.Body.String())
+	}
+	var po models.Attachment
+	json.Unmarshal(rec1.Body.Bytes(), &po)
+	if po.Visibility != models.VisibilityPrivate {
+		t.Errorf("expected rental attachments to be private, got %s", po.Visibility)
+	}
+
+	if code, list := listAttachments(t, e, 1, "seller", "rental", rentalID); code != http.StatusOK || len(list) != 1 {
+		t.Errorf("expected the machine owner to see the document, got %d %d", code, len(list))
+	}
+	if code, _ := listAttachments(t, e, 9, "buyer", "rental", rentalID); code != http.StatusForbidden {
+		t.Errorf("expected 403 for an outsider, got %d", code)
+	}
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"gorm.io/gorm"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// Calendar window for the availability endpoint when to is not given;
+	// windows may span at most a year
+	defaultAvailabilityDays = 90
+)
+
+// errRentalOverlap is returned when a rental collides with an existing booking
+var errRentalOverlap = errors.New("machine is already booked for the requested dates")
+
+// DateRange is an inclusive range of calendar days
+type DateRange struct {
+	StartDate string `json:"start_date" example:"2025-01-01"`
+	EndDate   string `json:"end_date" example:"2025-01-05"`
+}
+
+// BookedRange is a date range reserved by an approved or active rental
+type BookedRange struct {
+	DateRange
+	Status string `json:"status" example:"approved"`
+}
+
+// AvailabilityResponse is the calendar view for a machine
+type AvailabilityResponse struct {
+	MachineID string        `json:"machine_id"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Booked    []BookedRange `json:"booked"`
+	Free      []DateRange   `json:"free"`
+}
+
+// GetMachineAvailability godoc
+//
+//	@Summary		Get machine availability calendar
+//	@Description	Returns booked (approved or active rentals) and free date ranges for a machine. Defaults to the next 90 days; the window may span at most a year.
+//	@Tags			Rentals
+//	@Produce		json
+//	@Param			id		path		string	true	"Machine ID"
+//	@Param			from	query		string	false	"Start of window (YYYY-MM-DD)"
+//	@Param			to		query		string	false	"End of window (YYYY-MM-DD)"
+//	@Success		200		{object}	AvailabilityResponse
+//	@Failure		400		{object}	map[string]string	"Invalid date range"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Router			/machines/{id}/availability [get]
+func GetMachineAvailability(c echo.Context) error {
+	id := c.Param("id")
+
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	}
+
+	// 1. Parse window
+	from := toDay(time.Now())
+	if v := c.QueryParam("from"); v != "" {
+		parsed, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from date format"})
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, defaultAvailabilityDays-1)
+	if v := c.QueryParam("to"); v != "" {
+		parsed, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to date format"})
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "to cannot be before from"})
+	}
+	if to.After(from.AddDate(1, 0, 0)) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Date range cannot exceed one year"})
+	}
+
+	// 2. Fetch bookings that touch the window
+	var rentals []models.Rental
+	err := config.DB.
+		Where("machine_id = ? AND status IN ?", machine.ID, models.BlockingRentalStatuses).
+		Where("start_date < ? AND end_date >= ?", to.AddDate(0, 0, 1), from).
+		Order("start_date asc").
+		Find(&rentals).Error
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch bookings"})
+	}
+
+	// 3. Build calendar
+	booked := make([]BookedRange, 0, len(rentals))
+	for _, r := range rentals {
+		booked = append(booked, BookedRange{
+			DateRange: DateRange{
+				StartDate: toDay(r.StartDate).Format(dateLayout),
+				EndDate:   toDay(r.EndDate).Format(dateLayout),
+			},
+			Status: r.Status,
+		})
+	}
+
+	return c.JSON(http.StatusOK, AvailabilityResponse{
+		MachineID: machine.ID.String(),
+		From:      from.Format(dateLayout),
+		To:        to.Format(dateLayout),
+		Booked:    booked,
+		Free:      freeRanges(from, to, rentals),
+	})
+}
+
+// freeRanges returns the gaps between bookings inside [from, to], by whole day.
+func freeRanges(from, to time.Time, rentals []models.Rental) []DateRange {
+	sorted := make([]models.Rental, len(rentals))
+	copy(sorted, rentals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartDate.Before(sorted[j].StartDate) })
+
+	free := []DateRange{}
+	cursor := from
+	for _, r := range sorted {
+		start, end := toDay(r.StartDate), toDay(r.EndDate)
+		if end.Before(cursor) {
+			continue
+		}
+		if start.After(to) {
+			break
+		}
+		if start.After(cursor) {
+			free = append(free, DateRange{
+				StartDate: cursor.Format(dateLayout),
+				EndDate:   start.AddDate(0, 0, -1).Format(dateLayout),
+			})
+		}
+		cursor = end.AddDate(0, 0, 1)
+	}
+
+	if !cursor.After(to) {
+		free = append(free, DateRange{StartDate: cursor.Format(dateLayout), EndDate: to.Format(dateLayout)})
+	}
+
+	return free
+}
+
+// findOverlappingRental returns an approved or active rental of the machine
+// whose dates intersect [start, end], ignoring the rental being checked.
+func findOverlappingRental(tx *gorm.DB, machineID uuid.UUID, start, end time.Time, excludeID uuid.UUID) (*models.Rental, error) {
+	var conflict models.Rental
+	result := tx.
+		Where("machine_id = ? AND status IN ? AND id <> ?", machineID, models.BlockingRentalStatuses, excludeID).
+		Where("start_date <= ? AND end_date >= ?", end, start).
+		Limit(1).
+		Find(&conflict)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &conflict, nil
+}
+
+// isOverlapViolation reports whether err came from the rentals_no_overlap constraint
+func isOverlapViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+// toDay truncates a timestamp to its UTC calendar day
+func toDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
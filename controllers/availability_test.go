@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse(dateLayout, s)
+	return t
+}
+
+func TestFreeRanges(t *testing.T) {
+	rentals := []models.Rental{
+		{StartDate: day("2025-01-10"), EndDate: day("2025-01-12")},
+		{StartDate: day("2025-01-03"), EndDate: day("2025-01-05")},
+		{StartDate: day("2024-12-25"), EndDate: day("2025-01-01")},
+	}
+
+	free := freeRanges(day("2025-01-01"), day("2025-01-15"), rentals)
+
+	expected := []DateRange{
+		{StartDate: "2025-01-02", EndDate: "2025-01-02"},
+		{StartDate: "2025-01-06", EndDate: "2025-01-09"},
+		{StartDate: "2025-01-13", EndDate: "2025-01-15"},
+	}
+	if len(free) != len(expected) {
+		t.Fatalf("expected %d free ranges, got %d: %+v", len(expected), len(free), free)
+	}
+	for i := range expected {
+		if free[i] != expected[i] {
+			t.Errorf("range %d: expected %+v, got %+v", i, expected[i], free[i])
+		}
+	}
+}
+
+func TestFreeRanges_FullyBooked(t *testing.T) {
+	rentals := []models.Rental{
+		{StartDate: day("2024-12-01"), EndDate: day("2025-02-01")},
+	}
+
+	free := freeRanges(day("2025-01-01"), day("2025-01-15"), rentals)
+	if len(free) != 0 {
+		t.Errorf("expected no free ranges, got %+v", free)
+	}
+}
+
+func TestGetMachineAvailability(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+
+	approved := models.Rental{
+		MachineID: machine.ID, RenterID: 2,
+		StartDate: day("2025-03-05"), EndDate: day("2025-03-07"),
+		TotalAmount: 3000, SecurityDeposit: 5000, Status: "approved",
+	}
+	pending := models.Rental{
+		MachineID: machine.ID, RenterID: 3,
+		StartDate: day("2025-03-10"), EndDate: day("2025-03-12"),
+		TotalAmount: 3000, SecurityDeposit: 5000, Status: "pending",
+	}
+	db.Create(&approved)
+	db.Create(&pending)
+
+	req := httptest.NewRequest(http.MethodGet, "/?from=2025-03-01&to=2025-03-15", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/machines/:id/availability")
+	c.SetParamNames("id")
+	c.SetParamValues(machine.ID.String())
+
+	if err := GetMachineAvailability(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var resp AvailabilityResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	// Pending requests do not block the calendar
+	if len(resp.Booked) != 1 {
+		t.Fatalf("expected 1 booked range, got %d", len(resp.Booked))
+	}
+	if len(resp.Free) != 2 {
+		t.Errorf("expected 2 free ranges, got %d", len(resp.Free))
+	}
+}
+
+func TestGetMachineAvailability_InvalidRange(t *testing.T) {
+	e := echo.New()
+	machine, _ := seedRentableMachine(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{"Inverted", "from=2025-03-15&to=2025-03-01", http.StatusBadRequest},
+		{"One Year", "from=2025-03-01&to=2026-03-01", http.StatusOK},
+		{"One Year Over A Leap Day", "from=2024-01-01&to=2025-01-01", http.StatusOK},
+		{"Over A Year", "from=2025-03-01&to=2026-03-02", http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/machines/:id/availability")
+			c.SetParamNames("id")
+			c.SetParamValues(machine.ID.String())
+
+			GetMachineAvailability(c)
+			if rec.Code != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/specs"
+	"github.com/vishwakarma-setu-backend/validation"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var errUnknownCategory = errors.New("unknown category")
+
+// ValidationErrorResponse reports field-level validation problems
+type ValidationErrorResponse struct {
+	Error  string            `json:"error" example:"Invalid specs"`
+	Fields map[string]string `json:"fields"`
+}
+
+// GetCategories godoc
+//
+//	@Summary		List machine categories
+//	@Description	List the active machine categories with their spec fields, by name, a page at a time.
+//	@Tags			Categories
+//	@Produce		json
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.Category]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/categories [get]
+func GetCategories(c echo.Context) error {
+	query := config.DB.Where("active = ?", true)
+	return respondPage(c, query, categoryOrder, "Failed to fetch categories")
+}
+
+// categoryOrder lists categories by name
+var categoryOrder = pagination.Order[models.Category]{
+	{Expr: "name", Type: "text", Value: func(cat *models.Category) interface{} { return cat.Name }},
+	{Expr: "id", Type: "uuid", Value: func(cat *models.Category) interface{} { return cat.ID }},
+}
+
+// GetCategorySchema godoc
+//
+//	@Summary		Get a category's spec schema
+//	@Description	Retrieve the spec fields (types, units, allowed values and whether they are required) that listings in this category must provide. Used to render listing forms.
+//	@Tags			Categories
+//	@Produce		json
+//	@Param			slug	path		string	true	"Category slug"
+//	@Success		200		{object}	specs.Schema
+//	@Failure		404		{object}	map[string]string	"Category not found"
+//	@Router			/categories/{slug}/schema [get]
+func GetCategorySchema(c echo.Context) error {
+	var category models.Category
+	if err := config.DB.Where("slug = ? AND active = ?", c.Param("slug"), true).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	}
+
+	return c.JSON(http.StatusOK, category.Schema())
+}
+
+// CreateCategory godoc
+//
+//	@Summary		Create a machine category
+//	@Description	Register a category and its spec fields. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			category	body		models.Category			true	"Category"
+//	@Success		201			{object}	models.Category
+//	@Failure		400			{object}	ValidationErrorResponse	"Invalid schema"
+//	@Failure		403			{object}	map[string]string		"Admins only"
+//	@Failure		409			{object}	map[string]string		"Slug or name taken"
+//	@Router			/admin/categories [post]
+func CreateCategory(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.CategoryManage) {
+		return policy.Forbid(c, policy.CategoryManage, "Only admins can manage categories")
+	}
+
+	category := models.Category{Active: true}
+	if err := c.Bind(&category); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if err := specs.ValidateSchema(category.Schema()); err != nil {
+		return validationError(c, "Invalid category", err)
+	}
+
+	if categoryTaken(&category) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A category with this slug or name already exists"})
+	}
+
+	if err := config.DB.Create(&category).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create category"})
+	}
+
+	return c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+//
+//	@Summary		Update a machine category
+//	@Description	Replace a category's name, description, spec fields and active flag. The slug cannot change; renaming also renames the category on existing listings and fee rules. Existing listings are validated against the new fields the next time they are edited. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			slug		path		string					true	"Category slug"
+//	@Param			category	body		models.Category			true	"Category"
+//	@Success		200			{object}	models.Category
+//	@Failure		400			{object}	ValidationErrorResponse	"Invalid schema"
+//	@Failure		403			{object}	map[string]string		"Admins only"
+//	@Failure		404			{object}	map[string]string		"Category not found"
+//	@Router			/admin/categories/{slug} [put]
+func UpdateCategory(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.CategoryManage) {
+		return policy.Forbid(c, policy.CategoryManage, "Only admins can manage categories")
+	}
+
+	var category models.Category
+	if err := config.DB.Where("slug = ?", c.Param("slug")).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	}
+
+	var updateData models.Category
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	previousName := category.Name
+	category.Name = updateData.Name
+	category.Description = updateData.Description
+	category.Fields = updateData.Fields
+	category.Active = updateData.Active
+
+	if err := specs.ValidateSchema(category.Schema()); err != nil {
+		return validationError(c, "Invalid category", err)
+	}
+	if categoryTaken(&category) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A category with this name already exists"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Renaming carries existing listings and fee rules along
+		if category.Name != previousName {
+			if err := tx.Model(&models.Machine{}).Where("category = ?", previousName).Update("category", category.Name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.PlatformFeeRule{}).Where("category = ?", previousName).Update("category", category.Name).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(&category).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update category"})
+	}
+
+	return c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+//
+//	@Summary		Delete a machine category
+//	@Description	Remove a category from the registry. Existing listings keep their category name but their specs are no longer validated or filterable. Admin only.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			slug	path		string				true	"Category slug"
+//	@Success		200		{object}	map[string]string	"Success"
+//	@Failure		403		{object}	map[string]string	"Admins only"
+//	@Failure		404		{object}	map[string]string	"Category not found"
+//	@Router			/admin/categories/{slug} [delete]
+func DeleteCategory(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.CategoryManage) {
+		return policy.Forbid(c, policy.CategoryManage, "Only admins can manage categories")
+	}
+
+	var category models.Category
+	if err := config.DB.Where("slug = ?", c.Param("slug")).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	}
+
+	if err := config.DB.Delete(&category).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete category"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+}
+
+// categoryTaken reports whether another category already uses the slug or name
+func categoryTaken(category *models.Category) bool {
+	var count int64
+	config.DB.Model(&models.Category{}).
+		Where("(slug = ? OR LOWER(name) = LOWER(?)) AND id <> ?", category.Slug, category.Name, category.ID).
+		Count(&count)
+	return count > 0
+}
+
+// categorySchema looks up an active category by name or slug
+func categorySchema(category string) (*specs.Schema, error) {
+	var found models.Category
+	err := config.DB.Where("active = ? AND (LOWER(name) = LOWER(?) OR slug = ?)", true, category, strings.ToLower(category)).
+		First(&found).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errUnknownCategory
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found.Schema(), nil
+}
+
+// validateMachineSpecs checks a machine's specs against its category's
+// schema and stores them normalised. previous is the category the machine
+// had before (empty for a new one). Machines without a category are not
+// checked, nor are machines keeping a category that has since been deleted
+// or deactivated.
+func validateMachineSpecs(machine *models.Machine, previous string) error {
+	if machine.Category == "" {
+		return nil
+	}
+
+	schema, err := categorySchema(machine.Category)
+	if errors.Is(err, errUnknownCategory) {
+		if previous != "" && strings.EqualFold(machine.Category, previous) {
+			return nil
+		}
+		return validation.Field("specs", "category", "is not a registered category")
+	}
+	if err != nil {
+		return err
+	}
+	// Store the registered spelling so exact category filters match
+	machine.Category = schema.Name
+
+	values := map[string]interface{}{}
+	if len(machine.Specs) > 0 && string(machine.Specs) != "null" {
+		if err := json.Unmarshal(machine.Specs, &values); err != nil {
+			return validation.Field("specs", "specs", "must be a JSON object")
+		}
+	}
+
+	clean, err := schema.Validate(values)
+	if err != nil {
+		return err
+	}
+
+	normalised, _ := json.Marshal(clean)
+	machine.Specs = datatypes.JSON(normalised)
+	return nil
+}
+
+// validationError responds with field-level errors, or 500 for anything else
+func validationError(c echo.Context, msg string, err error) error {
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse{Error: msg, Fields: verr.Fields})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to validate: " + err.Error()})
+}
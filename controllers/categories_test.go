@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/specs"
+	"gorm.io/gorm"
+)
+
+// seedCategory registers the CNC Mill category used by spec tests
+func seedCategory(t *testing.T, db *gorm.DB) models.Category {
+	t.Helper()
+	category := models.Category{
+		Slug:   "cnc-mill",
+		Name:   "CNC Mill",
+		Active: true,
+		Fields: specs.Fields{
+			{Key: "axes", Label: "Axes", Type: specs.TypeInteger, Required: true},
+			{Key: "spindle_speed_rpm", Label: "Spindle speed", Type: specs.TypeNumber, Unit: "rpm", Required: true},
+			{Key: "control", Label: "Control", Type: specs.TypeEnum, Values: []string{"Fanuc", "Siemens", "Haas"}},
+		},
+	}
+	if err := db.Create(&category).Error; err != nil {
+		t.Fatalf("failed to seed category: %v", err)
+	}
+	return category
+}
+
+func TestGetCategorySchema(t *testing.T) {
+	e := echo.New()
+	db := setupTestDB(t, nil)
+	seedCategory(t, db)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("slug")
+	c.SetParamValues("cnc-mill")
+
+	if err := GetCategorySchema(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var schema specs.Schema
+	json.Unmarshal(rec.Body.Bytes(), &schema)
+	if schema.Name != "CNC Mill" || len(schema.Fields) != 3 || schema.Fields[1].Unit != "rpm" {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+}
+
+func TestCreateListing_ValidatesSpecs(t *testing.T) {
+	e := echo.New()
+	db := setupTestDB(t, nil)
+	seedCategory(t, db)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		fieldErrors  []string
+	}{
+		{
+			"Valid Specs Are Normalised",
+			`{"title":"VMC","description":"5 axis","category":"cnc mill","specs":{"axes":5,"spindle_speed_rpm":12000,"control":"fanuc"}}`,
+			http.StatusCreated, nil,
+		},
+		{
+			"Field Errors",
+			`{"title":"VMC","description":"5 axis","category":"CNC Mill","specs":{"axes":"five","RPM":12000}}`,
+			http.StatusBadRequest, []string{"axes", "RPM", "spindle_speed_rpm"},
+		},
+		{
+			"Unregistered Category",
+			`{"title":"VMC","description":"5 axis","category":"Spaceship","specs":{}}`,
+			http.StatusBadRequest, []string{"category"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := paymentCtx(e, http.MethodPost, tc.body, 1, "seller", "", "")
+			CreateListing(c)
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, rec.Code, rec.Body.String())
+			}
+
+			if tc.expectedCode == http.StatusCreated {
+				var machine models.Machine
+				json.Unmarshal(rec.Body.Bytes(), &machine)
+				var stored map[string]interface{}
+				json.Unmarshal(machine.Specs, &stored)
+				if machine.Category != "CNC Mill" || stored["control"] != "Fanuc" {
+					t.Errorf("expected normalised category and specs, got %s %v", machine.Category, stored)
+				}
+				return
+			}
+
+			var resp ValidationErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			for _, field := range tc.fieldErrors {
+				if _, ok := resp.Fields[field]; !ok {
+					t.Errorf("expected an error for %s, got %v", field, resp.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCategory(t *testing.T) {
+	e := echo.New()
+	db := setupTestDB(t, nil)
+	seedCategory(t, db)
+
+	body := `{"slug":"edm","name":"EDM","fields":[{"key":"type","label":"Type","type":"enum","values":["Wire","Sinker"],"required":true}]}`
+
+	c1, rec1 := paymentCtx(e, http.MethodPost, body, 1, "seller", "", "")
+	CreateCategory(c1)
+	if rec1.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin, got %d", rec1.Code)
+	}
+
+	c2, rec2 := paymentCtx(e, http.MethodPost, body, 99, "admin", "", "")
+	CreateCategory(c2)
+	if rec2.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+
+	dup := `{"slug":"cnc-mill-2","name":"cnc mill","fields":[]}`
+	c3, rec3 := paymentCtx(e, http.MethodPost, dup, 99, "admin", "", "")
+	CreateCategory(c3)
+	if rec3.Code != http.StatusConflict {
+		t.Errorf("expected 409 for duplicate name, got %d", rec3.Code)
+	}
+
+	bad := `{"slug":"Bad Slug","name":"Bad","fields":[{"key":"x","type":"enum"}]}`
+	c4, rec4 := paymentCtx(e, http.MethodPost, bad, 99, "admin", "", "")
+	CreateCategory(c4)
+	if rec4.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid schema, got %d", rec4.Code)
+	}
+
+	// A deleted category's slug and name can be used again
+	db.Where("slug = ?", "edm").Delete(&models.Category{})
+	c5, rec5 := paymentCtx(e, http.MethodPost, body, 99, "admin", "", "")
+	CreateCategory(c5)
+	if rec5.Code != http.StatusCreated {
+		t.Errorf("expected 201 reusing a deleted category's slug, got %d: %s", rec5.Code, rec5.Body.String())
+	}
+}
+
+func TestUpdateListing_RetiredCategory(t *testing.T) {
+	e := echo.New()
+	db := setupTestDB(t, []models.Machine{{Title: "Lathe", SellerID: 1, Category: "Retired Lathe"}})
+	seedCategory(t, db)
+	var m models.Machine
+	db.First(&m)
+
+	// A listing keeps a category that is no longer registered
+	c, rec := paymentCtx(e, http.MethodPut, `{"title":"Lathe, serviced","category":"Retired Lathe"}`, 1, "seller", "id", m.ID.String())
+	UpdateListing(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 when the category is unchanged, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// but cannot move to one
+	c, rec = paymentCtx(e, http.MethodPut, `{"title":"Lathe","category":"Spaceship"}`, 1, "seller", "id", m.ID.String())
+	UpdateListing(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unregistered category, got %d", rec.Code)
+	}
+}
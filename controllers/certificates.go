@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/certificate"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/imaging"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/gorm"
+)
+
+// certificatePhotoEdge is the longest edge photos are embedded at, plenty
+// for a third of an A4 page
+const certificatePhotoEdge = 800
+
+// CertificateVerification is what the verification endpoint reports about
+// a certificate
+type CertificateVerification struct {
+	// Valid is false if the report no longer matches its signature;
+	// Problem then says why
+	Valid          bool      `json:"valid" example:"true"`
+	Problem        string    `json:"problem,omitempty" example:"content changed after signing"`
+	Code           string    `json:"code" example:"7KQ2-M9XD-4TRA"`
+	ReportID       uuid.UUID `json:"report_id"`
+	MachineID      uuid.UUID `json:"machine_id"`
+	MachineTitle   string    `json:"machine_title" example:"Haas VF-2 CNC Mill"`
+	Category       string    `json:"category" example:"CNC Mill"`
+	InspectionDate time.Time `json:"inspection_date"`
+	InspectorID    uint      `json:"inspector_id"`
+	Verdict        string    `json:"verdict" example:"Pass"`
+	ConditionScore *float64  `json:"condition_score,omitempty" example:"87.5"`
+	ConditionGrade string    `json:"condition_grade,omitempty" example:"B"`
+	// Current is false once the machine has been inspected again; the
+	// newer report supersedes this certificate
+	Current       bool   `json:"current" example:"true"`
+	MachineStatus string `json:"machine_status" example:"verified"`
+}
+
+// GetInspectionCertificate godoc
+//
+//	@Summary		Download a machine's inspection certificate
+//	@Description	Render the latest listing inspection report of a verified machine as a PDF: machine details, inspector, date, verdict, condition grade, summary, checklist results and up to six photos. The certificate carries a verification code and a QR code linking to GET /certificates/{code}, so anyone holding a copy can check it is genuine and current. The code is assigned on the first download and stays the same for the report.
+//	@Tags			Inspection
+//	@Produce		application/pdf
+//	@Param			machine_id	path		string	true	"Machine UUID"
+//	@Success		200			{file}		binary
+//	@Failure		404			{object}	map[string]string	"Machine not found, not verified, never inspected, or its latest inspection failed"
+//	@Router			/machines/{machine_id}/inspection/certificate.pdf [get]
+func GetInspectionCertificate(c echo.Context) error {
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", c.Param("machine_id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	}
+	if machine.Status == "pending_inspection" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine has not passed inspection"})
+	}
+
+	report, err := latestListingReport(config.DB, machine.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No inspection report found for this machine"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch inspection report"})
+	}
+	if !inspectionPassed(report) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine has not passed inspection"})
+	}
+
+	code, err := verificationCode(report)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to issue certificate"})
+	}
+
+	cert := &certificate.Certificate{
+		Code:      code,
+		VerifyURL: verifyURL(c, code),
+		IssuedAt:  time.Now(),
+		Machine:   machine,
+		Report:    *report,
+		Photos:    certificatePhotos(c, report),
+	}
+	if report.TemplateID != nil {
+		var template models.InspectionTemplate
+		if err := config.DB.First(&template, "id = ?", report.TemplateID).Error; err == nil {
+			cert.Template = &template
+		}
+	}
+
+	pdf, err := certificate.Render(cert)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to render certificate"})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="inspection-certificate-`+code+`.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", pdf)
+}
+
+// VerifyCertificate godoc
+//
+//	@Summary		Verify an inspection certificate
+//	@Description	Look up the inspection report behind a certificate's verification code, as printed on it or encoded in its QR code. Case, dashes and spaces do not matter. valid is false, with the problem, if the report no longer matches its signature (GET /inspections/{id}/verify checks its earlier revisions too). current is false once the machine has been inspected again.
+//	@Tags			Inspection
+//	@Produce		json
+//	@Param			code	path		string	true	"Verification code, e.g. 7KQ2-M9XD-4TRA"
+//	@Success		200		{object}	CertificateVerification
+//	@Failure		404		{object}	map[string]string	"No certificate with this code"
+//	@Router			/certificates/{code} [get]
+func VerifyCertificate(c echo.Context) error {
+	code, err := certificate.NormalizeCode(c.Param("code"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No certificate with this code"})
+	}
+
+	var report models.InspectionReport
+	if err := config.DB.First(&report, "verification_code = ?", code).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No certificate with this code"})
+	}
+	var machine models.Machine
+	if err := config.DB.Unscoped().First(&machine, "id = ?", report.MachineID).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch machine"})
+	}
+
+	latest, err := latestListingReport(config.DB, machine.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch inspection report"})
+	}
+
+	problem := checkRevision(&report)
+	return c.JSON(http.StatusOK, CertificateVerification{
+		Valid:          problem == "",
+		Problem:        problem,
+		Code:           code,
+		ReportID:       report.ID,
+		MachineID:      machine.ID,
+		MachineTitle:   machine.Title,
+		Category:       machine.Category,
+		InspectionDate: report.InspectionDate,
+		InspectorID:    report.InspectorID,
+		Verdict:        report.Verdict,
+		ConditionScore: report.ConditionScore,
+		ConditionGrade: report.ConditionGrade,
+		Current:        latest.ID == report.ID && !machine.DeletedAt.Valid,
+		MachineStatus:  machine.Status,
+	})
+}
+
+// latestListingReport is the machine's most recent report that is not a
+// rental check-out or check-in
+func latestListingReport(db *gorm.DB, machineID uuid.UUID) (*models.InspectionReport, error) {
+	var report models.InspectionReport
+	err := db.Where("machine_id = ? AND rental_id IS NULL", machineID).Order("created_at desc").First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// verificationCode returns the report's code, assigning one the first time
+func verificationCode(report *models.InspectionReport) (string, error) {
+	if report.VerificationCode != nil {
+		return *report.VerificationCode, nil
+	}
+	code := certificate.NewCode()
+	result := config.DB.Model(&models.InspectionReport{}).
+		Where("id = ? AND verification_code IS NULL", report.ID).
+		Update("verification_code", code)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		// A concurrent download assigned one first
+		var current models.InspectionReport
+		if err := config.DB.Select("verification_code").First(&current, "id = ?", report.ID).Error; err != nil {
+			return "", err
+		}
+		if current.VerificationCode == nil {
+			return "", errors.New("verification code not assigned")
+		}
+		code = *current.VerificationCode
+	}
+	report.VerificationCode = &code
+	return code, nil
+}
+
+// verifyURL is the address the certificate's QR code points at
+func verifyURL(c echo.Context, code string) string {
+	if certificate.VerifyURL != "" {
+		return certificate.VerifyURL + code
+	}
+	return c.Scheme() + "://" + c.Request().Host + "/api/certificates/" + code
+}
+
+// certificatePhotos loads the report's first photos from storage. Media
+// that is not an uploaded image, or that fails to load, is left out
+// rather than failing the certificate.
+func certificatePhotos(c echo.Context, report *models.InspectionReport) []image.Image {
+	var urls []string
+	json.Unmarshal(report.MediaURLs, &urls)
+
+	var photos []image.Image
+	for _, url := range urls {
+		if len(photos) == certificate.MaxPhotos {
+			break
+		}
+		upload, err := files.Find(config.DB, url)
+		if err != nil {
+			continue
+		}
+		file, ok := files.Variant(upload, "medium")
+		if !ok {
+			if file, ok = files.Variant(upload, "full"); !ok {
+				continue
+			}
+		}
+		rc, err := storage.Active.Get(c.Request().Context(), file.Key)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxImageSize+1))
+		rc.Close()
+		if err != nil || len(data) > maxImageSize {
+			continue
+		}
+		src, err := imaging.Decode(data)
+		if err != nil {
+			continue
+		}
+		photos = append(photos, src.Scaled(certificatePhotoEdge))
+	}
+	return photos
+}
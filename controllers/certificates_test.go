@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestInspectionCertificate(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	db.Model(&machine).Update("status", "pending_inspection")
+
+	download := func() (int, string, []byte) {
+		c, rec := paymentCtx(e, http.MethodGet, "", 0, "", "machine_id", machine.ID.String())
+		if err := GetInspectionCertificate(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		return rec.Code, rec.Header().Get(echo.HeaderContentDisposition), rec.Body.Bytes()
+	}
+	verify := func(code string) (int, CertificateVerification) {
+		c, rec := paymentCtx(e, http.MethodGet, "", 0, "", "code", code)
+		if err := VerifyCertificate(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var v CertificateVerification
+		json.Unmarshal(rec.Body.Bytes(), &v)
+		return rec.Code, v
+	}
+
+	// 1. No certificate until the machine has passed inspection
+	report := models.InspectionReport{
+		ID:             uuid.New(),
+		MachineID:      machine.ID,
+		InspectorID:    3,
+		ReportType:     "listing",
+		InspectionDate: time.Now().Truncate(time.Microsecond),
+		Verdict:        "Pass",
+		Summary:        "Well maintained, full service history",
+		ReportData:     []byte(`{"spindle_noise":"pass"}`),
+		MediaURLs:      []byte(`["https://elsewhere.example/photo.jpg"]`),
+		Revision:       1,
+	}
+	signReport(&report)
+	db.Create(&report)
+	if code, _, _ := download(); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unverified machine, got %d", code)
+	}
+
+	// 2. Verified machines get a PDF; the code is assigned once
+	db.Model(&machine).Update("status", "verified")
+	code, disposition, body := download()
+	if code != http.StatusOK || !bytes.HasPrefix(body, []byte("%PDF-")) {
+		t.Fatalf("expected a PDF, got %d %.100s", code, body)
+	}
+	db.First(&report, "id = ?", report.ID)
+	if report.VerificationCode == nil || !strings.Contains(disposition, *report.VerificationCode) {
+		t.Fatalf("expected the code in %q, got %v", disposition, report.VerificationCode)
+	}
+	issued := *report.VerificationCode
+	if _, again, _ := download(); !strings.Contains(again, issued) {
+		t.Errorf("expected the same code on a second download, got %q", again)
+	}
+
+	// 3. The code verifies however it is typed
+	status, v := verify(strings.ToLower(strings.ReplaceAll(issued, "-", "")))
+	if status != http.StatusOK || !v.Valid || !v.Current || v.ReportID != report.ID || v.Verdict != "Pass" {
+		t.Fatalf("unexpected verification %d %+v", status, v)
+	}
+	if v.Problem != "" {
+		t.Errorf("expected no problem with a signed report, got %q", v.Problem)
+	}
+	if status, _ := verify("0000-0000-0000"); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown code, got %d", status)
+	}
+	if status, _ := verify("not-a-code"); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a malformed code, got %d", status)
+	}
+
+	// 4. A newer inspection supersedes the certificate; rental check-ins
+	// do not
+	rental := models.Rental{MachineID: machine.ID, RenterID: 2, Status: "active"}
+	db.Create(&rental)
+	db.Create(&models.InspectionReport{MachineID: machine.ID, InspectorID: 3, ReportType: "check_in", RentalID: &rental.ID, Verdict: "Pass"})
+	if _, v := verify(issued); !v.Current {
+		t.Error("expected a check-in report not to supersede the certificate")
+	}
+	db.Create(&models.InspectionReport{MachineID: machine.ID, InspectorID: 3, ReportType: "listing", Verdict: "Pass"})
+	if _, v := verify(issued); !v.Valid || v.Current {
+		t.Errorf("expected a superseded certificate, got %+v", v)
+	}
+
+	// 5. No certificate is issued for a failed inspection, whatever the
+	// machine's status says
+	db.Create(&models.InspectionReport{MachineID: machine.ID, InspectorID: 3, ReportType: "listing", Verdict: "Fail"})
+	if code, _, _ := download(); code != http.StatusNotFound {
+		t.Errorf("expected 404 after a failed inspection, got %d", code)
+	}
+
+	// 6. A report changed behind the signature no longer verifies
+	db.Model(&report).Update("summary", "Brand new")
+	if _, v := verify(issued); v.Valid || v.Problem != "content changed after signing" {
+		t.Errorf("expected a tampered report to be invalid, got %+v", v)
+	}
+}
@@ -0,0 +1,519 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/payments"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errDepositSettled    = errors.New("deposit has already been settled")
+	errClaimOpen         = errors.New("damage claim is still awaiting a decision")
+	errInvalidClaimState = errors.New("claim is not in a state that allows this action")
+)
+
+// DamageClaimItemRequest is one itemised deduction
+type DamageClaimItemRequest struct {
+	Description  string   `json:"description" example:"Cracked spindle housing"`
+	Amount       float64  `json:"amount" example:"4500"`
+	EvidenceURLs []string `json:"evidence_urls"`
+}
+
+// DamageClaimRequest is the payload for filing a claim against a deposit
+type DamageClaimRequest struct {
+	CheckOutReportID string                   `json:"check_out_report_id"`
+	CheckInReportID  string                   `json:"check_in_report_id"`
+	Notes            string                   `json:"notes"`
+	Items            []DamageClaimItemRequest `json:"items"`
+}
+
+// ClaimResponseRequest is the renter's answer to a claim
+type ClaimResponseRequest struct {
+	Action string `json:"action" example:"accept"` // accept, dispute
+	Reason string `json:"reason"`
+}
+
+// ClaimResolutionRequest is an admin's decision on a disputed claim
+type ClaimResolutionRequest struct {
+	ApprovedAmount float64 `json:"approved_amount" example:"3000"`
+	Notes          string  `json:"notes"`
+}
+
+// CreateDamageClaim godoc
+//
+//	@Summary		File a damage claim
+//	@Description	File an itemised claim against the security deposit of a completed rental, backed by its check-out and check-in inspection reports. The total may not exceed the deposit. Machine owner only; one claim per rental.
+//	@Tags			Claims
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Rental ID"
+//	@Param			claim	body		DamageClaimRequest	true	"Claim"
+//	@Success		201		{object}	models.DamageClaim
+//	@Failure		400		{object}	map[string]string	"Invalid claim"
+//	@Failure		403		{object}	map[string]string	"Not the owner"
+//	@Failure		404		{object}	map[string]string	"Rental not found"
+//	@Failure		409		{object}	map[string]string	"Rental not completed, deposit settled or claim exists"
+//	@Router			/rentals/{id}/claims [post]
+func CreateDamageClaim(c echo.Context) error {
+	var req DamageClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if !user.Owns(machineOwner(&rental.Machine)) || !user.Can(policy.ClaimCreate) {
+		return policy.Forbid(c, policy.ClaimCreate, "Only the machine owner can file a claim")
+	}
+	if rental.Status != models.RentalCompleted {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Claims can only be filed on completed rentals"})
+	}
+	if rental.DepositSettledAt != nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": errDepositSettled.Error()})
+	}
+
+	if len(req.Items) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "At least one deduction is required"})
+	}
+	total := 0.0
+	for _, item := range req.Items {
+		if item.Description == "" || item.Amount <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Each deduction needs a description and a positive amount"})
+		}
+		total += item.Amount
+	}
+	total = math.Round(total*100) / 100
+	if total > rental.SecurityDeposit {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Deductions exceed the security deposit"})
+	}
+
+	checkOut, err := rentalInspection(&rental, req.CheckOutReportID, "check_out")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	checkIn, err := rentalInspection(&rental, req.CheckInReportID, "check_in")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if checkIn.InspectionDate.Before(checkOut.InspectionDate) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Check-in inspection predates check-out inspection"})
+	}
+
+	claim := models.DamageClaim{
+		RentalID:         rental.ID,
+		ClaimantID:       user.ID,
+		CheckOutReportID: checkOut.ID,
+		CheckInReportID:  checkIn.ID,
+		Notes:            req.Notes,
+		Status:           models.ClaimSubmitted,
+		DepositAmount:    rental.SecurityDeposit,
+		ClaimedAmount:    total,
+	}
+	for _, item := range req.Items {
+		evidence, _ := json.Marshal(item.EvidenceURLs)
+		claim.Items = append(claim.Items, models.DamageClaimItem{
+			Description:  item.Description,
+			Amount:       item.Amount,
+			EvidenceURLs: datatypes.JSON(evidence),
+		})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.DamageClaim{}).Where("rental_id = ?", rental.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errClaimOpen
+		}
+		if err := tx.Create(&claim).Error; err != nil {
+			return err
+		}
+		// Evidence may come from either party, so it is not checked
+		// against the claimant's uploads, only kept from being swept
+		var evidence []string
+		for _, item := range req.Items {
+			evidence = append(evidence, item.EvidenceURLs...)
+		}
+		return files.SetReferences(tx, files.Ref("claim", claim.ID), evidence)
+	})
+	if errors.Is(err, errClaimOpen) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A claim has already been filed for this rental"})
+	}
+	if msg, ok := uploadError(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save claim"})
+	}
+
+	return c.JSON(http.StatusCreated, claim)
+}
+
+// GetRentalClaims godoc
+//
+//	@Summary		List damage claims for a rental
+//	@Description	Retrieve the damage claims filed against a rental's deposit, with their deductions, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them.
+//	@Tags			Claims
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		string	true	"Rental ID"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.DamageClaim]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Failure		403				{object}	map[string]string	"Not authorized"
+//	@Failure		404				{object}	map[string]string	"Rental not found"
+//	@Router			/rentals/{id}/claims [get]
+func GetRentalClaims(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if len(rentalParties(&rental, user)) == 0 {
+		return policy.Deny(c, "You are not a party to this rental")
+	}
+
+	query := config.DB.Preload("Items").Where("rental_id = ?", rental.ID)
+	return respondPage(c, query, claimOrder, "Failed to fetch claims")
+}
+
+// claimOrder lists claims oldest first
+var claimOrder = pagination.Order[models.DamageClaim]{
+	{Expr: "created_at", Type: "timestamptz", Value: func(cl *models.DamageClaim) interface{} { return cl.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(cl *models.DamageClaim) interface{} { return cl.ID }},
+}
+
+// RespondToClaim godoc
+//
+//	@Summary		Accept or dispute a damage claim
+//	@Description	The renter accepts a submitted claim, which settles the deposit (deductions are captured and the rest released), or disputes it with a reason for an admin to resolve.
+//	@Tags			Claims
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string					true	"Claim ID"
+//	@Param			response	body		ClaimResponseRequest	true	"Response"
+//	@Success		200			{object}	models.DamageClaim
+//	@Failure		400			{object}	map[string]string	"Invalid action"
+//	@Failure		403			{object}	map[string]string	"Not the renter"
+//	@Failure		409			{object}	map[string]string	"Claim is not awaiting a response"
+//	@Failure		502			{object}	map[string]string	"Deposit settlement failed"
+//	@Router			/claims/{id}/respond [put]
+func RespondToClaim(c echo.Context) error {
+	var req ClaimResponseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if req.Action != "accept" && req.Action != "dispute" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Action must be accept or dispute"})
+	}
+	if req.Action == "dispute" && req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A reason is required to dispute a claim"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	claim, rental, err := loadClaim(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Claim not found"})
+	}
+	if !user.Owns(rentalRenter(rental)) {
+		return policy.Deny(c, "Only the renter can respond to this claim")
+	}
+
+	err = decideClaim(claim, models.ClaimSubmitted, func(current *models.DamageClaim) {
+		current.RenterResponse = req.Reason
+		if req.Action == "dispute" {
+			current.Status = models.ClaimDisputed
+			return
+		}
+		current.Status = models.ClaimAccepted
+		current.ApprovedAmount = current.ClaimedAmount
+	})
+	if err != nil {
+		return claimError(c, err)
+	}
+
+	if claim.Status == models.ClaimAccepted {
+		if err := settleDeposit(c.Request().Context(), rental, claim); err != nil {
+			return claimError(c, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// ResolveClaim godoc
+//
+//	@Summary		Resolve a disputed damage claim
+//	@Description	An admin decides how much of a disputed claim is deducted (0 up to the claimed amount) and settles the deposit.
+//	@Tags			Claims
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string					true	"Claim ID"
+//	@Param			resolution	body		ClaimResolutionRequest	true	"Decision"
+//	@Success		200			{object}	models.DamageClaim
+//	@Failure		400			{object}	map[string]string	"Invalid amount"
+//	@Failure		403			{object}	map[string]string	"Admins only"
+//	@Failure		409			{object}	map[string]string	"Claim is not disputed"
+//	@Failure		502			{object}	map[string]string	"Deposit settlement failed"
+//	@Router			/claims/{id}/resolve [put]
+func ResolveClaim(c echo.Context) error {
+	var req ClaimResolutionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.ClaimResolve) {
+		return policy.Forbid(c, policy.ClaimResolve, "Only admins can resolve claims")
+	}
+
+	claim, rental, err := loadClaim(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Claim not found"})
+	}
+	if req.ApprovedAmount < 0 || req.ApprovedAmount > claim.ClaimedAmount {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Approved amount must be between 0 and the claimed amount"})
+	}
+
+	err = decideClaim(claim, models.ClaimDisputed, func(current *models.DamageClaim) {
+		current.Status = models.ClaimResolved
+		current.ApprovedAmount = math.Round(req.ApprovedAmount*100) / 100
+		current.ResolutionNotes = req.Notes
+		current.ResolvedBy = &user.ID
+	})
+	if err != nil {
+		return claimError(c, err)
+	}
+
+	if err := settleDeposit(c.Request().Context(), rental, claim); err != nil {
+		return claimError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// SettleRentalDeposit godoc
+//
+//	@Summary		Settle a rental's security deposit
+//	@Description	Settle the deposit of a completed rental. Without a claim the deposit is returned in full; with an accepted or resolved claim the approved deductions are kept (use this to retry a failed settlement). Machine owner or admin.
+//	@Tags			Claims
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Rental ID"
+//	@Success		200	{object}	models.Rental
+//	@Failure		403	{object}	map[string]string	"Not authorized"
+//	@Failure		404	{object}	map[string]string	"Rental not found"
+//	@Failure		409	{object}	map[string]string	"Rental not completed, claim undecided or already settled"
+//	@Failure		502	{object}	map[string]string	"Deposit settlement failed"
+//	@Router			/rentals/{id}/deposit/settle [post]
+func SettleRentalDeposit(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if !user.Allows(policy.DepositSettle, machineOwner(&rental.Machine)) {
+		return policy.Forbid(c, policy.DepositSettle, "Only the machine owner or an admin can settle the deposit")
+	}
+	if rental.Status != models.RentalCompleted {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Deposits are settled after the rental is completed"})
+	}
+
+	var claim *models.DamageClaim
+	var existing models.DamageClaim
+	found := config.DB.Where("rental_id = ?", rental.ID).Limit(1).Find(&existing)
+	if found.Error != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch claims"})
+	}
+	if found.RowsAffected > 0 {
+		if existing.Status != models.ClaimAccepted && existing.Status != models.ClaimResolved {
+			return claimError(c, errClaimOpen)
+		}
+		claim = &existing
+	}
+
+	if err := settleDeposit(c.Request().Context(), &rental, claim); err != nil {
+		return claimError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rental)
+}
+
+// rentalInspection loads a check-out or check-in report recorded for the rental
+func rentalInspection(rental *models.Rental, reportID, reportType string) (*models.InspectionReport, error) {
+	var report models.InspectionReport
+	if err := config.DB.First(&report, "id = ?", reportID).Error; err != nil {
+		return nil, errors.New(reportType + " inspection report not found")
+	}
+	if report.ReportType != reportType || report.RentalID == nil || *report.RentalID != rental.ID {
+		return nil, errors.New("report " + reportID + " is not the " + reportType + " inspection of this rental")
+	}
+	return &report, nil
+}
+
+// loadClaim loads a claim with its items and its rental
+func loadClaim(id string) (*models.DamageClaim, *models.Rental, error) {
+	var claim models.DamageClaim
+	if err := config.DB.Preload("Items").First(&claim, "id = ?", id).Error; err != nil {
+		return nil, nil, err
+	}
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", claim.RentalID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &claim, &rental, nil
+}
+
+// decideClaim applies a decision to a claim in the expected status, under a
+// row lock so the renter and an admin cannot decide it twice.
+func decideClaim(claim *models.DamageClaim, from string, decide func(*models.DamageClaim)) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.DamageClaim
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", claim.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != from {
+			return errInvalidClaimState
+		}
+
+		decide(&current)
+		if err := tx.Omit("Items").Save(&current).Error; err != nil {
+			return err
+		}
+
+		current.Items = claim.Items
+		*claim = current
+		return nil
+	})
+}
+
+// settleDeposit keeps the approved deductions of the claim (none if claim is
+// nil) from the rental's deposit and returns the rest to the renter. A held
+// deposit is partially captured or released; a captured one is partially
+// refunded. The outcome is recorded on the rental and the claim.
+func settleDeposit(ctx context.Context, rental *models.Rental, claim *models.DamageClaim) error {
+	deduction := 0.0
+	if claim != nil {
+		deduction = claim.ApprovedAmount
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Rental
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", rental.ID).Error; err != nil {
+			return err
+		}
+		if current.DepositSettledAt != nil {
+			return errDepositSettled
+		}
+
+		var deposits []models.Payment
+		if err := tx.Where("rental_id = ? AND purpose = ?", rental.ID, models.PaymentForDeposit).Find(&deposits).Error; err != nil {
+			return err
+		}
+		for i := range deposits {
+			if err := settleDepositPayment(ctx, &deposits[i], deduction); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		refund := math.Round((current.SecurityDeposit-deduction)*100) / 100
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"deposit_refund":     refund,
+			"deposit_settled_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if claim != nil {
+			if err := tx.Model(claim).Updates(map[string]interface{}{
+				"refund_amount": refund,
+				"settled_at":    now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		rental.DepositRefund, rental.DepositSettledAt = refund, &now
+		return nil
+	})
+}
+
+// settleDepositPayment keeps deduction from one deposit payment. It is safe
+// to repeat after a partial failure: a payment already settled is left alone.
+func settleDepositPayment(ctx context.Context, p *models.Payment, deduction float64) error {
+	switch p.Status {
+	case models.PaymentAuthorized:
+		if deduction > 0 {
+			// Capturing part of a hold releases the remainder
+			return capturePayment(ctx, p, deduction)
+		}
+		return releasePayment(ctx, p)
+	case models.PaymentCaptured, models.PaymentPartiallyRefunded:
+		excess := math.Round((p.CapturedAmount-p.RefundedAmount-deduction)*100) / 100
+		if excess <= 0 {
+			return nil
+		}
+		return refundPayment(ctx, p, excess)
+	case models.PaymentRequiresPayment:
+		return releasePayment(ctx, p)
+	}
+	return nil
+}
+
+// claimError maps claim and settlement errors to responses
+func claimError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, errInvalidClaimState), errors.Is(err, errClaimOpen), errors.Is(err, errDepositSettled):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, payments.ErrInvalidState):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Deposit payment cannot be settled: " + err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Claim not found"})
+	}
+	return c.JSON(http.StatusBadGateway, map[string]string{"error": "Deposit settlement failed: " + err.Error()})
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/payments"
+	"gorm.io/gorm"
+)
+
+// seedCompletedRental creates a completed rental whose deposit is held by
+// the fake provider, plus its check-out and check-in inspection reports.
+func seedCompletedRental(t *testing.T) (models.Rental, models.Payment, models.InspectionReport, models.InspectionReport, *gorm.DB) {
+	t.Helper()
+	fake := payments.NewFakeProvider("test-secret")
+	payments.Active = fake
+
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+	db.Model(&rental).Update("status", models.RentalCompleted)
+
+	intent, err := fake.CreateIntent(context.Background(), payments.IntentRequest{
+		Amount: rental.SecurityDeposit, Currency: "INR", CaptureMethod: payments.CaptureManual,
+	})
+	if err != nil {
+		t.Fatalf("failed to create intent: %v", err)
+	}
+	fake.Complete(intent.ProviderRef)
+
+	deposit := models.Payment{
+		RentalID: rental.ID, PayerID: 2, Purpose: models.PaymentForDeposit, Provider: fake.Name(),
+		ProviderRef: intent.ProviderRef, CaptureMethod: payments.CaptureManual,
+		Amount: rental.SecurityDeposit, Currency: "INR", Status: models.PaymentAuthorized,
+	}
+	db.Create(&deposit)
+
+	checkOut := models.InspectionReport{MachineID: machine.ID, InspectorID: 9, ReportType: "check_out",
+		RentalID: &rental.ID, InspectionDate: time.Now().Add(-48 * time.Hour), Verdict: "Pass"}
+	checkIn := models.InspectionReport{MachineID: machine.ID, InspectorID: 9, ReportType: "check_in",
+		RentalID: &rental.ID, InspectionDate: time.Now(), Verdict: "Fail"}
+	db.Create(&checkOut)
+	db.Create(&checkIn)
+
+	return rental, deposit, checkOut, checkIn, db
+}
+
+func fileClaim(t *testing.T, e *echo.Echo, rental models.Rental, checkOut, checkIn models.InspectionReport) models.DamageClaim {
+	t.Helper()
+	body := `{"check_out_report_id":"` + checkOut.ID.String() + `","check_in_report_id":"` + checkIn.ID.String() + `",
+		"items":[{"description":"Cracked guard","amount":1500},{"description":"Missing tool holder","amount":500}]}`
+	c, rec := paymentCtx(e, http.MethodPost, body, 1, "seller", "id", rental.ID.String())
+	if err := CreateDamageClaim(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var claim models.DamageClaim
+	json.Unmarshal(rec.Body.Bytes(), &claim)
+	return claim
+}
+
+func TestDamageClaim_Accepted(t *testing.T) {
+	e := echo.New()
+	rental, deposit, checkOut, checkIn, db := seedCompletedRental(t)
+
+	claim := fileClaim(t, e, rental, checkOut, checkIn)
+	if claim.ClaimedAmount != 2000 || len(claim.Items) != 2 {
+		t.Fatalf("expected 2 items totalling 2000, got %+v", claim)
+	}
+
+	// Renter accepts: 2000 is captured from the hold, 3000 returned
+	c, rec := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 2, "buyer", "id", claim.ID.String())
+	RespondToClaim(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var updated models.DamageClaim
+	db.First(&updated, "id = ?", claim.ID)
+	if updated.Status != models.ClaimAccepted || updated.RefundAmount != 3000 || updated.SettledAt == nil {
+		t.Errorf("unexpected claim after acceptance: %+v", updated)
+	}
+
+	var payment models.Payment
+	db.First(&payment, "id = ?", deposit.ID)
+	if payment.Status != models.PaymentCaptured || payment.CapturedAmount != 2000 {
+		t.Errorf("expected 2000 captured from the deposit, got %s %.2f", payment.Status, payment.CapturedAmount)
+	}
+
+	var settled models.Rental
+	db.First(&settled, "id = ?", rental.ID)
+	if settled.DepositRefund != 3000 || settled.DepositSettledAt == nil {
+		t.Errorf("expected deposit refund of 3000 recorded on rental, got %+v", settled)
+	}
+}
+
+func TestDamageClaim_DisputedAndResolved(t *testing.T) {
+	e := echo.New()
+	rental, _, checkOut, checkIn, db := seedCompletedRental(t)
+	claim := fileClaim(t, e, rental, checkOut, checkIn)
+
+	// A dispute needs a reason
+	c1, rec1 := paymentCtx(e, http.MethodPut, `{"action":"dispute"}`, 2, "buyer", "id", claim.ID.String())
+	RespondToClaim(c1)
+	if rec1.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec1.Code)
+	}
+
+	c2, rec2 := paymentCtx(e, http.MethodPut, `{"action":"dispute","reason":"Guard was cracked at check-out"}`, 2, "buyer", "id", claim.ID.String())
+	RespondToClaim(c2)
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+
+	// Settlement waits for the admin's decision
+	c3, rec3 := paymentCtx(e, http.MethodPost, "", 1, "seller", "id", rental.ID.String())
+	SettleRentalDeposit(c3)
+	if rec3.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while disputed, got %d", rec3.Code)
+	}
+
+	// Only admins resolve
+	c4, rec4 := paymentCtx(e, http.MethodPut, `{"approved_amount":500}`, 1, "seller", "id", claim.ID.String())
+	ResolveClaim(c4)
+	if rec4.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec4.Code)
+	}
+
+	c5, rec5 := paymentCtx(e, http.MethodPut, `{"approved_amount":500,"notes":"Only the tool holder"}`, 99, "admin", "id", claim.ID.String())
+	ResolveClaim(c5)
+	if rec5.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec5.Code, rec5.Body.String())
+	}
+
+	var updated models.DamageClaim
+	db.First(&updated, "id = ?", claim.ID)
+	if updated.Status != models.ClaimResolved || updated.ApprovedAmount != 500 || updated.RefundAmount != 4500 {
+		t.Errorf("unexpected claim after resolution: %+v", updated)
+	}
+
+	// Deciding again is rejected
+	c6, rec6 := paymentCtx(e, http.MethodPut, `{"approved_amount":0}`, 99, "admin", "id", claim.ID.String())
+	ResolveClaim(c6)
+	if rec6.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec6.Code)
+	}
+}
+
+func TestDamageClaim_Validation(t *testing.T) {
+	e := echo.New()
+	rental, _, checkOut, checkIn, _ := seedCompletedRental(t)
+
+	cases := []struct {
+		name   string
+		body   string
+		userID uint
+		code   int
+	}{
+		{"renter cannot file", `{}`, 2, http.StatusForbidden},
+		{"no items", `{"check_out_report_id":"` + checkOut.ID.String() + `","check_in_report_id":"` + checkIn.ID.String() + `","items":[]}`, 1, http.StatusBadRequest},
+		{"exceeds deposit", `{"check_out_report_id":"` + checkOut.ID.String() + `","check_in_report_id":"` + checkIn.ID.String() + `","items":[{"description":"Engine","amount":9000}]}`, 1, http.StatusBadRequest},
+		{"reports swapped", `{"check_out_report_id":"` + checkIn.ID.String() + `","check_in_report_id":"` + checkOut.ID.String() + `","items":[{"description":"Dent","amount":100}]}`, 1, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := paymentCtx(e, http.MethodPost, tc.body, tc.userID, "seller", "id", rental.ID.String())
+			CreateDamageClaim(c)
+			if rec.Code != tc.code {
+				t.Errorf("expected %d, got %d: %s", tc.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSettleRentalDeposit_NoClaim(t *testing.T) {
+	e := echo.New()
+	rental, deposit, _, _, db := seedCompletedRental(t)
+
+	c, rec := paymentCtx(e, http.MethodPost, "", 1, "seller", "id", rental.ID.String())
+	SettleRentalDeposit(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var payment models.Payment
+	db.First(&payment, "id = ?", deposit.ID)
+	if payment.Status != models.PaymentCancelled {
+		t.Errorf("expected hold to be released, got %s", payment.Status)
+	}
+
+	// Settling twice is a conflict
+	c2, rec2 := paymentCtx(e, http.MethodPost, "", 1, "seller", "id", rental.ID.String())
+	SettleRentalDeposit(c2)
+	if rec2.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec2.Code)
+	}
+}
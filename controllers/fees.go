@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+)
+
+// validateFeeRule checks a fee rule before it is saved
+func validateFeeRule(rule *models.PlatformFeeRule) string {
+	if rule.Name == "" {
+		return "Name is required"
+	}
+	if rule.Kind != models.FeeKindPercentage && rule.Kind != models.FeeKindFlat {
+		return "Kind must be 'percentage' or 'flat'"
+	}
+	if rule.Value < 0 || rule.MinFee < 0 || rule.MaxFee < 0 {
+		return "Fee amounts cannot be negative"
+	}
+	if rule.Kind == models.FeeKindPercentage && rule.Value > 100 {
+		return "Percentage cannot exceed 100"
+	}
+	if rule.MaxFee > 0 && rule.MinFee > rule.MaxFee {
+		return "min_fee cannot exceed max_fee"
+	}
+	return ""
+}
+
+// GetFeeRules godoc
+//
+//	@Summary		List platform fee rules
+//	@Description	List the platform fee rules, highest priority first, a page at a time. Admin only.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.PlatformFeeRule]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Failure		403				{object}	map[string]string	"Admins only"
+//	@Router			/admin/fee-rules [get]
+func GetFeeRules(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.FeeRuleManage) {
+		return policy.Forbid(c, policy.FeeRuleManage, "Only admins can manage fee rules")
+	}
+
+	return respondPage(c, config.DB, feeRuleOrder, "Failed to fetch fee rules")
+}
+
+// feeRuleOrder lists rules highest priority first, then oldest first
+var feeRuleOrder = pagination.Order[models.PlatformFeeRule]{
+	{Expr: "priority", Type: "integer", Desc: true, Value: func(r *models.PlatformFeeRule) interface{} { return r.Priority }},
+	{Expr: "created_at", Type: "timestamptz", Value: func(r *models.PlatformFeeRule) interface{} { return r.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(r *models.PlatformFeeRule) interface{} { return r.ID }},
+}
+
+// CreateFeeRule godoc
+//
+//	@Summary		Create a platform fee rule
+//	@Description	Add a percentage or flat commission for a category and/or listing type, with optional min/max caps. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			rule	body		models.PlatformFeeRule	true	"Fee Rule"
+//	@Success		201		{object}	models.PlatformFeeRule
+//	@Failure		400		{object}	map[string]string	"Invalid input"
+//	@Failure		403		{object}	map[string]string	"Admins only"
+//	@Router			/admin/fee-rules [post]
+func CreateFeeRule(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.FeeRuleManage) {
+		return policy.Forbid(c, policy.FeeRuleManage, "Only admins can manage fee rules")
+	}
+
+	rule := models.PlatformFeeRule{Active: true}
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if msg := validateFeeRule(&rule); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	if err := config.DB.Create(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create fee rule"})
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateFeeRule godoc
+//
+//	@Summary		Update a platform fee rule
+//	@Description	Replace a fee rule's settings. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Fee Rule ID"
+//	@Param			rule	body		models.PlatformFeeRule	true	"Fee Rule"
+//	@Success		200		{object}	models.PlatformFeeRule
+//	@Failure		400		{object}	map[string]string	"Invalid input"
+//	@Failure		403		{object}	map[string]string	"Admins only"
+//	@Failure		404		{object}	map[string]string	"Fee rule not found"
+//	@Router			/admin/fee-rules/{id} [put]
+func UpdateFeeRule(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.FeeRuleManage) {
+		return policy.Forbid(c, policy.FeeRuleManage, "Only admins can manage fee rules")
+	}
+
+	var rule models.PlatformFeeRule
+	if err := config.DB.First(&rule, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Fee rule not found"})
+	}
+
+	var updateData models.PlatformFeeRule
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	rule.Name = updateData.Name
+	rule.Category = updateData.Category
+	rule.ListingType = updateData.ListingType
+	rule.Kind = updateData.Kind
+	rule.Value = updateData.Value
+	rule.MinFee = updateData.MinFee
+	rule.MaxFee = updateData.MaxFee
+	rule.Priority = updateData.Priority
+	rule.Active = updateData.Active
+
+	if msg := validateFeeRule(&rule); msg != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+
+	if err := config.DB.Save(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update fee rule"})
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// DeleteFeeRule godoc
+//
+//	@Summary		Delete a platform fee rule
+//	@Description	Remove a fee rule. Machines it covered fall back to the next matching rule. Admin only.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string				true	"Fee Rule ID"
+//	@Success		200	{object}	map[string]string	"Success"
+//	@Failure		403	{object}	map[string]string	"Admins only"
+//	@Failure		404	{object}	map[string]string	"Fee rule not found"
+//	@Router			/admin/fee-rules/{id} [delete]
+func DeleteFeeRule(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.FeeRuleManage) {
+		return policy.Forbid(c, policy.FeeRuleManage, "Only admins can manage fee rules")
+	}
+
+	var rule models.PlatformFeeRule
+	if err := config.DB.First(&rule, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Fee rule not found"})
+	}
+
+	if err := config.DB.Delete(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete fee rule"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Fee rule deleted successfully"})
+}
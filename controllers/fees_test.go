@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestCreateFeeRule(t *testing.T) {
+	e := echo.New()
+	setupTestDB(t, nil)
+
+	setupCtx := func(body string, role string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/fee-rules", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		tokenStr := createTestToken(1, role)
+		token, _ := jwt.ParseWithClaims(tokenStr, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		})
+		c.Set("user", token)
+		return c, rec
+	}
+
+	valid := `{"name":"CNC rentals","category":"CNC Mill","listing_type":"rent","kind":"percentage","value":3,"max_fee":10000}`
+
+	// Case 1: Success (Admin)
+	c1, rec1 := setupCtx(valid, "admin")
+	if err := CreateFeeRule(c1); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec1.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d. Body: %s", rec1.Code, rec1.Body.String())
+	}
+	var rule models.PlatformFeeRule
+	json.Unmarshal(rec1.Body.Bytes(), &rule)
+	if !rule.Active || rule.MaxFee != 10000 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	// Case 2: Forbidden (Seller)
+	c2, rec2 := setupCtx(valid, "seller")
+	CreateFeeRule(c2)
+	if rec2.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec2.Code)
+	}
+
+	// Case 3: Invalid kind
+	c3, rec3 := setupCtx(`{"name":"Bad","kind":"tiered","value":3}`, "admin")
+	CreateFeeRule(c3)
+	if rec3.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec3.Code)
+	}
+}
+
+func TestQuoteRental_UsesFeeRule(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	db.Create(&models.PlatformFeeRule{Name: "Flat rentals", ListingType: "rent", Kind: models.FeeKindFlat, Value: 250, Active: true})
+
+	payload := `{"machine_id":"` + machine.ID.String() + `","start_date":"2025-01-01","end_date":"2025-01-05","renter_state":"Delhi"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/rentals/quote", strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := QuoteRental(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var quote struct {
+		PlatformFee float64 `json:"platform_fee"`
+		Tax         struct {
+			TaxableValue float64 `json:"taxable_value"`
+			RenterState  string  `json:"renter_state"`
+		} `json:"tax"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &quote)
+	if quote.PlatformFee != 250 {
+		t.Errorf("expected platform fee 250, got %.2f", quote.PlatformFee)
+	}
+	if quote.Tax.TaxableValue != 4250 || quote.Tax.RenterState != "Delhi" {
+		t.Errorf("unexpected tax: %+v", quote.Tax)
+	}
+}
@@ -2,36 +2,50 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/checklist"
 	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+// reportTypes are the kinds of inspection report
+var reportTypes = []string{"listing", "check_out", "check_in"}
+
 // InspectionRequest payload
 type InspectionRequest struct {
 	MachineID  string                 `json:"machine_id"`
 	ReportType string                 `json:"report_type"` // listing, check_out, check_in
-	Verdict    string                 `json:"verdict"`
+	RentalID   string                 `json:"rental_id"`   // required for check_out / check_in
+	RequestID  string                 `json:"request_id"`  // required for listing reports
+	Verdict    string                 `json:"verdict"`     // Pass or Fail
 	Summary    string                 `json:"summary"`
 	ReportData map[string]interface{} `json:"report_data"` // Flexible Key-Value pairs
-	MediaURLs  []string               `json:"media_urls"`  // Array of image URLs
+	MediaURLs  []string               `json:"media_urls"`  // URLs of the inspector's own uploads
 }
 
 // CreateInspectionReport godoc
 //
 //	@Summary		Submit an inspection report
-//	@Description	Submit a verification report with media URLs. Only Inspectors/Admins should ideally do this.
+//	@Description	Submit a verification report with media URLs. Requires the inspector or admin role. Listing reports need the request_id of a scheduled or in-progress inspection request and can only be submitted by its assigned inspector (or an admin); submitting closes the request. Check-out and check-in reports are tied to their rental_id instead. Media URLs must be the inspector's own uploads (admins may use anyone's). When the machine's category has an inspection checklist (GET /categories/{slug}/inspection-template), report_data must answer its items; the report is then scored 0-100 with a grade from A to F. A listing report's score and grade become the listing's condition_score and condition_grade. report_type is listing (the default), check_out or check_in, and verdict is Pass or Fail; only a listing report verifies a machine awaiting inspection, and not if it fails or grades F. The report is signed with the server's Ed25519 key (GET /inspections/{id}/verify) and cannot be edited afterwards; corrections are new revisions (POST /inspections/{id}/revisions).
 //	@Tags			Inspection
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			report	body		InspectionRequest	true	"Inspection Data"
 //	@Success		201		{object}	models.InspectionReport
-//	@Failure		400		{object}	map[string]string	"Invalid Input"
+//	@Failure		400		{object}	ValidationErrorResponse	"Invalid input, report type, verdict or checklist answers"
+//	@Failure		403		{object}	policy.Denial			"Inspectors only, or not the assigned inspector"
+//	@Failure		409		{object}	map[string]string		"Inspection not scheduled or already submitted"
 //	@Router			/inspections [post]
 func CreateInspectionReport(c echo.Context) error {
 	var req InspectionRequest
@@ -45,10 +59,10 @@ func CreateInspectionReport(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 	}
 
-	// Optional: Enforce Role (e.g., only inspectors or admins)
-	// if user.Role != "inspector" && user.Role != "admin" {
-	//     return c.JSON(http.StatusForbidden, map[string]string{"error": "Only inspectors can submit reports"})
-	// }
+	// Only inspectors (and admins) submit reports
+	if !user.Can(policy.InspectionSubmit) {
+		return policy.Forbid(c, policy.InspectionSubmit, "Only inspectors can submit reports")
+	}
 
 	// 1. Verify Machine Exists
 	var machine models.Machine
@@ -56,28 +70,137 @@ func CreateInspectionReport(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
 	}
 
-	// 2. Serialize JSON fields
+	// 2. Check-out / check-in reports belong to a rental of this machine
+	if req.ReportType == "" {
+		req.ReportType = "listing"
+	}
+	if !slices.Contains(reportTypes, req.ReportType) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "report_type must be listing, check_out or check_in"})
+	}
+	verdict, ok := models.ParseVerdict(req.Verdict)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "verdict must be Pass or Fail"})
+	}
+	req.Verdict = verdict
+	var rentalID *uuid.UUID
+	if req.ReportType == "check_out" || req.ReportType == "check_in" {
+		var rental models.Rental
+		if err := config.DB.First(&rental, "id = ?", req.RentalID).Error; err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "check_out and check_in reports need a valid rental_id"})
+		}
+		if rental.MachineID != machine.ID {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Rental is not for this machine"})
+		}
+		rentalID = &rental.ID
+	}
+
+	// 3. Listing reports close a scheduled inspection request, and only its
+	// assigned inspector may submit them
+	var requestID *uuid.UUID
+	if rentalID == nil {
+		var request models.InspectionRequest
+		if err := config.DB.Preload("Machine").First(&request, "id = ?", req.RequestID).Error; err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "listing reports need the request_id of a scheduled inspection"})
+		}
+		if request.MachineID != machine.ID {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Inspection request is not for this machine"})
+		}
+		parties := inspectionParties(&request, user)
+		if !slices.Contains(parties, models.PartyInspector) && !slices.Contains(parties, models.PartyAdmin) {
+			return policy.Deny(c, "Only the assigned inspector can submit this report")
+		}
+		requestID = &request.ID
+	}
+
+	// 4. Machines in a category with a checklist are scored against it
+	template, err := machineTemplate(&machine)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load inspection checklist"})
+	}
+	var result checklist.Result
+	scored := false
+	if template != nil {
+		answers, err := template.Sections.Validate(req.ReportData)
+		if err != nil {
+			return validationError(c, "Invalid checklist", err)
+		}
+		req.ReportData = answers
+		result, scored = template.Sections.Score(answers)
+	}
+
+	// 5. Serialize JSON fields
 	reportDataJSON, _ := json.Marshal(req.ReportData)
 	mediaURLsJSON, _ := json.Marshal(req.MediaURLs)
 
 	report := models.InspectionReport{
+		ID:             uuid.New(),
 		MachineID:      machine.ID,
 		InspectorID:    user.ID, // Use ID from claims
 		ReportType:     req.ReportType,
-		InspectionDate: time.Now(),
+		RentalID:       rentalID,
+		InspectionDate: time.Now().Truncate(time.Microsecond),
 		Verdict:        req.Verdict,
 		Summary:        req.Summary,
 		ReportData:     datatypes.JSON(reportDataJSON),
 		MediaURLs:      datatypes.JSON(mediaURLsJSON),
 	}
-
-	if err := config.DB.Create(&report).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save report"})
+	if template != nil {
+		report.TemplateID = &template.ID
+	}
+	if scored {
+		sectionsJSON, _ := json.Marshal(result.Sections)
+		report.ConditionScore = &result.Score
+		report.ConditionGrade = result.Grade
+		report.SectionScores = datatypes.JSON(sectionsJSON)
+	}
+	report.Revision = 1
+	if err := signReport(&report); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to sign report"})
 	}
 
-	// 3. Optional: Update Machine Status to 'verified' if it was pending
-	if machine.Status == "pending_inspection" && req.Verdict != "Fail" {
-		config.DB.Model(&machine).Update("status", "verified")
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// 6. Media must have been uploaded by the inspector, checked here
+		// so the uploads cannot be swept before the report refers to them
+		if err := checkUploads(tx, user, req.MediaURLs); err != nil {
+			return err
+		}
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		if requestID != nil {
+			if err := submitInspection(tx, *requestID, &report, user); err != nil {
+				return err
+			}
+			// 7. A passing listing inspection verifies a machine awaiting one
+			if inspectionPassed(&report) {
+				if err := tx.Model(&models.Machine{}).Where("id = ? AND status = ?", machine.ID, "pending_inspection").
+					Update("status", "verified").Error; err != nil {
+					return err
+				}
+			}
+			// The listing shows the condition from its latest scored
+			// listing report; check-outs and check-ins leave it alone
+			if scored {
+				if err := tx.Model(&machine).Updates(map[string]interface{}{
+					"condition_score": result.Score,
+					"condition_grade": result.Grade,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return files.SetReferences(tx, files.Ref(models.AttachToInspection, report.ID), req.MediaURLs)
+	})
+	if msg, ok := uploadError(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	switch {
+	case errors.Is(err, models.ErrInspectionTransitionDenied):
+		return policy.Deny(c, "Only the assigned inspector can submit this report")
+	case errors.Is(err, models.ErrInvalidInspectionTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This inspection is not scheduled, or its report was already submitted"})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save report"})
 	}
 
 	return c.JSON(http.StatusCreated, report)
@@ -103,3 +226,9 @@ func GetMachineInspection(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, report)
 }
+
+// inspectionPassed reports whether a report leaves its machine fit to be
+// verified: it was not failed outright and did not grade F
+func inspectionPassed(report *models.InspectionReport) bool {
+	return report.Verdict != models.VerdictFail && report.ConditionGrade != "F"
+}
@@ -0,0 +1,460 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errNotAwaitingInspection = errors.New("only machines awaiting inspection can be inspected")
+	errInspectionOpen        = errors.New("this machine already has an open inspection request")
+	errInspectorBusy         = errors.New("the inspector already has an inspection in this slot")
+)
+
+// InspectionRequestInput asks for a machine to be inspected
+type InspectionRequestInput struct {
+	MachineID string `json:"machine_id"`
+	Notes     string `json:"notes" example:"Available weekdays after 10am; ask for the shop floor manager"`
+}
+
+// ScheduleInspectionInput assigns an inspector and a time slot
+type ScheduleInspectionInput struct {
+	InspectorID    uint      `json:"inspector_id" example:"3"`
+	ScheduledStart time.Time `json:"scheduled_start" example:"2026-03-02T10:00:00+05:30"`
+	ScheduledEnd   time.Time `json:"scheduled_end" example:"2026-03-02T13:00:00+05:30"`
+}
+
+// InspectionStatusUpdate starts or cancels an inspection request
+type InspectionStatusUpdate struct {
+	Status string `json:"status" example:"in_progress"`
+	Reason string `json:"reason"` // when cancelling
+}
+
+// CreateInspectionRequest godoc
+//
+//	@Summary		Request an inspection
+//	@Description	Ask for a machine awaiting inspection (status pending_inspection) to be inspected. Owners request inspections of their own machines; a machine has at most one open request. An admin then assigns an inspector and a time slot.
+//	@Tags			Inspection
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		InspectionRequestInput	true	"Machine to inspect"
+//	@Success		201		{object}	models.InspectionRequest
+//	@Failure		403		{object}	policy.Denial		"Not your machine"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Failure		409		{object}	map[string]string	"Machine not awaiting inspection, or already requested"
+//	@Router			/inspection-requests [post]
+func CreateInspectionRequest(c echo.Context) error {
+	var input InspectionRequestInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", input.MachineID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	}
+	if !user.Allows(policy.InspectionRequest, machineOwner(&machine)) {
+		return policy.Forbid(c, policy.InspectionRequest, "You can only request inspections of your own machines")
+	}
+
+	request := models.InspectionRequest{
+		MachineID:   machine.ID,
+		RequestedBy: user.ID,
+		Notes:       input.Notes,
+		Status:      models.InspectionRequested,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the machine so concurrent requests cannot both open
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&machine, "id = ?", machine.ID).Error; err != nil {
+			return err
+		}
+		if machine.Status != "pending_inspection" {
+			return errNotAwaitingInspection
+		}
+		var open int64
+		if err := tx.Model(&models.InspectionRequest{}).
+			Where("machine_id = ? AND status IN ?", machine.ID, models.OpenInspectionStatuses).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errInspectionOpen
+		}
+		return tx.Omit(clause.Associations).Create(&request).Error
+	})
+
+	switch {
+	case errors.Is(err, errNotAwaitingInspection), errors.Is(err, errInspectionOpen):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to request inspection"})
+	}
+
+	return c.JSON(http.StatusCreated, request)
+}
+
+// GetMyInspectionRequests godoc
+//
+//	@Summary		Get my inspection requests
+//	@Description	List the inspection requests the current user has made, newest first. Filter with status (requested, scheduled, in_progress, submitted, cancelled).
+//	@Tags			Inspection
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status			query		string	false	"Only requests in this status"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching requests"
+//	@Success		200				{object}	pagination.Page[models.InspectionRequest]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor or status"
+//	@Router			/inspection-requests/my [get]
+func GetMyInspectionRequests(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query, herr := inspectionRequestQuery(c)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	query = query.Where("requested_by = ?", user.ID)
+	return respondPage(c, query, inspectionRequestOrder, "Failed to fetch inspection requests")
+}
+
+// GetInspectorQueue godoc
+//
+//	@Summary		Get my inspection queue
+//	@Description	List the inspections assigned to the current inspector, soonest slot first. Without a status filter the queue holds scheduled and in-progress inspections.
+//	@Tags			Inspection
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status			query		string	false	"Only requests in this status"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching requests"
+//	@Success		200				{object}	pagination.Page[models.InspectionRequest]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor or status"
+//	@Failure		403				{object}	policy.Denial		"Inspectors only"
+//	@Router			/inspection-requests/queue [get]
+func GetInspectorQueue(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query, herr := inspectionRequestQuery(c)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	query = query.Where("inspector_id = ?", user.ID)
+	if c.QueryParam("status") == "" {
+		query = query.Where("status IN ?", []string{models.InspectionScheduled, models.InspectionInProgress})
+	}
+	return respondPage(c, query, inspectionQueueOrder, "Failed to fetch inspection queue")
+}
+
+// GetInspectionRequests godoc
+//
+//	@Summary		List inspection requests
+//	@Description	List every inspection request, oldest first, for scheduling. Filter with status, e.g. status=requested for those still waiting for an inspector. Admin only.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status			query		string	false	"Only requests in this status"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching requests"
+//	@Success		200				{object}	pagination.Page[models.InspectionRequest]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor or status"
+//	@Failure		403				{object}	policy.Denial		"Admins only"
+//	@Router			/admin/inspection-requests [get]
+func GetInspectionRequests(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.InspectionAssign) {
+		return policy.Forbid(c, policy.InspectionAssign, "Only admins can schedule inspections")
+	}
+
+	query, herr := inspectionRequestQuery(c)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	return respondPage(c, query, inspectionBacklogOrder, "Failed to fetch inspection requests")
+}
+
+// GetInspectionRequest godoc
+//
+//	@Summary		Get an inspection request
+//	@Description	Retrieve one inspection request. Visible to the machine's owner, the assigned inspector and admins.
+//	@Tags			Inspection
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Inspection request ID"
+//	@Success		200	{object}	models.InspectionRequest
+//	@Failure		403	{object}	policy.Denial		"Not a party to the request"
+//	@Failure		404	{object}	map[string]string	"Request not found"
+//	@Router			/inspection-requests/{id} [get]
+func GetInspectionRequest(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var request models.InspectionRequest
+	if err := config.DB.Preload("Machine").First(&request, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Inspection request not found"})
+	}
+	if len(inspectionParties(&request, user)) == 0 {
+		return policy.Deny(c, "You are not a party to this inspection")
+	}
+	return c.JSON(http.StatusOK, request)
+}
+
+// ScheduleInspection godoc
+//
+//	@Summary		Schedule an inspection
+//	@Description	Assign an inspector and a time slot to a requested inspection, or reschedule one that has not started. The slot must lie in the future and must not overlap the inspector's other scheduled or in-progress inspections. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string					true	"Inspection request ID"
+//	@Param			schedule	body		ScheduleInspectionInput	true	"Inspector and slot"
+//	@Success		200			{object}	models.InspectionRequest
+//	@Failure		400			{object}	map[string]string	"Invalid slot"
+//	@Failure		403			{object}	policy.Denial		"Admins only"
+//	@Failure		404			{object}	map[string]string	"Request not found"
+//	@Failure		409			{object}	map[string]string	"Request already started or closed, or inspector busy"
+//	@Router			/admin/inspection-requests/{id}/schedule [put]
+func ScheduleInspection(c echo.Context) error {
+	var input ScheduleInspectionInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.InspectionAssign) {
+		return policy.Forbid(c, policy.InspectionAssign, "Only admins can schedule inspections")
+	}
+
+	switch {
+	case input.InspectorID == 0:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "inspector_id is required"})
+	case !input.ScheduledStart.After(time.Now()):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "scheduled_start must be in the future"})
+	case !input.ScheduledEnd.After(input.ScheduledStart):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "scheduled_end must be after scheduled_start"})
+	}
+
+	var request models.InspectionRequest
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, "id = ?", c.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := models.CheckInspectionTransition(request.Status, models.InspectionScheduled, []string{models.PartyAdmin}); err != nil {
+			return err
+		}
+
+		var clashes int64
+		if err := tx.Model(&models.InspectionRequest{}).
+			Where("inspector_id = ? AND id <> ? AND status IN ?", input.InspectorID, request.ID,
+				[]string{models.InspectionScheduled, models.InspectionInProgress}).
+			Where("scheduled_start < ? AND scheduled_end > ?", input.ScheduledEnd, input.ScheduledStart).
+			Count(&clashes).Error; err != nil {
+			return err
+		}
+		if clashes > 0 {
+			return errInspectorBusy
+		}
+
+		request.Status = models.InspectionScheduled
+		request.InspectorID = &input.InspectorID
+		request.ScheduledStart = &input.ScheduledStart
+		request.ScheduledEnd = &input.ScheduledEnd
+		return tx.Omit(clause.Associations).Save(&request).Error
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Inspection request not found"})
+	case errors.Is(err, models.ErrInvalidInspectionTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Only requested or scheduled inspections can be scheduled"})
+	case errors.Is(err, errInspectorBusy):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to schedule inspection"})
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+// UpdateInspectionRequestStatus godoc
+//
+//	@Summary		Start or cancel an inspection
+//	@Description	The assigned inspector moves a scheduled inspection to in_progress when they begin. The machine's owner may cancel a request until it starts; admins may cancel at any point before submission. Inspections are submitted by posting the report with its request_id (POST /inspections).
+//	@Tags			Inspection
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Inspection request ID"
+//	@Param			status	body		InspectionStatusUpdate	true	"New status"
+//	@Success		200		{object}	models.InspectionRequest
+//	@Failure		400		{object}	map[string]string	"Unknown status"
+//	@Failure		403		{object}	policy.Denial		"Not allowed"
+//	@Failure		404		{object}	map[string]string	"Request not found"
+//	@Failure		409		{object}	map[string]string	"Invalid transition"
+//	@Router			/inspection-requests/{id}/status [put]
+func UpdateInspectionRequestStatus(c echo.Context) error {
+	var req InspectionStatusUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	switch req.Status {
+	case models.InspectionScheduled:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Inspections are scheduled by an admin"})
+	case models.InspectionSubmitted:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Submit the inspection report with its request_id"})
+	}
+
+	var request models.InspectionRequest
+	if err := config.DB.Preload("Machine").First(&request, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Inspection request not found"})
+	}
+
+	parties := inspectionParties(&request, user)
+	if len(parties) == 0 {
+		return policy.Deny(c, "You are not a party to this inspection")
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+
+		if err := models.CheckInspectionTransition(request.Status, req.Status, parties); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		request.Status = req.Status
+		switch req.Status {
+		case models.InspectionInProgress:
+			request.StartedAt = &now
+		case models.InspectionCancelled:
+			request.CancelledAt = &now
+			request.CancelReason = req.Reason
+		}
+		return tx.Omit(clause.Associations).Save(&request).Error
+	})
+
+	switch {
+	case errors.Is(err, models.ErrUnknownInspectionStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrInspectionTransitionDenied):
+		return policy.Deny(c, err.Error())
+	case errors.Is(err, models.ErrInvalidInspectionTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update inspection request"})
+	}
+
+	return c.JSON(http.StatusOK, request)
+}
+
+// submitInspection closes the request a report was submitted for. The
+// request is locked, so only one report is ever accepted for it.
+func submitInspection(tx *gorm.DB, requestID uuid.UUID, report *models.InspectionReport, user *UserClaims) error {
+	var request models.InspectionRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Machine").First(&request, "id = ?", requestID).Error; err != nil {
+		return err
+	}
+	if err := models.CheckInspectionTransition(request.Status, models.InspectionSubmitted, inspectionParties(&request, user)); err != nil {
+		return err
+	}
+
+	return tx.Model(&request).Omit(clause.Associations).Updates(map[string]interface{}{
+		"status":       models.InspectionSubmitted,
+		"report_id":    report.ID,
+		"submitted_at": time.Now(),
+	}).Error
+}
+
+// inspectionParties lists the roles the caller plays in an inspection
+// request, which must have its Machine loaded
+func inspectionParties(r *models.InspectionRequest, user *UserClaims) []string {
+	var parties []string
+	if r.RequestedBy == user.ID || user.Owns(machineOwner(&r.Machine)) {
+		parties = append(parties, models.PartySeller)
+	}
+	if r.InspectorID != nil && *r.InspectorID == user.ID {
+		parties = append(parties, models.PartyInspector)
+	}
+	if user.Can(policy.InspectionAssign) {
+		parties = append(parties, models.PartyAdmin)
+	}
+	return parties
+}
+
+// inspectionRequestQuery applies the status filter shared by the list
+// endpoints
+func inspectionRequestQuery(c echo.Context) (*gorm.DB, *echo.HTTPError) {
+	query := config.DB.Preload("Machine")
+	if status := c.QueryParam("status"); status != "" {
+		if !models.IsInspectionStatus(status) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Unknown status")
+		}
+		query = query.Where("status = ?", status)
+	}
+	return query, nil
+}
+
+// inspectionRequestOrder lists requests newest first
+var inspectionRequestOrder = pagination.Order[models.InspectionRequest]{
+	{Expr: "created_at", Type: "timestamptz", Desc: true, Value: func(r *models.InspectionRequest) interface{} { return r.CreatedAt }},
+	{Expr: "id", Type: "uuid", Desc: true, Value: func(r *models.InspectionRequest) interface{} { return r.ID }},
+}
+
+// inspectionBacklogOrder lists requests oldest first, so the longest
+// waiting are scheduled first
+var inspectionBacklogOrder = pagination.Order[models.InspectionRequest]{
+	{Expr: "created_at", Type: "timestamptz", Value: func(r *models.InspectionRequest) interface{} { return r.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(r *models.InspectionRequest) interface{} { return r.ID }},
+}
+
+// inspectionQueueOrder lists an inspector's requests by slot, soonest first
+var inspectionQueueOrder = pagination.Order[models.InspectionRequest]{
+	{Expr: "coalesce(scheduled_start, created_at)", Type: "timestamptz", Value: func(r *models.InspectionRequest) interface{} {
+		if r.ScheduledStart != nil {
+			return *r.ScheduledStart
+		}
+		return r.CreatedAt
+	}},
+	{Expr: "id", Type: "uuid", Value: func(r *models.InspectionRequest) interface{} { return r.ID }},
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"gorm.io/gorm"
+)
+
+// seedInspectionRequest schedules an inspection of a machine with an
+// inspector, starting tomorrow
+func seedInspectionRequest(t *testing.T, db *gorm.DB, machineID uuid.UUID, inspectorID uint) models.InspectionRequest {
+	t.Helper()
+	start := time.Now().Add(24 * time.Hour)
+	end := start.Add(3 * time.Hour)
+	request := models.InspectionRequest{
+		MachineID:      machineID,
+		RequestedBy:    1,
+		Status:         models.InspectionScheduled,
+		InspectorID:    &inspectorID,
+		ScheduledStart: &start,
+		ScheduledEnd:   &end,
+	}
+	if err := db.Create(&request).Error; err != nil {
+		t.Fatalf("failed to seed inspection request: %v", err)
+	}
+	return request
+}
+
+// inspectionList calls a list handler with a status filter
+func inspectionList(t *testing.T, e *echo.Echo, handler echo.HandlerFunc, userID uint, role, status string) []models.InspectionRequest {
+	t.Helper()
+	c, rec := paymentCtx(e, http.MethodGet, "", userID, role, "", "")
+	c.Request().URL.RawQuery = "status=" + status
+	if err := handler(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var page pagination.Page[models.InspectionRequest]
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	return page.Data
+}
+
+func TestInspectionRequestWorkflow(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	db.Model(&machine).Update("status", "pending_inspection")
+	other := models.Machine{Title: "Press", SellerID: 1, Status: "pending_inspection"}
+	db.Create(&other)
+
+	request := func(userID uint, machineID uuid.UUID) (int, models.InspectionRequest) {
+		c, rec := paymentCtx(e, http.MethodPost, `{"machine_id":"`+machineID.String()+`","notes":"Weekdays only"}`, userID, "seller", "", "")
+		if err := CreateInspectionRequest(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var created models.InspectionRequest
+		json.Unmarshal(rec.Body.Bytes(), &created)
+		return rec.Code, created
+	}
+	schedule := func(id uuid.UUID, inspectorID uint, start time.Time) int {
+		body := `{"inspector_id":` + fmt.Sprint(inspectorID) + `,"scheduled_start":"` + start.Format(time.RFC3339) +
+			`","scheduled_end":"` + start.Add(3*time.Hour).Format(time.RFC3339) + `"}`
+		c, rec := paymentCtx(e, http.MethodPut, body, 9, "admin", "id", id.String())
+		ScheduleInspection(c)
+		return rec.Code
+	}
+	setStatus := func(id uuid.UUID, userID uint, role, body string) int {
+		c, rec := paymentCtx(e, http.MethodPut, body, userID, role, "id", id.String())
+		UpdateInspectionRequestStatus(c)
+		return rec.Code
+	}
+	submit := func(userID uint, requestID uuid.UUID) int {
+		body := `{"machine_id":"` + machine.ID.String() + `","report_type":"listing","request_id":"` + requestID.String() + `","verdict":"Pass"}`
+		c, rec := paymentCtx(e, http.MethodPost, body, userID, "inspector", "", "")
+		CreateInspectionReport(c)
+		return rec.Code
+	}
+
+	// 1. Owners request inspections of their machines awaiting one, once
+	if code, _ := request(2, machine.ID); code != http.StatusForbidden {
+		t.Errorf("expected 403 for another seller, got %d", code)
+	}
+	code, req := request(1, machine.ID)
+	if code != http.StatusCreated || req.Status != models.InspectionRequested {
+		t.Fatalf("expected a new request, got %d %+v", code, req)
+	}
+	if code, _ := request(1, machine.ID); code != http.StatusConflict {
+		t.Errorf("expected 409 for a second open request, got %d", code)
+	}
+	_, otherReq := request(1, other.ID)
+
+	// 2. Admins see the backlog and schedule it
+	if waiting := inspectionList(t, e, GetInspectionRequests, 9, "admin", models.InspectionRequested); len(waiting) != 2 {
+		t.Errorf("expected 2 waiting requests, got %d", len(waiting))
+	}
+	tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Minute)
+	if code := schedule(req.ID, 3, time.Now().Add(-time.Hour)); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a slot in the past, got %d", code)
+	}
+	if code := schedule(req.ID, 3, tomorrow); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := schedule(otherReq.ID, 3, tomorrow.Add(time.Hour)); code != http.StatusConflict {
+		t.Errorf("expected 409 for an overlapping slot, got %d", code)
+	}
+
+	// 3. The inspector sees it in their queue, nobody else does
+	if queue := inspectionList(t, e, GetInspectorQueue, 3, "inspector", ""); len(queue) != 1 || queue[0].ID != req.ID {
+		t.Errorf("expected the request in inspector 3's queue, got %+v", queue)
+	}
+	if queue := inspectionList(t, e, GetInspectorQueue, 4, "inspector", ""); len(queue) != 0 {
+		t.Errorf("expected an empty queue for inspector 4, got %d", len(queue))
+	}
+
+	// 4. Only the assigned inspector starts and submits it
+	if code := setStatus(req.ID, 1, "seller", `{"status":"in_progress"}`); code != http.StatusForbidden {
+		t.Errorf("expected 403 for the seller starting, got %d", code)
+	}
+	if code := setStatus(req.ID, 3, "inspector", `{"status":"in_progress"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := setStatus(req.ID, 1, "seller", `{"status":"cancelled"}`); code != http.StatusForbidden {
+		t.Errorf("expected 403 for the seller cancelling a started inspection, got %d", code)
+	}
+	if code := submit(4, req.ID); code != http.StatusForbidden {
+		t.Errorf("expected 403 for another inspector, got %d", code)
+	}
+	if code := submit(3, req.ID); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if code := submit(3, req.ID); code != http.StatusConflict {
+		t.Errorf("expected 409 for a second report, got %d", code)
+	}
+
+	var stored models.InspectionRequest
+	db.First(&stored, "id = ?", req.ID)
+	if stored.Status != models.InspectionSubmitted || stored.ReportID == nil || stored.SubmittedAt == nil {
+		t.Errorf("expected the request to be submitted with its report, got %+v", stored)
+	}
+	var verified models.Machine
+	db.First(&verified, "id = ?", machine.ID)
+	if verified.Status != "verified" {
+		t.Errorf("expected the machine to be verified, got %s", verified.Status)
+	}
+
+	// 5. Owners may cancel until the inspection starts
+	if code := setStatus(otherReq.ID, 1, "seller", `{"status":"cancelled","reason":"Sold privately"}`); code != http.StatusOK {
+		t.Errorf("expected 200, got %d", code)
+	}
+	if mine := inspectionList(t, e, GetMyInspectionRequests, 1, "seller", models.InspectionCancelled); len(mine) != 1 || mine[0].CancelReason != "Sold privately" {
+		t.Errorf("expected the cancelled request with its reason, got %+v", mine)
+	}
+}
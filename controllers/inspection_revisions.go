@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/checklist"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/signing"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRevisions bounds how far back verification walks a revision chain
+const maxRevisions = 100
+
+var (
+	errAlreadyRevised = errors.New("report already revised")
+	errTampered       = errors.New("report fails verification")
+)
+
+// InspectionRevisionInput corrects a report. Fields left out keep their
+// previous values; report_data and media_urls replace the old ones whole.
+type InspectionRevisionInput struct {
+	Verdict    *string                `json:"verdict"` // Pass or Fail
+	Summary    *string                `json:"summary"`
+	ReportData map[string]interface{} `json:"report_data"`
+	MediaURLs  []string               `json:"media_urls"`
+	Note       string                 `json:"note" example:"Runout re-measured with a calibrated gauge"` // why the report changed
+}
+
+// InspectionVerification is the result of checking a report's signature
+// and the chain of revisions behind it
+type InspectionVerification struct {
+	ReportID uuid.UUID `json:"report_id"`
+	// Valid is true when this revision and every earlier one are signed,
+	// unchanged since signing and correctly linked
+	Valid       bool   `json:"valid" example:"true"`
+	Algorithm   string `json:"algorithm,omitempty" example:"Ed25519"`
+	KeyID       string `json:"key_id,omitempty" example:"3f9a1c04b2d7e865"`
+	PublicKey   string `json:"public_key,omitempty"` // base64
+	ContentHash string `json:"content_hash"`         // SHA-256 of the report as stored now
+	Revision    int    `json:"revision" example:"2"`
+	// Current is false once the report has been revised; SupersededBy is
+	// the next revision
+	Current      bool            `json:"current" example:"true"`
+	SupersededBy *uuid.UUID      `json:"superseded_by,omitempty"`
+	Chain        []RevisionCheck `json:"chain"`
+}
+
+// RevisionCheck is the verdict on one revision, newest first
+type RevisionCheck struct {
+	ReportID uuid.UUID `json:"report_id"`
+	Revision int       `json:"revision" example:"1"`
+	Valid    bool      `json:"valid" example:"true"`
+	Problem  string    `json:"problem,omitempty" example:"content changed after signing"`
+}
+
+// ReviseInspectionReport godoc
+//
+//	@Summary		Revise an inspection report
+//	@Description	Correct a signed inspection report by adding a new revision; the original is never modified. The revision copies the report, applies the changes, links to it by previous_id and previous_hash and is signed in turn. Answers are re-scored against the checklist the original used. Revising a machine's latest listing report updates its condition, and a revision that fails or grades F returns a verified machine to pending_inspection. Only the report's inspector (or an admin) may revise it, only its latest revision can be revised, and a note explaining the change is required.
+//	@Tags			Inspection
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string					true	"Report UUID (the latest revision)"
+//	@Param			revision	body		InspectionRevisionInput	true	"Changes"
+//	@Success		201			{object}	models.InspectionReport
+//	@Failure		400			{object}	ValidationErrorResponse	"Invalid input or checklist answers"
+//	@Failure		403			{object}	policy.Denial			"Not the report's inspector"
+//	@Failure		404			{object}	map[string]string		"Report not found"
+//	@Failure		409			{object}	map[string]string		"Already revised, or the report fails verification"
+//	@Router			/inspections/{id}/revisions [post]
+func ReviseInspectionReport(c echo.Context) error {
+	var req InspectionRevisionInput
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "note is required: say why the report changed"})
+	}
+
+	var previous models.InspectionReport
+	if err := config.DB.First(&previous, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Inspection report not found"})
+	}
+	if !user.Allows(policy.InspectionSubmit, policy.Owner{UserID: previous.InspectorID}) {
+		return policy.Forbid(c, policy.InspectionSubmit, "Only the report's inspector can revise it")
+	}
+
+	revision := previous
+	revision.ID = uuid.New()
+	revision.VerificationCode = nil
+	revision.CreatedAt, revision.UpdatedAt = time.Time{}, time.Time{}
+	revision.Revision = previous.Revision + 1
+	revision.PreviousID = &previous.ID
+	revision.RevisedBy = &user.ID
+	revision.RevisionNote = strings.TrimSpace(req.Note)
+	if req.Verdict != nil {
+		verdict, ok := models.ParseVerdict(*req.Verdict)
+		if !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "verdict must be Pass or Fail"})
+		}
+		revision.Verdict = verdict
+	}
+	if req.Summary != nil {
+		revision.Summary = *req.Summary
+	}
+
+	// New media must be the reviser's own uploads, checked in the
+	// transaction below
+	var oldURLs, newURLs, added []string
+	json.Unmarshal(previous.MediaURLs, &oldURLs)
+	if req.MediaURLs != nil {
+		for _, u := range req.MediaURLs {
+			if !slices.Contains(oldURLs, u) {
+				added = append(added, u)
+			}
+		}
+		mediaJSON, _ := json.Marshal(req.MediaURLs)
+		revision.MediaURLs = datatypes.JSON(mediaJSON)
+		newURLs = req.MediaURLs
+	} else {
+		newURLs = oldURLs
+	}
+
+	// New answers are checked and scored against the original's checklist
+	var result checklist.Result
+	scored := false
+	if req.ReportData != nil {
+		answers := req.ReportData
+		if previous.TemplateID != nil {
+			var template models.InspectionTemplate
+			if err := config.DB.First(&template, "id = ?", previous.TemplateID).Error; err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load inspection checklist"})
+			}
+			if answers, err = template.Sections.Validate(req.ReportData); err != nil {
+				return validationError(c, "Invalid checklist", err)
+			}
+			result, scored = template.Sections.Score(answers)
+		}
+		reportDataJSON, _ := json.Marshal(answers)
+		revision.ReportData = datatypes.JSON(reportDataJSON)
+		if previous.TemplateID != nil {
+			revision.ConditionScore, revision.ConditionGrade, revision.SectionScores = nil, "", nil
+			if scored {
+				sectionsJSON, _ := json.Marshal(result.Sections)
+				revision.ConditionScore = &result.Score
+				revision.ConditionGrade = result.Grade
+				revision.SectionScores = datatypes.JSON(sectionsJSON)
+			}
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the report so that concurrent revisions cannot fork the chain
+		var locked models.InspectionReport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", previous.ID).Error; err != nil {
+			return err
+		}
+		var next int64
+		if err := tx.Model(&models.InspectionReport{}).Where("previous_id = ?", previous.ID).Count(&next).Error; err != nil {
+			return err
+		}
+		if next > 0 {
+			return errAlreadyRevised
+		}
+		// Never chain onto a report that was changed behind our back
+		if locked.Signature != "" && checkRevision(&locked) != "" {
+			return errTampered
+		}
+		revision.PreviousHash = locked.Hash()
+
+		if err := checkUploads(tx, user, added); err != nil {
+			return err
+		}
+
+		// Only the machine's latest listing report sets its condition and
+		// whether it is verified
+		latest := false
+		if revision.RentalID == nil {
+			head, err := latestListingReport(tx, revision.MachineID)
+			if err != nil {
+				return err
+			}
+			latest = head.ID == previous.ID
+		}
+
+		if err := signReport(&revision); err != nil {
+			return err
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if latest && scored {
+			if err := tx.Model(&models.Machine{}).Where("id = ?", revision.MachineID).Updates(map[string]interface{}{
+				"condition_score": result.Score,
+				"condition_grade": result.Grade,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if latest {
+			// A revision that fails the machine takes its verification away;
+			// one that passes it restores it. Sold machines stay sold.
+			from, to := "verified", "pending_inspection"
+			if inspectionPassed(&revision) {
+				from, to = to, from
+			}
+			if err := tx.Model(&models.Machine{}).Where("id = ? AND status = ?", revision.MachineID, from).
+				Update("status", to).Error; err != nil {
+				return err
+			}
+		}
+		return files.SetReferences(tx, files.Ref(models.AttachToInspection, revision.ID), newURLs)
+	})
+	if msg, ok := uploadError(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	switch {
+	case errors.Is(err, errAlreadyRevised):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This report has already been revised; revise its latest revision"})
+	case errors.Is(err, errTampered):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This report fails verification and cannot be revised"})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save revision"})
+	}
+
+	return c.JSON(http.StatusCreated, revision)
+}
+
+// VerifyInspectionReport godoc
+//
+//	@Summary		Verify an inspection report
+//	@Description	Check that a report is exactly as its inspector submitted it. The report's canonical content is re-hashed and its Ed25519 signature checked with the server key named by key_id; then every earlier revision is checked the same way, along with the links between them. valid is false, with the problem listed in chain, if anything was changed after signing. Reports submitted before signing was introduced are reported as unsigned.
+//	@Tags			Inspection
+//	@Produce		json
+//	@Param			id	path		string	true	"Report UUID"
+//	@Success		200	{object}	InspectionVerification
+//	@Failure		404	{object}	map[string]string	"Report not found"
+//	@Router			/inspections/{id}/verify [get]
+func VerifyInspectionReport(c echo.Context) error {
+	var report models.InspectionReport
+	if err := config.DB.Unscoped().First(&report, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Inspection report not found"})
+	}
+
+	resp := InspectionVerification{
+		ReportID:    report.ID,
+		Valid:       true,
+		ContentHash: report.Hash(),
+		Revision:    report.Revision,
+		Current:     true,
+	}
+	if report.Signature != "" {
+		resp.Algorithm = signing.Algorithm
+		resp.KeyID = report.SigningKeyID
+		if signing.Active != nil {
+			if key, ok := signing.Active.PublicKey(report.SigningKeyID); ok {
+				resp.PublicKey = base64.StdEncoding.EncodeToString(key)
+			}
+		}
+	}
+
+	var next models.InspectionReport
+	err := config.DB.Unscoped().Select("id").Where("previous_id = ?", report.ID).Limit(1).Find(&next).Error
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to verify report"})
+	}
+	if next.ID != uuid.Nil {
+		resp.Current = false
+		resp.SupersededBy = &next.ID
+	}
+
+	// Walk back through the revisions
+	seen := map[uuid.UUID]bool{}
+	current := &report
+	for current != nil && len(resp.Chain) < maxRevisions {
+		seen[current.ID] = true
+		check := RevisionCheck{ReportID: current.ID, Revision: current.Revision, Problem: checkRevision(current)}
+		problem := func(p string) {
+			if check.Problem == "" {
+				check.Problem = p
+			}
+		}
+
+		var previous *models.InspectionReport
+		if current.PreviousID != nil {
+			previous = &models.InspectionReport{}
+			err := config.DB.Unscoped().First(previous, "id = ?", current.PreviousID).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				problem("previous revision is missing")
+				previous = nil
+			case err != nil:
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to verify report"})
+			case seen[previous.ID]:
+				problem("revisions form a loop")
+				previous = nil
+			case previous.Hash() != current.PreviousHash:
+				problem("previous revision changed after this one was made")
+			case previous.Revision != current.Revision-1:
+				problem("revision numbers out of sequence")
+			}
+		}
+		check.Valid = check.Problem == ""
+		resp.Valid = resp.Valid && check.Valid
+		resp.Chain = append(resp.Chain, check)
+		current = previous
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// signReport hashes and signs a report that is about to be created
+func signReport(report *models.InspectionReport) error {
+	if signing.Active == nil {
+		return signing.ErrNoKey
+	}
+	keyID, signature := signing.Active.Sign(report.SignedContent())
+	report.ContentHash = report.Hash()
+	report.Signature = base64.StdEncoding.EncodeToString(signature)
+	report.SigningKeyID = keyID
+	return nil
+}
+
+// checkRevision checks one stored report against its own hash and
+// signature, describing the first problem found
+func checkRevision(report *models.InspectionReport) string {
+	if report.Signature == "" {
+		return "not signed"
+	}
+	if report.Hash() != report.ContentHash {
+		return "content changed after signing"
+	}
+	signature, err := base64.StdEncoding.DecodeString(report.Signature)
+	if err != nil {
+		return "signature is malformed"
+	}
+	if signing.Active == nil {
+		return signing.ErrNoKey.Error()
+	}
+	switch err := signing.Active.Verify(report.SigningKeyID, report.SignedContent(), signature); {
+	case errors.Is(err, signing.ErrUnknownKey):
+		return "signed with an unknown key"
+	case err != nil:
+		return "signature does not match"
+	}
+	return ""
+}
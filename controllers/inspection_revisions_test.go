@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestInspectionRevisions(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	db.Model(&machine).Update("status", "pending_inspection")
+	request := seedInspectionRequest(t, db, machine.ID, 3)
+
+	verify := func(id string) InspectionVerification {
+		c, rec := paymentCtx(e, http.MethodGet, "", 0, "", "id", id)
+		if err := VerifyInspectionReport(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+		}
+		var v InspectionVerification
+		json.Unmarshal(rec.Body.Bytes(), &v)
+		return v
+	}
+	revise := func(id, body string, userID uint) (int, models.InspectionReport) {
+		c, rec := paymentCtx(e, http.MethodPost, body, userID, "inspector", "id", id)
+		if err := ReviseInspectionReport(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var report models.InspectionReport
+		json.Unmarshal(rec.Body.Bytes(), &report)
+		return rec.Code, report
+	}
+
+	// 1. Reports are signed when submitted
+	body := `{"machine_id":"` + machine.ID.String() + `","report_type":"listing","request_id":"` + request.ID.String() + `","verdict":"Pass","summary":"Good","report_data":{"hours":1200}}`
+	c, rec := paymentCtx(e, http.MethodPost, body, 3, "inspector", "", "")
+	CreateInspectionReport(c)
+	var original models.InspectionReport
+	json.Unmarshal(rec.Body.Bytes(), &original)
+	if rec.Code != http.StatusCreated || original.Signature == "" || original.SigningKeyID == "" || original.Revision != 1 {
+		t.Fatalf("expected a signed report, got %d %s", rec.Code, rec.Body.String())
+	}
+	v := verify(original.ID.String())
+	if !v.Valid || !v.Current || v.Algorithm != "Ed25519" || v.PublicKey == "" || v.ContentHash != original.ContentHash || len(v.Chain) != 1 {
+		t.Fatalf("expected a valid report, got %+v", v)
+	}
+
+	var verified models.Machine
+	db.First(&verified, "id = ?", machine.ID)
+	if verified.Status != "verified" {
+		t.Fatalf("expected the passing report to verify the machine, got %s", verified.Status)
+	}
+
+	// 2. Only the report's inspector revises it, and says why
+	if code, _ := revise(original.ID.String(), `{"verdict":"Fail","note":"Leak found"}`, 4); code != http.StatusForbidden {
+		t.Errorf("expected 403 for another inspector, got %d", code)
+	}
+	if code, _ := revise(original.ID.String(), `{"verdict":"Fail"}`, 3); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a note, got %d", code)
+	}
+	if code, _ := revise(original.ID.String(), `{"verdict":"Excellent","note":"Better than it looked"}`, 3); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown verdict, got %d", code)
+	}
+
+	// 3. A revision is a new signed report; the original is untouched
+	code, revision := revise(original.ID.String(), `{"verdict":"Fail","note":"Hydraulic leak found on re-check"}`, 3)
+	if code != http.StatusCreated || revision.Revision != 2 || revision.PreviousID == nil || *revision.PreviousID != original.ID ||
+		revision.PreviousHash != original.ContentHash || revision.Verdict != "Fail" || revision.Summary != "Good" || revision.Signature == "" {
+		t.Fatalf("unexpected revision %d %+v", code, revision)
+	}
+	var stored models.InspectionReport
+	db.First(&stored, "id = ?", original.ID)
+	if stored.Verdict != "Pass" {
+		t.Errorf("expected the original to keep its verdict, got %s", stored.Verdict)
+	}
+	var failed models.Machine
+	db.First(&failed, "id = ?", machine.ID)
+	if failed.Status != "pending_inspection" {
+		t.Errorf("expected the failing revision to unverify the machine, got %s", failed.Status)
+	}
+	if code, _ := revise(original.ID.String(), `{"verdict":"Pass","note":"Second thoughts"}`, 3); code != http.StatusConflict {
+		t.Errorf("expected 409 revising a superseded report, got %d", code)
+	}
+
+	c, rec = paymentCtx(e, http.MethodGet, "", 0, "", "machine_id", machine.ID.String())
+	GetMachineInspection(c)
+	var latest models.InspectionReport
+	json.Unmarshal(rec.Body.Bytes(), &latest)
+	if latest.ID != revision.ID {
+		t.Errorf("expected the machine to show the revision, got %s", latest.ID)
+	}
+
+	v = verify(original.ID.String())
+	if !v.Valid || v.Current || v.SupersededBy == nil || *v.SupersededBy != revision.ID {
+		t.Errorf("expected a valid superseded report, got %+v", v)
+	}
+	v = verify(revision.ID.String())
+	if !v.Valid || !v.Current || len(v.Chain) != 2 || v.Chain[1].ReportID != original.ID {
+		t.Fatalf("expected a valid chain of two, got %+v", v)
+	}
+
+	// 4. Editing a row directly breaks its signature and the chain
+	db.Model(&models.InspectionReport{}).Where("id = ?", original.ID).Update("verdict", "Fail")
+	v = verify(revision.ID.String())
+	if v.Valid || v.Chain[0].Valid || v.Chain[1].Problem != "content changed after signing" {
+		t.Errorf("expected the edit to be caught, got %+v", v)
+	}
+	if v.Chain[0].Problem != "previous revision changed after this one was made" {
+		t.Errorf("expected the broken link to be reported, got %+v", v.Chain[0])
+	}
+
+	// 5. Unsigned reports from before signing are reported as such
+	legacy := models.InspectionReport{MachineID: machine.ID, InspectorID: 3, Verdict: "Pass"}
+	db.Create(&legacy)
+	if v := verify(legacy.ID.String()); v.Valid || v.Chain[0].Problem != "not signed" {
+		t.Errorf("expected an unsigned report, got %+v", v)
+	}
+}
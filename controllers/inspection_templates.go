@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/checklist"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InspectionTemplateInput is a category's new inspection checklist
+type InspectionTemplateInput struct {
+	Sections checklist.Sections `json:"sections"`
+}
+
+// GetInspectionTemplate godoc
+//
+//	@Summary		Get a category's inspection checklist
+//	@Description	Retrieve the current inspection template of a category: its sections, checklist items (pass_fail checks and measurements with an acceptable min/max) and their weights. Inspection reports for machines in the category answer these items in report_data, keyed by item, e.g. {"spindle_noise": "pass", "spindle_runout_mm": 0.004}.
+//	@Tags			Categories
+//	@Produce		json
+//	@Param			slug	path		string	true	"Category slug"
+//	@Success		200		{object}	models.InspectionTemplate
+//	@Failure		404		{object}	map[string]string	"Category or template not found"
+//	@Router			/categories/{slug}/inspection-template [get]
+func GetInspectionTemplate(c echo.Context) error {
+	var category models.Category
+	if err := config.DB.Where("slug = ? AND active = ?", c.Param("slug"), true).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	}
+
+	template, err := latestTemplate(config.DB, category.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "This category has no inspection checklist"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch inspection checklist"})
+	}
+	return c.JSON(http.StatusOK, template)
+}
+
+// SaveInspectionTemplate godoc
+//
+//	@Summary		Set a category's inspection checklist
+//	@Description	Save a new version of a category's inspection template. Earlier versions are kept, so existing reports stay tied to the checklist they were scored against. Item keys must be unique across the template. Each section scores the weighted share of its answered items that passed (measurements pass within min/max); the condition score (0-100) weighs the sections, and grades run A (90+), B (75+), C (60+), D (40+) and F. Admin only.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			slug		path		string					true	"Category slug"
+//	@Param			template	body		InspectionTemplateInput	true	"Checklist"
+//	@Success		201			{object}	models.InspectionTemplate
+//	@Failure		400			{object}	ValidationErrorResponse	"Invalid template"
+//	@Failure		403			{object}	map[string]string		"Admins only"
+//	@Failure		404			{object}	map[string]string		"Category not found"
+//	@Router			/admin/categories/{slug}/inspection-template [put]
+func SaveInspectionTemplate(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.CategoryManage) {
+		return policy.Forbid(c, policy.CategoryManage, "Only admins can manage categories")
+	}
+
+	var category models.Category
+	if err := config.DB.Where("slug = ?", c.Param("slug")).First(&category).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+	}
+
+	var input InspectionTemplateInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if err := checklist.ValidateTemplate(input.Sections); err != nil {
+		return validationError(c, "Invalid inspection template", err)
+	}
+
+	template := models.InspectionTemplate{CategoryID: category.ID, Sections: input.Sections, CreatedBy: user.ID}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the category so concurrent saves take successive versions
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Category{}, "id = ?", category.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.InspectionTemplate{}).Where("category_id = ?", category.ID).
+			Select("COALESCE(MAX(version), 0) + 1").Scan(&template.Version).Error; err != nil {
+			return err
+		}
+		return tx.Create(&template).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save inspection template"})
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+// latestTemplate returns the current version of a category's checklist
+func latestTemplate(db *gorm.DB, categoryID interface{}) (*models.InspectionTemplate, error) {
+	var template models.InspectionTemplate
+	if err := db.Where("category_id = ?", categoryID).Order("version desc").First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// machineTemplate returns the checklist a machine's inspections are scored
+// against, or nil when its category has none
+func machineTemplate(machine *models.Machine) (*models.InspectionTemplate, error) {
+	if machine.Category == "" {
+		return nil, nil
+	}
+	var category models.Category
+	err := config.DB.Where("active = ? AND (LOWER(name) = LOWER(?) OR slug = ?)", true, machine.Category, strings.ToLower(machine.Category)).
+		First(&category).Error
+	if err == nil {
+		var template *models.InspectionTemplate
+		if template, err = latestTemplate(config.DB, category.ID); err == nil {
+			return template, nil
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+const millTemplate = `{"sections": [
+	{"key": "spindle", "label": "Spindle", "weight": 3, "items": [
+		{"key": "spindle_noise", "label": "Runs quietly", "type": "pass_fail", "weight": 1, "required": true},
+		{"key": "spindle_runout_mm", "label": "Runout", "type": "measurement", "unit": "mm", "max": 0.01, "weight": 2}
+	]},
+	{"key": "hydraulics", "label": "Hydraulics", "weight": 1, "items": [
+		{"key": "hydraulic_leaks", "label": "No leaks", "type": "pass_fail", "weight": 1}
+	]}
+]}`
+
+func TestInspectionTemplates(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	seedCategory(t, db)
+	db.Model(&machine).Updates(map[string]interface{}{"category": "CNC Mill", "status": "pending_inspection"})
+
+	// 1. Only admins save checklists, and only valid ones
+	c, rec := paymentCtx(e, http.MethodPut, millTemplate, 1, "seller", "slug", "cnc-mill")
+	SaveInspectionTemplate(c)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a seller, got %d", rec.Code)
+	}
+	c, rec = paymentCtx(e, http.MethodPut, `{"sections":[{"key":"Spindle","items":[]}]}`, 9, "admin", "slug", "cnc-mill")
+	SaveInspectionTemplate(c)
+	var invalid ValidationErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &invalid)
+	if rec.Code != http.StatusBadRequest || invalid.Fields["sections[0].key"] == "" {
+		t.Errorf("expected field errors for an invalid template, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 2. Each save is a new version; the latest is public
+	for version := 1; version <= 2; version++ {
+		c, rec = paymentCtx(e, http.MethodPut, millTemplate, 9, "admin", "slug", "cnc-mill")
+		SaveInspectionTemplate(c)
+		var saved models.InspectionTemplate
+		json.Unmarshal(rec.Body.Bytes(), &saved)
+		if rec.Code != http.StatusCreated || saved.Version != version {
+			t.Fatalf("expected version %d, got %d %s", version, rec.Code, rec.Body.String())
+		}
+	}
+	c, rec = paymentCtx(e, http.MethodGet, "", 0, "", "slug", "cnc-mill")
+	GetInspectionTemplate(c)
+	var current models.InspectionTemplate
+	json.Unmarshal(rec.Body.Bytes(), &current)
+	if rec.Code != http.StatusOK || current.Version != 2 || len(current.Sections) != 2 {
+		t.Fatalf("expected the latest template, got %d %+v", rec.Code, current)
+	}
+
+	// 3. Reports must answer the checklist
+	request := seedInspectionRequest(t, db, machine.ID, 3)
+	submit := func(data string) (int, models.InspectionReport) {
+		body := `{"machine_id":"` + machine.ID.String() + `","report_type":"listing","request_id":"` + request.ID.String() + `","verdict":"Pass","report_data":` + data + `}`
+		c, rec := paymentCtx(e, http.MethodPost, body, 3, "inspector", "", "")
+		if err := CreateInspectionReport(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var report models.InspectionReport
+		json.Unmarshal(rec.Body.Bytes(), &report)
+		return rec.Code, report
+	}
+	if code, _ := submit(`{"spindle_runout_mm":"tight","paint":"pass"}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid answers, got %d", code)
+	}
+
+	// 4. A worn spindle fails the machine: (33.3*3 + 0) / 4
+	code, report := submit(`{"spindle_noise":"Pass","spindle_runout_mm":0.02,"hydraulic_leaks":"fail"}`)
+	if code != http.StatusCreated || report.ConditionScore == nil || *report.ConditionScore != 25 || report.ConditionGrade != "F" || *report.TemplateID != current.ID {
+		t.Fatalf("unexpected scored report %d %+v", code, report)
+	}
+	var stored models.Machine
+	db.First(&stored, "id = ?", machine.ID)
+	if stored.Status != "pending_inspection" || stored.ConditionGrade != "F" {
+		t.Errorf("expected an F machine to stay unverified, got %s %s", stored.Status, stored.ConditionGrade)
+	}
+
+	// 5. After repairs the latest report sets the listing's condition
+	request = seedInspectionRequest(t, db, machine.ID, 3)
+	_, report = submit(`{"spindle_noise":"pass","spindle_runout_mm":0.005,"hydraulic_leaks":"pass"}`)
+	db.First(&stored, "id = ?", machine.ID)
+	if report.ConditionGrade != "A" || stored.Status != "verified" || stored.ConditionScore == nil || *stored.ConditionScore != 100 || stored.ConditionGrade != "A" {
+		t.Errorf("expected a verified A machine, got %s %v %s", stored.Status, stored.ConditionScore, stored.ConditionGrade)
+	}
+
+	// 6. A rental's check-out report is scored but leaves the listing alone
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+	body := `{"machine_id":"` + machine.ID.String() + `","report_type":"check_out","rental_id":"` + rental.ID.String() + `","verdict":"Pass","report_data":{"spindle_noise":"pass","spindle_runout_mm":0.02,"hydraulic_leaks":"fail"}}`
+	c, rec = paymentCtx(e, http.MethodPost, body, 3, "inspector", "", "")
+	CreateInspectionReport(c)
+	json.Unmarshal(rec.Body.Bytes(), &report)
+	db.First(&stored, "id = ?", machine.ID)
+	if rec.Code != http.StatusCreated || report.ConditionGrade != "F" || stored.ConditionGrade != "A" {
+		t.Errorf("expected the listing to keep grade A, got %d %s %s", rec.Code, report.ConditionGrade, stored.ConditionGrade)
+	}
+}
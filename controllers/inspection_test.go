@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/files"
 	"github.com/vishwakarma-setu-backend/models"
 )
 
@@ -23,17 +24,22 @@ func TestCreateInspectionReport_Success(t *testing.T) {
 		t.Fatalf("failed to migrate inspection table: %v", err)
 	}
 
-	// 3. Prepare Payload
+	// 3. Media must be the inspector's own upload, for a scheduled inspection
+	upload, mediaURL := seedUpload(t, db, 3)
+	request := seedInspectionRequest(t, db, machine.ID, 3)
+
+	// 4. Prepare Payload
 	payload := `{
 		"machine_id": "` + machine.ID.String() + `",
 		"report_type": "listing",
-		"verdict": "Good",
+		"request_id": "` + request.ID.String() + `",
+		"verdict": "pass",
 		"summary": "Machine runs smoothly.",
 		"report_data": {
 			"hydraulic_pressure": "Pass",
 			"spindle_noise": "Normal"
 		},
-		"media_urls": ["/uploads/img1.jpg"]
+		"media_urls": ["` + mediaURL + `"]
 	}`
 
 	req := httptest.NewRequest(http.MethodPost, "/api/inspections", strings.NewReader(payload))
@@ -41,19 +47,19 @@ func TestCreateInspectionReport_Success(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	// 4. Mock Auth (Inspector ID 3)
+	// 5. Mock Auth (Inspector ID 3)
 	testToken := createTestToken(3, "inspector")
 	token, _ := jwt.ParseWithClaims(testToken, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
 		return []byte(os.Getenv("JWT_SECRET")), nil
 	})
 	c.Set("user", token)
 
-	// 5. Execute
+	// 6. Execute
 	if err := CreateInspectionReport(c); err != nil {
 		t.Fatalf("handler error: %v", err)
 	}
 
-	// 6. Assertions
+	// 7. Assertions
 	if rec.Code != http.StatusCreated {
 		t.Errorf("expected status 201, got %d. Body: %s", rec.Code, rec.Body.String())
 	}
@@ -63,12 +69,16 @@ func TestCreateInspectionReport_Success(t *testing.T) {
 		t.Fatalf("invalid response json: %v", err)
 	}
 
-	if resp.Verdict != "Good" {
-		t.Errorf("expected verdict 'Good', got '%s'", resp.Verdict)
+	if resp.Verdict != "Pass" {
+		t.Errorf("expected verdict 'Pass', got '%s'", resp.Verdict)
 	}
 	if resp.MachineID != machine.ID {
 		t.Errorf("expected machine ID match")
 	}
+	db.First(&upload, "id = ?", upload.ID)
+	if want := `["` + files.Ref(models.AttachToInspection, resp.ID) + `"]`; string(upload.ReferencedBy) != want {
+		t.Errorf("expected the media upload to be referenced by the report, got %s", upload.ReferencedBy)
+	}
 
 	// Verify Machine Status Updated to 'verified'
 	var updatedMachine models.Machine
@@ -105,6 +115,70 @@ func TestCreateInspectionReport_MachineNotFound(t *testing.T) {
 	}
 }
 
+func TestCreateInspectionReport_Forbidden(t *testing.T) {
+	e := echo.New()
+
+	payload := `{"machine_id": "00000000-0000-0000-0000-000000000000", "verdict": "Pass"}`
+
+	for _, role := range []string{"seller", "buyer"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/inspections", strings.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		testToken := createTestToken(1, role)
+		token, _ := jwt.ParseWithClaims(testToken, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		})
+		c.Set("user", token)
+
+		CreateInspectionReport(c)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 for %s, got %d", role, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), `"permission":"inspection:submit"`) {
+			t.Errorf("expected the missing permission in the body, got %s", rec.Body.String())
+		}
+	}
+}
+
+func TestCreateInspectionReport_RentalReportsDoNotVerify(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	db.Model(&machine).Update("status", "pending_inspection")
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+
+	// Unknown report types are rejected
+	body := `{"machine_id":"` + machine.ID.String() + `","report_type":"walkaround","verdict":"Pass"}`
+	c, rec := paymentCtx(e, http.MethodPost, body, 4, "inspector", "", "")
+	CreateInspectionReport(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown report type, got %d", rec.Code)
+	}
+
+	// So are verdicts other than Pass and Fail
+	body = `{"machine_id":"` + machine.ID.String() + `","report_type":"check_out","rental_id":"` + rental.ID.String() + `","verdict":"Excellent"}`
+	c, rec = paymentCtx(e, http.MethodPost, body, 4, "inspector", "", "")
+	CreateInspectionReport(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown verdict, got %d", rec.Code)
+	}
+
+	// A check-out report does not stand in for a listing inspection
+	body = `{"machine_id":"` + machine.ID.String() + `","report_type":"check_out","rental_id":"` + rental.ID.String() + `","verdict":"Pass"}`
+	c, rec = paymentCtx(e, http.MethodPost, body, 4, "inspector", "", "")
+	CreateInspectionReport(c)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body.String())
+	}
+	var stored models.Machine
+	db.First(&stored, "id = ?", machine.ID)
+	if stored.Status != "pending_inspection" {
+		t.Errorf("expected the machine to stay pending_inspection, got %s", stored.Status)
+	}
+}
+
 func TestGetMachineInspection_Success(t *testing.T) {
 	e := echo.New()
 	machine, db := seedRentableMachine(t)
@@ -114,7 +188,7 @@ func TestGetMachineInspection_Success(t *testing.T) {
 	report := models.InspectionReport{
 		MachineID:   machine.ID,
 		InspectorID: 3,
-		Verdict:     "Excellent",
+		Verdict:     "Pass",
 		Summary:     "Top condition",
 	}
 	db.Create(&report)
@@ -136,8 +210,8 @@ func TestGetMachineInspection_Success(t *testing.T) {
 
 	var resp models.InspectionReport
 	json.Unmarshal(rec.Body.Bytes(), &resp)
-	if resp.Verdict != "Excellent" {
-		t.Errorf("expected verdict 'Excellent', got '%s'", resp.Verdict)
+	if resp.Verdict != "Pass" {
+		t.Errorf("expected verdict 'Pass', got '%s'", resp.Verdict)
 	}
 }
 
@@ -1,55 +1,55 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/geo"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/specs"
+	"github.com/vishwakarma-setu-backend/storage"
+	"github.com/vishwakarma-setu-backend/validation"
+	"gorm.io/gorm"
 )
 
-// Helper struct for token data
-type UserClaims struct {
-	ID   uint
-	Role string
-}
-
-// Updated Helper to extract BOTH ID and Role from JWT
-func getUserClaims(c echo.Context) (*UserClaims, error) {
-	userToken := c.Get("user").(*jwt.Token)
-	claims := userToken.Claims.(*jwt.MapClaims)
+// wordSimilarity is the pg_trgm word similarity above which a title,
+// manufacturer or model number counts as a match for a search term ("Hass"
+// vs "Haas" is 0.4). It is the threshold of the indexable <% operator, set
+// per transaction by wordSimilaritySQL.
+const wordSimilarity = 0.35
 
-	// Extract User ID
-	// JSON numbers are float64 by default
-	idFloat, okID := (*claims)["user_id"].(float64)
+// wordSimilaritySQL sets the <% threshold for the current transaction only,
+// so pooled connections keep the server default
+var wordSimilaritySQL = fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", wordSimilarity)
 
-	// Extract Role
-	role, okRole := (*claims)["role"].(string)
-
-	if !okID || !okRole {
-		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims: missing user_id or role")
-	}
+// UserClaims is the authenticated caller
+type UserClaims = policy.Subject
 
-	return &UserClaims{
-		ID:   uint(idFloat),
-		Role: role,
-	}, nil
+// getUserClaims extracts the caller's ID and role from the JWT
+func getUserClaims(c echo.Context) (*UserClaims, error) {
+	return policy.SubjectFrom(c)
 }
 
 // CreateListing godoc
 //
 //	@Summary		Create a new machine listing
-//	@Description	Register a new machine for sale or rent. Requires Seller or Admin Role.
+//	@Description	Register a new machine for sale or rent. Requires Seller or Admin Role. Send X-Organization-ID to list the machine for a company you belong to. If a category is given it must be registered, and specs are validated against its schema (see GET /categories/{slug}/schema). Latitude and longitude are geocoded from the location unless both are given. New listings await inspection; any status sent is ignored.
 //	@Tags			Machines
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			machine	body		models.Machine	true	"Machine Details"
 //	@Success		201		{object}	models.Machine
-//	@Failure		400		{object}	map[string]string	"Invalid input"
+//	@Failure		400		{object}	ValidationErrorResponse	"Invalid input, specs or coordinates"
 //	@Failure		401		{object}	map[string]string	"Unauthorized"
 //	@Failure		403		{object}	map[string]string	"Forbidden (Buyers cannot list)"
 //	@Router			/machines [post]
@@ -65,11 +65,26 @@ func CreateListing(c echo.Context) error {
 	}
 
 	// RBAC Check: Only Sellers or Admins can create listings
-	if user.Role != "seller" && user.Role != "admin" {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only sellers can list machines"})
+	if !user.Can(policy.MachineCreate) {
+		return policy.Forbid(c, policy.MachineCreate, "Only sellers can list machines")
 	}
 
 	machine.SellerID = user.ID
+	machine.OrganizationID = user.OrgID()
+	// Photos are added through the gallery endpoints, which check uploads
+	machine.Images = nil
+	// Condition comes from scored inspections
+	machine.ConditionScore = nil
+	machine.ConditionGrade = ""
+	// Only an inspection verifies a machine
+	machine.Status = "pending_inspection"
+
+	if err := validateMachineSpecs(&machine, ""); err != nil {
+		return validationError(c, "Invalid specs", err)
+	}
+	if err := locateMachine(&machine); err != nil {
+		return validationError(c, "Invalid location", err)
+	}
 
 	if err := config.DB.Create(&machine).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not create listing: " + err.Error()})
@@ -81,27 +96,44 @@ func CreateListing(c echo.Context) error {
 // GetAllListings godoc
 //
 //	@Summary		Get all machine listings
-//	@Description	Retrieve a list of machines with optional filtering, sorting, and pagination.
+//	@Description	Retrieve a list of machines with optional filtering and sorting, a page at a time: pass the next_cursor of one page as cursor to get the next. Sold machines are not listed. With a search query each result carries a relevance score. Together with a category, specs can be filtered as specs.<key>=value or specs.<key>[op]=value (op: eq, ne, gt, gte, lt, lte, in), e.g. specs.axes=5&specs.spindle_speed_rpm[gte]=8000; keys and values are checked against the category's schema. With near (a lat,lng pair, a city or a PIN code) each result carries its distance_km, radius_km limits how far away listings may be and sort=distance puts the closest first; listings whose location could not be placed are left out of radius searches. Listings with photos carry the cover photo's cover_thumbnail_url.
 //	@Tags			Machines
 //	@Produce		json
-//	@Param			q				query		string	false	"Search query"
+//	@Param			q				query		string	false	"Full-text search over title, manufacturer, model number, category and description; titles, manufacturers and model numbers also match when misspelt"
 //	@Param			category		query		string	false	"Filter by Category"
-//	@Param			manufacturer	query		string	false	"Filter by Manufacturer"
+//	@Param			manufacturer	query		string	false	"Filter by Manufacturer (tolerates typos)"
 //	@Param			location		query		string	false	"Filter by Location"
 //	@Param			type			query		string	false	"Filter by Listing Type (sale, rent)"
-//	@Param			sort			query		string	false	"Sort order (price_asc, price_desc, oldest)"
-//	@Param			page			query		int		false	"Page number"
-//	@Param			limit			query		int		false	"Items per page"
-//	@Success		200				{object}	map[string]interface{}
+//	@Param			near			query		string	false	"Search point: lat,lng, a city or a PIN code"
+//	@Param			radius_km		query		number	false	"Only listings within this many km of near"
+//	@Param			sort			query		string	false	"Sort order (relevance, distance, price_asc, price_desc, oldest)"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching listings"
+//	@Success		200				{object}	pagination.Page[models.Machine]
+//	@Failure		400				{object}	map[string]string	"Invalid filter or cursor"
 //	@Router			/machines [get]
 func GetAllListings(c echo.Context) error {
-	var machines []models.Machine
-	query := config.DB.Model(&models.Machine{})
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(wordSimilaritySQL).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not fetch listings"})
+		}
+		return listListings(c, tx)
+	})
+}
 
-	// 1. Keyword Search
-	if q := c.QueryParam("q"); q != "" {
-		searchTerm := "%" + strings.ToLower(q) + "%"
-		query = query.Where("LOWER(title) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?)", searchTerm, searchTerm)
+// listListings serves GetAllListings within tx, which has the trigram
+// threshold set
+func listListings(c echo.Context, tx *gorm.DB) error {
+	query := tx.Model(&models.Machine{}).Where("status <> ?", models.MachineSold)
+
+	// 1. Keyword Search: full-text over the weighted search_vector, plus
+	// trigram matching so misspelt titles, manufacturers ("Hass" for "Haas")
+	// and model numbers still match
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q != "" {
+		query = query.Where("search_vector @@ websearch_to_tsquery('english', ?) OR ? <% manufacturer OR ? <% title OR ? <% model_number",
+			q, q, q, q)
 	}
 
 	// 2. Exact Filters
@@ -109,12 +141,52 @@ func GetAllListings(c echo.Context) error {
 		query = query.Where("category = ?", category)
 	}
 	if manufacturer := c.QueryParam("manufacturer"); manufacturer != "" {
-		query = query.Where("manufacturer = ?", manufacturer)
+		query = query.Where("LOWER(manufacturer) = LOWER(?) OR ? <% manufacturer", manufacturer, manufacturer)
 	}
 	if location := c.QueryParam("location"); location != "" {
 		query = query.Where("LOWER(location) LIKE LOWER(?)", "%"+location+"%")
 	}
 
+	// 2b. Spec Filters: specs.<key>[op]=value, typed by the category's schema
+	if specs.HasFilters(c.QueryParams()) {
+		schema, err := categorySchema(c.QueryParam("category"))
+		if errors.Is(err, errUnknownCategory) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Spec filters need a registered category"})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not load category"})
+		}
+		filters, err := specs.ParseFilters(schema, c.QueryParams())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		for _, f := range filters {
+			sql, args := f.SQL("specs")
+			query = query.Where(sql, args...)
+		}
+	}
+
+	// 2c. Distance: near=lat,lng (or a city / PIN code), optionally within radius_km
+	var near *geo.Point
+	if nearParam := c.QueryParam("near"); nearParam != "" {
+		point, err := geo.Resolve(nearParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid near: " + err.Error()})
+		}
+		near = &point
+	}
+	if radiusParam := c.QueryParam("radius_km"); radiusParam != "" {
+		radius, err := strconv.ParseFloat(radiusParam, 64)
+		if err != nil || radius <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "radius_km must be a positive number"})
+		}
+		if near == nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "radius_km needs a near point"})
+		}
+		sql, args := near.WithinSQL("latitude", "longitude", radius)
+		query = query.Where(sql, args...)
+	}
+
 	// 3. Listing Type Filter
 	if listingType := c.QueryParam("type"); listingType != "" {
 		if listingType == "sale" {
@@ -132,51 +204,93 @@ func GetAllListings(c echo.Context) error {
 		query = query.Where("price_for_sale <= ?", maxPrice)
 	}
 
-	// 5. Sorting
-	sortParam := c.QueryParam("sort")
-	switch sortParam {
-	case "price_asc":
-		query = query.Order("price_for_sale asc")
-	case "price_desc":
-		query = query.Order("price_for_sale desc")
-	case "oldest":
-		query = query.Order("created_at asc")
-	default:
-		query = query.Order("created_at desc")
-	}
-
-	// 6. Pagination
-	page, _ := strconv.Atoi(c.QueryParam("page"))
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if page <= 0 {
-		page = 1
+	// 5. Scores: relevance for keyword searches, distance_km for near
+	// searches; and every result's cover photo thumbnail
+	selects := []string{"machines.*", coverThumbnailSQL + " AS cover_thumbnail_url"}
+	var selectArgs []interface{}
+	if q != "" {
+		selects = append(selects, relevanceSQL+" AS relevance")
+		selectArgs = append(selectArgs, q, q)
 	}
-	if limit <= 0 {
-		limit = 10
+	if near != nil {
+		distance, args := distanceSQL(*near)
+		selects = append(selects, distance+" AS distance_km")
+		selectArgs = append(selectArgs, args...)
 	}
-	offset := (page - 1) * limit
+	query = query.Select(strings.Join(selects, ", "), selectArgs...)
+
+	// 6. Sorting & keyset pagination
+	return respondPage(c, query, listingOrder(c.QueryParam("sort"), q, near), "Could not fetch listings")
+}
+
+// coverThumbnailSQL is the thumbnail of a listing's cover photo
+const coverThumbnailSQL = "(SELECT thumbnail_url FROM machine_images WHERE machine_images.machine_id = machines.id AND machine_images.is_cover LIMIT 1)"
 
-	var total int64
-	query.Count(&total)
+// relevanceSQL scores a listing against the search terms (bound twice)
+const relevanceSQL = "ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) + word_similarity(?, manufacturer)"
 
-	if err := query.Offset(offset).Limit(limit).Find(&machines).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not fetch listings"})
+// distanceSQL is a listing's distance in km from near, to 0.1 km
+func distanceSQL(near geo.Point) (string, []interface{}) {
+	distance, args := near.DistanceSQL("latitude", "longitude")
+	return "round((" + distance + ")::numeric, 1)::float8", args
+}
+
+// listingOrder is the keyset order for a sort parameter. Every order ends
+// with the id so listings with equal keys still page deterministically.
+func listingOrder(sortParam, q string, near *geo.Point) pagination.Order[models.Machine] {
+	id := func(desc bool) pagination.Key[models.Machine] {
+		return pagination.Key[models.Machine]{Expr: "machines.id", Type: "uuid", Desc: desc,
+			Value: func(m *models.Machine) interface{} { return m.ID }}
+	}
+	createdAt := func(desc bool) pagination.Key[models.Machine] {
+		return pagination.Key[models.Machine]{Expr: "machines.created_at", Type: "timestamptz", Desc: desc,
+			Value: func(m *models.Machine) interface{} { return m.CreatedAt }}
+	}
+	price := func(desc bool) pagination.Key[models.Machine] {
+		return pagination.Key[models.Machine]{Expr: "machines.price_for_sale", Type: "numeric", Desc: desc,
+			Value: func(m *models.Machine) interface{} { return m.PriceForSale }}
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data":        machines,
-		"total":       total,
-		"page":        page,
-		"limit":       limit,
-		"total_pages": (total + int64(limit) - 1) / int64(limit),
-	})
+	switch {
+	case sortParam == "relevance" && q != "":
+		return pagination.Order[models.Machine]{
+			{Expr: relevanceSQL, Args: []interface{}{q, q}, Type: "real", Desc: true,
+				Value: func(m *models.Machine) interface{} { return m.Relevance }},
+			id(true),
+		}
+	case sortParam == "distance" && near != nil:
+		// Listings that could not be placed come last
+		distance, args := distanceSQL(*near)
+		return pagination.Order[models.Machine]{
+			{Expr: "coalesce(" + distance + ", 'Infinity')", Args: args, Type: "float8",
+				Value: func(m *models.Machine) interface{} {
+					if m.DistanceKm == nil {
+						return math.Inf(1)
+					}
+					return *m.DistanceKm
+				}},
+			id(false),
+		}
+	case sortParam == "price_asc":
+		return pagination.Order[models.Machine]{price(false), id(false)}
+	case sortParam == "price_desc":
+		return pagination.Order[models.Machine]{price(true), id(true)}
+	case sortParam == "oldest":
+		return pagination.Order[models.Machine]{createdAt(false), id(false)}
+	default:
+		return pagination.Order[models.Machine]{createdAt(true), id(true)}
+	}
 }
 
-// GetListingByID - GET /api/machines/:id
+// GetListingByID - GET /api/machines/:id, with the photo gallery in
+// display order
 func GetListingByID(c echo.Context) error {
 	id := c.Param("id")
 	var machine models.Machine
-	if err := config.DB.First(&machine, "id = ?", id).Error; err != nil {
+	err := config.DB.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, created_at")
+	}).First(&machine, "id = ?", id).Error
+	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
 	}
 	return c.JSON(http.StatusOK, machine)
@@ -185,7 +299,7 @@ func GetListingByID(c echo.Context) error {
 // UpdateListing godoc
 //
 //	@Summary		Update a listing
-//	@Description	Update details. Only Owner or Admin can perform this.
+//	@Description	Update details. Only the Owner (any member of the owning organization, for company listings) or an Admin can perform this. Specs are validated against the category's schema. Latitude and longitude are geocoded from the location unless both are given. When the location changes it is geocoded again, unless new coordinates are sent with it. The status is set by inspections and sales and cannot be changed here.
 //	@Tags			Machines
 //	@Accept			json
 //	@Produce		json
@@ -193,6 +307,7 @@ func GetListingByID(c echo.Context) error {
 //	@Param			id		path		string			true	"Machine ID"
 //	@Param			machine	body		models.Machine	true	"Updated Data"
 //	@Success		200		{object}	models.Machine
+//	@Failure		400		{object}	ValidationErrorResponse	"Invalid specs or coordinates"
 //	@Failure		403		{object}	map[string]string	"Not authorized"
 //	@Router			/machines/{id} [put]
 func UpdateListing(c echo.Context) error {
@@ -208,9 +323,9 @@ func UpdateListing(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 	}
 
-	// RBAC: Allow owner OR admin to update
-	if machine.SellerID != user.ID && user.Role != "admin" {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "You are not authorized to update this listing"})
+	// Owners may update their listings, admins any listing
+	if !user.Allows(policy.MachineUpdate, machineOwner(&machine)) {
+		return policy.Forbid(c, policy.MachineUpdate, "You are not authorized to update this listing")
 	}
 
 	var updateData models.Machine
@@ -218,26 +333,49 @@ func UpdateListing(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
 	}
 
+	// A new location is geocoded again unless new coordinates came with
+	// it: coordinates echoed back from the listing would place it where it
+	// used to be
+	if !sameLocation(machine.Location, updateData.Location) &&
+		sameCoordinate(machine.Latitude, updateData.Latitude) && sameCoordinate(machine.Longitude, updateData.Longitude) {
+		updateData.Latitude, updateData.Longitude = nil, nil
+	}
+
+	previousCategory := machine.Category
 	machine.Title = updateData.Title
 	machine.Description = updateData.Description
 	machine.Category = updateData.Category
 	machine.Location = updateData.Location
+	machine.Latitude = updateData.Latitude
+	machine.Longitude = updateData.Longitude
 	machine.PriceForSale = updateData.PriceForSale
 	machine.RentalPricePerMonth = updateData.RentalPricePerMonth
+	machine.RentalPricePerWeek = updateData.RentalPricePerWeek
 	machine.RentalPricePerDay = updateData.RentalPricePerDay
+	machine.MinRentalDays = updateData.MinRentalDays
 	machine.SecurityDeposit = updateData.SecurityDeposit
 	machine.Specs = updateData.Specs
-	machine.Status = updateData.Status
+	// Status is not the seller's to set: inspections verify a machine and
+	// accepted offers sell it
 	machine.ListingType = updateData.ListingType
 
-	config.DB.Save(&machine)
+	if err := validateMachineSpecs(&machine, previousCategory); err != nil {
+		return validationError(c, "Invalid specs", err)
+	}
+	if err := locateMachine(&machine); err != nil {
+		return validationError(c, "Invalid location", err)
+	}
+
+	if err := config.DB.Save(&machine).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update listing"})
+	}
 	return c.JSON(http.StatusOK, machine)
 }
 
 // DeleteListing godoc
 //
 //	@Summary		Delete a listing
-//	@Description	Remove a listing. Only Owner or Admin can perform this.
+//	@Description	Remove a listing with its photos and documents. Only the Owner (any member of the owning organization, for company listings) or an Admin can perform this. Uploads used only by the listing's photos are deleted after UPLOAD_ORPHAN_GRACE.
 //	@Tags			Machines
 //	@Produce		json
 //	@Security		BearerAuth
@@ -258,13 +396,73 @@ func DeleteListing(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 	}
 
-	// RBAC: Allow deletion if user owns it OR user is admin
-	if machine.SellerID != user.ID && user.Role != "admin" {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "You are not authorized to delete this listing"})
+	// Owners may delete their listings, admins any listing
+	if !user.Allows(policy.MachineDelete, machineOwner(&machine)) {
+		return policy.Forbid(c, policy.MachineDelete, "You are not authorized to delete this listing")
 	}
 
-	if err := config.DB.Delete(&machine).Error; err != nil {
+	// The listing's photos and documents go with it, and their uploads are
+	// swept once nothing else refers to them. Deleting the machine first
+	// takes its row lock, so a concurrent gallery edit finds it gone.
+	var attachments []models.Attachment
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&machine).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("machine_id = ?", machine.ID).Delete(&models.MachineImage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("parent_type = ? AND parent_id = ?", models.AttachToMachine, machine.ID).Find(&attachments).Error; err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			if err := tx.Delete(&attachments).Error; err != nil {
+				return err
+			}
+		}
+		return files.Release(tx, files.Ref(models.AttachToMachine, machine.ID))
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete listing"})
 	}
+	// Stored documents are removed once the delete has committed
+	for _, attachment := range attachments {
+		if err := storage.Active.Delete(c.Request().Context(), attachment.StorageKey); err != nil {
+			c.Logger().Errorf("deleting attachment file %s: %v", attachment.StorageKey, err)
+		}
+	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "Listing deleted successfully"})
 }
+
+// locateMachine geocodes a machine's location unless the seller gave both
+// coordinates. Locations the dataset cannot place are left without any.
+func locateMachine(machine *models.Machine) error {
+	if machine.Latitude == nil && machine.Longitude == nil {
+		if place, ok := geo.Geocode(machine.Location); ok {
+			machine.Latitude, machine.Longitude = &place.Lat, &place.Lng
+		}
+		return nil
+	}
+
+	if machine.Latitude == nil || machine.Longitude == nil {
+		return validation.Field("location", "latitude", "latitude and longitude must be given together")
+	}
+	if !(geo.Point{Lat: *machine.Latitude, Lng: *machine.Longitude}).Valid() {
+		return validation.Field("location", "latitude", geo.ErrInvalidPoint.Error())
+	}
+	return nil
+}
+
+// sameLocation reports whether two location strings name the same place,
+// ignoring case and surrounding space
+func sameLocation(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// sameCoordinate reports whether two optional coordinates are equal
+func sameCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	// "strconv"
 	"strings"
@@ -15,12 +19,19 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/config"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/payments"
+	"github.com/vishwakarma-setu-backend/signing"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func init() {
 	_ = godotenv.Load("../.env")
+	// Inspection reports are signed when created
+	signing.Active = signing.NewSigner(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	payments.Active = payments.NewFakeProvider("test-secret")
 }
 
 // Helper to generate JWT with Role
@@ -50,9 +61,29 @@ func setupTestDB(t *testing.T, seed []models.Machine) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to connect to test db: %v", err)
 	}
+	db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm")
+	_ = db.Migrator().DropTable(&models.MachineImage{})
+	_ = db.Migrator().DropTable(&models.Upload{})
+	_ = db.Migrator().DropTable(&models.Attachment{})
+	_ = db.Migrator().DropTable(&models.InspectionTemplate{})
+	_ = db.Migrator().DropTable(&models.Category{})
+	_ = db.Migrator().DropTable(&models.Order{})
+	_ = db.Migrator().DropTable(&models.OfferRound{})
+	_ = db.Migrator().DropTable(&models.Offer{})
+	_ = db.Migrator().DropTable(&models.DamageClaimItem{})
+	_ = db.Migrator().DropTable(&models.DamageClaim{})
+	_ = db.Migrator().DropTable(&models.InspectionRequest{})
+	_ = db.Migrator().DropTable(&models.InspectionReport{})
+	_ = db.Migrator().DropTable(&models.PaymentEvent{})
+	_ = db.Migrator().DropTable(&models.Payment{})
+	_ = db.Migrator().DropTable(&models.RentalStatusEvent{})
 	_ = db.Migrator().DropTable(&models.Rental{})
 	_ = db.Migrator().DropTable(&models.Machine{})
-	if err := db.AutoMigrate(&models.Machine{}, &models.Rental{}); err != nil {
+	_ = db.Migrator().DropTable(&models.PlatformFeeRule{})
+	_ = db.Migrator().DropTable(&models.OrganizationMember{})
+	_ = db.Migrator().DropTable(&models.Organization{})
+	if err := db.AutoMigrate(&models.Machine{}, &models.Rental{}, &models.RentalStatusEvent{}, &models.PlatformFeeRule{}, &models.Payment{}, &models.PaymentEvent{}, &models.InspectionReport{}, &models.DamageClaim{}, &models.DamageClaimItem{},
+		&models.Offer{}, &models.OfferRound{}, &models.Order{}, &models.Category{}, &models.Organization{}, &models.OrganizationMember{}, &models.Attachment{}, &models.Upload{}, &models.MachineImage{}, &models.InspectionTemplate{}, &models.InspectionRequest{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	if len(seed) > 0 {
@@ -204,7 +235,16 @@ func TestUpdateListing(t *testing.T) {
 		t.Errorf("expected 200 for admin, got %d", recAdmin.Code)
 	}
 
-	// Case 3: Unauthorized (Wrong User & Not Admin)
+	// Case 3: The seller cannot verify their own machine
+	cStatus, _ := setupCtx(`{"title":"Updated Title","status":"verified"}`, 1, "seller", m.ID.String())
+	UpdateListing(cStatus)
+	var stored models.Machine
+	db.First(&stored, "id = ?", m.ID)
+	if stored.Status != "pending_inspection" {
+		t.Errorf("expected the status to stay pending_inspection, got %s", stored.Status)
+	}
+
+	// Case 4: Unauthorized (Wrong User & Not Admin)
 	c3, rec3 := setupCtx(`{}`, 999, "seller", m.ID.String())
 	UpdateListing(c3)
 	if rec3.Code != http.StatusForbidden {
@@ -241,12 +281,27 @@ func TestDeleteListing(t *testing.T) {
 		t.Errorf("expected 403, got %d", rec2.Code)
 	}
 
-	// Case 2: Success (Owner)
+	// Case 2: Success (Owner), taking the listing's documents with it
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	storage.Active = storage.NewLocal(t.TempDir(), "/uploads", "secret")
+	doc := models.Attachment{ParentType: models.AttachToMachine, ParentID: m.ID, UploaderID: 1, Filename: "manual.pdf",
+		ContentType: "application/pdf", Size: 3, StorageKey: storage.PrivatePrefix + "attachments/manual.pdf"}
+	storage.Active.Put(context.Background(), doc.StorageKey, strings.NewReader("pdf"), 3, doc.ContentType)
+	db.Create(&doc)
+
 	c3, rec3 := setupCtx(1, "seller", m.ID.String())
 	DeleteListing(c3)
 	if rec3.Code != http.StatusOK {
 		t.Errorf("expected 200, got %d", rec3.Code)
 	}
+	var left int64
+	db.Model(&models.Attachment{}).Where("parent_id = ?", m.ID).Count(&left)
+	if r, err := storage.Active.Get(context.Background(), doc.StorageKey); left != 0 || err == nil {
+		t.Errorf("expected the listing's documents to be deleted, %d left (stored: %v)", left, err == nil)
+		if err == nil {
+			r.Close()
+		}
+	}
 }
 
 func TestGetAllListings_Comprehensive(t *testing.T) {
@@ -255,7 +310,8 @@ func TestGetAllListings_Comprehensive(t *testing.T) {
 	seed := []models.Machine{
 		{Title: "Alpha", Category: "A", ListingType: "sale", PriceForSale: 100, SellerID: 1, CreatedAt: now.Add(-10 * time.Hour)},
 		{Title: "Beta", Category: "B", ListingType: "rent", PriceForSale: 200, SellerID: 1, CreatedAt: now.Add(-5 * time.Hour)},
-		{Title: "Gamma", Category: "A", ListingType: "both", PriceForSale: 300, SellerID: 1, CreatedAt: now.Add(-1 * time.Hour)},
+		{Title: "Gamma", Category: "A", ListingType: "both", PriceForSale: 300, SellerID: 1, CreatedAt: now.Add(-1 * time.Hour),
+			Manufacturer: "Haas", Description: "Twin spindle lathe"},
 	}
 	setupTestDB(t, seed)
 
@@ -264,12 +320,15 @@ func TestGetAllListings_Comprehensive(t *testing.T) {
 		query         string
 		expectedTotal int
 	}{
-		{name: "Search Lower", query: "/?q=alpha", expectedTotal: 1},
-		{name: "Search Upper", query: "/?q=BETA", expectedTotal: 1},
-		{name: "Filter Sale", query: "/?type=sale", expectedTotal: 2},
-		{name: "Filter Rent", query: "/?type=rent", expectedTotal: 2},
-		{name: "Filter Category", query: "/?category=A", expectedTotal: 2},
-		{name: "Sort Price Asc", query: "/?sort=price_asc", expectedTotal: 3},
+		{name: "Search Lower", query: "/?q=alpha&include_total=true", expectedTotal: 1},
+		{name: "Search Upper", query: "/?q=BETA&include_total=true", expectedTotal: 1},
+		{name: "Filter Sale", query: "/?type=sale&include_total=true", expectedTotal: 2},
+		{name: "Filter Rent", query: "/?type=rent&include_total=true", expectedTotal: 2},
+		{name: "Filter Category", query: "/?category=A&include_total=true", expectedTotal: 2},
+		{name: "Sort Price Asc", query: "/?sort=price_asc&include_total=true", expectedTotal: 3},
+		{name: "Search Description Stem", query: "/?q=lathes&include_total=true", expectedTotal: 1},
+		{name: "Search Manufacturer Typo", query: "/?q=hass&include_total=true", expectedTotal: 1},
+		{name: "Filter Manufacturer Typo", query: "/?manufacturer=Hass&include_total=true", expectedTotal: 1},
 	}
 
 	for _, tc := range tests {
@@ -290,4 +349,256 @@ func TestGetAllListings_Comprehensive(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestGetAllListings_Relevance(t *testing.T) {
+	e := echo.New()
+	seed := []models.Machine{
+		{Title: "Hydraulic Press", Description: "Can be paired with a lathe", SellerID: 1},
+		{Title: "CNC Lathe", Description: "Two axis turning centre", SellerID: 1},
+	}
+	setupTestDB(t, seed)
+
+	rec, err := doRequest(t, e, "/?q=lathe&sort=relevance")
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+
+	var resp struct {
+		Data []models.Machine `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(resp.Data))
+	}
+	// A title match outranks a description match
+	if resp.Data[0].Title != "CNC Lathe" || resp.Data[0].Relevance <= resp.Data[1].Relevance {
+		t.Errorf("expected CNC Lathe ranked first, got %s (%.3f) then %s (%.3f)",
+			resp.Data[0].Title, resp.Data[0].Relevance, resp.Data[1].Title, resp.Data[1].Relevance)
+	}
+}
+
+func TestGetAllListings_SpecFilters(t *testing.T) {
+	e := echo.New()
+	seed := []models.Machine{
+		{Title: "5 Axis VMC", Category: "CNC Mill", SellerID: 1, Specs: datatypes.JSON(`{"axes": 5, "spindle_speed_rpm": 12000, "control": "Fanuc"}`)},
+		{Title: "3 Axis VMC", Category: "CNC Mill", SellerID: 1, Specs: datatypes.JSON(`{"axes": 3, "spindle_speed_rpm": 8000, "control": "Siemens"}`)},
+		{Title: "Old Mill", Category: "CNC Mill", SellerID: 1, Specs: datatypes.JSON(`{"axes": 3, "spindle_speed_rpm": "unknown"}`)},
+	}
+	db := setupTestDB(t, seed)
+	seedCategory(t, db)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedTotal int
+	}{
+		{"Equality", "/?category=CNC+Mill&specs.axes=5&include_total=true", http.StatusOK, 1},
+		{"Range Skips Non Numbers", "/?category=CNC+Mill&specs.spindle_speed_rpm[gte]=8000&include_total=true", http.StatusOK, 2},
+		{"Combined", "/?category=CNC+Mill&specs.axes=3&specs.spindle_speed_rpm[lt]=10000&include_total=true", http.StatusOK, 1},
+		{"Enum In", "/?category=CNC+Mill&specs.control[in]=fanuc,siemens&include_total=true", http.StatusOK, 2},
+		{"Unknown Key", "/?category=CNC+Mill&specs.rpm=8000", http.StatusBadRequest, 0},
+		{"Missing Category", "/?specs.axes=5", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, err := doRequest(t, e, tc.query)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, rec.Code, rec.Body.String())
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if total, ok := resp["total"].(float64); ok && int(total) != tc.expectedTotal {
+				t.Errorf("expected total %d, got %d", tc.expectedTotal, int(total))
+			}
+		})
+	}
+}
+
+func TestCreateListing_Geocodes(t *testing.T) {
+	e := echo.New()
+	setupTestDB(t, nil)
+
+	body := `{"title":"VMC","description":"Used","location":"Sector 24, Faridabad, Haryana","listing_type":"sale"}`
+	c, rec := paymentCtx(e, http.MethodPost, body, 1, "seller", "", "")
+	if err := CreateListing(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var machine models.Machine
+	json.Unmarshal(rec.Body.Bytes(), &machine)
+	if machine.Latitude == nil || machine.Longitude == nil || *machine.Latitude != 28.4089 || *machine.Longitude != 77.3178 {
+		t.Errorf("expected Faridabad's coordinates, got %v, %v", machine.Latitude, machine.Longitude)
+	}
+
+	// Coordinates must come in pairs
+	c, rec = paymentCtx(e, http.MethodPost, `{"title":"VMC","description":"Used","latitude":28.4,"listing_type":"sale"}`, 1, "seller", "", "")
+	CreateListing(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a lone latitude, got %d", rec.Code)
+	}
+
+	update := func(body string) models.Machine {
+		c, rec := paymentCtx(e, http.MethodPut, body, 1, "seller", "id", machine.ID.String())
+		if err := UpdateListing(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var updated models.Machine
+		json.Unmarshal(rec.Body.Bytes(), &updated)
+		return updated
+	}
+
+	// Moving the listing places it again, even when the old coordinates
+	// are echoed back
+	moved := update(`{"title":"VMC","description":"Used","location":"Pune, Maharashtra","latitude":28.4089,"longitude":77.3178,"listing_type":"sale"}`)
+	if moved.Latitude == nil || *moved.Latitude != 18.5204 || *moved.Longitude != 73.8567 {
+		t.Errorf("expected Pune's coordinates, got %v, %v", moved.Latitude, moved.Longitude)
+	}
+
+	// Coordinates sent with the new location are kept
+	pinned := update(`{"title":"VMC","description":"Used","location":"Pune, Maharashtra","latitude":18.6,"longitude":73.9,"listing_type":"sale"}`)
+	if pinned.Latitude == nil || *pinned.Latitude != 18.6 || *pinned.Longitude != 73.9 {
+		t.Errorf("expected the given coordinates, got %v, %v", pinned.Latitude, pinned.Longitude)
+	}
+}
+
+func TestGetAllListings_Near(t *testing.T) {
+	e := echo.New()
+	coords := func(lat, lng float64) (*float64, *float64) { return &lat, &lng }
+	faridabad := models.Machine{Title: "Faridabad Press", SellerID: 1}
+	faridabad.Latitude, faridabad.Longitude = coords(28.4089, 77.3178)
+	gurugram := models.Machine{Title: "Gurugram Lathe", SellerID: 1}
+	gurugram.Latitude, gurugram.Longitude = coords(28.4595, 77.0266)
+	pune := models.Machine{Title: "Pune Mill", SellerID: 1}
+	pune.Latitude, pune.Longitude = coords(18.5204, 73.8567)
+	setupTestDB(t, []models.Machine{pune, gurugram, faridabad, {Title: "Unplaced", SellerID: 1}})
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedFirst string
+		expectedTotal int
+	}{
+		{"Radius", "/?near=28.41,77.32&radius_km=50&sort=distance&include_total=true", http.StatusOK, "Faridabad Press", 2},
+		{"City As Point", "/?near=Gurgaon&radius_km=10&sort=distance&include_total=true", http.StatusOK, "Gurugram Lathe", 1},
+		{"PIN As Point", "/?near=411001&sort=distance&include_total=true", http.StatusOK, "Pune Mill", 4},
+		{"Invalid Point", "/?near=91,77", http.StatusBadRequest, "", 0},
+		{"Radius Without Point", "/?radius_km=50", http.StatusBadRequest, "", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, err := doRequest(t, e, tc.query)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, rec.Code, rec.Body.String())
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data  []models.Machine `json:"data"`
+				Total int              `json:"total"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if resp.Total != tc.expectedTotal {
+				t.Errorf("expected total %d, got %d", tc.expectedTotal, resp.Total)
+			}
+			if len(resp.Data) == 0 || resp.Data[0].Title != tc.expectedFirst {
+				t.Fatalf("expected %s first, got %+v", tc.expectedFirst, resp.Data)
+			}
+			if resp.Data[0].DistanceKm == nil {
+				t.Error("expected a distance on each placed result")
+			}
+		})
+	}
+}
+
+func TestGetAllListings_CursorPaging(t *testing.T) {
+	e := echo.New()
+	now := time.Now()
+	var seed []models.Machine
+	for i := 0; i < 5; i++ {
+		// Two listings share each timestamp so the id has to break ties
+		seed = append(seed, models.Machine{Title: fmt.Sprintf("Machine %d", i), SellerID: 1, PriceForSale: float64(100 * (i % 3)),
+			CreatedAt: now.Add(-time.Duration(i/2) * time.Hour)})
+	}
+	db := setupTestDB(t, seed)
+
+	type page struct {
+		Data       []models.Machine `json:"data"`
+		NextCursor *string          `json:"next_cursor"`
+		HasMore    bool             `json:"has_more"`
+		Total      *int64           `json:"total"`
+	}
+	fetch := func(query string) page {
+		t.Helper()
+		rec, err := doRequest(t, e, query)
+		if err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var p page
+		json.Unmarshal(rec.Body.Bytes(), &p)
+		return p
+	}
+
+	for _, sort := range []string{"", "oldest", "price_asc", "price_desc"} {
+		t.Run("Sort "+sort, func(t *testing.T) {
+			seen := map[string]bool{}
+			query := "/?limit=2&sort=" + sort
+			for pages := 0; ; pages++ {
+				p := fetch(query)
+				for _, m := range p.Data {
+					if seen[m.Title] {
+						t.Fatalf("%s served twice", m.Title)
+					}
+					seen[m.Title] = true
+				}
+				if !p.HasMore {
+					if p.NextCursor != nil {
+						t.Error("expected no cursor on the last page")
+					}
+					break
+				}
+				if pages == 0 {
+					// A listing created mid-way must not shift the pages being read
+					db.Create(&models.Machine{Title: "Late Arrival " + sort, SellerID: 1, CreatedAt: now.Add(-10 * time.Hour)})
+				}
+				query = "/?limit=2&sort=" + sort + "&cursor=" + url.QueryEscape(*p.NextCursor)
+			}
+			if len(seen) < 5 {
+				t.Errorf("expected every listing once, saw %d", len(seen))
+			}
+		})
+	}
+
+	if p := fetch("/?limit=2"); p.Total != nil {
+		t.Error("expected no total unless asked for")
+	}
+	if p := fetch("/?limit=2&include_total=true"); p.Total == nil || *p.Total != 9 {
+		t.Errorf("expected total 9, got %v", p.Total)
+	}
+
+	// A cursor is tied to the sort it came from
+	first := fetch("/?limit=2")
+	for _, query := range []string{"/?cursor=garbage", "/?sort=price_asc&cursor=" + url.QueryEscape(*first.NextCursor)} {
+		rec, _ := doRequest(t, e, query)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errGalleryFull   = fmt.Errorf("a listing can have at most %d images", models.MaxMachineImages)
+	errImageNotFound = errors.New("image not found")
+	errImageOrder    = errors.New("image_ids must list each of the listing's images exactly once")
+)
+
+// MachineImageInput adds a photo to a listing
+type MachineImageInput struct {
+	URL     string `json:"url" example:"/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"`
+	Caption string `json:"caption" example:"Spindle and tool changer"`
+	AltText string `json:"alt_text" example:"Front view of a Haas VF-2 vertical machining centre"`
+	IsCover bool   `json:"is_cover"`
+}
+
+// MachineImageUpdate changes a photo's caption or alt text, or makes it
+// the cover. Omitted fields are left alone.
+type MachineImageUpdate struct {
+	Caption *string `json:"caption"`
+	AltText *string `json:"alt_text"`
+	IsCover *bool   `json:"is_cover"`
+}
+
+// ReorderImagesInput lists every image of a listing in display order
+type ReorderImagesInput struct {
+	ImageIDs []uuid.UUID `json:"image_ids"`
+}
+
+// galleryMachine loads the listing named by the :id parameter and checks
+// that user may change its photos. Otherwise it answers the request and
+// returns no machine.
+func galleryMachine(c echo.Context, user *UserClaims) (*models.Machine, error) {
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", c.Param("id")).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	}
+	if !user.Allows(policy.MachineUpdate, machineOwner(&machine)) {
+		return nil, policy.Forbid(c, policy.MachineUpdate, "You are not authorized to update this listing")
+	}
+	return &machine, nil
+}
+
+// validImageText checks caption and alt text lengths
+func validImageText(caption, altText string) error {
+	if utf8.RuneCountInString(caption) > 200 {
+		return errors.New("caption may be at most 200 characters")
+	}
+	if utf8.RuneCountInString(altText) > 300 {
+		return errors.New("alt_text may be at most 300 characters")
+	}
+	return nil
+}
+
+// editGallery runs fn in a transaction holding the machine's row lock, so
+// concurrent edits to one gallery apply one after another. Positions are
+// then renumbered from 0, a cover is kept while any image remains, and
+// the machine's references to uploads are brought up to date.
+func editGallery(machineID uuid.UUID, fn func(tx *gorm.DB, images []models.MachineImage) error) ([]models.MachineImage, error) {
+	var images []models.MachineImage
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Machine{}, "id = ?", machineID).Error; err != nil {
+			return err
+		}
+		var current []models.MachineImage
+		if err := tx.Where("machine_id = ?", machineID).Order("position, created_at").Find(&current).Error; err != nil {
+			return err
+		}
+		if err := fn(tx, current); err != nil {
+			return err
+		}
+
+		if err := tx.Where("machine_id = ?", machineID).Order("position, created_at").Find(&images).Error; err != nil {
+			return err
+		}
+		hasCover := false
+		urls := make([]string, len(images))
+		for i := range images {
+			hasCover = hasCover || images[i].IsCover
+			urls[i] = images[i].URL
+		}
+		for i := range images {
+			img := &images[i]
+			cover := img.IsCover || (!hasCover && i == 0)
+			if img.Position == i && img.IsCover == cover {
+				continue
+			}
+			img.Position, img.IsCover = i, cover
+			if err := tx.Model(img).Updates(map[string]interface{}{"position": i, "is_cover": cover}).Error; err != nil {
+				return err
+			}
+		}
+		return files.SetReferences(tx, files.Ref(models.AttachToMachine, machineID), urls)
+	})
+	return images, err
+}
+
+// setCover makes image the machine's only cover
+func setCover(tx *gorm.DB, machineID, imageID uuid.UUID) error {
+	if err := tx.Model(&models.MachineImage{}).Where("machine_id = ? AND is_cover", machineID).Update("is_cover", false).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.MachineImage{}).Where("id = ?", imageID).Update("is_cover", true).Error
+}
+
+// galleryError writes the response for an editGallery error
+func galleryError(c echo.Context, err error, failMsg string) error {
+	if msg, ok := uploadError(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	switch {
+	case errors.Is(err, errImageNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+	case errors.Is(err, errGalleryFull), errors.Is(err, errImageOrder):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": failMsg})
+}
+
+// AddMachineImage godoc
+//
+//	@Summary		Add a photo to a listing
+//	@Description	Add one of the caller's uploaded images (see POST /upload) to a listing's gallery, after its existing photos. The first photo, or one sent with is_cover, becomes the cover. Only the owner (any member of the owning organization, for company listings) or an admin can do this; a listing holds at most 20 photos.
+//	@Tags			Machines
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Machine ID"
+//	@Param			image	body		MachineImageInput	true	"Photo"
+//	@Success		201		{object}	models.MachineImage
+//	@Failure		400		{object}	map[string]string	"Invalid input or not the caller's upload"
+//	@Failure		403		{object}	policy.Denial		"Not authorized"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Router			/machines/{id}/images [post]
+func AddMachineImage(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	machine, err := galleryMachine(c, user)
+	if machine == nil {
+		return err
+	}
+
+	var input MachineImageInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if input.URL == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "url is required"})
+	}
+	if err := validImageText(input.Caption, input.AltText); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	image := models.MachineImage{
+		MachineID:    machine.ID,
+		URL:          input.URL,
+		ThumbnailURL: input.URL,
+		Caption:      input.Caption,
+		AltText:      input.AltText,
+	}
+
+	images, err := editGallery(machine.ID, func(tx *gorm.DB, current []models.MachineImage) error {
+		if len(current) >= models.MaxMachineImages {
+			return errGalleryFull
+		}
+		if err := checkUploads(tx, user, []string{input.URL}); err != nil {
+			return err
+		}
+		// Galleries show the thumbnail variant; direct uploads have none
+		upload, err := files.Find(tx, input.URL)
+		if err != nil {
+			return err
+		}
+		if thumb, ok := files.Variant(upload, "thumbnail"); ok {
+			image.ThumbnailURL = storage.Active.URL(thumb.Key)
+		}
+		image.Position = len(current)
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		if input.IsCover {
+			return setCover(tx, machine.ID, image.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return galleryError(c, err, "Failed to add image")
+	}
+	for _, img := range images {
+		if img.ID == image.ID {
+			return c.JSON(http.StatusCreated, img)
+		}
+	}
+	return c.JSON(http.StatusCreated, image)
+}
+
+// UpdateMachineImage godoc
+//
+//	@Summary		Edit a listing photo
+//	@Description	Change a photo's caption or alt text, or make it the cover (is_cover: true; the previous cover is unset). A listing with photos always keeps a cover, so is_cover: false is ignored. Only the owner or an admin can do this.
+//	@Tags			Machines
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string				true	"Machine ID"
+//	@Param			image_id	path		string				true	"Image ID"
+//	@Param			image		body		MachineImageUpdate	true	"Changes"
+//	@Success		200			{object}	models.MachineImage
+//	@Failure		400			{object}	map[string]string	"Invalid input"
+//	@Failure		403			{object}	policy.Denial		"Not authorized"
+//	@Failure		404			{object}	map[string]string	"Machine or image not found"
+//	@Router			/machines/{id}/images/{image_id} [put]
+func UpdateMachineImage(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	machine, err := galleryMachine(c, user)
+	if machine == nil {
+		return err
+	}
+
+	var input MachineImageUpdate
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	var caption, altText string
+	if input.Caption != nil {
+		caption = *input.Caption
+	}
+	if input.AltText != nil {
+		altText = *input.AltText
+	}
+	if err := validImageText(caption, altText); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+	}
+
+	images, err := editGallery(machine.ID, func(tx *gorm.DB, current []models.MachineImage) error {
+		var image *models.MachineImage
+		for i := range current {
+			if current[i].ID == imageID {
+				image = &current[i]
+			}
+		}
+		if image == nil {
+			return errImageNotFound
+		}
+
+		if input.Caption != nil {
+			image.Caption = *input.Caption
+		}
+		if input.AltText != nil {
+			image.AltText = *input.AltText
+		}
+		if err := tx.Model(image).Updates(map[string]interface{}{"caption": image.Caption, "alt_text": image.AltText}).Error; err != nil {
+			return err
+		}
+		if input.IsCover != nil && *input.IsCover && !image.IsCover {
+			return setCover(tx, machine.ID, image.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return galleryError(c, err, "Failed to update image")
+	}
+	for _, img := range images {
+		if img.ID == imageID {
+			return c.JSON(http.StatusOK, img)
+		}
+	}
+	return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+}
+
+// ReorderMachineImages godoc
+//
+//	@Summary		Reorder a listing's photos
+//	@Description	Set the display order of a listing's gallery. image_ids must list every photo of the listing exactly once. Only the owner or an admin can do this. The reordered gallery is returned whole, in the list envelope.
+//	@Tags			Machines
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Machine ID"
+//	@Param			order	body		ReorderImagesInput	true	"Image IDs in display order"
+//	@Success		200		{object}	pagination.Page[models.MachineImage]
+//	@Failure		400		{object}	map[string]string	"Not a permutation of the listing's images"
+//	@Failure		403		{object}	policy.Denial		"Not authorized"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Router			/machines/{id}/images/order [put]
+func ReorderMachineImages(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	machine, err := galleryMachine(c, user)
+	if machine == nil {
+		return err
+	}
+
+	var input ReorderImagesInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	images, err := editGallery(machine.ID, func(tx *gorm.DB, current []models.MachineImage) error {
+		if len(input.ImageIDs) != len(current) {
+			return errImageOrder
+		}
+		position := map[uuid.UUID]int{}
+		for i, id := range input.ImageIDs {
+			if _, dup := position[id]; dup {
+				return errImageOrder
+			}
+			position[id] = i
+		}
+		for _, img := range current {
+			i, ok := position[img.ID]
+			if !ok {
+				return errImageOrder
+			}
+			if img.Position != i {
+				if err := tx.Model(&img).Update("position", i).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return galleryError(c, err, "Failed to reorder images")
+	}
+	// A gallery is small enough to fit on one page
+	return c.JSON(http.StatusOK, pagination.Page[models.MachineImage]{Data: images, Limit: models.MaxMachineImages})
+}
+
+// DeleteMachineImage godoc
+//
+//	@Summary		Remove a listing photo
+//	@Description	Remove a photo from a listing's gallery. The photos after it move up and, if it was the cover, the new first photo becomes the cover. The uploaded file is deleted once nothing else uses it. Only the owner or an admin can do this.
+//	@Tags			Machines
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Machine ID"
+//	@Param			image_id	path		string	true	"Image ID"
+//	@Success		200			{object}	map[string]string
+//	@Failure		403			{object}	policy.Denial		"Not authorized"
+//	@Failure		404			{object}	map[string]string	"Machine or image not found"
+//	@Router			/machines/{id}/images/{image_id} [delete]
+func DeleteMachineImage(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	machine, err := galleryMachine(c, user)
+	if machine == nil {
+		return err
+	}
+
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+	}
+
+	_, err = editGallery(machine.ID, func(tx *gorm.DB, current []models.MachineImage) error {
+		result := tx.Where("machine_id = ?", machine.ID).Delete(&models.MachineImage{}, "id = ?", imageID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errImageNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return galleryError(c, err, "Failed to delete image")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Image deleted successfully"})
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/storage"
+)
+
+// galleryCtx builds a gallery request on a machine, and on one of its
+// images when imageID is set
+func galleryCtx(e *echo.Echo, method, body string, userID uint, role, machineID, imageID string) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := paymentCtx(e, method, body, userID, role, "id", machineID)
+	if imageID != "" {
+		c.SetParamNames("id", "image_id")
+		c.SetParamValues(machineID, imageID)
+	}
+	return c, rec
+}
+
+func addImage(t *testing.T, e *echo.Echo, machineID string, userID uint, body string) (*httptest.ResponseRecorder, models.MachineImage) {
+	t.Helper()
+	c, rec := galleryCtx(e, http.MethodPost, body, userID, "seller", machineID, "")
+	if err := AddMachineImage(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	var image models.MachineImage
+	json.Unmarshal(rec.Body.Bytes(), &image)
+	return rec, image
+}
+
+func TestMachineImages(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	storage.Active = storage.NewLocal(t.TempDir(), "/uploads", "secret")
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	id := machine.ID.String()
+
+	_, first := uploadImage(t, 1, "front.jpg", testJPEG(t, 400, 300))
+	_, second := uploadImage(t, 1, "side.jpg", testJPEG(t, 300, 400))
+	_, strangers := uploadImage(t, 2, "theirs.jpg", testJPEG(t, 50, 50))
+
+	// 1. Only the owner, and only with their own uploads
+	denied, _ := addImage(t, e, id, 2, `{"url":"`+strangers.URL+`"}`)
+	var denial policy.Denial
+	json.Unmarshal(denied.Body.Bytes(), &denial)
+	if denied.Code != http.StatusForbidden || denial.Permission != policy.MachineUpdate {
+		t.Errorf("expected a 403 denial for another seller, got %d %s", denied.Code, denied.Body.String())
+	}
+	if rec, _ := addImage(t, e, id, 1, `{"url":"`+strangers.URL+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for someone else's upload, got %d", rec.Code)
+	}
+
+	// 2. The first photo becomes the cover and shows its thumbnail
+	rec, front := addImage(t, e, id, 1, `{"url":"`+first.URL+`","caption":"Front","alt_text":"Front view"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !front.IsCover || front.Position != 0 || front.ThumbnailURL != first.Variants[0].URL {
+		t.Errorf("unexpected first image %+v", front)
+	}
+	_, side := addImage(t, e, id, 1, `{"url":"`+second.URL+`","is_cover":true}`)
+	if !side.IsCover || side.Position != 1 {
+		t.Errorf("expected the second image to take the cover, got %+v", side)
+	}
+
+	// 3. Reorder: every image exactly once
+	c, rec := galleryCtx(e, http.MethodPut, `{"image_ids":["`+side.ID.String()+`"]}`, 1, "seller", id, "")
+	ReorderMachineImages(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a partial order, got %d", rec.Code)
+	}
+	c, rec = galleryCtx(e, http.MethodPut, `{"image_ids":["`+side.ID.String()+`","`+front.ID.String()+`"]}`, 1, "seller", id, "")
+	ReorderMachineImages(c)
+	var ordered pagination.Page[models.MachineImage]
+	json.Unmarshal(rec.Body.Bytes(), &ordered)
+	if rec.Code != http.StatusOK || len(ordered.Data) != 2 || ordered.Data[0].ID != side.ID || ordered.Data[1].Position != 1 {
+		t.Fatalf("unexpected order %d %+v", rec.Code, ordered.Data)
+	}
+
+	// 4. The listing carries its gallery, results their cover thumbnail
+	c, rec = galleryCtx(e, http.MethodGet, "", 0, "", id, "")
+	GetListingByID(c)
+	var listing models.Machine
+	json.Unmarshal(rec.Body.Bytes(), &listing)
+	if len(listing.Images) != 2 || listing.Images[0].ID != side.ID || listing.Images[1].Caption != "Front" {
+		t.Errorf("expected the gallery in display order, got %+v", listing.Images)
+	}
+	pageRec, _ := doRequest(t, e, "")
+	var page pagination.Page[models.Machine]
+	json.Unmarshal(pageRec.Body.Bytes(), &page)
+	if len(page.Data) != 1 || page.Data[0].CoverThumbnailURL == nil || *page.Data[0].CoverThumbnailURL != side.ThumbnailURL {
+		t.Errorf("expected the cover thumbnail on the listing result, got %+v", page.Data)
+	}
+
+	// 5. Removing the cover promotes the next photo
+	c, rec = galleryCtx(e, http.MethodDelete, "", 1, "seller", id, side.ID.String())
+	DeleteMachineImage(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var remaining models.MachineImage
+	db.First(&remaining, "id = ?", front.ID)
+	if !remaining.IsCover || remaining.Position != 0 {
+		t.Errorf("expected the remaining photo to be the first cover, got %+v", remaining)
+	}
+
+	// The removed photo's upload is no longer used by the listing
+	var upload models.Upload
+	db.First(&upload, "id = ?", second.ID)
+	if string(upload.ReferencedBy) != "[]" {
+		t.Errorf("expected the removed photo's upload to be released, got %s", upload.ReferencedBy)
+	}
+
+	// 6. Captions are edited in place
+	c, rec = galleryCtx(e, http.MethodPut, `{"caption":"Front, guards on"}`, 1, "seller", id, front.ID.String())
+	UpdateMachineImage(c)
+	var edited models.MachineImage
+	json.Unmarshal(rec.Body.Bytes(), &edited)
+	if rec.Code != http.StatusOK || edited.Caption != "Front, guards on" || edited.AltText != "Front view" {
+		t.Errorf("unexpected edit %d %+v", rec.Code, edited)
+	}
+
+	// 7. Deleting the listing removes its gallery and releases the uploads
+	c, rec = paymentCtx(e, http.MethodDelete, "", 1, "seller", "id", id)
+	DeleteListing(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var images int64
+	db.Model(&models.MachineImage{}).Where("machine_id = ?", machine.ID).Count(&images)
+	db.First(&upload, "id = ?", first.ID)
+	if images != 0 || string(upload.ReferencedBy) != "[]" {
+		t.Errorf("expected the gallery gone and its upload released, got %d images, %s", images, upload.ReferencedBy)
+	}
+}
@@ -6,7 +6,11 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
 )
 
 type MaintenanceRequest struct {
@@ -16,13 +20,13 @@ type MaintenanceRequest struct {
 	Description string  `json:"description"`
 	Cost        float64 `json:"cost"`
 	Technician  string  `json:"technician"`
-	DocumentURL string  `json:"document_url"`
+	DocumentURL string  `json:"document_url"` // one of the caller's uploads
 }
 
 // AddMaintenanceRecord godoc
 //
 //	@Summary		Add a maintenance record
-//	@Description	Add a service history log for a machine. Only the owner can do this.
+//	@Description	Add a service history log for a machine. Only the owner (any member of the owning organization, for company machines) can do this. The document URL, if any, must be one of the caller's uploads.
 //	@Tags			Maintenance
 //	@Accept			json
 //	@Produce		json
@@ -49,8 +53,8 @@ func AddMaintenanceRecord(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
 	}
 
-	if machine.SellerID != ownerID.ID {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "You are not the owner of this machine"})
+	if !ownerID.Owns(machineOwner(&machine)) || !ownerID.Can(policy.MaintenanceCreate) {
+		return policy.Forbid(c, policy.MaintenanceCreate, "You are not the owner of this machine")
 	}
 
 	date, _ := time.Parse("2006-01-02", req.ServiceDate)
@@ -65,7 +69,19 @@ func AddMaintenanceRecord(c echo.Context) error {
 		DocumentURL: req.DocumentURL,
 	}
 
-	if err := config.DB.Create(&record).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkUploads(tx, ownerID, []string{req.DocumentURL}); err != nil {
+			return err
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		return files.SetReferences(tx, files.Ref(models.AttachToMaintenance, record.ID), []string{req.DocumentURL})
+	})
+	if msg, ok := uploadError(err); ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": msg})
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save record"})
 	}
 
@@ -75,19 +91,25 @@ func AddMaintenanceRecord(c echo.Context) error {
 // GetMaintenanceHistory godoc
 //
 //	@Summary		Get maintenance history
-//	@Description	Retrieve full service history for a machine.
+//	@Description	Retrieve a machine's service history, latest service first, a page at a time.
 //	@Tags			Maintenance
 //	@Produce		json
-//	@Param			machine_id	path	string	true	"Machine ID"
-//	@Success		200			{array}	models.MaintenanceRecord
+//	@Param			machine_id		path		string	true	"Machine ID"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.MaintenanceRecord]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
 //	@Router			/machines/{machine_id}/maintenance [get]
 func GetMaintenanceHistory(c echo.Context) error {
 	machineID := c.Param("machine_id")
-	var records []models.MaintenanceRecord
+	query := config.DB.Where("machine_id = ?", machineID)
 
-	if err := config.DB.Where("machine_id = ?", machineID).Order("service_date desc").Find(&records).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch records"})
-	}
+	return respondPage(c, query, maintenanceOrder, "Failed to fetch records")
+}
 
-	return c.JSON(http.StatusOK, records)
+// maintenanceOrder lists records by service date, latest first
+var maintenanceOrder = pagination.Order[models.MaintenanceRecord]{
+	{Expr: "service_date", Type: "timestamptz", Desc: true, Value: func(r *models.MaintenanceRecord) interface{} { return r.ServiceDate }},
+	{Expr: "id", Type: "uuid", Desc: true, Value: func(r *models.MaintenanceRecord) interface{} { return r.ID }},
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
 	"gorm.io/gorm"
 )
 
@@ -180,13 +181,13 @@ func TestGetMaintenanceHistory_Success(t *testing.T) {
 		t.Errorf("expected status 200, got %d", rec.Code)
 	}
 
-	var records []models.MaintenanceRecord
-	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+	var page pagination.Page[models.MaintenanceRecord]
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
 		t.Fatalf("invalid response json: %v", err)
 	}
 
-	if len(records) != 2 {
-		t.Errorf("expected 2 records, got %d", len(records))
+	if len(page.Data) != 2 {
+		t.Errorf("expected 2 records, got %d", len(page.Data))
 	}
 	// Verify sorting (default desc) is handled by DB, but we just check count here
 }
@@ -213,12 +214,12 @@ func TestGetMaintenanceHistory_Empty(t *testing.T) {
 		t.Errorf("expected status 200, got %d", rec.Code)
 	}
 
-	var records []models.MaintenanceRecord
-	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+	var page pagination.Page[models.MaintenanceRecord]
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
 		t.Fatalf("invalid response json: %v", err)
 	}
 
-	if len(records) != 0 {
-		t.Errorf("expected 0 records, got %d", len(records))
+	if page.Data == nil || len(page.Data) != 0 || page.NextCursor != nil {
+		t.Errorf("expected an empty page, got %+v", page)
 	}
 }
\ No newline at end of file
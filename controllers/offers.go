@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errMachineSold = errors.New("machine has already been sold")
+	errOfferOpen   = errors.New("you already have an open offer on this machine")
+)
+
+// OfferRequest is a buyer's opening offer
+type OfferRequest struct {
+	Amount  float64 `json:"amount" example:"950000"`
+	Message string  `json:"message"`
+}
+
+// OfferResponseRequest answers an open offer
+type OfferResponseRequest struct {
+	Action  string  `json:"action" example:"counter"` // accept, reject, counter, withdraw
+	Amount  float64 `json:"amount" example:"980000"`  // required to counter
+	Message string  `json:"message"`
+}
+
+// OfferResult is the outcome of answering an offer; Order is set once accepted
+type OfferResult struct {
+	Offer models.Offer  `json:"offer"`
+	Order *models.Order `json:"order,omitempty"`
+}
+
+// CreateOffer godoc
+//
+//	@Summary		Make an offer on a machine
+//	@Description	Offer to buy a machine listed for sale. The seller can accept, reject or counter. A buyer can have one open offer per machine.
+//	@Tags			Offers
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Machine ID"
+//	@Param			offer	body		OfferRequest	true	"Offer"
+//	@Success		201		{object}	models.Offer
+//	@Failure		400		{object}	map[string]string	"Invalid offer or machine not for sale"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Failure		409		{object}	map[string]string	"Machine sold or offer already open"
+//	@Router			/machines/{id}/offers [post]
+func CreateOffer(c echo.Context) error {
+	var req OfferRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	}
+
+	if machine.ListingType == "rent" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "This machine is not for sale"})
+	}
+	if machine.Status == models.MachineSold {
+		return c.JSON(http.StatusConflict, map[string]string{"error": errMachineSold.Error()})
+	}
+	if user.Owns(machineOwner(&machine)) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You cannot make an offer on your own machine"})
+	}
+	if req.Amount <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": models.ErrInvalidOfferAmount.Error()})
+	}
+
+	offer := models.Offer{
+		MachineID:      machine.ID,
+		BuyerID:        user.ID,
+		SellerID:       machine.SellerID,
+		OrganizationID: machine.OrganizationID,
+		Amount:         req.Amount,
+		Status:         models.OfferPending,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&models.Offer{}).
+			Where("machine_id = ? AND buyer_id = ? AND status IN ?", machine.ID, user.ID, models.OpenOfferStatuses).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errOfferOpen
+		}
+
+		if err := tx.Create(&offer).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OfferRound{
+			OfferID: offer.ID,
+			ActorID: user.ID,
+			Party:   models.PartyBuyer,
+			Action:  "offer",
+			Amount:  req.Amount,
+			Message: req.Message,
+		}).Error
+	})
+	if errors.Is(err, errOfferOpen) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save offer"})
+	}
+
+	return c.JSON(http.StatusCreated, offer)
+}
+
+// GetMyOffers godoc
+//
+//	@Summary		Get offers I have made
+//	@Description	List the offers the current user has made as a buyer, most recently active first.
+//	@Tags			Offers
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching offers"
+//	@Success		200				{object}	pagination.Page[models.Offer]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/offers/my [get]
+func GetMyOffers(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query := config.DB.Preload("Machine").Where("buyer_id = ?", user.ID)
+	return respondPage(c, query, offerOrder, "Failed to fetch offers")
+}
+
+// GetReceivedOffers godoc
+//
+//	@Summary		Get offers on my machines
+//	@Description	List the offers received on machines the current user is selling (the organization's, when acting for one), most recently active first.
+//	@Tags			Offers
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching offers"
+//	@Success		200				{object}	pagination.Page[models.Offer]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/offers/manage [get]
+func GetReceivedOffers(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query := ownedBy(config.DB.Preload("Machine"), "offers", "seller_id", user)
+	return respondPage(c, query, offerOrder, "Failed to fetch offers")
+}
+
+// offerOrder lists offers by their latest negotiation round, newest first
+var offerOrder = pagination.Order[models.Offer]{
+	{Expr: "updated_at", Type: "timestamptz", Desc: true, Value: func(o *models.Offer) interface{} { return o.UpdatedAt }},
+	{Expr: "id", Type: "uuid", Desc: true, Value: func(o *models.Offer) interface{} { return o.ID }},
+}
+
+// GetOffer godoc
+//
+//	@Summary		Get an offer
+//	@Description	Retrieve an offer with its full negotiation history. Only the buyer, the seller or an admin can view it.
+//	@Tags			Offers
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Offer ID"
+//	@Success		200	{object}	models.Offer
+//	@Failure		403	{object}	map[string]string	"Not authorized"
+//	@Failure		404	{object}	map[string]string	"Offer not found"
+//	@Router			/offers/{id} [get]
+func GetOffer(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var offer models.Offer
+	err = config.DB.Preload("Machine").
+		Preload("Rounds", func(db *gorm.DB) *gorm.DB { return db.Order("created_at asc") }).
+		First(&offer, "id = ?", c.Param("id")).Error
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Offer not found"})
+	}
+
+	if len(saleParties(offer.BuyerID, offerSeller(&offer), user)) == 0 {
+		return policy.Deny(c, "You are not a party to this offer")
+	}
+
+	return c.JSON(http.StatusOK, offer)
+}
+
+// RespondToOffer godoc
+//
+//	@Summary		Answer an offer
+//	@Description	Accept, reject or counter an open offer when it is your turn (the seller answers pending offers, the buyer answers counter-offers). The buyer may withdraw at any time. Accepting creates an order, marks the machine as sold and closes all other open offers on it.
+//	@Tags			Offers
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string					true	"Offer ID"
+//	@Param			response	body		OfferResponseRequest	true	"Response"
+//	@Success		200			{object}	OfferResult
+//	@Failure		400			{object}	map[string]string	"Invalid action or amount"
+//	@Failure		403			{object}	map[string]string	"Not a party"
+//	@Failure		409			{object}	map[string]string	"Offer closed, not your turn or machine sold"
+//	@Router			/offers/{id}/respond [put]
+func RespondToOffer(c echo.Context) error {
+	var req OfferResponseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var offer models.Offer
+	if err := config.DB.First(&offer, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Offer not found"})
+	}
+
+	// Admins do not negotiate on anyone's behalf
+	var party string
+	switch {
+	case offer.BuyerID == user.ID:
+		party = models.PartyBuyer
+	case user.Owns(offerSeller(&offer)):
+		party = models.PartySeller
+	default:
+		return policy.Deny(c, "You are not a party to this offer")
+	}
+
+	var result OfferResult
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Offer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", offer.ID).Error; err != nil {
+			return err
+		}
+
+		if err := current.Respond(party, req.Action, req.Amount); err != nil {
+			return err
+		}
+
+		if current.Status == models.OfferAccepted {
+			order, err := acceptOffer(tx, &current)
+			if err != nil {
+				return err
+			}
+			result.Order = order
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&current).Error; err != nil {
+			return err
+		}
+		result.Offer = current
+
+		return tx.Create(&models.OfferRound{
+			OfferID: current.ID,
+			ActorID: user.ID,
+			Party:   party,
+			Action:  req.Action,
+			Amount:  current.Amount,
+			Message: req.Message,
+		}).Error
+	})
+
+	switch {
+	case errors.Is(err, models.ErrUnknownOfferAction), errors.Is(err, models.ErrInvalidOfferAmount):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrOfferClosed), errors.Is(err, models.ErrNotYourTurn), errors.Is(err, errMachineSold):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update offer"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// acceptOffer turns an accepted offer into an order, marks the machine as
+// sold and closes every other open offer on it.
+func acceptOffer(tx *gorm.DB, offer *models.Offer) (*models.Order, error) {
+	// Lock the machine row so two offers cannot be accepted at once
+	var machine models.Machine
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&machine, "id = ?", offer.MachineID).Error; err != nil {
+		return nil, err
+	}
+	if machine.Status == models.MachineSold {
+		return nil, errMachineSold
+	}
+
+	order := models.Order{
+		OfferID:               offer.ID,
+		MachineID:             machine.ID,
+		BuyerID:               offer.BuyerID,
+		SellerID:              offer.SellerID,
+		OrganizationID:        offer.OrganizationID,
+		Amount:                offer.Amount,
+		Status:                models.OrderAccepted,
+		PreviousMachineStatus: machine.Status,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&machine).Update("status", models.MachineSold).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Offer{}).
+		Where("machine_id = ? AND id <> ? AND status IN ?", machine.ID, offer.ID, models.OpenOfferStatuses).
+		Update("status", models.OfferRejected).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// saleParties lists the capacities in which the user is involved in a sale.
+// Anyone allowed to manage others' orders joins as admin.
+func saleParties(buyerID uint, seller policy.Owner, user *UserClaims) []string {
+	var parties []string
+	if buyerID == user.ID {
+		parties = append(parties, models.PartyBuyer)
+	}
+	if user.Owns(seller) {
+		parties = append(parties, models.PartySeller)
+	}
+	if user.Can(policy.Any(policy.OrderManage)) {
+		parties = append(parties, models.PartyAdmin)
+	}
+	return parties
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/models"
+	"gorm.io/gorm"
+)
+
+func seedSaleMachine(t *testing.T) (models.Machine, *gorm.DB) {
+	t.Helper()
+	db := setupTestDB(t, nil)
+
+	machine := models.Machine{
+		Title:        "Used VMC",
+		Description:  "Vertical machining centre",
+		SellerID:     1,
+		ListingType:  "sale",
+		PriceForSale: 1000000,
+		Status:       "verified",
+	}
+	if err := db.Create(&machine).Error; err != nil {
+		t.Fatalf("failed to seed machine: %v", err)
+	}
+	return machine, db
+}
+
+func makeOffer(t *testing.T, e *echo.Echo, machine models.Machine, buyerID uint, amount string) models.Offer {
+	t.Helper()
+	c, rec := paymentCtx(e, http.MethodPost, `{"amount":`+amount+`}`, buyerID, "buyer", "id", machine.ID.String())
+	if err := CreateOffer(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var offer models.Offer
+	json.Unmarshal(rec.Body.Bytes(), &offer)
+	return offer
+}
+
+func TestOfferNegotiationToDelivery(t *testing.T) {
+	e := echo.New()
+	machine, db := seedSaleMachine(t)
+
+	offer := makeOffer(t, e, machine, 2, "900000")
+	rival := makeOffer(t, e, machine, 3, "850000")
+
+	// Buyer cannot answer their own offer
+	c1, rec1 := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 2, "buyer", "id", offer.ID.String())
+	RespondToOffer(c1)
+	if rec1.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec1.Code)
+	}
+
+	// Seller counters, buyer accepts the counter
+	c2, rec2 := paymentCtx(e, http.MethodPut, `{"action":"counter","amount":950000}`, 1, "seller", "id", offer.ID.String())
+	RespondToOffer(c2)
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+
+	c3, rec3 := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 2, "buyer", "id", offer.ID.String())
+	RespondToOffer(c3)
+	if rec3.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec3.Code, rec3.Body.String())
+	}
+
+	var result OfferResult
+	json.Unmarshal(rec3.Body.Bytes(), &result)
+	if result.Order == nil || result.Order.Amount != 950000 || result.Order.Status != models.OrderAccepted {
+		t.Fatalf("expected an accepted order for 950000, got %+v", result.Order)
+	}
+
+	var sold models.Machine
+	db.First(&sold, "id = ?", machine.ID)
+	if sold.Status != models.MachineSold {
+		t.Errorf("expected machine to be sold, got %s", sold.Status)
+	}
+
+	var closed models.Offer
+	db.First(&closed, "id = ?", rival.ID)
+	if closed.Status != models.OfferRejected {
+		t.Errorf("expected rival offer to be rejected, got %s", closed.Status)
+	}
+
+	// Sold machines drop out of the listings
+	rec, _ := doRequest(t, e, "/api/machines?include_total=true")
+	var listing struct {
+		Total int64 `json:"total"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &listing)
+	if listing.Total != 0 {
+		t.Errorf("expected sold machine to be hidden, got total %d", listing.Total)
+	}
+
+	// accepted -> paid -> shipped -> delivered
+	orderID := result.Order.ID.String()
+	steps := []struct {
+		body   string
+		userID uint
+		code   int
+	}{
+		{`{"status":"paid"}`, 2, http.StatusForbidden}, // only the seller confirms payment
+		{`{"status":"paid"}`, 1, http.StatusOK},
+		{`{"status":"shipped"}`, 1, http.StatusBadRequest}, // tracking number required
+		{`{"status":"shipped","carrier":"VRL","tracking_number":"VRL123"}`, 1, http.StatusOK},
+		{`{"status":"cancelled"}`, 2, http.StatusConflict},
+		{`{"status":"delivered"}`, 2, http.StatusOK},
+	}
+	for i, step := range steps {
+		c, rec := paymentCtx(e, http.MethodPut, step.body, step.userID, "buyer", "id", orderID)
+		UpdateOrderStatus(c)
+		if rec.Code != step.code {
+			t.Fatalf("step %d %s: expected %d, got %d: %s", i, step.body, step.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCancelOrderRelistsMachine(t *testing.T) {
+	e := echo.New()
+	machine, db := seedSaleMachine(t)
+	offer := makeOffer(t, e, machine, 2, "1000000")
+
+	c1, rec1 := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 1, "seller", "id", offer.ID.String())
+	RespondToOffer(c1)
+	var result OfferResult
+	json.Unmarshal(rec1.Body.Bytes(), &result)
+	if result.Order == nil {
+		t.Fatalf("expected an order, got %s", rec1.Body.String())
+	}
+
+	c2, rec2 := paymentCtx(e, http.MethodPut, `{"status":"cancelled"}`, 2, "buyer", "id", result.Order.ID.String())
+	UpdateOrderStatus(c2)
+	if rec2.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+
+	var relisted models.Machine
+	db.First(&relisted, "id = ?", machine.ID)
+	if relisted.Status != "verified" {
+		t.Errorf("expected machine status to be restored, got %s", relisted.Status)
+	}
+}
+
+func TestCreateOffer_Validation(t *testing.T) {
+	e := echo.New()
+	machine, db := seedSaleMachine(t)
+
+	rentOnly := models.Machine{Title: "Crane", Description: "For rent", SellerID: 1, ListingType: "rent", Status: "verified"}
+	db.Create(&rentOnly)
+
+	cases := []struct {
+		name    string
+		machine models.Machine
+		body    string
+		userID  uint
+		code    int
+	}{
+		{"own machine", machine, `{"amount":500000}`, 1, http.StatusBadRequest},
+		{"zero amount", machine, `{"amount":0}`, 2, http.StatusBadRequest},
+		{"not for sale", rentOnly, `{"amount":500000}`, 2, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := paymentCtx(e, http.MethodPost, tc.body, tc.userID, "buyer", "id", tc.machine.ID.String())
+			CreateOffer(c)
+			if rec.Code != tc.code {
+				t.Errorf("expected %d, got %d: %s", tc.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// A second open offer from the same buyer is rejected
+	makeOffer(t, e, machine, 2, "900000")
+	c, rec := paymentCtx(e, http.MethodPost, `{"amount":910000}`, 2, "buyer", "id", machine.ID.String())
+	CreateOffer(c)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+}
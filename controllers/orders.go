@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderStatusUpdate moves an order along its flow
+type OrderStatusUpdate struct {
+	Status         string `json:"status" example:"shipped"`
+	Carrier        string `json:"carrier" example:"VRL Logistics"` // when shipping
+	TrackingNumber string `json:"tracking_number"`                 // when shipping
+}
+
+// GetMyOrders godoc
+//
+//	@Summary		Get my purchases
+//	@Description	List the orders the current user has placed as a buyer, newest first.
+//	@Tags			Orders
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching orders"
+//	@Success		200				{object}	pagination.Page[models.Order]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/orders/my [get]
+func GetMyOrders(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query := config.DB.Preload("Machine").Where("buyer_id = ?", user.ID)
+	return respondPage(c, query, orderOrder, "Failed to fetch orders")
+}
+
+// GetSellerOrders godoc
+//
+//	@Summary		Get my sales
+//	@Description	List the orders for machines the current user has sold (the organization's, when acting for one), newest first.
+//	@Tags			Orders
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching orders"
+//	@Success		200				{object}	pagination.Page[models.Order]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/orders/manage [get]
+func GetSellerOrders(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query := ownedBy(config.DB.Preload("Machine"), "orders", "seller_id", user)
+	return respondPage(c, query, orderOrder, "Failed to fetch orders")
+}
+
+// orderOrder lists orders newest first
+var orderOrder = pagination.Order[models.Order]{
+	{Expr: "created_at", Type: "timestamptz", Desc: true, Value: func(o *models.Order) interface{} { return o.CreatedAt }},
+	{Expr: "id", Type: "uuid", Desc: true, Value: func(o *models.Order) interface{} { return o.ID }},
+}
+
+// UpdateOrderStatus godoc
+//
+//	@Summary		Update order status
+//	@Description	Move an order through accepted → paid → shipped → delivered. The seller confirms payment and ships (with carrier and tracking number); the buyer confirms delivery. Either side may cancel before payment, which puts the machine back on sale.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Order ID"
+//	@Param			status	body		OrderStatusUpdate	true	"New Status"
+//	@Success		200		{object}	models.Order
+//	@Failure		400		{object}	map[string]string	"Unknown status or missing tracking number"
+//	@Failure		403		{object}	map[string]string	"Not authorized"
+//	@Failure		409		{object}	map[string]string	"Invalid transition"
+//	@Router			/orders/{id}/status [put]
+func UpdateOrderStatus(c echo.Context) error {
+	var req OrderStatusUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Order not found"})
+	}
+
+	parties := saleParties(order.BuyerID, orderSeller(&order), user)
+	if len(parties) == 0 {
+		return policy.Deny(c, "You are not a party to this order")
+	}
+
+	if req.Status == models.OrderShipped && req.TrackingNumber == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "A tracking number is required to ship"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", order.ID).Error; err != nil {
+			return err
+		}
+
+		if err := models.CheckOrderTransition(order.Status, req.Status, parties); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		order.Status = req.Status
+		switch req.Status {
+		case models.OrderPaid:
+			order.PaidAt = &now
+		case models.OrderShipped:
+			order.ShippedAt = &now
+			order.Carrier = req.Carrier
+			order.TrackingNumber = req.TrackingNumber
+		case models.OrderDelivered:
+			order.DeliveredAt = &now
+		case models.OrderCancelled:
+			order.CancelledAt = &now
+			// Put the machine back on sale
+			if err := tx.Model(&models.Machine{}).Where("id = ?", order.MachineID).
+				Update("status", order.PreviousMachineStatus).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit(clause.Associations).Save(&order).Error
+	})
+
+	switch {
+	case errors.Is(err, models.ErrUnknownOrderStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrOrderTransitionDenied):
+		return policy.Deny(c, err.Error())
+	case errors.Is(err, models.ErrInvalidOrderTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update order"})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errLastOwner = errors.New("an organization must keep at least one owner")
+
+// OrganizationRequest is the payload to create an organization
+type OrganizationRequest struct {
+	Name  string `json:"name" example:"Shakti Precision Tools Pvt Ltd"`
+	GSTIN string `json:"gstin" example:"06AABCS1429B1Z5"`
+	State string `json:"state" example:"Haryana"`
+}
+
+// MemberRequest is the payload to add a member to an organization
+type MemberRequest struct {
+	UserID uint   `json:"user_id" example:"7"`
+	Role   string `json:"role" example:"member"` // owner, admin or member
+}
+
+// machineOwner is who a listing belongs to
+func machineOwner(m *models.Machine) policy.Owner {
+	return policy.Owner{UserID: m.SellerID, OrgID: m.OrganizationID}
+}
+
+// offerSeller is who sells the machine an offer is made on
+func offerSeller(o *models.Offer) policy.Owner {
+	return policy.Owner{UserID: o.SellerID, OrgID: o.OrganizationID}
+}
+
+// orderSeller is who sold the machine in an order
+func orderSeller(o *models.Order) policy.Owner {
+	return policy.Owner{UserID: o.SellerID, OrgID: o.OrganizationID}
+}
+
+// rentalRenter is who booked a rental
+func rentalRenter(r *models.Rental) policy.Owner {
+	return policy.Owner{UserID: r.RenterID, OrgID: r.OrganizationID}
+}
+
+// ownedBy restricts query to rows owned by the caller: the organization's
+// rows when they act for one, otherwise their personal rows
+func ownedBy(query *gorm.DB, table, userColumn string, user *UserClaims) *gorm.DB {
+	if user.Org != nil {
+		return query.Where(table+".organization_id = ?", user.Org.OrgID)
+	}
+	return query.Where(table+"."+userColumn+" = ? AND "+table+".organization_id IS NULL", user.ID)
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create an organization
+//	@Description	Register a company. The caller becomes its first owner. Send its ID in the X-Organization-ID header to list machines and book rentals on its behalf.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			organization	body		OrganizationRequest	true	"Organization"
+//	@Success		201				{object}	models.Organization
+//	@Failure		400				{object}	map[string]string	"Invalid input"
+//	@Router			/organizations [post]
+func CreateOrganization(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.OrganizationManage) {
+		return policy.Forbid(c, policy.OrganizationManage, "")
+	}
+
+	var req OrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name is required"})
+	}
+
+	org := models.Organization{Name: req.Name, GSTIN: strings.ToUpper(strings.TrimSpace(req.GSTIN)), State: req.State}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		owner := models.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: models.OrgRoleOwner}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		org.Members = []models.OrganizationMember{owner}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create organization"})
+	}
+
+	return c.JSON(http.StatusCreated, org)
+}
+
+// GetMyOrganizations godoc
+//
+//	@Summary		List my organizations
+//	@Description	List the organizations the logged-in user belongs to, with their member role in each, oldest membership first, a page at a time.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.OrganizationMember]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Router			/organizations/my [get]
+func GetMyOrganizations(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	query := config.DB.Preload("Organization").Where("user_id = ?", user.ID)
+	return respondPage(c, query, membershipOrder, "Failed to fetch organizations")
+}
+
+// membershipOrder lists memberships oldest first
+var membershipOrder = pagination.Order[models.OrganizationMember]{
+	{Expr: "created_at", Type: "timestamptz", Value: func(m *models.OrganizationMember) interface{} { return m.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(m *models.OrganizationMember) interface{} { return m.ID }},
+}
+
+// GetOrganization godoc
+//
+//	@Summary		Get an organization
+//	@Description	Retrieve an organization and its members. Members and admins only.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Organization ID"
+//	@Success		200	{object}	models.Organization
+//	@Failure		403	{object}	map[string]string	"Not a member"
+//	@Failure		404	{object}	map[string]string	"Organization not found"
+//	@Router			/organizations/{id} [get]
+func GetOrganization(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var org models.Organization
+	if err := config.DB.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc")
+	}).First(&org, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Organization not found"})
+	}
+
+	if findMember(org.Members, user.ID) == nil && !user.Can(policy.Any(policy.OrganizationManage)) {
+		return policy.Deny(c, "You are not a member of this organization")
+	}
+
+	return c.JSON(http.StatusOK, org)
+}
+
+// AddOrganizationMember godoc
+//
+//	@Summary		Add a member to an organization
+//	@Description	Add a user with the owner, admin or member role. Owners and admins manage members; only owners can add owners. Every member can manage the organization's machines and rentals.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Organization ID"
+//	@Param			member	body		MemberRequest	true	"Member"
+//	@Success		201		{object}	models.OrganizationMember
+//	@Failure		400		{object}	map[string]string	"Invalid input or role"
+//	@Failure		403		{object}	map[string]string	"Not allowed to manage members"
+//	@Failure		404		{object}	map[string]string	"Organization not found"
+//	@Failure		409		{object}	map[string]string	"Already a member"
+//	@Router			/organizations/{id}/members [post]
+func AddOrganizationMember(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req MemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if req.Role == "" {
+		req.Role = models.OrgRoleMember
+	}
+	if req.UserID == 0 || !models.IsOrgRole(req.Role) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "user_id and a role of owner, admin or member are required"})
+	}
+
+	org, actor, herr := loadManagedOrganization(c.Param("id"), user)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+	if req.Role == models.OrgRoleOwner && !canActAsOwner(actor, user) {
+		return policy.Deny(c, "Only owners can add owners")
+	}
+
+	// The unique (organization_id, user_id) index decides between
+	// concurrent adds of the same user
+	member := models.OrganizationMember{OrganizationID: org.ID, UserID: req.UserID, Role: req.Role}
+	added := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+	if added.Error != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add member"})
+	}
+	if added.RowsAffected == 0 {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "User is already a member"})
+	}
+
+	return c.JSON(http.StatusCreated, member)
+}
+
+// RemoveOrganizationMember godoc
+//
+//	@Summary		Remove a member from an organization
+//	@Description	Owners and admins remove members; any member may remove themselves. Only owners can remove owners, and the last owner cannot be removed.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Organization ID"
+//	@Param			user_id	path		int		true	"User ID"
+//	@Success		200		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string	"Not allowed to manage members"
+//	@Failure		404		{object}	map[string]string	"Organization or member not found"
+//	@Failure		409		{object}	map[string]string	"Last owner"
+//	@Router			/organizations/{id}/members/{user_id} [delete]
+func RemoveOrganizationMember(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+	}
+
+	var org models.Organization
+	if err := config.DB.Preload("Members").First(&org, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Organization not found"})
+	}
+
+	target := findMember(org.Members, uint(userID))
+	if target == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+	}
+
+	// Leaving is always allowed; removing someone else takes an owner or
+	// admin, and removing an owner takes an owner
+	if target.UserID != user.ID {
+		actor := findMember(org.Members, user.ID)
+		if !canManageMembers(actor, user) {
+			return policy.Deny(c, "Only owners and admins can remove members")
+		}
+		if target.Role == models.OrgRoleOwner && !canActAsOwner(actor, user) {
+			return policy.Deny(c, "Only owners can remove owners")
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the owners so two owners cannot remove each other at once
+		var owners []models.OrganizationMember
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND role = ?", org.ID, models.OrgRoleOwner).Find(&owners).Error; err != nil {
+			return err
+		}
+		if target.Role == models.OrgRoleOwner && len(owners) <= 1 {
+			return errLastOwner
+		}
+		return tx.Delete(&models.OrganizationMember{}, "id = ?", target.ID).Error
+	})
+	switch {
+	case errors.Is(err, errLastOwner):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to remove member"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Member removed"})
+}
+
+// loadManagedOrganization loads the organization in the path and the
+// caller's membership, failing unless they may manage its members
+func loadManagedOrganization(id string, user *UserClaims) (*models.Organization, *models.OrganizationMember, *echo.HTTPError) {
+	var org models.Organization
+	if err := config.DB.Preload("Members").First(&org, "id = ?", id).Error; err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Organization not found")
+	}
+
+	actor := findMember(org.Members, user.ID)
+	if !canManageMembers(actor, user) {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Only owners and admins can manage members")
+	}
+	return &org, actor, nil
+}
+
+func findMember(members []models.OrganizationMember, userID uint) *models.OrganizationMember {
+	for i := range members {
+		if members[i].UserID == userID {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// canManageMembers: owners and admins of the organization, and platform
+// admins
+func canManageMembers(actor *models.OrganizationMember, user *UserClaims) bool {
+	if user.Can(policy.Any(policy.OrganizationManage)) {
+		return true
+	}
+	return actor != nil && actor.CanManageMembers() && user.Can(policy.OrganizationManage)
+}
+
+func canActAsOwner(actor *models.OrganizationMember, user *UserClaims) bool {
+	if user.Can(policy.Any(policy.OrganizationManage)) {
+		return true
+	}
+	return actor != nil && actor.Role == models.OrgRoleOwner
+}
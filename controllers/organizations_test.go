@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/middleware"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
+)
+
+// asOrg runs handler behind the organization middleware with the
+// X-Organization-ID header set
+func asOrg(c echo.Context, orgID string, handler echo.HandlerFunc) error {
+	c.Request().Header.Set(policy.OrganizationHeader, orgID)
+	return middleware.Organization()(handler)(c)
+}
+
+func createOrganization(t *testing.T, e *echo.Echo, ownerID uint) models.Organization {
+	t.Helper()
+	c, rec := paymentCtx(e, http.MethodPost, `{"name":"Shakti Precision Tools","state":"Haryana"}`, ownerID, "seller", "", "")
+	CreateOrganization(c)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var org models.Organization
+	json.Unmarshal(rec.Body.Bytes(), &org)
+	return org
+}
+
+func TestOrganizationMembers(t *testing.T) {
+	e := echo.New()
+	setupTestDB(t, nil)
+	org := createOrganization(t, e, 1)
+
+	// A plain member cannot add others
+	c1, rec1 := paymentCtx(e, http.MethodPost, `{"user_id":5,"role":"member"}`, 1, "seller", "id", org.ID.String())
+	AddOrganizationMember(c1)
+	if rec1.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec1.Code, rec1.Body.String())
+	}
+	cDup, recDup := paymentCtx(e, http.MethodPost, `{"user_id":5,"role":"admin"}`, 1, "seller", "id", org.ID.String())
+	AddOrganizationMember(cDup)
+	if recDup.Code != http.StatusConflict {
+		t.Errorf("expected 409 for an existing member, got %d", recDup.Code)
+	}
+	c2, rec2 := paymentCtx(e, http.MethodPost, `{"user_id":6}`, 5, "seller", "id", org.ID.String())
+	AddOrganizationMember(c2)
+	if rec2.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a member adding members, got %d", rec2.Code)
+	}
+
+	// The last owner cannot leave
+	c3, rec3 := paymentCtx(e, http.MethodDelete, "", 1, "seller", "id", org.ID.String())
+	c3.SetParamNames("id", "user_id")
+	c3.SetParamValues(org.ID.String(), "1")
+	RemoveOrganizationMember(c3)
+	if rec3.Code != http.StatusConflict {
+		t.Errorf("expected 409 for the last owner, got %d", rec3.Code)
+	}
+
+	// Outsiders cannot act for the organization
+	c4, rec4 := paymentCtx(e, http.MethodGet, "", 9, "seller", "", "")
+	asOrg(c4, org.ID.String(), GetMyRentals)
+	if rec4.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-member, got %d", rec4.Code)
+	}
+}
+
+func TestOrganizationFleet(t *testing.T) {
+	e := echo.New()
+	setupTestDB(t, nil)
+	org := createOrganization(t, e, 1)
+
+	c1, _ := paymentCtx(e, http.MethodPost, `{"user_id":5}`, 1, "seller", "id", org.ID.String())
+	AddOrganizationMember(c1)
+
+	// The owner lists a machine for the company
+	c2, rec2 := paymentCtx(e, http.MethodPost, `{"title":"Company Lathe","description":"Fleet","listing_type":"rent","rental_price_per_day":900}`, 1, "seller", "", "")
+	asOrg(c2, org.ID.String(), CreateListing)
+	if rec2.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec2.Code, rec2.Body.String())
+	}
+	var machine models.Machine
+	json.Unmarshal(rec2.Body.Bytes(), &machine)
+	if machine.OrganizationID == nil || *machine.OrganizationID != org.ID {
+		t.Fatalf("expected the machine to belong to the organization, got %v", machine.OrganizationID)
+	}
+
+	// Another member manages it while acting for the company...
+	c3, rec3 := paymentCtx(e, http.MethodPut, `{"title":"Company Lathe (serviced)","description":"Fleet","listing_type":"rent"}`, 5, "seller", "id", machine.ID.String())
+	asOrg(c3, org.ID.String(), UpdateListing)
+	if rec3.Code != http.StatusOK {
+		t.Errorf("expected 200 for a member, got %d: %s", rec3.Code, rec3.Body.String())
+	}
+
+	// ...but not on their own account
+	c4, rec4 := paymentCtx(e, http.MethodDelete, "", 5, "seller", "id", machine.ID.String())
+	DeleteListing(c4)
+	if rec4.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without X-Organization-ID, got %d", rec4.Code)
+	}
+
+	// Members can log maintenance and decide rentals of company machines
+	config.DB.AutoMigrate(&models.MaintenanceRecord{})
+	c5, rec5 := paymentCtx(e, http.MethodPost, `{"machine_id":"`+machine.ID.String()+`","service_date":"2025-01-10","type":"Routine"}`, 5, "seller", "", "")
+	asOrg(c5, org.ID.String(), AddMaintenanceRecord)
+	if rec5.Code != http.StatusCreated {
+		t.Errorf("expected 201 for maintenance, got %d: %s", rec5.Code, rec5.Body.String())
+	}
+
+	rental := seedRentalRequest(t, config.DB, machine.ID, 2)
+	c6, rec6 := paymentCtx(e, http.MethodPut, `{"status":"rejected"}`, 5, "seller", "id", rental.ID.String())
+	asOrg(c6, org.ID.String(), UpdateRentalStatus)
+	if rec6.Code != http.StatusOK {
+		t.Errorf("expected 200 for a member rejecting, got %d: %s", rec6.Code, rec6.Body.String())
+	}
+}
+
+func TestOrganizationSales(t *testing.T) {
+	e := echo.New()
+	setupTestDB(t, nil)
+	org := createOrganization(t, e, 1)
+	c1, _ := paymentCtx(e, http.MethodPost, `{"user_id":5}`, 1, "seller", "id", org.ID.String())
+	AddOrganizationMember(c1)
+
+	c2, rec2 := paymentCtx(e, http.MethodPost, `{"title":"Company Press","description":"Surplus","listing_type":"sale","price_for_sale":500000}`, 1, "seller", "", "")
+	asOrg(c2, org.ID.String(), CreateListing)
+	var machine models.Machine
+	json.Unmarshal(rec2.Body.Bytes(), &machine)
+
+	c3, rec3 := paymentCtx(e, http.MethodPost, `{"amount":450000}`, 2, "buyer", "id", machine.ID.String())
+	CreateOffer(c3)
+	var offer models.Offer
+	json.Unmarshal(rec3.Body.Bytes(), &offer)
+	if rec3.Code != http.StatusCreated || offer.OrganizationID == nil || *offer.OrganizationID != org.ID {
+		t.Fatalf("expected an offer to the organization, got %d %s", rec3.Code, rec3.Body.String())
+	}
+
+	// The member who listed it cannot answer on their own account...
+	c4, rec4 := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 1, "seller", "id", offer.ID.String())
+	RespondToOffer(c4)
+	if rec4.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without X-Organization-ID, got %d", rec4.Code)
+	}
+
+	// ...while any member acting for the company can
+	c5, rec5 := paymentCtx(e, http.MethodPut, `{"action":"accept"}`, 5, "seller", "id", offer.ID.String())
+	asOrg(c5, org.ID.String(), RespondToOffer)
+	var result OfferResult
+	json.Unmarshal(rec5.Body.Bytes(), &result)
+	if rec5.Code != http.StatusOK || result.Order == nil || result.Order.OrganizationID == nil || *result.Order.OrganizationID != org.ID {
+		t.Fatalf("expected a company order, got %d %s", rec5.Code, rec5.Body.String())
+	}
+
+	c6, rec6 := paymentCtx(e, http.MethodPut, `{"status":"paid"}`, 5, "seller", "id", result.Order.ID.String())
+	asOrg(c6, org.ID.String(), UpdateOrderStatus)
+	if rec6.Code != http.StatusOK {
+		t.Errorf("expected 200 for a member confirming payment, got %d: %s", rec6.Code, rec6.Body.String())
+	}
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"gorm.io/gorm"
+)
+
+// respondPage pages query by order using the request's limit, cursor and
+// include_total parameters and writes the list envelope
+func respondPage[T any](c echo.Context, query *gorm.DB, order pagination.Order[T], failMsg string) error {
+	page, err := pagination.Paginate(query, pagination.FromQuery(c.QueryParams()), order)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": failMsg})
+	}
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,653 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/payments"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errPaymentRequired blocks approval until the renter has paid
+	errPaymentRequired  = errors.New("rental charge must be captured and the security deposit held before approval")
+	errRentalNotPending = errors.New("rental is not pending")
+	// errEventSeen marks a webhook event that has already been applied
+	errEventSeen = errors.New("payment event already applied")
+	// errIntentClosed means an open intent was closed while a new one was
+	// being created for the other purpose
+	errIntentClosed = errors.New("payment intent closed meanwhile")
+)
+
+// PaymentAmountRequest is the payload for partial captures and refunds
+type PaymentAmountRequest struct {
+	Amount float64 `json:"amount" example:"2500"` // 0 = the full remaining amount
+}
+
+// SimulatePaymentRequest is the payload for the fake provider's checkout simulator
+type SimulatePaymentRequest struct {
+	Outcome string `json:"outcome" example:"success"` // success, failure
+}
+
+// CreateRentalPayments godoc
+//
+//	@Summary		Start payment for a rental
+//	@Description	Create payment intents for a pending rental: the rental charge (captured on payment) and, if any, the security deposit (held until settlement). Returns client secrets for checkout. Repeat calls return the open intents. Renter only.
+//	@Tags			Payments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Rental ID"
+//	@Success		201	{array}		models.Payment
+//	@Failure		403	{object}	map[string]string	"Not the renter"
+//	@Failure		404	{object}	map[string]string	"Rental not found"
+//	@Failure		409	{object}	map[string]string	"Rental is not pending"
+//	@Router			/rentals/{id}/payments [post]
+func CreateRentalPayments(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.First(&rental, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if !user.Owns(rentalRenter(&rental)) || !user.Can(policy.PaymentPay) {
+		return policy.Forbid(c, policy.PaymentPay, "Only the renter can pay for this rental")
+	}
+
+	wanted := []struct {
+		purpose       string
+		amount        float64
+		captureMethod string
+	}{
+		{models.PaymentForRental, rental.ChargeAmount(), payments.CaptureAutomatic},
+		{models.PaymentForDeposit, rental.SecurityDeposit, payments.CaptureManual},
+	}
+
+	// Intents are created with the provider before the rental is locked, so
+	// a slow provider never holds the lock. Under the lock an intent another
+	// call opened meanwhile wins, and ours is cancelled afterwards.
+	ctx := c.Request().Context()
+	intents := map[string]*payments.Intent{}
+	var result []models.Payment
+	var saved map[string]bool
+	for attempt := 0; attempt < 2; attempt++ {
+		for _, w := range wanted {
+			if w.amount <= 0 || intents[w.purpose] != nil {
+				continue
+			}
+			open, err := openPayment(config.DB, rental.ID, w.purpose)
+			if err != nil {
+				cancelIntents(ctx, intents, nil)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save payments"})
+			}
+			if open != nil {
+				continue
+			}
+			intent, err := payments.Active.CreateIntent(ctx, payments.IntentRequest{
+				Amount:        w.amount,
+				Currency:      "INR",
+				CaptureMethod: w.captureMethod,
+				Description:   "Rental " + rental.ID.String() + " " + w.purpose,
+				Metadata:      map[string]string{"rental_id": rental.ID.String(), "purpose": w.purpose},
+			})
+			if err != nil {
+				cancelIntents(ctx, intents, nil)
+				return c.JSON(http.StatusBadGateway, map[string]string{"error": "Payment provider error: " + err.Error()})
+			}
+			intents[w.purpose] = intent
+		}
+
+		result, saved = nil, map[string]bool{}
+		err = config.DB.Transaction(func(tx *gorm.DB) error {
+			var locked models.Rental
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", rental.ID).Error; err != nil {
+				return err
+			}
+			if locked.Status != models.RentalPending {
+				return errRentalNotPending
+			}
+
+			for _, w := range wanted {
+				if w.amount <= 0 {
+					continue
+				}
+
+				// Reuse an intent that is still usable
+				existing, err := openPayment(tx, rental.ID, w.purpose)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					result = append(result, *existing)
+					continue
+				}
+
+				intent := intents[w.purpose]
+				if intent == nil {
+					// The intent seen before the lock has since failed
+					return errIntentClosed
+				}
+				payment := models.Payment{
+					RentalID:      rental.ID,
+					PayerID:       user.ID,
+					Purpose:       w.purpose,
+					Provider:      payments.Active.Name(),
+					ProviderRef:   intent.ProviderRef,
+					ClientSecret:  intent.ClientSecret,
+					CaptureMethod: w.captureMethod,
+					Amount:        w.amount,
+					Currency:      "INR",
+					Status:        models.PaymentRequiresPayment,
+				}
+				if err := tx.Create(&payment).Error; err != nil {
+					return err
+				}
+				result = append(result, payment)
+				saved[w.purpose] = true
+			}
+			return nil
+		})
+		if !errors.Is(err, errIntentClosed) {
+			break
+		}
+	}
+	if err != nil {
+		saved = nil
+	}
+	cancelIntents(ctx, intents, saved)
+
+	switch {
+	case errors.Is(err, errRentalNotPending):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Payments can only be started for pending rentals"})
+	case errors.Is(err, errIntentClosed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Payments changed meanwhile, please try again"})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save payments"})
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// openPayment finds a rental's intent for purpose that is still usable, or nil
+func openPayment(tx *gorm.DB, rentalID uuid.UUID, purpose string) (*models.Payment, error) {
+	var existing models.Payment
+	found := tx.
+		Where("rental_id = ? AND purpose = ? AND status IN ?", rentalID, purpose,
+			[]string{models.PaymentRequiresPayment, models.PaymentAuthorized, models.PaymentCaptured}).
+		Limit(1).Find(&existing)
+	if found.Error != nil || found.RowsAffected == 0 {
+		return nil, found.Error
+	}
+	return &existing, nil
+}
+
+// cancelIntents cancels the provider intents that were not saved. Failures
+// are logged; an unpaid intent that is never used simply expires.
+func cancelIntents(ctx context.Context, intents map[string]*payments.Intent, saved map[string]bool) {
+	for purpose, intent := range intents {
+		if saved[purpose] {
+			continue
+		}
+		if err := payments.Active.Cancel(ctx, intent.ProviderRef); err != nil {
+			log.Printf("❌ Could not cancel unused payment intent %s: %v", intent.ProviderRef, err)
+		}
+	}
+}
+
+// GetRentalPayments godoc
+//
+//	@Summary		List payments for a rental
+//	@Description	Retrieve the payment intents of a rental, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them; client secrets are only shown to the payer.
+//	@Tags			Payments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		string	true	"Rental ID"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.Payment]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Failure		403				{object}	map[string]string	"Not authorized"
+//	@Failure		404				{object}	map[string]string	"Rental not found"
+//	@Router			/rentals/{id}/payments [get]
+func GetRentalPayments(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if len(rentalParties(&rental, user)) == 0 {
+		return policy.Deny(c, "You are not a party to this rental")
+	}
+
+	query := config.DB.Where("rental_id = ?", rental.ID)
+	page, err := pagination.Paginate(query, pagination.FromQuery(c.QueryParams()), paymentOrder)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch payments"})
+	}
+
+	for i := range page.Data {
+		if page.Data[i].PayerID != user.ID {
+			page.Data[i].ClientSecret = ""
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// paymentOrder lists payments oldest first
+var paymentOrder = pagination.Order[models.Payment]{
+	{Expr: "created_at", Type: "timestamptz", Value: func(p *models.Payment) interface{} { return p.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(p *models.Payment) interface{} { return p.ID }},
+}
+
+// PaymentWebhook godoc
+//
+//	@Summary		Payment provider webhook
+//	@Description	Receives signed status notifications from the payment provider. The body must be signed with HMAC-SHA256 in the X-Payment-Signature header.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Payment-Signature	header		string	true	"Hex HMAC-SHA256 of the body"
+//	@Success		200					{object}	map[string]string
+//	@Failure		400					{object}	map[string]string	"Invalid payload"
+//	@Failure		401					{object}	map[string]string	"Invalid signature"
+//	@Router			/payments/webhook [post]
+func PaymentWebhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Could not read body"})
+	}
+
+	event, err := payments.Active.ParseWebhook(body, c.Request().Header.Get(payments.SignatureHeader))
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+	}
+	if err != nil || event.ID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid payload"})
+	}
+
+	if err := applyPaymentEvent(event); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// Not one of ours; acknowledge so the provider stops retrying
+			return c.JSON(http.StatusOK, map[string]string{"status": "ignored"})
+		case errors.Is(err, errEventSeen):
+			return c.JSON(http.StatusOK, map[string]string{"status": "duplicate"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process event"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"status": "processed"})
+}
+
+// applyPaymentEvent moves a payment forward in response to a webhook.
+// Each event ID is applied once (errEventSeen on a replay), and
+// out-of-order deliveries never regress a payment.
+func applyPaymentEvent(event *payments.WebhookEvent) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&payment, "provider_ref = ?", event.ProviderRef).Error; err != nil {
+			return err
+		}
+
+		seen := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.PaymentEvent{ID: event.ID, PaymentID: payment.ID, Type: event.Type})
+		if seen.Error != nil {
+			return seen.Error
+		}
+		if seen.RowsAffected == 0 {
+			return errEventSeen
+		}
+
+		switch event.Type {
+		case payments.EventAuthorized:
+			if payment.Status == models.PaymentRequiresPayment {
+				payment.Status = models.PaymentAuthorized
+			}
+		case payments.EventCaptured:
+			if payment.Status == models.PaymentRequiresPayment || payment.Status == models.PaymentAuthorized {
+				payment.Status = models.PaymentCaptured
+				payment.CapturedAmount = event.Amount
+			}
+		case payments.EventFailed:
+			if payment.Status == models.PaymentRequiresPayment {
+				payment.Status = models.PaymentFailed
+			}
+		case payments.EventCancelled:
+			if payment.Status == models.PaymentRequiresPayment || payment.Status == models.PaymentAuthorized {
+				payment.Status = models.PaymentCancelled
+			}
+		case payments.EventRefunded:
+			payment.RefundedAmount = math.Max(payment.RefundedAmount, event.Amount)
+			payment.Status = refundStatus(&payment)
+		}
+
+		return tx.Save(&payment).Error
+	})
+}
+
+// SimulatePayment godoc
+//
+//	@Summary		Simulate checkout (fake provider only)
+//	@Description	Completes or declines a payment intent with the in-process fake provider and delivers the signed webhook, for local development. Only registered when PAYMENT_PROVIDER=fake. Payer or admin only.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Payment ID"
+//	@Param			request	body		SimulatePaymentRequest	true	"Outcome"
+//	@Success		200		{object}	models.Payment
+//	@Failure		403		{object}	map[string]string	"Not authorized"
+//	@Failure		404		{object}	map[string]string	"Not found or provider is not fake"
+//	@Router			/payments/{id}/simulate [post]
+func SimulatePayment(c echo.Context) error {
+	fake, ok := payments.Active.(*payments.FakeProvider)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Simulation is only available with the fake provider"})
+	}
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req SimulatePaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	var payment models.Payment
+	if err := config.DB.First(&payment, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Payment not found"})
+	}
+	if !user.Allows(policy.PaymentPay, policy.Owner{UserID: payment.PayerID}) {
+		return policy.Forbid(c, policy.PaymentPay, "You are not the payer")
+	}
+
+	var payload []byte
+	var signature string
+	if req.Outcome == "failure" {
+		payload, signature, err = fake.Fail(payment.ProviderRef)
+	} else {
+		payload, signature, err = fake.Complete(payment.ProviderRef)
+	}
+	if err != nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+
+	// Deliver through the same path as a real webhook
+	event, err := fake.ParseWebhook(payload, signature)
+	if err == nil {
+		err = applyPaymentEvent(event)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to apply payment event"})
+	}
+
+	config.DB.First(&payment, "id = ?", payment.ID)
+	return c.JSON(http.StatusOK, payment)
+}
+
+// CapturePayment godoc
+//
+//	@Summary		Capture a held payment
+//	@Description	Capture all or part of an authorised hold (e.g. a security deposit). The rest of the hold is released. Admin only.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Payment ID"
+//	@Param			request	body		PaymentAmountRequest	false	"Amount (0 = full hold)"
+//	@Success		200		{object}	models.Payment
+//	@Failure		403		{object}	map[string]string	"Admins only"
+//	@Failure		409		{object}	map[string]string	"Payment is not held"
+//	@Router			/payments/{id}/capture [post]
+func CapturePayment(c echo.Context) error {
+	return adminPaymentAction(c, capturePayment)
+}
+
+// ReleasePayment godoc
+//
+//	@Summary		Release a held payment
+//	@Description	Void an authorised hold or an unpaid intent without charging the payer. Admin only.
+//	@Tags			Payments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Payment ID"
+//	@Success		200	{object}	models.Payment
+//	@Failure		403	{object}	map[string]string	"Admins only"
+//	@Failure		409	{object}	map[string]string	"Payment cannot be released"
+//	@Router			/payments/{id}/release [post]
+func ReleasePayment(c echo.Context) error {
+	return adminPaymentAction(c, func(ctx context.Context, p *models.Payment, _ float64) error {
+		return releasePayment(ctx, p)
+	})
+}
+
+// RefundPayment godoc
+//
+//	@Summary		Refund a captured payment
+//	@Description	Refund all or part of a captured payment. Admin only.
+//	@Tags			Payments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string					true	"Payment ID"
+//	@Param			request	body		PaymentAmountRequest	false	"Amount (0 = everything not yet refunded)"
+//	@Success		200		{object}	models.Payment
+//	@Failure		403		{object}	map[string]string	"Admins only"
+//	@Failure		409		{object}	map[string]string	"Payment cannot be refunded"
+//	@Router			/payments/{id}/refund [post]
+func RefundPayment(c echo.Context) error {
+	return adminPaymentAction(c, refundPayment)
+}
+
+// adminPaymentAction loads a payment and runs a money-moving action on it
+func adminPaymentAction(c echo.Context, action func(context.Context, *models.Payment, float64) error) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	if !user.Can(policy.PaymentManage) {
+		return policy.Forbid(c, policy.PaymentManage, "Only admins can move funds manually")
+	}
+
+	var req PaymentAmountRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+		}
+	}
+	if req.Amount < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Amount cannot be negative"})
+	}
+
+	var payment models.Payment
+	if err := config.DB.First(&payment, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Payment not found"})
+	}
+
+	if err := action(c.Request().Context(), &payment, req.Amount); err != nil {
+		if errors.Is(err, payments.ErrInvalidState) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": "Payment provider error: " + err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, payment)
+}
+
+// capturePayment captures amount (0 = all) of an authorised hold
+func capturePayment(ctx context.Context, p *models.Payment, amount float64) error {
+	return movePayment(p, func(current *models.Payment) (map[string]interface{}, error) {
+		if current.Status != models.PaymentAuthorized {
+			return nil, payments.ErrInvalidState
+		}
+		if amount == 0 {
+			amount = current.Amount
+		}
+		if amount > current.Amount {
+			return nil, payments.ErrInvalidState
+		}
+
+		if err := payments.Active.Capture(ctx, current.ProviderRef, amount); err != nil {
+			return nil, err
+		}
+
+		current.Status = models.PaymentCaptured
+		current.CapturedAmount = amount
+		return map[string]interface{}{"status": current.Status, "captured_amount": amount}, nil
+	})
+}
+
+// releasePayment voids a hold or an intent that was never paid
+func releasePayment(ctx context.Context, p *models.Payment) error {
+	return movePayment(p, func(current *models.Payment) (map[string]interface{}, error) {
+		if current.Status != models.PaymentAuthorized && current.Status != models.PaymentRequiresPayment {
+			return nil, payments.ErrInvalidState
+		}
+
+		if err := payments.Active.Cancel(ctx, current.ProviderRef); err != nil {
+			return nil, err
+		}
+
+		current.Status = models.PaymentCancelled
+		return map[string]interface{}{"status": current.Status}, nil
+	})
+}
+
+// refundPayment refunds amount (0 = everything left) of a captured payment
+func refundPayment(ctx context.Context, p *models.Payment, amount float64) error {
+	return movePayment(p, func(current *models.Payment) (map[string]interface{}, error) {
+		if current.Status != models.PaymentCaptured && current.Status != models.PaymentPartiallyRefunded {
+			return nil, payments.ErrInvalidState
+		}
+
+		remaining := current.CapturedAmount - current.RefundedAmount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return nil, payments.ErrInvalidState
+		}
+		if amount == 0 {
+			return nil, nil
+		}
+
+		if err := payments.Active.Refund(ctx, current.ProviderRef, amount); err != nil {
+			return nil, err
+		}
+
+		current.RefundedAmount += amount
+		current.Status = refundStatus(current)
+		return map[string]interface{}{"status": current.Status, "refunded_amount": current.RefundedAmount}, nil
+	})
+}
+
+// movePayment runs a money-moving action on a payment under its row lock,
+// so that a webhook applied meanwhile is seen rather than overwritten, and
+// saves only the columns the action returns. p is refreshed from the row.
+func movePayment(p *models.Payment, action func(current *models.Payment) (map[string]interface{}, error)) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", p.ID).Error; err != nil {
+			return err
+		}
+
+		changes, err := action(&current)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			if err := tx.Model(&current).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+
+		*p = current
+		return nil
+	})
+}
+
+func refundStatus(p *models.Payment) string {
+	if p.RefundedAmount <= 0 {
+		return p.Status
+	}
+	if p.RefundedAmount >= p.CapturedAmount {
+		return models.PaymentRefunded
+	}
+	return models.PaymentPartiallyRefunded
+}
+
+// requirePaymentSecured checks that the rental charge is captured and any
+// deposit is held (or captured) before a rental can be approved.
+func requirePaymentSecured(tx *gorm.DB, rental *models.Rental) error {
+	var list []models.Payment
+	if err := tx.Where("rental_id = ?", rental.ID).Find(&list).Error; err != nil {
+		return err
+	}
+
+	chargePaid, depositHeld := rental.ChargeAmount() <= 0, rental.SecurityDeposit <= 0
+	for _, p := range list {
+		switch {
+		case p.Purpose == models.PaymentForRental && p.Status == models.PaymentCaptured:
+			chargePaid = true
+		case p.Purpose == models.PaymentForDeposit && (p.Status == models.PaymentAuthorized || p.Status == models.PaymentCaptured):
+			depositHeld = true
+		}
+	}
+
+	if !chargePaid || !depositHeld {
+		return errPaymentRequired
+	}
+	return nil
+}
+
+// unwindRentalPayments returns all money for a rejected or cancelled rental:
+// captured charges are refunded and holds or unpaid intents are voided.
+// Failures are logged and left for an admin to retry.
+func unwindRentalPayments(ctx context.Context, rental *models.Rental) {
+	var list []models.Payment
+	if err := config.DB.Where("rental_id = ?", rental.ID).Find(&list).Error; err != nil {
+		log.Printf("❌ Could not load payments for rental %s: %v", rental.ID, err)
+		return
+	}
+
+	for i := range list {
+		p := &list[i]
+		var err error
+		switch p.Status {
+		case models.PaymentCaptured, models.PaymentPartiallyRefunded:
+			err = refundPayment(ctx, p, 0)
+		case models.PaymentAuthorized, models.PaymentRequiresPayment:
+			err = releasePayment(ctx, p)
+		}
+		if err != nil {
+			log.Printf("❌ Could not return payment %s for rental %s: %v", p.ID, rental.ID, err)
+		}
+	}
+}
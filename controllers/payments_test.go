@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/payments"
+	"gorm.io/gorm"
+)
+
+// Helper to mark a rental as paid: charge captured and deposit held
+func seedSecuredPayments(t *testing.T, db *gorm.DB, rental models.Rental) {
+	list := []models.Payment{
+		{RentalID: rental.ID, PayerID: rental.RenterID, Purpose: models.PaymentForRental, Provider: "fake",
+			ProviderRef: "seed_" + rental.ID.String() + "_rental", CaptureMethod: payments.CaptureAutomatic,
+			Amount: rental.ChargeAmount(), CapturedAmount: rental.ChargeAmount(), Status: models.PaymentCaptured},
+		{RentalID: rental.ID, PayerID: rental.RenterID, Purpose: models.PaymentForDeposit, Provider: "fake",
+			ProviderRef: "seed_" + rental.ID.String() + "_deposit", CaptureMethod: payments.CaptureManual,
+			Amount: rental.SecurityDeposit, Status: models.PaymentAuthorized},
+	}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatalf("failed to seed payments: %v", err)
+	}
+}
+
+func paymentCtx(e *echo.Echo, method, body string, userID uint, role string, paramName, paramValue string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames(paramName)
+	c.SetParamValues(paramValue)
+	tokenStr := createTestToken(userID, role)
+	token, _ := jwt.ParseWithClaims(tokenStr, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	c.Set("user", token)
+	return c, rec
+}
+
+func TestRentalPaymentFlow(t *testing.T) {
+	e := echo.New()
+	payments.Active = payments.NewFakeProvider("test-secret")
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+	config.DB = db
+
+	// 1. Approval is blocked until paid
+	c1, rec1 := paymentCtx(e, http.MethodPut, `{"status":"approved"}`, 1, "seller", "id", rental.ID.String())
+	UpdateRentalStatus(c1)
+	if rec1.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected 402, got %d", rec1.Code)
+	}
+
+	// 2. Renter starts payment: rental charge + deposit hold
+	c2, rec2 := paymentCtx(e, http.MethodPost, "", 2, "buyer", "id", rental.ID.String())
+	if err := CreateRentalPayments(c2); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec2.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d. Body: %s", rec2.Code, rec2.Body.String())
+	}
+	var created []models.Payment
+	json.Unmarshal(rec2.Body.Bytes(), &created)
+	if len(created) != 2 {
+		t.Fatalf("expected 2 payments, got %d", len(created))
+	}
+	cRepeat, recRepeat := paymentCtx(e, http.MethodPost, "", 2, "buyer", "id", rental.ID.String())
+	CreateRentalPayments(cRepeat)
+	var repeated []models.Payment
+	json.Unmarshal(recRepeat.Body.Bytes(), &repeated)
+	if len(repeated) != 2 || repeated[0].ID != created[0].ID || repeated[1].ID != created[1].ID {
+		t.Fatalf("expected a repeat call to return the open intents, got %+v", repeated)
+	}
+
+	// 3. Renter completes checkout for both
+	for _, p := range created {
+		c3, rec3 := paymentCtx(e, http.MethodPost, `{"outcome":"success"}`, 2, "buyer", "id", p.ID.String())
+		SimulatePayment(c3)
+		if rec3.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d. Body: %s", rec3.Code, rec3.Body.String())
+		}
+	}
+
+	var deposit models.Payment
+	db.First(&deposit, "rental_id = ? AND purpose = ?", rental.ID, models.PaymentForDeposit)
+	if deposit.Status != models.PaymentAuthorized {
+		t.Errorf("expected deposit to be held, got %s", deposit.Status)
+	}
+
+	// 4. Owner can now approve
+	c4, rec4 := paymentCtx(e, http.MethodPut, `{"status":"approved"}`, 1, "seller", "id", rental.ID.String())
+	UpdateRentalStatus(c4)
+	if rec4.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d. Body: %s", rec4.Code, rec4.Body.String())
+	}
+
+	// 5. Renter cancels: charge refunded, deposit released
+	c5, rec5 := paymentCtx(e, http.MethodPut, `{"status":"cancelled"}`, 2, "buyer", "id", rental.ID.String())
+	UpdateRentalStatus(c5)
+	if rec5.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec5.Code)
+	}
+
+	var after []models.Payment
+	db.Where("rental_id = ?", rental.ID).Find(&after)
+	for _, p := range after {
+		if p.Purpose == models.PaymentForRental && p.Status != models.PaymentRefunded {
+			t.Errorf("expected rental charge refunded, got %s", p.Status)
+		}
+		if p.Purpose == models.PaymentForDeposit && p.Status != models.PaymentCancelled {
+			t.Errorf("expected deposit released, got %s", p.Status)
+		}
+	}
+}
+
+func TestPaymentWebhook_InvalidSignature(t *testing.T) {
+	e := echo.New()
+	payments.Active = payments.NewFakeProvider("test-secret")
+	setupTestDB(t, nil)
+
+	body := []byte(`{"id":"evt_1","type":"payment.captured","provider_ref":"fake_pi_1","amount":100}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader(body))
+	req.Header.Set(payments.SignatureHeader, payments.Sign("wrong-secret", body))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	PaymentWebhook(c)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestPaymentWebhook_Captured(t *testing.T) {
+	e := echo.New()
+	payments.Active = payments.NewFakeProvider("test-secret")
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+
+	payment := models.Payment{RentalID: rental.ID, PayerID: 2, Purpose: models.PaymentForRental, Provider: "fake",
+		ProviderRef: "fake_pi_hook", CaptureMethod: payments.CaptureAutomatic, Amount: 1000, Status: models.PaymentRequiresPayment}
+	db.Create(&payment)
+
+	body := []byte(`{"id":"evt_1","type":"payment.captured","provider_ref":"fake_pi_hook","amount":1000}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader(body))
+	req.Header.Set(payments.SignatureHeader, payments.Sign("test-secret", body))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := PaymentWebhook(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	db.First(&payment, "id = ?", payment.ID)
+	if payment.Status != models.PaymentCaptured || payment.CapturedAmount != 1000 {
+		t.Errorf("unexpected payment after webhook: %+v", payment)
+	}
+
+	// A retried delivery of the same event is acknowledged but not applied
+	req = httptest.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader(body))
+	req.Header.Set(payments.SignatureHeader, payments.Sign("test-secret", body))
+	rec = httptest.NewRecorder()
+	PaymentWebhook(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte("duplicate")) {
+		t.Errorf("expected the replay to be reported as a duplicate, got %d %s", rec.Code, rec.Body.String())
+	}
+	var events int64
+	db.Model(&models.PaymentEvent{}).Where("payment_id = ?", payment.ID).Count(&events)
+	if events != 1 {
+		t.Errorf("expected one recorded event, got %d", events)
+	}
+}
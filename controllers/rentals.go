@@ -1,39 +1,147 @@
 package controllers
 
 import (
-	"math"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/config"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/pricing"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RentalRequest represents the payload to create a rental
 type RentalRequest struct {
-	MachineID string `json:"machine_id" example:"uuid-string"`
-	StartDate string `json:"start_date" example:"2025-01-01"` // Format: YYYY-MM-DD
-	EndDate   string `json:"end_date" example:"2025-01-05"`   // Format: YYYY-MM-DD
+	MachineID   string `json:"machine_id" example:"uuid-string"`
+	StartDate   string `json:"start_date" example:"2025-01-01"`    // Format: YYYY-MM-DD
+	EndDate     string `json:"end_date" example:"2025-01-05"`      // Format: YYYY-MM-DD
+	RenterState string `json:"renter_state" example:"Maharashtra"` // GST place of supply; defaults to the machine's state
 }
 
 // RentalStatusUpdate represents the payload to update status
 type RentalStatusUpdate struct {
-	Status string `json:"status" example:"approved"` // approved, rejected, completed
+	Status string `json:"status" example:"approved"` // approved, rejected, active, completed, cancelled
+	Reason string `json:"reason" example:"Machine under repair that week"`
+}
+
+// rentalPlan is a validated rental request, priced and ready to book
+type rentalPlan struct {
+	Machine models.Machine
+	Start   time.Time
+	End     time.Time
+	Quote   *pricing.Quote
+}
+
+// planRental validates the requested machine and dates and prices the rental.
+func planRental(req RentalRequest) (*rentalPlan, *echo.HTTPError) {
+	if req.MachineID == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Machine ID is required")
+	}
+
+	var machine models.Machine
+	if err := config.DB.First(&machine, "id = ?", req.MachineID).Error; err != nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Machine not found")
+	}
+
+	if machine.ListingType == "sale" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "This machine is not for rent")
+	}
+	if machine.Status == models.MachineSold {
+		return nil, echo.NewHTTPError(http.StatusConflict, errMachineSold.Error())
+	}
+
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid start_date format")
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid end_date format")
+	}
+
+	if end.Before(start) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "End date cannot be before start date")
+	}
+
+	opts, err := quoteOptions(req.RenterState)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Failed to load pricing rules")
+	}
+
+	quote, err := pricing.QuoteRental(machine, start, end, opts)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return &rentalPlan{Machine: machine, Start: start, End: end, Quote: quote}, nil
+}
+
+// quoteOptions loads the active platform fee rules and the GST rate (GST_RATE, percent)
+func quoteOptions(renterState string) (pricing.QuoteOptions, error) {
+	var rules []models.PlatformFeeRule
+	if err := config.DB.Where("active = ?", true).Find(&rules).Error; err != nil {
+		return pricing.QuoteOptions{}, err
+	}
+
+	gstRate, _ := strconv.ParseFloat(os.Getenv("GST_RATE"), 64)
+
+	return pricing.QuoteOptions{
+		FeeRules:    rules,
+		RenterState: renterState,
+		GSTRate:     gstRate,
+	}, nil
+}
+
+// QuoteRental godoc
+//
+//	@Summary		Get a rental price quote
+//	@Description	Price a rental without creating it. Whole months and weeks are charged at the monthly and weekly rates, the remainder at the daily rate. Includes the platform fee and GST (CGST+SGST within a state, IGST across states).
+//	@Tags			Rentals
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		RentalRequest	true	"Rental Details"
+//	@Success		200		{object}	pricing.Quote
+//	@Failure		400		{object}	map[string]string	"Invalid input, dates or duration"
+//	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Router			/rentals/quote [post]
+func QuoteRental(c echo.Context) error {
+	var req RentalRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+
+	plan, herr := planRental(req)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
+
+	return c.JSON(http.StatusOK, plan.Quote)
 }
 
 // CreateRentalRequest godoc
 //
 //	@Summary		Request to rent a machine
-//	@Description	Initiate a rental request. Calculates fees based on duration. Requires Renter Auth.
+//	@Description	Initiate a rental request. Prices the rental using the machine's monthly, weekly and daily rates. Requires Renter Auth.
 //	@Tags			Rentals
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			request	body		RentalRequest	true	"Rental Details"
 //	@Success		201		{object}	models.Rental
-//	@Failure		400		{object}	map[string]string	"Invalid input or dates"
+//	@Failure		400		{object}	map[string]string	"Invalid input, dates or duration"
 //	@Failure		404		{object}	map[string]string	"Machine not found"
+//	@Failure		409		{object}	map[string]string	"Dates already booked"
 //	@Router			/rentals [post]
 func CreateRentalRequest(c echo.Context) error {
 	var req RentalRequest
@@ -51,49 +159,54 @@ func CreateRentalRequest(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 	}
 
-	var machine models.Machine
-	if err := config.DB.First(&machine, "id = ?", req.MachineID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "Machine not found"})
+	plan, herr := planRental(req)
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
 	}
 
-	if machine.ListingType == "sale" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "This machine is not for rent"})
-	}
-
-	layout := "2006-01-02"
-	start, err := time.Parse(layout, req.StartDate)
+	// Reject early if the dates are already taken; approval re-checks under lock
+	conflict, err := findOverlappingRental(config.DB, plan.Machine.ID, plan.Start, plan.End, uuid.Nil)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid start_date format"})
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check availability"})
 	}
-	end, err := time.Parse(layout, req.EndDate)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid end_date format"})
+	if conflict != nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": errRentalOverlap.Error()})
 	}
 
-	if end.Before(start) {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "End date cannot be before start date"})
-	}
-
-	days := math.Ceil(end.Sub(start).Hours() / 24)
-	if days < 1 {
-		days = 1
-	}
-
-	rentalFee := days * machine.RentalPricePerDay
-	platformFee := rentalFee * 0.05 // 5% Commission
+	breakdownJSON, _ := json.Marshal(plan.Quote.Breakdown)
 
 	rental := models.Rental{
-		MachineID:       machine.ID,
+		MachineID:       plan.Machine.ID,
 		RenterID:        user.ID, // Use ID from claims
-		StartDate:       start,
-		EndDate:         end,
-		TotalAmount:     rentalFee,
-		SecurityDeposit: machine.SecurityDeposit,
-		PlatformFee:     platformFee,
-		Status:          "pending",
+		OrganizationID:  user.OrgID(),
+		StartDate:       plan.Start,
+		EndDate:         plan.End,
+		TotalAmount:     plan.Quote.Subtotal,
+		SecurityDeposit: plan.Quote.SecurityDeposit,
+		PlatformFee:     plan.Quote.PlatformFee,
+		GSTRate:         plan.Quote.Tax.Rate,
+		TaxableAmount:   plan.Quote.Tax.TaxableValue,
+		CGST:            plan.Quote.Tax.CGST,
+		SGST:            plan.Quote.Tax.SGST,
+		IGST:            plan.Quote.Tax.IGST,
+		SellerState:     plan.Quote.Tax.SellerState,
+		RenterState:     plan.Quote.Tax.RenterState,
+		PriceBreakdown:  datatypes.JSON(breakdownJSON),
+		Status:          models.RentalPending,
 	}
 
-	if err := config.DB.Create(&rental).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rental).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RentalStatusEvent{
+			RentalID:  rental.ID,
+			ActorID:   user.ID,
+			ActorRole: user.Role,
+			ToStatus:  models.RentalPending,
+		}).Error
+	})
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create rental request"})
 	}
 
@@ -103,11 +216,18 @@ func CreateRentalRequest(c echo.Context) error {
 // GetMyRentals godoc
 //
 //	@Summary		Get my rental history
-//	@Description	Retrieve all rental requests made by the logged-in user.
+//	@Description	Retrieve the rental requests made by the logged-in user (or by the organization named in X-Organization-ID), newest first, a page at a time. Filter by status and by a date range the rental overlaps.
 //	@Tags			Rentals
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{array}	models.Rental
+//	@Param			status			query		string	false	"Only these statuses (comma-separated, e.g. approved,active)"
+//	@Param			from			query		string	false	"Only rentals ending on or after this date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Only rentals starting on or before this date (YYYY-MM-DD)"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching rentals"
+//	@Success		200				{object}	pagination.Page[models.Rental]
+//	@Failure		400				{object}	map[string]string	"Invalid filter or cursor"
 //	@Router			/rentals/my [get]
 func GetMyRentals(c echo.Context) error {
 	// FIX: Use shared helper
@@ -116,22 +236,29 @@ func GetMyRentals(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 	}
 
-	var rentals []models.Rental
-	if err := config.DB.Preload("Machine").Where("renter_id = ?", user.ID).Find(&rentals).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch rentals"})
+	query, herr := filterRentals(c, ownedBy(config.DB.Preload("Machine"), "rentals", "renter_id", user))
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
 	}
 
-	return c.JSON(http.StatusOK, rentals)
+	return respondPage(c, query, rentalOrder, "Failed to fetch rentals")
 }
 
 // GetOwnerRentals godoc
 //
 //	@Summary		Get rental requests for my machines
-//	@Description	Retrieve all incoming rental requests for machines owned by the logged-in user.
+//	@Description	Retrieve the incoming rental requests for machines owned by the logged-in user (or by the organization named in X-Organization-ID), newest first, a page at a time. Filter by status and by a date range the rental overlaps.
 //	@Tags			Rentals
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{array}	models.Rental
+//	@Param			status			query		string	false	"Only these statuses (comma-separated, e.g. approved,active)"
+//	@Param			from			query		string	false	"Only rentals ending on or after this date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Only rentals starting on or before this date (YYYY-MM-DD)"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching rentals"
+//	@Success		200				{object}	pagination.Page[models.Rental]
+//	@Failure		400				{object}	map[string]string	"Invalid filter or cursor"
 //	@Router			/rentals/manage [get]
 func GetOwnerRentals(c echo.Context) error {
 	// FIX: Use shared helper
@@ -140,23 +267,63 @@ func GetOwnerRentals(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 	}
 
-	var rentals []models.Rental
-	err = config.DB.Preload("Machine").
-		Joins("JOIN machines ON machines.id = rentals.machine_id").
-		Where("machines.seller_id = ?", user.ID).
-		Find(&rentals).Error
+	query, herr := filterRentals(c, ownedBy(config.DB.Preload("Machine").
+		Joins("JOIN machines ON machines.id = rentals.machine_id"), "machines", "seller_id", user))
+	if herr != nil {
+		return c.JSON(herr.Code, map[string]interface{}{"error": herr.Message})
+	}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch requests"})
+	return respondPage(c, query, rentalOrder, "Failed to fetch requests")
+}
+
+// rentalOrder lists rentals newest first. Columns are qualified because
+// owners' rentals are joined with machines.
+var rentalOrder = pagination.Order[models.Rental]{
+	{Expr: "rentals.created_at", Type: "timestamptz", Desc: true, Value: func(r *models.Rental) interface{} { return r.CreatedAt }},
+	{Expr: "rentals.id", Type: "uuid", Desc: true, Value: func(r *models.Rental) interface{} { return r.ID }},
+}
+
+// filterRentals applies the status and from/to query filters. A rental
+// matches a date range when its dates overlap it.
+func filterRentals(c echo.Context, query *gorm.DB) (*gorm.DB, *echo.HTTPError) {
+	if v := c.QueryParam("status"); v != "" {
+		statuses := strings.Split(v, ",")
+		for _, status := range statuses {
+			if !models.IsRentalStatus(status) {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "Unknown rental status: "+status)
+			}
+		}
+		query = query.Where("rentals.status IN ?", statuses)
+	}
+
+	var from, to time.Time
+	if v := c.QueryParam("from"); v != "" {
+		parsed, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid from date format")
+		}
+		from = parsed
+		query = query.Where("rentals.end_date >= ?", from)
+	}
+	if v := c.QueryParam("to"); v != "" {
+		parsed, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid to date format")
+		}
+		to = parsed
+		query = query.Where("rentals.start_date <= ?", to)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "to cannot be before from")
 	}
 
-	return c.JSON(http.StatusOK, rentals)
+	return query, nil
 }
 
 // UpdateRentalStatus godoc
 //
 //	@Summary		Update rental status
-//	@Description	Approve, reject, or complete a rental. Only the machine owner can do this.
+//	@Description	Move a rental through pending → approved → active → completed (or rejected/cancelled). The owner (any member of the owning organization, for company machines) may approve, reject, activate and complete; the renter may cancel. Approval requires the rental charge to be captured and the deposit held, and fails if the dates overlap another approved or active rental. Rejection and cancellation refund the renter.
 //	@Tags			Rentals
 //	@Accept			json
 //	@Produce		json
@@ -164,7 +331,10 @@ func GetOwnerRentals(c echo.Context) error {
 //	@Param			id		path		string				true	"Rental ID"
 //	@Param			status	body		RentalStatusUpdate	true	"New Status"
 //	@Success		200		{object}	models.Rental
+//	@Failure		400		{object}	map[string]string	"Unknown status"
+//	@Failure		402		{object}	map[string]string	"Payment not yet captured"
 //	@Failure		403		{object}	map[string]string	"Not authorized"
+//	@Failure		409		{object}	map[string]string	"Invalid transition or dates already booked"
 //	@Router			/rentals/{id}/status [put]
 func UpdateRentalStatus(c echo.Context) error {
 	rentalID := c.Param("id")
@@ -174,7 +344,6 @@ func UpdateRentalStatus(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
 	}
 
-	// FIX: Use shared helper
 	user, err := getUserClaims(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
@@ -185,12 +354,142 @@ func UpdateRentalStatus(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
 	}
 
-	if rental.Machine.SellerID != user.ID {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "You are not the owner of this machine"})
+	parties := rentalParties(&rental, user)
+	if len(parties) == 0 {
+		return policy.Deny(c, "You are not a party to this rental")
 	}
 
-	rental.Status = req.Status
-	config.DB.Save(&rental)
+	// fromStatus is the status read under the lock, which may differ from
+	// the one loaded above
+	var fromStatus string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Re-read the status under lock so concurrent updates cannot both pass the check
+		var current models.Rental
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", rental.ID).Error; err != nil {
+			return err
+		}
+		fromStatus = current.Status
+
+		if err := models.CheckRentalTransition(current.Status, req.Status, parties); err != nil {
+			return err
+		}
+
+		if req.Status == models.RentalApproved {
+			// Lock the machine row so concurrent approvals for it are serialised
+			var locked models.Machine
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", rental.MachineID).Error; err != nil {
+				return err
+			}
+
+			conflict, err := findOverlappingRental(tx, rental.MachineID, rental.StartDate, rental.EndDate, rental.ID)
+			if err != nil {
+				return err
+			}
+			if conflict != nil {
+				return errRentalOverlap
+			}
+
+			if err := requirePaymentSecured(tx, &current); err != nil {
+				return err
+			}
+		}
 
+		if err := tx.Model(&current).Update("status", req.Status).Error; err != nil {
+			return err
+		}
+
+		event := models.RentalStatusEvent{
+			RentalID:   rental.ID,
+			ActorID:    user.ID,
+			ActorRole:  user.Role,
+			FromStatus: current.Status,
+			ToStatus:   req.Status,
+			Reason:     req.Reason,
+		}
+		return tx.Create(&event).Error
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, models.ErrUnknownRentalStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrRentalTransitionDenied):
+		return policy.Deny(c, err.Error())
+	case errors.Is(err, models.ErrInvalidRentalTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Cannot change status from " + fromStatus + " to " + req.Status})
+	case errors.Is(err, errRentalOverlap) || isOverlapViolation(err):
+		return c.JSON(http.StatusConflict, map[string]string{"error": errRentalOverlap.Error()})
+	case errors.Is(err, errPaymentRequired):
+		return c.JSON(http.StatusPaymentRequired, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update rental status"})
+	}
+
+	// Money goes back to the renter when a rental does not go ahead
+	if req.Status == models.RentalRejected || req.Status == models.RentalCancelled {
+		unwindRentalPayments(c.Request().Context(), &rental)
+	}
+
+	rental.Status = req.Status
 	return c.JSON(http.StatusOK, rental)
 }
+
+// GetRentalHistory godoc
+//
+//	@Summary		Get rental status history
+//	@Description	Retrieve the status changes of a rental, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them.
+//	@Tags			Rentals
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		string	true	"Rental ID"
+//	@Param			limit			query		int		false	"Items per page (default 20, max 100)"
+//	@Param			cursor			query		string	false	"next_cursor from the previous page"
+//	@Param			include_total	query		bool	false	"Also count all matching records"
+//	@Success		200				{object}	pagination.Page[models.RentalStatusEvent]
+//	@Failure		400				{object}	map[string]string	"Invalid cursor"
+//	@Failure		403				{object}	map[string]string	"Not authorized"
+//	@Failure		404				{object}	map[string]string	"Rental not found"
+//	@Router			/rentals/{id}/history [get]
+func GetRentalHistory(c echo.Context) error {
+	rentalID := c.Param("id")
+
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var rental models.Rental
+	if err := config.DB.Preload("Machine").First(&rental, "id = ?", rentalID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Rental not found"})
+	}
+
+	if len(rentalParties(&rental, user)) == 0 {
+		return policy.Deny(c, "You are not a party to this rental")
+	}
+
+	query := config.DB.Where("rental_id = ?", rental.ID)
+	return respondPage(c, query, rentalEventOrder, "Failed to fetch history")
+}
+
+// rentalEventOrder lists status changes oldest first
+var rentalEventOrder = pagination.Order[models.RentalStatusEvent]{
+	{Expr: "created_at", Type: "timestamptz", Value: func(e *models.RentalStatusEvent) interface{} { return e.CreatedAt }},
+	{Expr: "id", Type: "uuid", Value: func(e *models.RentalStatusEvent) interface{} { return e.ID }},
+}
+
+// rentalParties lists the capacities in which the user is involved in a rental.
+// The rental must have its Machine preloaded. Anyone allowed to approve
+// others' rentals joins as admin.
+func rentalParties(rental *models.Rental, user *UserClaims) []string {
+	var parties []string
+	if user.Owns(machineOwner(&rental.Machine)) {
+		parties = append(parties, models.PartyOwner)
+	}
+	if user.Owns(rentalRenter(rental)) {
+		parties = append(parties, models.PartyRenter)
+	}
+	if user.Can(policy.Any(policy.RentalApprove)) {
+		parties = append(parties, models.PartyAdmin)
+	}
+	return parties
+}
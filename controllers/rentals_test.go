@@ -14,6 +14,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/vishwakarma-setu-backend/config"
 	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/pagination"
 	"gorm.io/gorm"
 )
 
@@ -179,10 +180,10 @@ func TestGetMyRentals(t *testing.T) {
 		t.Errorf("expected 200, got %d", rec.Code)
 	}
 
-	var rentals []models.Rental
-	json.Unmarshal(rec.Body.Bytes(), &rentals)
-	if len(rentals) != 1 {
-		t.Errorf("expected 1 rental, got %d", len(rentals))
+	var page pagination.Page[models.Rental]
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Data) != 1 || page.HasMore {
+		t.Errorf("expected 1 rental on a single page, got %d (more: %v)", len(page.Data), page.HasMore)
 	}
 }
 
@@ -210,10 +211,10 @@ func TestGetOwnerRentals(t *testing.T) {
 		t.Errorf("expected 200, got %d", rec.Code)
 	}
 
-	var rentals []models.Rental
-	json.Unmarshal(rec.Body.Bytes(), &rentals)
-	if len(rentals) != 1 {
-		t.Errorf("expected 1 rental request, got %d", len(rentals))
+	var page pagination.Page[models.Rental]
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Data) != 1 {
+		t.Errorf("expected 1 rental request, got %d", len(page.Data))
 	}
 }
 
@@ -221,6 +222,7 @@ func TestUpdateRentalStatus(t *testing.T) {
 	e := echo.New()
 	machine, db := seedRentableMachine(t)
 	rental := seedRentalRequest(t, db, machine.ID, 2)
+	seedSecuredPayments(t, db, rental)
 
 	payload := `{"status":"approved"}`
 	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(payload))
@@ -280,4 +282,236 @@ func TestUpdateRentalStatus_Unauthorized(t *testing.T) {
 	if rec.Code != http.StatusForbidden {
 		t.Errorf("expected 403 Forbidden, got %d", rec.Code)
 	}
-}
\ No newline at end of file
+}
+func TestUpdateRentalStatus_Overlap(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+
+	// An approved booking already covers the same dates
+	existing := seedRentalRequest(t, db, machine.ID, 3)
+	db.Model(&existing).Update("status", "approved")
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"status":"approved"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/rentals/:id/status")
+	c.SetParamNames("id")
+	c.SetParamValues(rental.ID.String())
+
+	testToken := createTestToken(1, "seller")
+	token, _ := jwt.ParseWithClaims(testToken, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	c.Set("user", token)
+	config.DB = db
+
+	UpdateRentalStatus(c)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 Conflict, got %d", rec.Code)
+	}
+
+	var unchanged models.Rental
+	db.First(&unchanged, "id = ?", rental.ID)
+	if unchanged.Status != "pending" {
+		t.Errorf("expected status pending, got %s", unchanged.Status)
+	}
+}
+
+func TestUpdateRentalStatus_InvalidTransition(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+
+	// pending -> completed skips approval and activation
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"status":"completed"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/rentals/:id/status")
+	c.SetParamNames("id")
+	c.SetParamValues(rental.ID.String())
+
+	testToken := createTestToken(1, "seller")
+	token, _ := jwt.ParseWithClaims(testToken, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	c.Set("user", token)
+	config.DB = db
+
+	UpdateRentalStatus(c)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 Conflict, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "from pending to completed") {
+		t.Errorf("expected the locked status in the message, got %s", rec.Body.String())
+	}
+}
+
+func TestUpdateRentalStatus_RenterCancels(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"status":"cancelled","reason":"Plans changed"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/rentals/:id/status")
+	c.SetParamNames("id")
+	c.SetParamValues(rental.ID.String())
+
+	testToken := createTestToken(2, "buyer")
+	token, _ := jwt.ParseWithClaims(testToken, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	c.Set("user", token)
+	config.DB = db
+
+	if err := UpdateRentalStatus(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var events []models.RentalStatusEvent
+	db.Where("rental_id = ?", rental.ID).Find(&events)
+	if len(events) != 1 {
+		t.Fatalf("expected 1 status event, got %d", len(events))
+	}
+	if events[0].FromStatus != "pending" || events[0].ToStatus != "cancelled" || events[0].Reason != "Plans changed" {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+}
+
+func TestGetRentalHistory(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	rental := seedRentalRequest(t, db, machine.ID, 2)
+	db.Create(&models.RentalStatusEvent{RentalID: rental.ID, ActorID: 2, ToStatus: "pending"})
+	db.Create(&models.RentalStatusEvent{RentalID: rental.ID, ActorID: 1, FromStatus: "pending", ToStatus: "approved"})
+
+	setupCtx := func(userID uint, role string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/rentals/:id/history")
+		c.SetParamNames("id")
+		c.SetParamValues(rental.ID.String())
+		tokenStr := createTestToken(userID, role)
+		token, _ := jwt.ParseWithClaims(tokenStr, new(jwt.MapClaims), func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		})
+		c.Set("user", token)
+		return c, rec
+	}
+
+	// Case 1: Owner can view
+	c1, rec1 := setupCtx(1, "seller")
+	GetRentalHistory(c1)
+	if rec1.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec1.Code)
+	}
+	var events pagination.Page[models.RentalStatusEvent]
+	json.Unmarshal(rec1.Body.Bytes(), &events)
+	if len(events.Data) != 2 || events.Data[0].ToStatus != "pending" {
+		t.Errorf("expected 2 events oldest first, got %+v", events.Data)
+	}
+
+	// Case 2: Stranger cannot
+	c2, rec2 := setupCtx(999, "buyer")
+	GetRentalHistory(c2)
+	if rec2.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec2.Code)
+	}
+}
+
+func TestQuoteRental(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+
+	payload := `{"machine_id":"` + machine.ID.String() + `","start_date":"2025-01-01","end_date":"2025-01-05"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/rentals/quote", strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := QuoteRental(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var quote map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &quote)
+	if quote["subtotal"] != float64(4000) {
+		t.Errorf("expected subtotal 4000, got %v", quote["subtotal"])
+	}
+
+	// Quoting must not create a rental
+	var count int64
+	db.Model(&models.Rental{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no rentals, got %d", count)
+	}
+}
+
+func TestGetMyRentals_Filters(t *testing.T) {
+	e := echo.New()
+	machine, db := seedRentableMachine(t)
+	config.DB = db
+
+	day := func(s string) time.Time { d, _ := time.Parse(dateLayout, s); return d }
+	seed := []models.Rental{
+		{MachineID: machine.ID, RenterID: 2, StartDate: day("2025-03-01"), EndDate: day("2025-03-05"), Status: models.RentalCompleted},
+		{MachineID: machine.ID, RenterID: 2, StartDate: day("2025-04-10"), EndDate: day("2025-04-12"), Status: models.RentalApproved},
+		{MachineID: machine.ID, RenterID: 2, StartDate: day("2025-05-01"), EndDate: day("2025-05-03"), Status: models.RentalPending},
+		{MachineID: machine.ID, RenterID: 3, StartDate: day("2025-04-01"), EndDate: day("2025-04-02"), Status: models.RentalPending},
+	}
+	for i := range seed {
+		if err := db.Create(&seed[i]).Error; err != nil {
+			t.Fatalf("failed to seed rental: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedLen  int
+	}{
+		{"All Mine", "", http.StatusOK, 3},
+		{"By Status", "status=approved,pending", http.StatusOK, 2},
+		{"Overlapping Range", "from=2025-03-04&to=2025-04-10", http.StatusOK, 2},
+		{"Status And Range", "status=pending&from=2025-04-01", http.StatusOK, 1},
+		{"Unknown Status", "status=paid", http.StatusBadRequest, 0},
+		{"Bad Date", "from=04/01/2025", http.StatusBadRequest, 0},
+		{"Inverted Range", "from=2025-05-01&to=2025-04-01", http.StatusBadRequest, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, rec := paymentCtx(e, http.MethodGet, "", 2, "buyer", "", "")
+			c.Request().URL.RawQuery = tc.query
+			if err := GetMyRentals(c); err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected %d, got %d: %s", tc.expectedCode, rec.Code, rec.Body.String())
+			}
+			var page pagination.Page[models.Rental]
+			json.Unmarshal(rec.Body.Bytes(), &page)
+			if len(page.Data) != tc.expectedLen {
+				t.Errorf("expected %d rentals, got %d", tc.expectedLen, len(page.Data))
+			}
+		})
+	}
+}
@@ -1,35 +1,217 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
-	"os"
 	"path/filepath"
-
-	// "time"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/imaging"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
-// UploadResponse
+// maxImageSize caps uploaded images at 5MB
+const maxImageSize = 5 * 1024 * 1024
+
+// presignExpiry is how long a presigned upload or download URL stays valid
+const presignExpiry = 15 * time.Minute
+
+// UploadResponse describes a stored image. URL, Width and Height are those
+// of the largest variant. Duplicate is set when the caller had already
+// uploaded the same bytes and the earlier upload is returned instead.
 type UploadResponse struct {
-	URL string `json:"url"`
+	ID          uuid.UUID       `json:"id"`
+	Duplicate   bool            `json:"duplicate"`
+	URL         string          `json:"url" example:"/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"`
+	ContentType string          `json:"content_type" example:"image/jpeg"`
+	Width       int             `json:"width" example:"2048"`
+	Height      int             `json:"height" example:"1536"`
+	Hash        string          `json:"hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Variants    []UploadVariant `json:"variants"`
+}
+
+// UploadVariant is one stored rendition of an uploaded image
+type UploadVariant struct {
+	Name        string `json:"name" example:"thumbnail"`
+	Format      string `json:"format" example:"jpeg"`
+	ContentType string `json:"content_type" example:"image/jpeg"`
+	URL         string `json:"url" example:"/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-thumbnail.jpg"`
+	Width       int    `json:"width" example:"320"`
+	Height      int    `json:"height" example:"240"`
+	Size        int    `json:"size" example:"18342"`
+}
+
+// PresignUploadInput names the file the client is about to upload
+type PresignUploadInput struct {
+	Filename string `json:"filename" example:"excavator.jpg"`
+}
+
+// PresignUploadResponse tells the client where to PUT the file. Once it
+// is sent, POST /upload/{id}/complete turns it into a stored image.
+type PresignUploadResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Key       string    `json:"key" example:"private/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-incoming.jpg"`
+	Method    string    `json:"method" example:"PUT"`
+	UploadURL string    `json:"upload_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// maxPresignedImageSize caps what a presigned upload may send, matching
+// the local backend's limit; the image is re-encoded anyway
+const maxPresignedImageSize = 50 << 20
+
+// incomingFile names the object a presigned upload PUTs its bytes to. It
+// lives under storage.PrivatePrefix, so it is never served and cannot be
+// submitted as an upload URL before it has been processed.
+const incomingFile = "incoming"
+
+// isImageName reports whether filename has an image extension
+func isImageName(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".jpg" || ext == ".jpeg" || ext == ".png"
+}
+
+// storeImage renders img's variants, which drops the original's metadata,
+// and stores them under base on the active backend. On error nothing it
+// stored is left behind.
+func storeImage(ctx context.Context, img *imaging.Source, base string) ([]models.UploadFile, error) {
+	outputs, err := imaging.Active.Render(img)
+	if err != nil {
+		return nil, fmt.Errorf("rendering: %w", err)
+	}
+
+	var stored []models.UploadFile
+	for _, out := range outputs {
+		key := base + "-" + out.Name + out.Ext()
+		if err := storage.Active.Put(ctx, key, bytes.NewReader(out.Data), int64(len(out.Data)), out.ContentType); err != nil {
+			deleteFiles(ctx, stored)
+			return nil, fmt.Errorf("storing %s: %w", key, err)
+		}
+		stored = append(stored, models.UploadFile{
+			Name:        out.Name,
+			Format:      out.Format,
+			ContentType: out.ContentType,
+			Key:         key,
+			Width:       out.Width,
+			Height:      out.Height,
+			Size:        len(out.Data),
+		})
+	}
+	return stored, nil
+}
+
+// deleteFiles removes stored objects, best effort
+func deleteFiles(ctx context.Context, stored []models.UploadFile) {
+	for _, f := range stored {
+		storage.Active.Delete(ctx, f.Key)
+	}
+}
+
+// findDuplicate returns the caller's earlier upload of the same bytes, if
+// any. Returning it restarts its grace period, so it is not swept from
+// under the caller.
+func findDuplicate(userID uint, hash string) (*models.Upload, error) {
+	var existing models.Upload
+	err := config.DB.Where("uploader_id = ? AND hash = ?", userID, hash).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config.DB.Model(&existing).Update("updated_at", time.Now())
+	return &existing, nil
+}
+
+// imageError describes why bytes were rejected by imaging.Decode
+func imageError(err error) string {
+	if errors.Is(err, imaging.ErrUnsupportedType) {
+		return "Only JPEG, PNG and WebP images allowed"
+	}
+	return err.Error()
+}
+
+// checkUploads verifies that the URLs a user submits are their own
+// uploads. Those allowed to upload for others may use anyone's. Run it in
+// the transaction that references the uploads, which it locks.
+func checkUploads(tx *gorm.DB, user *UserClaims, urls []string) error {
+	if user.Can(policy.Any(policy.FileUpload)) {
+		return files.Known(tx, urls)
+	}
+	return files.Owned(tx, user.ID, urls)
+}
+
+// uploadError is the message for a submitted URL that is not a usable
+// upload, if err is about one
+func uploadError(err error) (string, bool) {
+	var foreign *files.ForeignError
+	if errors.As(err, &foreign) {
+		return foreign.Error(), true
+	}
+	var missing *files.MissingError
+	if errors.As(err, &missing) {
+		return missing.Error(), true
+	}
+	return "", false
+}
+
+// uploadResponse describes a stored upload
+func uploadResponse(u *models.Upload, duplicate bool) UploadResponse {
+	resp := UploadResponse{ID: u.ID, Duplicate: duplicate, ContentType: u.ContentType, Hash: u.Hash}
+	for _, f := range files.Files(u) {
+		variant := UploadVariant{
+			Name:        f.Name,
+			Format:      f.Format,
+			ContentType: f.ContentType,
+			URL:         storage.Active.URL(f.Key),
+			Width:       f.Width,
+			Height:      f.Height,
+			Size:        f.Size,
+		}
+		resp.Variants = append(resp.Variants, variant)
+		// The top-level URL is the largest JPEG/PNG rendition
+		if f.Format != imaging.FormatWebP && f.Width*f.Height >= resp.Width*resp.Height {
+			resp.URL, resp.Width, resp.Height = variant.URL, variant.Width, variant.Height
+		}
+	}
+	return resp
 }
 
 // UploadImage godoc
 //
 //	@Summary		Upload an image
-//	@Description	Upload an image file (jpg, png, jpeg) and get a local URL. Max size 5MB.
+//	@Description	Upload a JPEG, PNG or WebP image (max 5MB). The type is detected from the file's content, not its name. Metadata such as EXIF GPS coordinates is stripped, and thumbnail, medium and full variants are stored on the configured backend (plus WebP copies when IMAGE_WEBP is set). `hash` is the SHA-256 of the uploaded bytes. Uploading the same bytes again returns the earlier upload with `duplicate` set. Uploads no record refers to are deleted after UPLOAD_ORPHAN_GRACE (24h by default).
 //	@Tags			Utility
 //	@Accept			multipart/form-data
 //	@Produce		json
+//	@Security		BearerAuth
 //	@Param			file	formData	file	true	"Image file"
 //	@Success		201		{object}	UploadResponse
 //	@Failure		400		{object}	map[string]string	"Invalid file"
 //	@Failure		500		{object}	map[string]string	"Server error"
 //	@Router			/upload [post]
 func UploadImage(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
 	// 1. Read form file
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -37,50 +219,259 @@ func UploadImage(c echo.Context) error {
 	}
 
 	// 2. Validate File Size (e.g., Max 5MB)
-	if file.Size > 5*1024*1024 {
+	if file.Size > maxImageSize {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "File too large (Max 5MB)"})
 	}
 
-	// 3. Open the file
+	// 3. Read the file
 	src, err := file.Open()
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not open file"})
 	}
 	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, maxImageSize+1))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not read file"})
+	}
+	if len(data) > maxImageSize {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "File too large (Max 5MB)"})
+	}
 
-	// 4. Create unique filename
-	// Extract extension
-	ext := filepath.Ext(file.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+	// 4. Check what the bytes really are and decode them
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": imageError(err)})
+	}
+
+	// 5. The same bytes from the same user are stored once
+	existing, err := findDuplicate(user.ID, img.Hash)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check uploads"})
+	}
+	if existing != nil {
+		return c.JSON(http.StatusCreated, uploadResponse(existing, true))
+	}
+
+	// 6. Render the variants and store them under one "upload-<uuid>"
+	// prefix on the active backend
+	ctx := c.Request().Context()
+	base := files.NewBaseKey()
+	stored, err := storeImage(ctx, img, base)
+	if err != nil {
+		c.Logger().Errorf("storing upload: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
+	}
+
+	// 7. Record who uploaded it
+	filesJSON, _ := json.Marshal(stored)
+	upload := models.Upload{
+		UploaderID:  user.ID,
+		Hash:        img.Hash,
+		Size:        int64(len(data)),
+		ContentType: img.ContentType,
+		BaseKey:     base,
+		Files:       datatypes.JSON(filesJSON),
+	}
+	if err := config.DB.Create(&upload).Error; err != nil {
+		deleteFiles(ctx, stored)
+		if isUniqueViolation(err, uploaderHashIndex) {
+			// A concurrent upload of the same bytes was stored first
+			if existing, err := findDuplicate(user.ID, img.Hash); err == nil && existing != nil {
+				return c.JSON(http.StatusCreated, uploadResponse(existing, true))
+			}
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
+	}
+
+	return c.JSON(http.StatusCreated, uploadResponse(&upload, false))
+}
+
+// PresignUpload godoc
+//
+//	@Summary		Get a presigned image upload URL
+//	@Description	Returns a short-lived URL the client PUTs the image bytes to directly, bypassing the API. The bytes are not usable until POST /upload/{id}/complete has checked and processed them like any other upload. Presigned uploads that are never completed, or never referred to afterwards, are deleted after UPLOAD_ORPHAN_GRACE.
+//	@Tags			Utility
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			input	body		PresignUploadInput	true	"File to upload"
+//	@Success		201		{object}	PresignUploadResponse
+//	@Failure		400		{object}	map[string]string	"Invalid filename"
+//	@Failure		500		{object}	map[string]string	"Server error"
+//	@Router			/upload/presign [post]
+func PresignUpload(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var input PresignUploadInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid input"})
+	}
+	if !isImageName(input.Filename) {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only JPG, JPEG, and PNG allowed"})
 	}
 
-	// Generate random name: "upload-<uuid><ext>"
-	newFileName := "upload-" + uuid.New().String() + ext
+	// The client writes to a private staging key; the variants are only
+	// stored under base once the upload is completed
+	base := files.NewBaseKey()
+	key := storage.PrivatePrefix + base + "-" + incomingFile + strings.ToLower(filepath.Ext(input.Filename))
+	uploadURL, err := storage.Active.PresignPut(c.Request().Context(), key, presignExpiry)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not presign upload"})
+	}
+
+	// Record the upload up front, so it is swept if it is never completed.
+	// The content is not known yet, so it has no hash.
+	filesJSON, _ := json.Marshal([]models.UploadFile{{Name: incomingFile, Key: key}})
+	upload := models.Upload{
+		UploaderID:  user.ID,
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		BaseKey:     base,
+		Files:       datatypes.JSON(filesJSON),
+	}
+	if err := config.DB.Create(&upload).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not record upload"})
+	}
+
+	return c.JSON(http.StatusCreated, PresignUploadResponse{
+		ID:        upload.ID,
+		Key:       key,
+		Method:    http.MethodPut,
+		UploadURL: uploadURL,
+		ExpiresAt: time.Now().Add(presignExpiry),
+	})
+}
+
+// CompleteUpload godoc
+//
+//	@Summary		Complete a presigned image upload
+//	@Description	Processes the bytes PUT to a presigned upload URL exactly like POST /upload: the type is detected from the content, metadata is stripped and the variants are stored. The bytes as sent are then deleted. Only the uploader can complete an upload. If the caller had already uploaded the same bytes, the earlier upload is returned with `duplicate` set.
+//	@Tags			Utility
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Upload ID from POST /upload/presign"
+//	@Success		201	{object}	UploadResponse
+//	@Failure		400	{object}	map[string]string	"Nothing uploaded, or not an image"
+//	@Failure		403	{object}	map[string]string	"Not your upload"
+//	@Failure		404	{object}	map[string]string	"Upload not found"
+//	@Failure		409	{object}	map[string]string	"Upload already completed"
+//	@Failure		500	{object}	map[string]string	"Server error"
+//	@Router			/upload/{id}/complete [post]
+func CompleteUpload(c echo.Context) error {
+	user, err := getUserClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var upload models.Upload
+	if err := config.DB.First(&upload, "id = ?", c.Param("id")).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Upload not found"})
+	}
+	if upload.UploaderID != user.ID {
+		return policy.Deny(c, "You can only complete your own uploads")
+	}
+	incoming, ok := files.Variant(&upload, incomingFile)
+	if !ok {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Upload already completed"})
+	}
 
-	// 5. Ensure upload directory exists
-	uploadPath := "./uploads"
-	if _, err := os.Stat(uploadPath); os.IsNotExist(err) {
-		os.Mkdir(uploadPath, 0755)
+	// 1. Read what the client sent
+	ctx := c.Request().Context()
+	r, err := storage.Active.Get(ctx, incoming.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Nothing has been uploaded yet"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not read upload"})
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxPresignedImageSize+1))
+	r.Close()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not read upload"})
 	}
 
-	// 6. Create destination file
-	dstPath := filepath.Join(uploadPath, newFileName)
-	dst, err := os.Create(dstPath)
+	// 2. Rejected bytes are deleted right away; the record is swept later
+	if len(data) > maxPresignedImageSize {
+		storage.Active.Delete(ctx, incoming.Key)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "File too large (Max 50MB)"})
+	}
+	img, err := imaging.Decode(data)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Could not create destination file"})
+		storage.Active.Delete(ctx, incoming.Key)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": imageError(err)})
 	}
-	defer dst.Close()
 
-	// 7. Copy data
-	if _, err = io.Copy(dst, src); err != nil {
+	// 3. The same bytes from the same user are stored once
+	existing, err := findDuplicate(user.ID, img.Hash)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check uploads"})
+	}
+	if existing != nil {
+		return completeAsDuplicate(c, &upload, incoming, existing)
+	}
+
+	// 4. Store the variants and swap them in for the staged bytes. Each
+	// attempt stores under its own base key and the update only applies
+	// while the upload is still incomplete, so of two concurrent
+	// completions one registers its files and the other deletes only its own.
+	base := files.NewBaseKey()
+	stored, err := storeImage(ctx, img, base)
+	if err != nil {
+		c.Logger().Errorf("storing upload: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
 	}
+	filesJSON, _ := json.Marshal(stored)
+	result := config.DB.Model(&upload).Where("hash = ?", "").Updates(map[string]interface{}{
+		"hash":         img.Hash,
+		"size":         int64(len(data)),
+		"content_type": img.ContentType,
+		"base_key":     base,
+		"files":        datatypes.JSON(filesJSON),
+	})
+	if result.Error != nil {
+		deleteFiles(ctx, stored)
+		if isUniqueViolation(result.Error, uploaderHashIndex) {
+			// The same bytes were stored by another upload meanwhile
+			if existing, err := findDuplicate(user.ID, img.Hash); err == nil && existing != nil {
+				return completeAsDuplicate(c, &upload, incoming, existing)
+			}
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
+	}
+	if result.RowsAffected == 0 {
+		deleteFiles(ctx, stored)
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Upload already completed"})
+	}
+	storage.Active.Delete(ctx, incoming.Key)
+
+	upload.Hash, upload.Size, upload.ContentType, upload.BaseKey = img.Hash, int64(len(data)), img.ContentType, base
+	upload.Files = datatypes.JSON(filesJSON)
+	return c.JSON(http.StatusCreated, uploadResponse(&upload, false))
+}
+
+// completeAsDuplicate finishes a presigned upload whose bytes the caller
+// had already uploaded as existing. The upload is claimed by deleting it
+// while it is still incomplete, so a concurrent completion cannot register
+// it meanwhile.
+func completeAsDuplicate(c echo.Context, upload *models.Upload, incoming models.UploadFile, existing *models.Upload) error {
+	result := config.DB.Where("hash = ?", "").Delete(upload)
+	if result.Error != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save file"})
+	}
+	if result.RowsAffected == 0 {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Upload already completed"})
+	}
+	storage.Active.Delete(c.Request().Context(), incoming.Key)
+	return c.JSON(http.StatusCreated, uploadResponse(existing, true))
+}
 
-	// 8. Return the relative URL
-	// Ideally, construct full URL based on env var, but relative path works for simple setups
-	// The URL will look like: /uploads/upload-xyz-123.jpg
-	fileURL := "/uploads/" + newFileName
+// uploaderHashIndex keeps one upload of the same bytes per uploader
+const uploaderHashIndex = "idx_uploads_uploader_hash"
 
-	return c.JSON(http.StatusCreated, UploadResponse{URL: fileURL})
+// isUniqueViolation reports whether err came from the named unique index
+func isUniqueViolation(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == index
 }
@@ -2,20 +2,35 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 func TestUploadImage_Success(t *testing.T) {
 	e := echo.New()
+	setupTestDB(t, nil)
 
 	// 1. Prepare Multipart Form Data
 	body := new(bytes.Buffer)
@@ -25,8 +40,8 @@ func TestUploadImage_Success(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	// Write dummy image content
-	part.Write([]byte("fake image binary data"))
+	// Write a real (tiny) JPEG; the handler checks the content, not the name
+	part.Write(testJPEG(t, 8, 6))
 	writer.Close()
 
 	// 2. Create Request
@@ -57,13 +72,13 @@ func TestUploadImage_Success(t *testing.T) {
 		t.Errorf("expected status 201 Created, got %d. Body: %s", rec.Code, rec.Body.String())
 	}
 
-	var resp map[string]string
+	var resp map[string]interface{}
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("invalid response json: %v", err)
 	}
 
 	// Verify URL format
-	url, ok := resp["url"]
+	url, ok := resp["url"].(string)
 	if !ok {
 		t.Fatal("response missing 'url' field")
 	}
@@ -71,15 +86,86 @@ func TestUploadImage_Success(t *testing.T) {
 		t.Errorf("unexpected url format: %s", url)
 	}
 
-	// Cleanup: Remove the created file
-	// The controller writes to ./uploads/, so we map the relative URL back to fs path
-	if strings.HasPrefix(url, "/uploads/") {
-		// "./uploads/filename.jpg"
-		filePath := "." + url
-		os.Remove(filePath)
+	// Cleanup: Remove the created files
+	// The controller writes to ./uploads/, so we map the relative URLs back to fs paths
+	var parsed UploadResponse
+	json.Unmarshal(rec.Body.Bytes(), &parsed)
+	for _, v := range parsed.Variants {
+		if strings.HasPrefix(v.URL, "/uploads/") {
+			os.Remove("." + v.URL)
+		}
 	}
 }
 
+func TestUploadImage_RenamedExecutable(t *testing.T) {
+	e := echo.New()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "excavator.jpg")
+	part.Write([]byte("MZ\x90\x00\x03\x00\x00\x00 This program cannot be run in DOS mode."))
+	writer.Close()
+
+	c, rec := paymentCtx(e, http.MethodPost, "", 1, "seller", "", "")
+	c.Request().Body = io.NopCloser(body)
+	c.Request().Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+	if err := UploadImage(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-image named .jpg, got %d", rec.Code)
+	}
+}
+
+// uploadImage posts content to UploadImage as userID
+func uploadImage(t *testing.T, userID uint, filename string, content []byte) (*httptest.ResponseRecorder, UploadResponse) {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write(content)
+	writer.Close()
+
+	c, rec := paymentCtx(echo.New(), http.MethodPost, "", userID, "seller", "", "")
+	c.Request().Body = io.NopCloser(body)
+	c.Request().Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	if err := UploadImage(c); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	var resp UploadResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec, resp
+}
+
+// seedUpload records an upload by uploaderID and returns the URL of its
+// only file
+func seedUpload(t *testing.T, db *gorm.DB, uploaderID uint) (models.Upload, string) {
+	t.Helper()
+	base := files.NewBaseKey()
+	key := base + "-full.jpg"
+	filesJSON, _ := json.Marshal([]models.UploadFile{{Name: "full", Format: "jpeg", ContentType: "image/jpeg", Key: key}})
+	upload := models.Upload{
+		UploaderID:  uploaderID,
+		Hash:        strings.Repeat("0", 64),
+		ContentType: "image/jpeg",
+		BaseKey:     base,
+		Files:       datatypes.JSON(filesJSON),
+	}
+	if err := db.Create(&upload).Error; err != nil {
+		t.Fatalf("failed to seed upload: %v", err)
+	}
+	return upload, storage.Active.URL(key)
+}
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
 func TestUploadImage_InvalidExtension(t *testing.T) {
 	e := echo.New()
 	body := new(bytes.Buffer)
@@ -135,4 +221,216 @@ func TestUploadImage_NoFile(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Errorf("expected status 400 Bad Request for missing file, got %d", rec.Code)
 	}
-}
\ No newline at end of file
+}
+func TestUploadImage_UsesActiveBackend(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	dir := t.TempDir()
+	storage.Active = storage.NewLocal(dir, "https://cdn.example.com/files", "secret")
+
+	setupTestDB(t, nil)
+
+	rec, resp := uploadImage(t, 1, "photo.PNG", testJPEG(t, 400, 300))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// The name said PNG but the bytes are a JPEG
+	if resp.ContentType != "image/jpeg" || resp.Width != 400 || resp.Height != 300 || len(resp.Hash) != 64 {
+		t.Errorf("unexpected metadata %+v", resp)
+	}
+	if !strings.HasPrefix(resp.URL, "https://cdn.example.com/files/upload-") || !strings.HasSuffix(resp.URL, "-full.jpg") {
+		t.Fatalf("expected the backend's url, got %s", resp.URL)
+	}
+	if len(resp.Variants) != 3 {
+		t.Fatalf("expected thumbnail, medium and full variants, got %+v", resp.Variants)
+	}
+	for _, v := range resp.Variants {
+		key := strings.TrimPrefix(v.URL, "https://cdn.example.com/files/")
+		if _, err := os.Stat(filepath.Join(dir, key)); err != nil {
+			t.Errorf("expected the %s variant in the backend's directory: %v", v.Name, err)
+		}
+	}
+	if thumb := resp.Variants[0]; thumb.Name != "thumbnail" || thumb.Width != 320 || thumb.Height != 240 {
+		t.Errorf("unexpected thumbnail %+v", thumb)
+	}
+}
+
+func TestPresignUpload(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	local := storage.NewLocal(t.TempDir(), "/uploads", "secret")
+	storage.Active = local
+
+	setupTestDB(t, nil)
+
+	presign := func(filename string) *httptest.ResponseRecorder {
+		c, rec := paymentCtx(echo.New(), http.MethodPost, `{"filename":"`+filename+`"}`, 1, "seller", "", "")
+		if err := PresignUpload(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		return rec
+	}
+
+	if rec := presign("notes.txt"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-image, got %d", rec.Code)
+	}
+
+	rec := presign("excavator.jpg")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp PresignUploadResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Method != http.MethodPut || !strings.HasPrefix(resp.Key, storage.PrivatePrefix) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	// Until completed, the bytes sent cannot be submitted as an upload
+	if err := files.Owned(config.DB, 1, []string{storage.Active.URL(resp.Key)}); err == nil {
+		t.Error("expected an incomplete upload to be refused")
+	}
+
+	put := func(url string, body []byte) {
+		putRec := httptest.NewRecorder()
+		local.Handler().ServeHTTP(putRec, httptest.NewRequest(http.MethodPut, url, bytes.NewReader(body)))
+		if putRec.Code != http.StatusOK {
+			t.Fatalf("expected the presigned put to succeed, got %d", putRec.Code)
+		}
+	}
+	complete := func(id uuid.UUID, userID uint) (*httptest.ResponseRecorder, UploadResponse) {
+		c, rec := paymentCtx(echo.New(), http.MethodPost, "", userID, "seller", "id", id.String())
+		if err := CompleteUpload(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		var upload UploadResponse
+		json.Unmarshal(rec.Body.Bytes(), &upload)
+		return rec, upload
+	}
+
+	if rec, _ := complete(resp.ID, 1); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 before anything was sent, got %d", rec.Code)
+	}
+
+	// Completing processes the bytes like a direct upload
+	put(resp.UploadURL, testJPEG(t, 640, 480))
+	if rec, _ := complete(resp.ID, 2); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for another user, got %d", rec.Code)
+	}
+	rec, upload := complete(resp.ID, 1)
+	if rec.Code != http.StatusCreated || upload.ID != resp.ID || upload.Hash == "" || len(upload.Variants) != 3 {
+		t.Fatalf("expected a processed upload, got %d %s", rec.Code, rec.Body.String())
+	}
+	if err := files.Owned(config.DB, 1, []string{upload.URL}); err != nil {
+		t.Errorf("expected the completed upload to be usable: %v", err)
+	}
+	if _, err := storage.Active.Get(context.Background(), resp.Key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected the bytes as sent to be deleted, got %v", err)
+	}
+	if rec, _ := complete(resp.ID, 1); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 completing twice, got %d", rec.Code)
+	}
+	// A losing completion leaves the winner's variants in place
+	for _, v := range upload.Variants {
+		key, _ := storage.Active.KeyFor(v.URL)
+		r, err := storage.Active.Get(context.Background(), key)
+		if err != nil {
+			t.Errorf("expected variant %s to be stored: %v", v.Name, err)
+			continue
+		}
+		r.Close()
+	}
+
+	// Anything that is not an image is refused
+	json.Unmarshal(presign("excavator.png").Body.Bytes(), &resp)
+	put(resp.UploadURL, []byte("not an image"))
+	if rec, _ := complete(resp.ID, 1); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-image, got %d", rec.Code)
+	}
+	if _, err := storage.Active.Get(context.Background(), resp.Key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected the rejected bytes to be deleted, got %v", err)
+	}
+}
+
+func TestUploadImage_Deduplicates(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	storage.Active = storage.NewLocal(t.TempDir(), "/uploads", "secret")
+	db := setupTestDB(t, nil)
+
+	image := testJPEG(t, 40, 30)
+	_, first := uploadImage(t, 1, "a.jpg", image)
+	rec, again := uploadImage(t, 1, "b.jpg", image)
+	if rec.Code != http.StatusCreated || !again.Duplicate || again.ID != first.ID || again.URL != first.URL {
+		t.Fatalf("expected the first upload back, got %d %+v", rec.Code, again)
+	}
+
+	// Another user uploading the same bytes gets their own copy
+	_, other := uploadImage(t, 2, "c.jpg", image)
+	if other.Duplicate || other.ID == first.ID {
+		t.Errorf("expected a separate upload for another user, got %+v", other)
+	}
+
+	var count int64
+	db.Model(&models.Upload{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 uploads, got %d", count)
+	}
+}
+
+func TestUploads_ReferencesAndSweep(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	dir := t.TempDir()
+	storage.Active = storage.NewLocal(dir, "/uploads", "secret")
+	machine, db := seedRentableMachine(t)
+
+	_, mine := uploadImage(t, 1, "invoice.jpg", testJPEG(t, 40, 30))
+	_, theirs := uploadImage(t, 2, "other.jpg", testJPEG(t, 30, 40))
+
+	addRecord := func(documentURL string) *httptest.ResponseRecorder {
+		body := `{"machine_id":"` + machine.ID.String() + `","service_date":"2025-01-15","type":"Repair","document_url":"` + documentURL + `"}`
+		c, rec := paymentCtx(echo.New(), http.MethodPost, body, 1, "seller", "", "")
+		if err := AddMaintenanceRecord(c); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+		return rec
+	}
+
+	// Someone else's upload, or a URL that is not an upload, is refused
+	for _, url := range []string{theirs.URL, "https://example.com/invoice.jpg"} {
+		if rec := addRecord(url); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", url, rec.Code)
+		}
+	}
+
+	rec := addRecord(mine.URL)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var record models.MaintenanceRecord
+	json.Unmarshal(rec.Body.Bytes(), &record)
+
+	// Backdate both uploads past the grace period: only the unreferenced
+	// one is swept
+	db.Model(&models.Upload{}).Where("1 = 1").Update("updated_at", time.Now().Add(-48*time.Hour))
+	swept, err := files.Sweep(context.Background(), db, storage.Active, 24*time.Hour)
+	if err != nil || swept != 1 {
+		t.Fatalf("expected to sweep 1 upload, swept %d: %v", swept, err)
+	}
+	if err := db.First(&models.Upload{}, "id = ?", theirs.ID).Error; err == nil {
+		t.Error("expected the orphaned upload to be deleted")
+	}
+	for _, v := range theirs.Variants {
+		if _, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(v.URL, "/uploads/"))); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be deleted from storage", v.URL)
+		}
+	}
+	var kept models.Upload
+	if err := db.First(&kept, "id = ?", mine.ID).Error; err != nil {
+		t.Fatalf("expected the referenced upload to be kept: %v", err)
+	}
+	if want := `["` + files.Ref(models.AttachToMaintenance, record.ID) + `"]`; string(kept.ReferencedBy) != want {
+		t.Errorf("expected references %s, got %s", want, kept.ReferencedBy)
+	}
+
+	// Once released it becomes an orphan too
+	files.Release(db, files.Ref(models.AttachToMaintenance, record.ID))
+	if swept, _ := files.Sweep(context.Background(), db, storage.Active, 0); swept != 1 {
+		t.Errorf("expected the released upload to be swept, swept %d", swept)
+	}
+}
@@ -38,18 +38,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/bad-request": {
-            "get": {
+        "/admin/categories": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a category and its spec fields. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Errors"
+                    "Admin"
+                ],
+                "summary": "Create a machine category",
+                "parameters": [
+                    {
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    }
                 ],
-                "summary": "Bad Request Handler",
                 "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid schema",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Slug or name taken",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -60,18 +101,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/forbidden": {
-            "get": {
+        "/admin/categories/{slug}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace a category's name, description, spec fields and active flag. The slug cannot change; renaming also renames the category on existing listings and fee rules. Existing listings are validated against the new fields the next time they are edited. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Errors"
+                    "Admin"
+                ],
+                "summary": "Update a machine category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    }
                 ],
-                "summary": "Forbidden Handler",
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid schema",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -80,21 +169,51 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "Checks if the backend server is running.",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a category from the registry. Existing listings keep their category name but their specs are no longer validated or filterable. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Admin"
+                ],
+                "summary": "Delete a machine category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health Check",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Success",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -105,14 +224,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/inspections": {
-            "post": {
+        "/admin/categories/{slug}/inspection-template": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Submit a verification report with media URLs. Only Inspectors/Admins should ideally do this.",
+                "description": "Save a new version of a category's inspection template. Earlier versions are kept, so existing reports stay tied to the checklist they were scored against. Item keys must be unique across the template. Each section scores the weighted share of its answered items that passed (measurements pass within min/max); the condition score (0-100) weighs the sections, and grades run A (90+), B (75+), C (60+), D (40+) and F. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,17 +239,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Inspection"
+                    "Admin"
                 ],
-                "summary": "Submit an inspection report",
+                "summary": "Set a category's inspection checklist",
                 "parameters": [
                     {
-                        "description": "Inspection Data",
-                        "name": "report",
+                        "type": "string",
+                        "description": "Category slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checklist",
+                        "name": "template",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.InspectionRequest"
+                            "$ref": "#/definitions/controllers.InspectionTemplateInput"
                         }
                     }
                 ],
@@ -138,33 +264,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.InspectionReport"
+                            "$ref": "#/definitions/models.InspectionTemplate"
                         }
                     },
                     "400": {
-                        "description": "Invalid Input",
+                        "description": "Invalid template",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/internal-server-error": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Errors"
-                ],
-                "summary": "Internal Server Error Handler",
-                "responses": {
-                    "500": {
-                        "description": "Internal Server Error",
+                    },
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -175,72 +294,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/machines": {
+        "/admin/fee-rules": {
             "get": {
-                "description": "Retrieve a list of machines with optional filtering, sorting, and pagination.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the platform fee rules, highest priority first, a page at a time. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Machines"
+                    "Admin"
                 ],
-                "summary": "Get all machine listings",
+                "summary": "List platform fee rules",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by Category",
-                        "name": "category",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by Manufacturer",
-                        "name": "manufacturer",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by Location",
-                        "name": "location",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by Listing Type (sale, rent)",
-                        "name": "type",
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order (price_asc, price_desc, oldest)",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Items per page",
-                        "name": "limit",
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_PlatformFeeRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
@@ -251,7 +362,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Register a new machine for sale or rent. Requires Seller or Admin Role.",
+                "description": "Add a percentage or flat commission for a category and/or listing type, with optional min/max caps. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -259,17 +370,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Machines"
+                    "Admin"
                 ],
-                "summary": "Create a new machine listing",
+                "summary": "Create a platform fee rule",
                 "parameters": [
                     {
-                        "description": "Machine Details",
-                        "name": "machine",
+                        "description": "Fee Rule",
+                        "name": "rule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Machine"
+                            "$ref": "#/definitions/models.PlatformFeeRule"
                         }
                     }
                 ],
@@ -277,7 +388,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Machine"
+                            "$ref": "#/definitions/models.PlatformFeeRule"
                         }
                     },
                     "400": {
@@ -289,17 +400,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "403": {
-                        "description": "Forbidden (Buyers cannot list)",
+                        "description": "Admins only",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -310,14 +412,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/machines/{id}": {
+        "/admin/fee-rules/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update details. Only Owner or Admin can perform this.",
+                "description": "Replace a fee rule's settings. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -325,24 +427,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Machines"
+                    "Admin"
                 ],
-                "summary": "Update a listing",
+                "summary": "Update a platform fee rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Machine ID",
+                        "description": "Fee Rule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Data",
-                        "name": "machine",
+                        "description": "Fee Rule",
+                        "name": "rule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Machine"
+                            "$ref": "#/definitions/models.PlatformFeeRule"
                         }
                     }
                 ],
@@ -350,38 +452,56 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Machine"
+                            "$ref": "#/definitions/models.PlatformFeeRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "403": {
-                        "description": "Not authorized",
+                        "description": "Admins only",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
+                    },
+                    "404": {
+                        "description": "Fee rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove a listing. Only Owner or Admin can perform this.",
+                "description": "Remove a fee rule. Machines it covered fall back to the next matching rule. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Machines"
+                    "Admin"
                 ],
-                "summary": "Delete a listing",
+                "summary": "Delete a platform fee rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Machine ID",
+                        "description": "Fee Rule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -398,7 +518,16 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Not authorized",
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Fee rule not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -409,75 +538,80 @@ const docTemplate = `{
                 }
             }
         },
-        "/machines/{machine_id}/inspection": {
+        "/admin/inspection-requests": {
             "get": {
-                "description": "Retrieve the latest inspection report for a specific machine.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Inspection"
-                ],
-                "summary": "Get inspection report for a machine",
-                "parameters": [
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Machine UUID",
-                        "name": "machine_id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.InspectionReport"
-                        }
-                    }
-                }
-            }
-        },
-        "/machines/{machine_id}/maintenance": {
-            "get": {
-                "description": "Retrieve full service history for a machine.",
+                "description": "List every inspection request, oldest first, for scheduling. Filter with status, e.g. status=requested for those still waiting for an inspector. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Maintenance"
+                    "Admin"
                 ],
-                "summary": "Get maintenance history",
+                "summary": "List inspection requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Machine ID",
-                        "name": "machine_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only requests in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching requests",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.MaintenanceRecord"
+                            "$ref": "#/definitions/pagination.Page-models_InspectionRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
                     }
                 }
             }
         },
-        "/maintenance": {
-            "post": {
+        "/admin/inspection-requests/{id}/schedule": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add a service history log for a machine. Only the owner can do this.",
+                "description": "Assign an inspector and a time slot to a requested inspection, or reschedule one that has not started. The slot must lie in the future and must not overlap the inspector's other scheduled or in-progress inspections. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -485,29 +619,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Maintenance"
+                    "Admin"
                 ],
-                "summary": "Add a maintenance record",
+                "summary": "Schedule an inspection",
                 "parameters": [
                     {
-                        "description": "Maintenance Data",
-                        "name": "record",
+                        "type": "string",
+                        "description": "Inspection request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Inspector and slot",
+                        "name": "schedule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.MaintenanceRequest"
+                            "$ref": "#/definitions/controllers.ScheduleInspectionInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MaintenanceRecord"
+                            "$ref": "#/definitions/models.InspectionRequest"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Invalid slot",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -516,7 +657,22 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Not authorized",
+                        "description": "Admins only",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Request already started or closed, or inspector busy",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -527,18 +683,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/not-found": {
+        "/attachments": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the documents attached to a machine, maintenance record, inspection report or rental, newest first, a page at a time. Private attachments are only listed for the record's owner and parties, with download URLs that expire after 15 minutes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Errors"
+                    "Attachments"
+                ],
+                "summary": "List a record's attachments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "machine, maintenance, inspection or rental",
+                        "name": "parent_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the record",
+                        "name": "parent_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this kind",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching attachments",
+                        "name": "include_total",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Not Found Handler",
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parent or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not a party to the rental",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Record not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -547,46 +770,68 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/rentals": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Initiate a rental request. Calculates fees based on duration. Requires Renter Auth.",
+                "description": "Upload a PDF, Word, Excel, PowerPoint, OpenDocument or CSV file and attach it to a machine, maintenance record, inspection report or rental. The type is detected from the file's content. Limits: PDF, PPT(X) and ODP 20MB; DOC(X), XLS(X), ODT and ODS 10MB; CSV 5MB. Files with macros are rejected. Machine owners attach to their machines and maintenance records, inspectors to their reports, and any party to a rental. Attachments are private unless ` + "`" + `visibility` + "`" + ` is ` + "`" + `public` + "`" + `; rental attachments are always private.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Attachments"
                 ],
-                "summary": "Request to rent a machine",
+                "summary": "Attach a document",
                 "parameters": [
                     {
-                        "description": "Rental Details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.RentalRequest"
-                        }
+                        "type": "file",
+                        "description": "Document",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "machine, maintenance, inspection or rental",
+                        "name": "parent_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the record",
+                        "name": "parent_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "invoice, manual, certificate, report or other (default)",
+                        "name": "kind",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "private (default) or public",
+                        "name": "visibility",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Rental"
+                            "$ref": "#/definitions/models.Attachment"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or dates",
+                        "description": "Invalid file or parent",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -594,8 +839,23 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Not allowed to attach here",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
                     "404": {
-                        "description": "Machine not found",
+                        "description": "Record not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "File too large for its type",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -606,107 +866,236 @@ const docTemplate = `{
                 }
             }
         },
-        "/rentals/manage": {
+        "/attachments/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all incoming rental requests for machines owned by the logged-in user.",
+                "description": "Fetch one attachment with a fresh download URL.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Attachments"
+                ],
+                "summary": "Get an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get rental requests for my machines",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Rental"
+                            "$ref": "#/definitions/models.Attachment"
+                        }
+                    },
+                    "404": {
+                        "description": "Attachment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/rentals/my": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all rental requests made by the logged-in user.",
+                "description": "Remove a document and its stored file. Allowed to whoever may attach documents to the record.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Attachments"
+                ],
+                "summary": "Delete an attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my rental history",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Rental"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Attachment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/rentals/{id}/status": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/bad-request": {
+            "get": {
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Approve, reject, or complete a rental. Only the machine owner can do this.",
-                "consumes": [
+                "tags": [
+                    "Errors"
+                ],
+                "summary": "Bad Request Handler",
+                "responses": {
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "List the active machine categories with their spec fields, by name, a page at a time.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List machine categories",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}/inspection-template": {
+            "get": {
+                "description": "Retrieve the current inspection template of a category: its sections, checklist items (pass_fail checks and measurements with an acceptable min/max) and their weights. Inspection reports for machines in the category answer these items in report_data, keyed by item, e.g. {\"spindle_noise\": \"pass\", \"spindle_runout_mm\": 0.004}.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rentals"
+                    "Categories"
                 ],
-                "summary": "Update rental status",
+                "summary": "Get a category's inspection checklist",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Rental ID",
-                        "name": "id",
+                        "description": "Category slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionTemplate"
+                        }
                     },
-                    {
-                        "description": "New Status",
-                        "name": "status",
-                        "in": "body",
-                        "required": true,
+                    "404": {
+                        "description": "Category or template not found",
                         "schema": {
-                            "$ref": "#/definitions/controllers.RentalStatusUpdate"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/categories/{slug}/schema": {
+            "get": {
+                "description": "Retrieve the spec fields (types, units, allowed values and whether they are required) that listings in this category must provide. Used to render listing forms.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get a category's spec schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Rental"
+                            "$ref": "#/definitions/specs.Schema"
                         }
                     },
-                    "403": {
-                        "description": "Not authorized",
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -717,18 +1106,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/unauthorized": {
+        "/certificates/{code}": {
             "get": {
+                "description": "Look up the inspection report behind a certificate's verification code, as printed on it or encoded in its QR code. Case, dashes and spaces do not matter. valid is false, with the problem, if the report no longer matches its signature (GET /inspections/{id}/verify checks its earlier revisions too). current is false once the machine has been inspected again.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Errors"
+                    "Inspection"
+                ],
+                "summary": "Verify an inspection certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification code, e.g. 7KQ2-M9XD-4TRA",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Unauthorized Handler",
                 "responses": {
-                    "401": {
-                        "description": "Unauthorized",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CertificateVerification"
+                        }
+                    },
+                    "404": {
+                        "description": "No certificate with this code",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -739,37 +1144,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/upload": {
-            "post": {
-                "description": "Upload an image file (jpg, png, jpeg) and get a local URL. Max size 5MB.",
+        "/claims/{id}/resolve": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An admin decides how much of a disputed claim is deducted (0 up to the claimed amount) and settles the deposit.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Utility"
+                    "Claims"
                 ],
-                "summary": "Upload an image",
+                "summary": "Resolve a disputed damage claim",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Decision",
+                        "name": "resolution",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ClaimResolutionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.UploadResponse"
+                            "$ref": "#/definitions/models.DamageClaim"
                         }
                     },
                     "400": {
-                        "description": "Invalid file",
+                        "description": "Invalid amount",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -777,8 +1196,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Claim is not disputed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Deposit settlement failed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -788,293 +1225,5791 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.InspectionRequest": {
-            "type": "object",
-            "properties": {
-                "machine_id": {
-                    "type": "string"
-                },
-                "media_urls": {
-                    "description": "Array of image URLs",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        },
+        "/claims/{id}/respond": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
-                },
-                "report_data": {
-                    "description": "Flexible Key-Value pairs",
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "report_type": {
-                    "description": "listing, check_out, check_in",
-                    "type": "string"
-                },
-                "summary": {
-                    "type": "string"
-                },
-                "verdict": {
+                ],
+                "description": "The renter accepts a submitted claim, which settles the deposit (deductions are captured and the rest released), or disputes it with a reason for an admin to resolve.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Accept or dispute a damage claim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Response",
+                        "name": "response",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ClaimResponseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DamageClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the renter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Claim is not awaiting a response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Deposit settlement failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/forbidden": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Errors"
+                ],
+                "summary": "Forbidden Handler",
+                "responses": {
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Checks if the backend server is running.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "General"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspection-requests": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ask for a machine awaiting inspection (status pending_inspection) to be inspected. Owners request inspections of their own machines; a machine has at most one open request. An admin then assigns an inspector and a time slot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Request an inspection",
+                "parameters": [
+                    {
+                        "description": "Machine to inspect",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InspectionRequestInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionRequest"
+                        }
+                    },
+                    "403": {
+                        "description": "Not your machine",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Machine not awaiting inspection, or already requested",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspection-requests/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the inspection requests the current user has made, newest first. Filter with status (requested, scheduled, in_progress, submitted, cancelled).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Get my inspection requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only requests in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching requests",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_InspectionRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspection-requests/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the inspections assigned to the current inspector, soonest slot first. Without a status filter the queue holds scheduled and in-progress inspections.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Get my inspection queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only requests in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching requests",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_InspectionRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor or status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Inspectors only",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    }
+                }
+            }
+        },
+        "/inspection-requests/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve one inspection request. Visible to the machine's owner, the assigned inspector and admins.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Get an inspection request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inspection request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionRequest"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a party to the request",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspection-requests/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The assigned inspector moves a scheduled inspection to in_progress when they begin. The machine's owner may cancel a request until it starts; admins may cancel at any point before submission. Inspections are submitted by posting the report with its request_id (POST /inspections).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Start or cancel an inspection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Inspection request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InspectionStatusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid transition",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspections": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submit a verification report with media URLs. Requires the inspector or admin role. Listing reports need the request_id of a scheduled or in-progress inspection request and can only be submitted by its assigned inspector (or an admin); submitting closes the request. Check-out and check-in reports are tied to their rental_id instead. Media URLs must be the inspector's own uploads (admins may use anyone's). When the machine's category has an inspection checklist (GET /categories/{slug}/inspection-template), report_data must answer its items; the report is then scored 0-100 with a grade from A to F. A listing report's score and grade become the listing's condition_score and condition_grade. report_type is listing (the default), check_out or check_in, and verdict is Pass or Fail; only a listing report verifies a machine awaiting inspection, and not if it fails or grades F. The report is signed with the server's Ed25519 key (GET /inspections/{id}/verify) and cannot be edited afterwards; corrections are new revisions (POST /inspections/{id}/revisions).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Submit an inspection report",
+                "parameters": [
+                    {
+                        "description": "Inspection Data",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InspectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, report type, verdict or checklist answers",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Inspectors only, or not the assigned inspector",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "409": {
+                        "description": "Inspection not scheduled or already submitted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspections/{id}/revisions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Correct a signed inspection report by adding a new revision; the original is never modified. The revision copies the report, applies the changes, links to it by previous_id and previous_hash and is signed in turn. Answers are re-scored against the checklist the original used. Revising a machine's latest listing report updates its condition, and a revision that fails or grades F returns a verified machine to pending_inspection. Only the report's inspector (or an admin) may revise it, only its latest revision can be revised, and a note explaining the change is required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Revise an inspection report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report UUID (the latest revision)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "revision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InspectionRevisionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or checklist answers",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the report's inspector",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Already revised, or the report fails verification",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspections/{id}/verify": {
+            "get": {
+                "description": "Check that a report is exactly as its inspector submitted it. The report's canonical content is re-hashed and its Ed25519 signature checked with the server key named by key_id; then every earlier revision is checked the same way, along with the links between them. valid is false, with the problem listed in chain, if anything was changed after signing. Reports submitted before signing was introduced are reported as unsigned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Verify an inspection report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InspectionVerification"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal-server-error": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Errors"
+                ],
+                "summary": "Internal Server Error Handler",
+                "responses": {
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines": {
+            "get": {
+                "description": "Retrieve a list of machines with optional filtering and sorting, a page at a time: pass the next_cursor of one page as cursor to get the next. Sold machines are not listed. With a search query each result carries a relevance score. Together with a category, specs can be filtered as specs.\u003ckey\u003e=value or specs.\u003ckey\u003e[op]=value (op: eq, ne, gt, gte, lt, lte, in), e.g. specs.axes=5\u0026specs.spindle_speed_rpm[gte]=8000; keys and values are checked against the category's schema. With near (a lat,lng pair, a city or a PIN code) each result carries its distance_km, radius_km limits how far away listings may be and sort=distance puts the closest first; listings whose location could not be placed are left out of radius searches. Listings with photos carry the cover photo's cover_thumbnail_url.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Get all machine listings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full-text search over title, manufacturer, model number, category and description; titles, manufacturers and model numbers also match when misspelt",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by Category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by Manufacturer (tolerates typos)",
+                        "name": "manufacturer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by Location",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by Listing Type (sale, rent)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search point: lat,lng, a city or a PIN code",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only listings within this many km of near",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order (relevance, distance, price_asc, price_desc, oldest)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching listings",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Machine"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a new machine for sale or rent. Requires Seller or Admin Role. Send X-Organization-ID to list the machine for a company you belong to. If a category is given it must be registered, and specs are validated against its schema (see GET /categories/{slug}/schema). Latitude and longitude are geocoded from the location unless both are given. New listings await inspection; any status sent is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Create a new machine listing",
+                "parameters": [
+                    {
+                        "description": "Machine Details",
+                        "name": "machine",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Machine"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Machine"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, specs or coordinates",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (Buyers cannot list)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update details. Only the Owner (any member of the owning organization, for company listings) or an Admin can perform this. Specs are validated against the category's schema. Latitude and longitude are geocoded from the location unless both are given. When the location changes it is geocoded again, unless new coordinates are sent with it. The status is set by inspections and sales and cannot be changed here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Update a listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Data",
+                        "name": "machine",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Machine"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Machine"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid specs or coordinates",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a listing with its photos and documents. Only the Owner (any member of the owning organization, for company listings) or an Admin can perform this. Uploads used only by the listing's photos are deleted after UPLOAD_ORPHAN_GRACE.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Delete a listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/availability": {
+            "get": {
+                "description": "Returns booked (approved or active rentals) and free date ranges for a machine. Defaults to the next 90 days; the window may span at most a year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get machine availability calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of window (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of window (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/images": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add one of the caller's uploaded images (see POST /upload) to a listing's gallery, after its existing photos. The first photo, or one sent with is_cover, becomes the cover. Only the owner (any member of the owning organization, for company listings) or an admin can do this; a listing holds at most 20 photos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Add a photo to a listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Photo",
+                        "name": "image",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MachineImageInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or not the caller's upload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/images/order": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the display order of a listing's gallery. image_ids must list every photo of the listing exactly once. Only the owner or an admin can do this. The reordered gallery is returned whole, in the list envelope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Reorder a listing's photos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image IDs in display order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReorderImagesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_MachineImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Not a permutation of the listing's images",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/images/{image_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change a photo's caption or alt text, or make it the cover (is_cover: true; the previous cover is unset). A listing with photos always keeps a cover, so is_cover: false is ignored. Only the owner or an admin can do this.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Edit a listing photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image ID",
+                        "name": "image_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "image",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MachineImageUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MachineImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine or image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a photo from a listing's gallery. The photos after it move up and, if it was the cover, the new first photo becomes the cover. The uploaded file is deleted once nothing else uses it. Only the owner or an admin can do this.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Machines"
+                ],
+                "summary": "Remove a listing photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image ID",
+                        "name": "image_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Denial"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine or image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/offers": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Offer to buy a machine listed for sale. The seller can accept, reject or counter. A buyer can have one open offer per machine.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offers"
+                ],
+                "summary": "Make an offer on a machine",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Offer",
+                        "name": "offer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OfferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Offer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid offer or machine not for sale",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Machine sold or offer already open",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{machine_id}/inspection": {
+            "get": {
+                "description": "Retrieve the latest inspection report for a specific machine.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Get inspection report for a machine",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine UUID",
+                        "name": "machine_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.InspectionReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{machine_id}/inspection/certificate.pdf": {
+            "get": {
+                "description": "Render the latest listing inspection report of a verified machine as a PDF: machine details, inspector, date, verdict, condition grade, summary, checklist results and up to six photos. The certificate carries a verification code and a QR code linking to GET /certificates/{code}, so anyone holding a copy can check it is genuine and current. The code is assigned on the first download and stays the same for the report.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Inspection"
+                ],
+                "summary": "Download a machine's inspection certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine UUID",
+                        "name": "machine_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found, not verified, never inspected, or its latest inspection failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{machine_id}/maintenance": {
+            "get": {
+                "description": "Retrieve a machine's service history, latest service first, a page at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
+                ],
+                "summary": "Get maintenance history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "machine_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_MaintenanceRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a service history log for a machine. Only the owner (any member of the owning organization, for company machines) can do this. The document URL, if any, must be one of the caller's uploads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
+                ],
+                "summary": "Add a maintenance record",
+                "parameters": [
+                    {
+                        "description": "Maintenance Data",
+                        "name": "record",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MaintenanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/not-found": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Errors"
+                ],
+                "summary": "Not Found Handler",
+                "responses": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/offers/manage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the offers received on machines the current user is selling (the organization's, when acting for one), most recently active first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offers"
+                ],
+                "summary": "Get offers on my machines",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching offers",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Offer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/offers/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the offers the current user has made as a buyer, most recently active first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offers"
+                ],
+                "summary": "Get offers I have made",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching offers",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Offer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/offers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve an offer with its full negotiation history. Only the buyer, the seller or an admin can view it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offers"
+                ],
+                "summary": "Get an offer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Offer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Offer"
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Offer not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/offers/{id}/respond": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accept, reject or counter an open offer when it is your turn (the seller answers pending offers, the buyer answers counter-offers). The buyer may withdraw at any time. Accepting creates an order, marks the machine as sold and closes all other open offers on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offers"
+                ],
+                "summary": "Answer an offer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Offer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Response",
+                        "name": "response",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OfferResponseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OfferResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid action or amount",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not a party",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Offer closed, not your turn or machine sold",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/manage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the orders for machines the current user has sold (the organization's, when acting for one), newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get my sales",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching orders",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the orders the current user has placed as a buyer, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get my purchases",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching orders",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move an order through accepted → paid → shipped → delivered. The seller confirms payment and ships (with carrier and tracking number); the buyer confirms delivery. Either side may cancel before payment, which puts the machine back on sale.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Update order status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New Status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OrderStatusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown status or missing tracking number",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid transition",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a company. The caller becomes its first owner. Send its ID in the X-Organization-ID header to list machines and book rentals on its behalf.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the organizations the logged-in user belongs to, with their member role in each, oldest membership first, a page at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List my organizations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_OrganizationMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve an organization and its members. Members and admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a user with the owner, admin or member role. Owners and admins manage members; only owners can add owners. Every member can manage the organization's machines and rentals.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add a member to an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or role",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage members",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Already a member",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Owners and admins remove members; any member may remove themselves. Only owners can remove owners, and the last owner cannot be removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove a member from an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage members",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or member not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Last owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/webhook": {
+            "post": {
+                "description": "Receives signed status notifications from the payment provider. The body must be signed with HMAC-SHA256 in the X-Payment-Signature header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Payment provider webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hex HMAC-SHA256 of the body",
+                        "name": "X-Payment-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Capture all or part of an authorised hold (e.g. a security deposit). The rest of the hold is released. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Capture a held payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount (0 = full hold)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PaymentAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Payment is not held",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Refund all or part of a captured payment. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Refund a captured payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount (0 = everything not yet refunded)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PaymentAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Payment cannot be refunded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Void an authorised hold or an unpaid intent without charging the payer. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Release a held payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "403": {
+                        "description": "Admins only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Payment cannot be released",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/simulate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completes or declines a payment intent with the in-process fake provider and delivers the signed webhook, for local development. Only registered when PAYMENT_PROVIDER=fake. Payer or admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Simulate checkout (fake provider only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outcome",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SimulatePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not found or provider is not fake",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Initiate a rental request. Prices the rental using the machine's monthly, weekly and daily rates. Requires Renter Auth.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Request to rent a machine",
+                "parameters": [
+                    {
+                        "description": "Rental Details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RentalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rental"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, dates or duration",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Dates already booked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/manage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the incoming rental requests for machines owned by the logged-in user (or by the organization named in X-Organization-ID), newest first, a page at a time. Filter by status and by a date range the rental overlaps.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get rental requests for my machines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only these statuses (comma-separated, e.g. approved,active)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals ending on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals starting on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching rentals",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Rental"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the rental requests made by the logged-in user (or by the organization named in X-Organization-ID), newest first, a page at a time. Filter by status and by a date range the rental overlaps.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get my rental history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only these statuses (comma-separated, e.g. approved,active)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals ending on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals starting on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching rentals",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Rental"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/quote": {
+            "post": {
+                "description": "Price a rental without creating it. Whole months and weeks are charged at the monthly and weekly rates, the remainder at the daily rate. Includes the platform fee and GST (CGST+SGST within a state, IGST across states).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get a rental price quote",
+                "parameters": [
+                    {
+                        "description": "Rental Details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RentalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pricing.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, dates or duration",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Machine not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/claims": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the damage claims filed against a rental's deposit, with their deductions, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "List damage claims for a rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_DamageClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "File an itemised claim against the security deposit of a completed rental, backed by its check-out and check-in inspection reports. The total may not exceed the deposit. Machine owner only; one claim per rental.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "File a damage claim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Claim",
+                        "name": "claim",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DamageClaimRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DamageClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid claim",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Rental not completed, deposit settled or claim exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/deposit/settle": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Settle the deposit of a completed rental. Without a claim the deposit is returned in full; with an accepted or resolved claim the approved deductions are kept (use this to retry a failed settlement). Machine owner or admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claims"
+                ],
+                "summary": "Settle a rental's security deposit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rental"
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Rental not completed, claim undecided or already settled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Deposit settlement failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the status changes of a rental, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Get rental status history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_RentalStatusEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the payment intents of a rental, oldest first, a page at a time. Only the renter, the machine owner or an admin can view them; client secrets are only shown to the payer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "List payments for a rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also count all matching records",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create payment intents for a pending rental: the rental charge (captured on payment) and, if any, the security deposit (held until settlement). Returns client secrets for checkout. Repeat calls return the open intents. Renter only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Start payment for a rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Payment"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not the renter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rental not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Rental is not pending",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rentals/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a rental through pending → approved → active → completed (or rejected/cancelled). The owner (any member of the owning organization, for company machines) may approve, reject, activate and complete; the renter may cancel. Approval requires the rental charge to be captured and the deposit held, and fails if the dates overlap another approved or active rental. Rejection and cancellation refund the renter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rentals"
+                ],
+                "summary": "Update rental status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rental ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New Status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RentalStatusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rental"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "402": {
+                        "description": "Payment not yet captured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid transition or dates already booked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/unauthorized": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Errors"
+                ],
+                "summary": "Unauthorized Handler",
+                "responses": {
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a JPEG, PNG or WebP image (max 5MB). The type is detected from the file's content, not its name. Metadata such as EXIF GPS coordinates is stripped, and thumbnail, medium and full variants are stored on the configured backend (plus WebP copies when IMAGE_WEBP is set). ` + "`" + `hash` + "`" + ` is the SHA-256 of the uploaded bytes. Uploading the same bytes again returns the earlier upload with ` + "`" + `duplicate` + "`" + ` set. Uploads no record refers to are deleted after UPLOAD_ORPHAN_GRACE (24h by default).",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Utility"
+                ],
+                "summary": "Upload an image",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/upload/presign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a short-lived URL the client PUTs the image bytes to directly, bypassing the API. The bytes are not usable until POST /upload/{id}/complete has checked and processed them like any other upload. Presigned uploads that are never completed, or never referred to afterwards, are deleted after UPLOAD_ORPHAN_GRACE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Utility"
+                ],
+                "summary": "Get a presigned image upload URL",
+                "parameters": [
+                    {
+                        "description": "File to upload",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PresignUploadInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PresignUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filename",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/upload/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Processes the bytes PUT to a presigned upload URL exactly like POST /upload: the type is detected from the content, metadata is stripped and the variants are stored. The bytes as sent are then deleted. Only the uploader can complete an upload. If the caller had already uploaded the same bytes, the earlier upload is returned with ` + "`" + `duplicate` + "`" + ` set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Utility"
+                ],
+                "summary": "Complete a presigned image upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID from POST /upload/presign",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Nothing uploaded, or not an image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not your upload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Upload already completed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "checklist.Item": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "spindle_runout_mm"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Spindle runout"
+                },
+                "max": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "min": {
+                    "type": "number"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "measurement"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "mm"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 2
+                }
+            }
+        },
+        "checklist.Section": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/checklist.Item"
+                    }
+                },
+                "key": {
+                    "type": "string",
+                    "example": "spindle"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Spindle"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 3
+                }
+            }
+        },
+        "controllers.AvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "booked": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.BookedRange"
+                    }
+                },
+                "free": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.DateRange"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.BookedRange": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "2025-01-05"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "approved"
+                }
+            }
+        },
+        "controllers.CertificateVerification": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "CNC Mill"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "7KQ2-M9XD-4TRA"
+                },
+                "condition_grade": {
+                    "type": "string",
+                    "example": "B"
+                },
+                "condition_score": {
+                    "type": "number",
+                    "example": 87.5
+                },
+                "current": {
+                    "description": "Current is false once the machine has been inspected again; the\nnewer report supersedes this certificate",
+                    "type": "boolean",
+                    "example": true
+                },
+                "inspection_date": {
+                    "type": "string"
+                },
+                "inspector_id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "machine_status": {
+                    "type": "string",
+                    "example": "verified"
+                },
+                "machine_title": {
+                    "type": "string",
+                    "example": "Haas VF-2 CNC Mill"
+                },
+                "problem": {
+                    "type": "string",
+                    "example": "content changed after signing"
+                },
+                "report_id": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is false if the report no longer matches its signature;\nProblem then says why",
+                    "type": "boolean",
+                    "example": true
+                },
+                "verdict": {
+                    "type": "string",
+                    "example": "Pass"
+                }
+            }
+        },
+        "controllers.ClaimResolutionRequest": {
+            "type": "object",
+            "properties": {
+                "approved_amount": {
+                    "type": "number",
+                    "example": 3000
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ClaimResponseRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "accept, dispute",
+                    "type": "string",
+                    "example": "accept"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.DamageClaimItemRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 4500
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Cracked spindle housing"
+                },
+                "evidence_urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.DamageClaimRequest": {
+            "type": "object",
+            "properties": {
+                "check_in_report_id": {
+                    "type": "string"
+                },
+                "check_out_report_id": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.DamageClaimItemRequest"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.DateRange": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "2025-01-05"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "controllers.InspectionRequest": {
+            "type": "object",
+            "properties": {
+                "machine_id": {
+                    "type": "string"
+                },
+                "media_urls": {
+                    "description": "URLs of the inspector's own uploads",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rental_id": {
+                    "description": "required for check_out / check_in",
+                    "type": "string"
+                },
+                "report_data": {
+                    "description": "Flexible Key-Value pairs",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "report_type": {
+                    "description": "listing, check_out, check_in",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "required for listing reports",
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "verdict": {
+                    "description": "Pass or Fail",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.InspectionRequestInput": {
+            "type": "object",
+            "properties": {
+                "machine_id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Available weekdays after 10am; ask for the shop floor manager"
+                }
+            }
+        },
+        "controllers.InspectionRevisionInput": {
+            "type": "object",
+            "properties": {
+                "media_urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "note": {
+                    "description": "why the report changed",
+                    "type": "string",
+                    "example": "Runout re-measured with a calibrated gauge"
+                },
+                "report_data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "verdict": {
+                    "description": "Pass or Fail",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.InspectionStatusUpdate": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "when cancelling",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "in_progress"
+                }
+            }
+        },
+        "controllers.InspectionTemplateInput": {
+            "type": "object",
+            "properties": {
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/checklist.Section"
+                    }
+                }
+            }
+        },
+        "controllers.InspectionVerification": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string",
+                    "example": "Ed25519"
+                },
+                "chain": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.RevisionCheck"
+                    }
+                },
+                "content_hash": {
+                    "description": "SHA-256 of the report as stored now",
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current is false once the report has been revised; SupersededBy is\nthe next revision",
+                    "type": "boolean",
+                    "example": true
+                },
+                "key_id": {
+                    "type": "string",
+                    "example": "3f9a1c04b2d7e865"
+                },
+                "public_key": {
+                    "description": "base64",
+                    "type": "string"
+                },
+                "report_id": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "superseded_by": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is true when this revision and every earlier one are signed,\nunchanged since signing and correctly linked",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controllers.MachineImageInput": {
+            "type": "object",
+            "properties": {
+                "alt_text": {
+                    "type": "string",
+                    "example": "Front view of a Haas VF-2 vertical machining centre"
+                },
+                "caption": {
+                    "type": "string",
+                    "example": "Spindle and tool changer"
+                },
+                "is_cover": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"
+                }
+            }
+        },
+        "controllers.MachineImageUpdate": {
+            "type": "object",
+            "properties": {
+                "alt_text": {
+                    "type": "string"
+                },
+                "caption": {
+                    "type": "string"
+                },
+                "is_cover": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.MaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_url": {
+                    "description": "one of the caller's uploads",
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "service_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "technician": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.MemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "owner, admin or member",
+                    "type": "string",
+                    "example": "member"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "controllers.OfferRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 950000
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OfferResponseRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "accept, reject, counter, withdraw",
+                    "type": "string",
+                    "example": "counter"
+                },
+                "amount": {
+                    "description": "required to counter",
+                    "type": "number",
+                    "example": 980000
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OfferResult": {
+            "type": "object",
+            "properties": {
+                "offer": {
+                    "$ref": "#/definitions/models.Offer"
+                },
+                "order": {
+                    "$ref": "#/definitions/models.Order"
+                }
+            }
+        },
+        "controllers.OrderStatusUpdate": {
+            "type": "object",
+            "properties": {
+                "carrier": {
+                    "description": "when shipping",
+                    "type": "string",
+                    "example": "VRL Logistics"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "shipped"
+                },
+                "tracking_number": {
+                    "description": "when shipping",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "gstin": {
+                    "type": "string",
+                    "example": "06AABCS1429B1Z5"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Shakti Precision Tools Pvt Ltd"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "Haryana"
+                }
+            }
+        },
+        "controllers.PaymentAmountRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "0 = the full remaining amount",
+                    "type": "number",
+                    "example": 2500
+                }
+            }
+        },
+        "controllers.PresignUploadInput": {
+            "type": "object",
+            "properties": {
+                "filename": {
+                    "type": "string",
+                    "example": "excavator.jpg"
+                }
+            }
+        },
+        "controllers.PresignUploadResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "private/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-incoming.jpg"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "PUT"
+                },
+                "upload_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RentalRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2025-01-05"
+                },
+                "machine_id": {
+                    "type": "string",
+                    "example": "uuid-string"
+                },
+                "renter_state": {
+                    "description": "GST place of supply; defaults to the machine's state",
+                    "type": "string",
+                    "example": "Maharashtra"
+                },
+                "start_date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "controllers.RentalStatusUpdate": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Machine under repair that week"
+                },
+                "status": {
+                    "description": "approved, rejected, active, completed, cancelled",
+                    "type": "string",
+                    "example": "approved"
+                }
+            }
+        },
+        "controllers.ReorderImagesInput": {
+            "type": "object",
+            "properties": {
+                "image_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.RevisionCheck": {
+            "type": "object",
+            "properties": {
+                "problem": {
+                    "type": "string",
+                    "example": "content changed after signing"
+                },
+                "report_id": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controllers.ScheduleInspectionInput": {
+            "type": "object",
+            "properties": {
+                "inspector_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "scheduled_end": {
+                    "type": "string",
+                    "example": "2026-03-02T13:00:00+05:30"
+                },
+                "scheduled_start": {
+                    "type": "string",
+                    "example": "2026-03-02T10:00:00+05:30"
+                }
+            }
+        },
+        "controllers.SimulatePaymentRequest": {
+            "type": "object",
+            "properties": {
+                "outcome": {
+                    "description": "success, failure",
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "controllers.UploadResponse": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "hash": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1536
+                },
+                "id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.UploadVariant"
+                    }
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 2048
+                }
+            }
+        },
+        "controllers.UploadVariant": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 240
+                },
+                "name": {
+                    "type": "string",
+                    "example": "thumbnail"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 18342
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-thumbnail.jpg"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 320
+                }
+            }
+        },
+        "controllers.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Invalid specs"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "application/pdf"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "service-invoice-0423.pdf"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "invoice"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "parent_type": {
+                    "type": "string",
+                    "example": "maintenance"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 248113
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uploader_id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "Where to download it: the public URL, or a presigned one that\nexpires at URLExpiresAt for private attachments",
+                    "type": "string"
+                },
+                "url_expires_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "type": "string",
+                    "example": "private"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/specs.Field"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CNC Mill"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "cnc-mill"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DamageClaim": {
+            "type": "object",
+            "properties": {
+                "approved_amount": {
+                    "type": "number"
+                },
+                "check_in_report_id": {
+                    "type": "string"
+                },
+                "check_out_report_id": {
+                    "type": "string"
+                },
+                "claimant_id": {
+                    "type": "integer"
+                },
+                "claimed_amount": {
+                    "description": "sum of items",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deposit_amount": {
+                    "description": "Financials",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DamageClaimItem"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "refund_amount": {
+                    "description": "deposit returned to renter",
+                    "type": "number"
+                },
+                "rental_id": {
+                    "type": "string"
+                },
+                "renter_response": {
+                    "type": "string"
+                },
+                "resolution_notes": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "integer"
+                },
+                "settled_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DamageClaimItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "claim_id": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "evidence_urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InspectionReport": {
+            "type": "object",
+            "properties": {
+                "condition_grade": {
+                    "type": "string",
+                    "example": "B"
+                },
+                "condition_score": {
+                    "type": "number",
+                    "example": 87.5
+                },
+                "content_hash": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inspection_date": {
+                    "type": "string"
+                },
+                "inspector_id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "media_urls": {
+                    "description": "FIX: Added swaggertype:\"array,string\"",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "previous_hash": {
+                    "type": "string"
+                },
+                "previous_id": {
+                    "type": "string"
+                },
+                "rental_id": {
+                    "description": "set for check_out / check_in",
+                    "type": "string"
+                },
+                "report_data": {
+                    "description": "FIX: Added swaggertype:\"object\"",
+                    "type": "object"
+                },
+                "report_type": {
+                    "type": "string"
+                },
+                "revised_by": {
+                    "type": "integer"
+                },
+                "revision": {
+                    "description": "Reports are signed when created and never edited: a correction is a\nnew revision that points at the report it replaces and records that\nreport's ContentHash, the SHA-256 of its SignedContent",
+                    "type": "integer",
+                    "example": 1
+                },
+                "revision_note": {
+                    "type": "string"
+                },
+                "section_scores": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "signature": {
+                    "description": "base64 Ed25519",
+                    "type": "string"
+                },
+                "signing_key_id": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "description": "Set when the machine's category has an inspection checklist: the\ntemplate version ReportData was checked against and the resulting\ncondition score (0-100), grade (A-F) and per-section scores",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verdict": {
+                    "type": "string"
+                },
+                "verification_code": {
+                    "description": "Printed on the report's certificate; assigned on first download",
+                    "type": "string",
+                    "example": "7KQ2-M9XD-4TRA"
+                }
+            }
+        },
+        "models.InspectionRequest": {
+            "type": "object",
+            "properties": {
+                "cancel_reason": {
+                    "type": "string"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inspector_id": {
+                    "description": "Set when an admin schedules the inspection",
+                    "type": "integer"
+                },
+                "machine": {
+                    "$ref": "#/definitions/models.Machine"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "description": "The submitted report",
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "integer"
+                },
+                "scheduled_end": {
+                    "type": "string"
+                },
+                "scheduled_start": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InspectionTemplate": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/checklist.Section"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.Machine": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "New Fields for Search",
+                    "type": "string"
+                },
+                "condition_grade": {
+                    "type": "string",
+                    "example": "B"
+                },
+                "condition_score": {
+                    "description": "Condition from the latest scored inspection report",
+                    "type": "number",
+                    "example": 87.5
+                },
+                "cover_thumbnail_url": {
+                    "description": "Thumbnail of the cover photo, only set on listing results",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "description": "Kilometres from the search point, only set on results of a \"near\" search",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "images": {
+                    "description": "Photo gallery, ordered by position; only loaded on a single listing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineImage"
+                    }
+                },
+                "latitude": {
+                    "description": "Geocoded from Location unless the seller gives exact coordinates",
+                    "type": "number",
+                    "example": 28.4089
+                },
+                "listing_type": {
+                    "description": "sale, rent, both",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "e.g., \"Faridabad, Haryana\"",
+                    "type": "string"
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": 77.3178
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "min_rental_days": {
+                    "description": "0 = no minimum",
+                    "type": "integer"
+                },
+                "model_number": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "Set when the listing belongs to a company; any member can manage it",
+                    "type": "string"
+                },
+                "price_for_sale": {
+                    "type": "number"
+                },
+                "relevance": {
+                    "description": "Search relevance, only set on keyword search results",
+                    "type": "number"
+                },
+                "rental_price_per_day": {
+                    "type": "number"
+                },
+                "rental_price_per_month": {
+                    "type": "number"
+                },
+                "rental_price_per_week": {
+                    "type": "number"
+                },
+                "security_deposit": {
+                    "type": "number"
+                },
+                "seller_id": {
+                    "type": "integer"
+                },
+                "specs": {
+                    "description": "UPDATED: Added swaggertype:\"object\" to fix Swagger generation",
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "year_of_manufacture": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MachineImage": {
+            "type": "object",
+            "properties": {
+                "alt_text": {
+                    "type": "string",
+                    "example": "Front view of a Haas VF-2 vertical machining centre"
+                },
+                "caption": {
+                    "type": "string",
+                    "example": "Spindle and tool changer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_cover": {
+                    "type": "boolean"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
+                    "type": "string",
+                    "example": "/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-thumbnail.jpg"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"
+                }
+            }
+        },
+        "models.MaintenanceRecord": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "document_url": {
+                    "description": "Optional: Link to invoice/document image",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "service_date": {
+                    "type": "string"
+                },
+                "technician": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "e.g., \"Routine\", \"Repair\", \"Replacement\"",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Offer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "buyer_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "machine": {
+                    "$ref": "#/definitions/models.Machine"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "Set when the machine belongs to a company; its members answer for the seller",
+                    "type": "string"
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OfferRound"
+                    }
+                },
+                "seller_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OfferRound": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "offer, counter, accept, reject, withdraw",
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "offer_id": {
+                    "type": "string"
+                },
+                "party": {
+                    "description": "buyer, seller",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "buyer_id": {
+                    "type": "integer"
+                },
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "carrier": {
+                    "description": "Shipping details, set when the order is shipped",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "machine": {
+                    "$ref": "#/definitions/models.Machine"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "offer_id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "Set when a company sold the machine; its members act for the seller",
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "seller_id": {
+                    "type": "integer"
+                },
+                "shipped_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tracking_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "gstin": {
+                    "type": "string",
+                    "example": "06AABCS1429B1Z5"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrganizationMember"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Shakti Precision Tools Pvt Ltd"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "Haryana"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrganizationMember": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "organization": {
+                    "description": "Only set when listing a user's own memberships",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    ]
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "capture_method": {
+                    "description": "automatic, manual",
+                    "type": "string"
+                },
+                "captured_amount": {
+                    "type": "number"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payer_id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_ref": {
+                    "type": "string"
+                },
+                "purpose": {
+                    "description": "rental, deposit",
+                    "type": "string"
+                },
+                "refunded_amount": {
+                    "type": "number"
+                },
+                "rental_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlatformFeeRule": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "category": {
+                    "description": "e.g., \"CNC Mill\"; empty = any",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "percentage, flat",
+                    "type": "string"
+                },
+                "listing_type": {
+                    "description": "rent, both; empty = any",
+                    "type": "string"
+                },
+                "max_fee": {
+                    "description": "0 = no cap",
+                    "type": "number"
+                },
+                "min_fee": {
+                    "description": "0 = no floor",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "percent (5 = 5%) or flat amount",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Rental": {
+            "type": "object",
+            "properties": {
+                "cgst": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deposit_refund": {
+                    "description": "Deposit settlement, recorded once the deposit is released or a claim settles",
+                    "type": "number"
+                },
+                "deposit_settled_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "gst_rate": {
+                    "description": "GST on TotalAmount + PlatformFee: CGST+SGST within a state, IGST across states",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "igst": {
+                    "type": "number"
+                },
+                "machine": {
+                    "description": "Preloads (Optional, for returning full details)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Machine"
+                        }
+                    ]
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "Set when the rental was booked on behalf of a company",
+                    "type": "string"
+                },
+                "platform_fee": {
+                    "description": "e.g. 5%",
+                    "type": "number"
+                },
+                "price_breakdown": {
+                    "description": "Tier line items (month/week/day) that make up TotalAmount",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "renter_id": {
+                    "description": "Matches Auth Service ID type",
+                    "type": "integer"
+                },
+                "renter_state": {
+                    "type": "string"
+                },
+                "security_deposit": {
+                    "type": "number"
+                },
+                "seller_state": {
+                    "type": "string"
+                },
+                "sgst": {
+                    "type": "number"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status Flow: pending -\u003e approved -\u003e active -\u003e completed (or rejected/cancelled)",
+                    "type": "string"
+                },
+                "taxable_amount": {
+                    "type": "number"
+                },
+                "total_amount": {
+                    "description": "Financials",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RentalStatusEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "description": "empty for the initial request",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rental_id": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "pagination.Page-models_Attachment": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Category": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_DamageClaim": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DamageClaim"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_InspectionRequest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InspectionRequest"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Machine": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Machine"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_MachineImage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MachineImage"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_MaintenanceRecord": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MaintenanceRecord"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Offer": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Offer"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.MaintenanceRequest": {
+        "pagination.Page-models_Order": {
             "type": "object",
             "properties": {
-                "cost": {
-                    "type": "number"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "document_url": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Order"
+                    }
                 },
-                "machine_id": {
-                    "type": "string"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "service_date": {
-                    "description": "YYYY-MM-DD",
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "technician": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "type": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.RentalRequest": {
+        "pagination.Page-models_OrganizationMember": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "description": "Format: YYYY-MM-DD",
-                    "type": "string",
-                    "example": "2025-01-05"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrganizationMember"
+                    }
                 },
-                "machine_id": {
-                    "type": "string",
-                    "example": "uuid-string"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "start_date": {
-                    "description": "Format: YYYY-MM-DD",
-                    "type": "string",
-                    "example": "2025-01-01"
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.RentalStatusUpdate": {
+        "pagination.Page-models_Payment": {
             "type": "object",
             "properties": {
-                "status": {
-                    "description": "approved, rejected, completed",
-                    "type": "string",
-                    "example": "approved"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payment"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.UploadResponse": {
+        "pagination.Page-models_PlatformFeeRule": {
             "type": "object",
             "properties": {
-                "url": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlatformFeeRule"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "models.InspectionReport": {
+        "pagination.Page-models_Rental": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Rental"
+                    }
                 },
-                "inspection_date": {
-                    "type": "string"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "inspector_id": {
+                "limit": {
                     "type": "integer"
                 },
-                "machine_id": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "media_urls": {
-                    "description": "FIX: Added swaggertype:\"array,string\"",
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_RentalStatusEvent": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.RentalStatusEvent"
                     }
                 },
-                "report_data": {
-                    "description": "FIX: Added swaggertype:\"object\"",
-                    "type": "object"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "report_type": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "summary": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "updated_at": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "policy.Denial": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
                 },
-                "verdict": {
-                    "type": "string"
+                "permission": {
+                    "$ref": "#/definitions/policy.Permission"
                 }
             }
         },
-        "models.Machine": {
+        "policy.Permission": {
+            "type": "string",
+            "enum": [
+                "machine:create",
+                "machine:update",
+                "machine:delete",
+                "maintenance:create",
+                "inspection:request",
+                "inspection:submit",
+                "inspection:assign",
+                "file:upload",
+                "rental:create",
+                "rental:approve",
+                "payment:pay",
+                "payment:manage",
+                "claim:create",
+                "claim:resolve",
+                "deposit:settle",
+                "offer:create",
+                "order:manage",
+                "organization:manage",
+                "category:manage",
+                "fee_rule:manage",
+                "*"
+            ],
+            "x-enum-comments": {
+                "InspectionAssign": "schedule inspectors",
+                "InspectionRequest": "ask for one's machines to be inspected",
+                "OrderManage": "act on offers and orders one is party to",
+                "OrganizationManage": "create companies, manage their members",
+                "PaymentManage": "capture, release and refund by hand",
+                "RentalApprove": "act as owner on rentals of one's machines"
+            },
+            "x-enum-varnames": [
+                "MachineCreate",
+                "MachineUpdate",
+                "MachineDelete",
+                "MaintenanceCreate",
+                "InspectionRequest",
+                "InspectionSubmit",
+                "InspectionAssign",
+                "FileUpload",
+                "RentalCreate",
+                "RentalApprove",
+                "PaymentPay",
+                "PaymentManage",
+                "ClaimCreate",
+                "ClaimResolve",
+                "DepositSettle",
+                "OfferCreate",
+                "OrderManage",
+                "OrganizationManage",
+                "CategoryManage",
+                "FeeRuleManage",
+                "all"
+            ]
+        },
+        "pricing.LineItem": {
             "type": "object",
             "properties": {
-                "category": {
-                    "description": "New Fields for Search",
-                    "type": "string"
+                "amount": {
+                    "type": "number",
+                    "example": 120000
                 },
-                "created_at": {
-                    "type": "string"
+                "rate": {
+                    "type": "number",
+                    "example": 120000
                 },
-                "description": {
-                    "type": "string"
+                "tier": {
+                    "type": "string",
+                    "example": "month"
                 },
-                "id": {
-                    "type": "string"
+                "units": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "pricing.Quote": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "description": "subtotal + fee + tax + deposit",
+                    "type": "number"
                 },
-                "listing_type": {
-                    "description": "sale, rent, both",
-                    "type": "string"
+                "breakdown": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.LineItem"
+                    }
                 },
-                "location": {
-                    "description": "e.g., \"Faridabad, Haryana\"",
-                    "type": "string"
+                "days": {
+                    "type": "integer",
+                    "example": 35
                 },
-                "manufacturer": {
-                    "type": "string"
+                "end_date": {
+                    "type": "string",
+                    "example": "2025-02-05"
                 },
-                "model_number": {
+                "machine_id": {
                     "type": "string"
                 },
-                "price_for_sale": {
-                    "type": "number"
-                },
-                "rental_price_per_day": {
+                "platform_fee": {
                     "type": "number"
                 },
-                "rental_price_per_month": {
-                    "type": "number"
+                "platform_fee_rule": {
+                    "type": "string",
+                    "example": "Default commission"
                 },
                 "security_deposit": {
                     "type": "number"
                 },
-                "seller_id": {
-                    "type": "integer"
-                },
-                "specs": {
-                    "description": "UPDATED: Added swaggertype:\"object\" to fix Swagger generation",
-                    "type": "object"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-01-01"
                 },
-                "updated_at": {
-                    "type": "string"
+                "subtotal": {
+                    "type": "number"
                 },
-                "year_of_manufacture": {
-                    "type": "integer"
+                "tax": {
+                    "$ref": "#/definitions/pricing.TaxBreakdown"
                 }
             }
         },
-        "models.MaintenanceRecord": {
+        "pricing.TaxBreakdown": {
             "type": "object",
             "properties": {
-                "cost": {
+                "cgst": {
                     "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "document_url": {
-                    "description": "Optional: Link to invoice/document image",
-                    "type": "string"
+                "igst": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "string"
+                "rate": {
+                    "type": "number",
+                    "example": 18
                 },
-                "machine_id": {
-                    "type": "string"
+                "renter_state": {
+                    "type": "string",
+                    "example": "Delhi"
                 },
-                "service_date": {
-                    "type": "string"
+                "seller_state": {
+                    "type": "string",
+                    "example": "Haryana"
                 },
-                "technician": {
-                    "type": "string"
+                "sgst": {
+                    "type": "number"
                 },
-                "type": {
-                    "description": "e.g., \"Routine\", \"Repair\", \"Replacement\"",
-                    "type": "string"
+                "taxable_value": {
+                    "type": "number"
                 },
-                "updated_at": {
-                    "type": "string"
+                "total": {
+                    "type": "number"
                 }
             }
         },
-        "models.Rental": {
+        "specs.Field": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "end_date": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "machine": {
-                    "description": "Preloads (Optional, for returning full details)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Machine"
-                        }
-                    ]
-                },
-                "machine_id": {
-                    "type": "string"
+                "key": {
+                    "type": "string",
+                    "example": "spindle_speed_rpm"
                 },
-                "platform_fee": {
-                    "description": "e.g. 5%",
-                    "type": "number"
+                "label": {
+                    "type": "string",
+                    "example": "Spindle speed"
                 },
-                "renter_id": {
-                    "description": "Matches Auth Service ID type",
-                    "type": "integer"
+                "required": {
+                    "type": "boolean"
                 },
-                "security_deposit": {
-                    "type": "number"
+                "type": {
+                    "type": "string",
+                    "example": "number"
                 },
-                "start_date": {
-                    "type": "string"
+                "unit": {
+                    "type": "string",
+                    "example": "rpm"
                 },
-                "status": {
-                    "description": "Status Flow: pending -\u003e approved -\u003e active -\u003e completed (or rejected/cancelled)",
-                    "type": "string"
+                "values": {
+                    "description": "allowed values for enums",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "specs.Schema": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/specs.Field"
+                    }
                 },
-                "total_amount": {
-                    "description": "Financials",
-                    "type": "number"
+                "name": {
+                    "type": "string",
+                    "example": "CNC Mill"
                 },
-                "updated_at": {
-                    "type": "string"
+                "slug": {
+                    "type": "string",
+                    "example": "cnc-mill"
                 }
             }
         }
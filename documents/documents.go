@@ -0,0 +1,156 @@
+// Package documents recognises the document files users attach to
+// machines, maintenance records, inspections and rentals: PDFs, Word,
+// Excel and PowerPoint files (legacy and OOXML), OpenDocument files and
+// CSV. Types are identified from file content; the name only decides
+// between formats whose bytes look alike.
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Type is an accepted document format
+type Type struct {
+	Name        string
+	ContentType string
+	Ext         string
+	MaxSize     int64
+}
+
+const (
+	mb = 1 << 20
+
+	// MaxSize is the largest any type allows, so handlers can stop
+	// reading there
+	MaxSize = 20 * mb
+)
+
+var (
+	PDF  = Type{"pdf", "application/pdf", ".pdf", 20 * mb}
+	DOC  = Type{"doc", "application/msword", ".doc", 10 * mb}
+	XLS  = Type{"xls", "application/vnd.ms-excel", ".xls", 10 * mb}
+	PPT  = Type{"ppt", "application/vnd.ms-powerpoint", ".ppt", 20 * mb}
+	DOCX = Type{"docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx", 10 * mb}
+	XLSX = Type{"xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx", 10 * mb}
+	PPTX = Type{"pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx", 20 * mb}
+	ODT  = Type{"odt", "application/vnd.oasis.opendocument.text", ".odt", 10 * mb}
+	ODS  = Type{"ods", "application/vnd.oasis.opendocument.spreadsheet", ".ods", 10 * mb}
+	ODP  = Type{"odp", "application/vnd.oasis.opendocument.presentation", ".odp", 20 * mb}
+	CSV  = Type{"csv", "text/csv", ".csv", 5 * mb}
+)
+
+var (
+	ErrUnsupportedType = errors.New("only PDF, Word, Excel, PowerPoint, OpenDocument and CSV files are accepted")
+	ErrMacros          = errors.New("documents containing macros are not accepted")
+)
+
+// SizeError reports a file over its type's limit
+type SizeError struct {
+	Type Type
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("%s files may be at most %dMB", strings.ToUpper(e.Type.Name), e.Type.MaxSize/mb)
+}
+
+var (
+	pdfMagic = []byte("%PDF-")
+	oleMagic = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+	zipMagic = []byte("PK\x03\x04")
+)
+
+// Detect identifies data and checks it against its type's size limit
+func Detect(data []byte, filename string) (Type, error) {
+	t, err := detect(data, strings.ToLower(filepath.Ext(filename)))
+	if err != nil {
+		return Type{}, err
+	}
+	if int64(len(data)) > t.MaxSize {
+		return Type{}, &SizeError{Type: t}
+	}
+	return t, nil
+}
+
+func detect(data []byte, ext string) (Type, error) {
+	switch {
+	case bytes.HasPrefix(data, pdfMagic):
+		return PDF, nil
+	case bytes.HasPrefix(data, oleMagic):
+		// Legacy Office files share one container format
+		for _, t := range []Type{DOC, XLS, PPT} {
+			if ext == t.Ext {
+				return t, nil
+			}
+		}
+		return Type{}, ErrUnsupportedType
+	case bytes.HasPrefix(data, zipMagic):
+		return detectZip(data)
+	case ext == CSV.Ext && utf8.Valid(data) && bytes.IndexByte(data, 0) < 0:
+		return CSV, nil
+	}
+	return Type{}, ErrUnsupportedType
+}
+
+// detectZip tells OOXML and OpenDocument files from other zip archives by
+// the entries each format requires
+func detectZip(data []byte) (Type, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Type{}, ErrUnsupportedType
+	}
+
+	entries := map[string]*zip.File{}
+	for _, f := range archive.File {
+		name := strings.ToLower(f.Name)
+		if strings.HasSuffix(name, "vbaproject.bin") || strings.HasPrefix(name, "basic/") {
+			return Type{}, ErrMacros
+		}
+		entries[name] = f
+	}
+
+	if f, ok := entries["mimetype"]; ok {
+		mimetype, err := readSmall(f)
+		if err != nil {
+			return Type{}, ErrUnsupportedType
+		}
+		for _, t := range []Type{ODT, ODS, ODP} {
+			if mimetype == t.ContentType {
+				return t, nil
+			}
+		}
+		return Type{}, ErrUnsupportedType
+	}
+
+	if _, ok := entries["[content_types].xml"]; ok {
+		switch {
+		case entries["word/document.xml"] != nil:
+			return DOCX, nil
+		case entries["xl/workbook.xml"] != nil:
+			return XLSX, nil
+		case entries["ppt/presentation.xml"] != nil:
+			return PPTX, nil
+		}
+	}
+	return Type{}, ErrUnsupportedType
+}
+
+// readSmall reads a zip entry of at most 256 bytes
+func readSmall(f *zip.File) (string, error) {
+	if f.UncompressedSize64 > 256 {
+		return "", errors.New("entry too large")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, 256))
+	return strings.TrimSpace(string(b)), err
+}
@@ -0,0 +1,89 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func zipOf(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, body := range entries {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	ole := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, make([]byte, 512)...)
+
+	cases := []struct {
+		name     string
+		data     []byte
+		filename string
+		want     Type
+	}{
+		{"pdf", []byte("%PDF-1.7\n..."), "invoice.bin", PDF},
+		{"legacy word", ole, "manual.DOC", DOC},
+		{"legacy excel", ole, "parts.xls", XLS},
+		{"docx", zipOf(t, map[string]string{"[Content_Types].xml": "<Types/>", "word/document.xml": "<w/>"}), "report.docx", DOCX},
+		{"xlsx", zipOf(t, map[string]string{"[Content_Types].xml": "<Types/>", "xl/workbook.xml": "<wb/>"}), "sheet", XLSX},
+		{"pptx", zipOf(t, map[string]string{"[Content_Types].xml": "<Types/>", "ppt/presentation.xml": "<p/>"}), "deck.pptx", PPTX},
+		{"odt", zipOf(t, map[string]string{"mimetype": "application/vnd.oasis.opendocument.text", "content.xml": "<x/>"}), "notes.odt", ODT},
+		{"csv", []byte("part,qty\nspindle,2\n"), "parts.csv", CSV},
+	}
+	for _, tc := range cases {
+		got, err := Detect(tc.data, tc.filename)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.name, tc.want.Name, got.Name, err)
+		}
+	}
+}
+
+func TestDetect_Rejects(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		filename string
+		want     error
+	}{
+		{"executable named pdf", []byte("MZ\x90\x00\x03\x00"), "invoice.pdf", ErrUnsupportedType},
+		{"image", []byte("\x89PNG\r\n\x1a\n...."), "scan.png", ErrUnsupportedType},
+		{"plain zip", zipOf(t, map[string]string{"payload.exe": "MZ"}), "manual.docx", ErrUnsupportedType},
+		{"ole without a known extension", []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, "file.msi", ErrUnsupportedType},
+		{"binary csv", []byte("a,b\x00c"), "data.csv", ErrUnsupportedType},
+		{"macro workbook", zipOf(t, map[string]string{"[Content_Types].xml": "<Types/>", "xl/workbook.xml": "<wb/>", "xl/vbaProject.bin": "..."}), "sheet.xlsx", ErrMacros},
+	}
+	for _, tc := range cases {
+		if _, err := Detect(tc.data, tc.filename); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestDetect_SizeLimits(t *testing.T) {
+	csv := []byte(strings.Repeat("a,b\n", int(CSV.MaxSize)/4+1))
+	_, err := Detect(csv, "big.csv")
+	var sizeErr *SizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Type != CSV {
+		t.Fatalf("expected a CSV size error, got %v", err)
+	}
+	if sizeErr.Error() != "CSV files may be at most 5MB" {
+		t.Errorf("unexpected message %q", sizeErr.Error())
+	}
+
+	// The same size is fine for a PDF
+	pdf := append([]byte("%PDF-1.4\n"), csv...)
+	if _, err := Detect(pdf, "big.pdf"); err != nil {
+		t.Errorf("expected a 5MB PDF to pass, got %v", err)
+	}
+}
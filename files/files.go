@@ -0,0 +1,256 @@
+// Package files tracks the objects users upload: who uploaded them, which
+// records refer to them, and when nothing does any more. Handlers check
+// submitted URLs against the caller's uploads with Owned, record what uses
+// them with SetReferences, and the sweeper deletes uploads that stay
+// unreferenced past a grace period.
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultGrace is how long an unreferenced upload is kept, which
+	// leaves time to upload first and submit the record after
+	DefaultGrace = 24 * time.Hour
+	// DefaultInterval is how often the sweeper runs
+	DefaultInterval = time.Hour
+
+	// KeyPrefix starts every upload's object keys
+	KeyPrefix = "upload-"
+)
+
+var (
+	// Grace and Interval are set by Configure; an Interval of 0 disables
+	// the sweeper
+	Grace    = DefaultGrace
+	Interval = DefaultInterval
+)
+
+// Configure reads UPLOAD_ORPHAN_GRACE and UPLOAD_SWEEP_INTERVAL
+func Configure() error {
+	for name, dst := range map[string]*time.Duration{"UPLOAD_ORPHAN_GRACE": &Grace, "UPLOAD_SWEEP_INTERVAL": &Interval} {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = d
+		}
+	}
+	return nil
+}
+
+// Ref names a record that refers to uploads, e.g. "inspection:<id>"
+func Ref(kind string, id uuid.UUID) string {
+	return kind + ":" + id.String()
+}
+
+// NewBaseKey returns a fresh "upload-<uuid>" prefix for an upload's objects
+func NewBaseKey() string {
+	return KeyPrefix + uuid.New().String()
+}
+
+// BaseKey returns the upload an object key belongs to
+func BaseKey(key string) (string, bool) {
+	n := len(KeyPrefix) + 36
+	if len(key) < n || !strings.HasPrefix(key, KeyPrefix) {
+		return "", false
+	}
+	if _, err := uuid.Parse(key[len(KeyPrefix):n]); err != nil {
+		return "", false
+	}
+	return key[:n], true
+}
+
+// ForeignError reports a submitted URL that is not one of the caller's
+// uploads
+type ForeignError struct {
+	URL string
+}
+
+func (e *ForeignError) Error() string {
+	return fmt.Sprintf("%s is not one of your uploads", e.URL)
+}
+
+// MissingError reports a URL naming an upload that no longer exists, for
+// example because it was swept
+type MissingError struct {
+	URL string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s is no longer stored", e.URL)
+}
+
+// Owned checks that every non-empty URL names an object of an upload by
+// uploaderID. The uploads are locked, so called in the transaction that
+// then refers to them with SetReferences, the sweeper cannot delete them
+// in between.
+func Owned(db *gorm.DB, uploaderID uint, urls []string) error {
+	return check(db.Where("uploader_id = ?", uploaderID), urls)
+}
+
+// Known checks that every non-empty URL names an uploaded object,
+// whoever uploaded it. It is for callers allowed to act for others, and
+// locks the uploads like Owned.
+func Known(db *gorm.DB, urls []string) error {
+	return check(db, urls)
+}
+
+func check(scope *gorm.DB, urls []string) error {
+	keys := map[string]string{} // url -> key
+	var bases []string
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		key, ok := storage.Active.KeyFor(u)
+		if !ok {
+			return &ForeignError{URL: u}
+		}
+		base, ok := BaseKey(key)
+		if !ok {
+			return &ForeignError{URL: u}
+		}
+		keys[u] = key
+		bases = append(bases, base)
+	}
+	if len(bases) == 0 {
+		return nil
+	}
+
+	var uploads []models.Upload
+	if err := scope.Clauses(clause.Locking{Strength: "UPDATE"}).Where("base_key IN ?", bases).Find(&uploads).Error; err != nil {
+		return err
+	}
+	stored := map[string]bool{}
+	for _, up := range uploads {
+		for _, f := range Files(&up) {
+			stored[f.Key] = true
+		}
+	}
+	for u, key := range keys {
+		if !stored[key] {
+			return &ForeignError{URL: u}
+		}
+	}
+	return nil
+}
+
+// Find returns the upload url belongs to, or a ForeignError
+func Find(db *gorm.DB, url string) (*models.Upload, error) {
+	key, ok := storage.Active.KeyFor(url)
+	if !ok {
+		return nil, &ForeignError{URL: url}
+	}
+	base, ok := BaseKey(key)
+	if !ok {
+		return nil, &ForeignError{URL: url}
+	}
+	var upload models.Upload
+	err := db.First(&upload, "base_key = ?", base).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &ForeignError{URL: url}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Variant returns the upload's object with the given name, such as
+// "thumbnail"
+func Variant(u *models.Upload, name string) (models.UploadFile, bool) {
+	for _, f := range Files(u) {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return models.UploadFile{}, false
+}
+
+// Files decodes the objects stored for an upload
+func Files(u *models.Upload) []models.UploadFile {
+	var files []models.UploadFile
+	json.Unmarshal(u.Files, &files)
+	return files
+}
+
+// SetReferences makes ref refer to exactly the uploads behind urls. URLs
+// that are not uploads are ignored, so it is safe on any submitted list,
+// but a MissingError is returned for an upload that no longer exists.
+func SetReferences(tx *gorm.DB, ref string, urls []string) error {
+	if err := Release(tx, ref); err != nil {
+		return err
+	}
+
+	byBase := map[string]string{} // base -> a url naming it
+	var bases []string
+	for _, u := range urls {
+		if key, ok := storage.Active.KeyFor(u); ok {
+			if base, ok := BaseKey(key); ok {
+				if _, seen := byBase[base]; !seen {
+					byBase[base] = u
+					bases = append(bases, base)
+				}
+			}
+		}
+	}
+	if len(bases) == 0 {
+		return nil
+	}
+
+	// Lock the uploads first, so none can be swept before it is referenced
+	var found []string
+	if err := tx.Model(&models.Upload{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("base_key IN ?", bases).Pluck("base_key", &found).Error; err != nil {
+		return err
+	}
+	if len(found) < len(bases) {
+		present := map[string]bool{}
+		for _, b := range found {
+			present[b] = true
+		}
+		for _, b := range bases {
+			if !present[b] {
+				return &MissingError{URL: byBase[b]}
+			}
+		}
+	}
+
+	refs := refJSON(ref)
+	return tx.Model(&models.Upload{}).
+		Where("base_key IN ? AND NOT referenced_by @> ?::jsonb", bases, refs).
+		Updates(map[string]interface{}{
+			"referenced_by": gorm.Expr("referenced_by || ?::jsonb", refs),
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// Release drops ref from every upload it refers to. Uploads left without
+// references are swept once the grace period has passed.
+func Release(tx *gorm.DB, ref string) error {
+	return tx.Model(&models.Upload{}).
+		Where("referenced_by @> ?::jsonb", refJSON(ref)).
+		Updates(map[string]interface{}{
+			"referenced_by": gorm.Expr("referenced_by - ?::text", ref),
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+func refJSON(ref string) string {
+	b, _ := json.Marshal([]string{ref})
+	return string(b)
+}
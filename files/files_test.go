@@ -0,0 +1,65 @@
+package files
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vishwakarma-setu-backend/storage"
+)
+
+func TestBaseKey(t *testing.T) {
+	base := NewBaseKey()
+	for _, key := range []string{base, base + ".jpg", base + "-thumbnail.webp"} {
+		if got, ok := BaseKey(key); !ok || got != base {
+			t.Errorf("expected %s for %s, got %q %v", base, key, got, ok)
+		}
+	}
+	for _, key := range []string{"attachments/" + uuid.NewString() + ".pdf", "upload-not-a-uuid-at-all-but-long-enough.jpg", "upload-"} {
+		if _, ok := BaseKey(key); ok {
+			t.Errorf("expected %q not to be an upload key", key)
+		}
+	}
+}
+
+func TestRef(t *testing.T) {
+	id := uuid.MustParse("3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f")
+	if got := Ref("inspection", id); got != "inspection:3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f" {
+		t.Errorf("unexpected ref %s", got)
+	}
+}
+
+func TestKnown_RejectsForeignURLs(t *testing.T) {
+	defer func(prev storage.Storage) { storage.Active = prev }(storage.Active)
+	storage.Active = storage.NewLocal(t.TempDir(), "/uploads", "")
+
+	// These are refused before any query, so no database is needed
+	for _, url := range []string{"https://example.com/a.jpg", "/uploads/attachments/a.pdf", "/uploads/../etc/passwd"} {
+		var foreign *ForeignError
+		if err := Known(nil, []string{"", url}); !errors.As(err, &foreign) || foreign.URL != url {
+			t.Errorf("expected %s to be refused, got %v", url, err)
+		}
+	}
+	if err := Known(nil, []string{""}); err != nil {
+		t.Errorf("expected empty URLs to be skipped, got %v", err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func() { Grace, Interval = DefaultGrace, DefaultInterval }()
+
+	t.Setenv("UPLOAD_ORPHAN_GRACE", "72h")
+	t.Setenv("UPLOAD_SWEEP_INTERVAL", "0")
+	if err := Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if Grace != 72*time.Hour || Interval != 0 {
+		t.Errorf("unexpected grace %s and interval %s", Grace, Interval)
+	}
+
+	t.Setenv("UPLOAD_ORPHAN_GRACE", "a day")
+	if err := Configure(); err == nil {
+		t.Error("expected an invalid duration to be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package files
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sweepBatch is how many uploads one sweep transaction deletes
+const sweepBatch = 100
+
+// Sweep deletes uploads that have had no references since before grace
+// ago, objects first, and returns how many it removed. Rows are locked
+// while their objects are deleted, so concurrent sweepers skip each
+// other's batches; a batch that fails is retried on the next sweep.
+func Sweep(ctx context.Context, db *gorm.DB, store storage.Storage, grace time.Duration) (int, error) {
+	cutoff := time.Now().Add(-grace)
+	swept := 0
+	for {
+		var batch []models.Upload
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("referenced_by = '[]'::jsonb AND updated_at < ?", cutoff).
+				Order("updated_at").Limit(sweepBatch).
+				Find(&batch).Error; err != nil {
+				return err
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+
+			ids := make([]interface{}, len(batch))
+			for i := range batch {
+				for _, f := range Files(&batch[i]) {
+					if err := store.Delete(ctx, f.Key); err != nil {
+						return err
+					}
+				}
+				ids[i] = batch[i].ID
+			}
+			return tx.Delete(&models.Upload{}, "id IN ?", ids).Error
+		})
+		if err != nil {
+			return swept, err
+		}
+		swept += len(batch)
+		if len(batch) < sweepBatch {
+			return swept, nil
+		}
+	}
+}
+
+// RunSweeper sweeps the active backend every Interval until ctx is done.
+// It returns at once when Interval is 0.
+func RunSweeper(ctx context.Context, db *gorm.DB) {
+	if Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := Sweep(ctx, db, storage.Active, Grace)
+		if err != nil {
+			log.Printf("files: sweeping orphaned uploads: %v", err)
+		}
+		if n > 0 {
+			log.Printf("files: swept %d orphaned uploads", n)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package geo
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/*.csv
+var dataFS embed.FS
+
+var (
+	loadOnce    sync.Once
+	cities      map[string][]Place // normalised name -> places, in file order
+	pinPrefixes map[string]Place   // first three PIN digits -> district town
+)
+
+// aliases maps former and colloquial names onto the dataset's spelling
+var aliases = map[string]string{
+	"gurgaon":                   "gurugram",
+	"bombay":                    "mumbai",
+	"new bombay":                "navi mumbai",
+	"bangalore":                 "bengaluru",
+	"madras":                    "chennai",
+	"calcutta":                  "kolkata",
+	"baroda":                    "vadodara",
+	"poona":                     "pune",
+	"pimpri":                    "pimpri-chinchwad",
+	"chinchwad":                 "pimpri-chinchwad",
+	"trivandrum":                "thiruvananthapuram",
+	"cochin":                    "kochi",
+	"ernakulam":                 "kochi",
+	"calicut":                   "kozhikode",
+	"mysore":                    "mysuru",
+	"mangalore":                 "mangaluru",
+	"hubli":                     "hubballi",
+	"belgaum":                   "belagavi",
+	"gulbarga":                  "kalaburagi",
+	"bellary":                   "ballari",
+	"tumkur":                    "tumakuru",
+	"shimoga":                   "shivamogga",
+	"trichy":                    "tiruchirappalli",
+	"tuticorin":                 "thoothukudi",
+	"pondicherry":               "puducherry",
+	"allahabad":                 "prayagraj",
+	"vizag":                     "visakhapatnam",
+	"sambhajinagar":             "aurangabad",
+	"chhatrapati sambhajinagar": "aurangabad",
+	"bokaro":                    "bokaro steel city",
+	"panjim":                    "panaji",
+	"sas nagar":                 "mohali",
+	"gobindgarh":                "mandi gobindgarh",
+}
+
+// load parses the embedded dataset once. The files are part of the binary,
+// so a malformed row is a programming error.
+func load() {
+	loadOnce.Do(func() {
+		cities = map[string][]Place{}
+		for _, row := range readCSV("data/cities.csv") {
+			place := Place{City: row[0], State: row[1], Point: Point{Lat: parseCoord(row[2]), Lng: parseCoord(row[3])}}
+			key := normalise(place.City)
+			cities[key] = append(cities[key], place)
+		}
+
+		pinPrefixes = map[string]Place{}
+		for _, row := range readCSV("data/pincodes.csv") {
+			for _, place := range cities[normalise(row[1])] {
+				if place.State == row[2] {
+					pinPrefixes[row[0]] = place
+				}
+			}
+			if _, ok := pinPrefixes[row[0]]; !ok {
+				panic(fmt.Sprintf("geo: PIN prefix %s points at unknown town %s, %s", row[0], row[1], row[2]))
+			}
+		}
+	})
+}
+
+func readCSV(name string) [][]string {
+	f, err := dataFS.Open(name)
+	if err != nil {
+		panic("geo: " + err.Error())
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		panic("geo: " + name + ": " + err.Error())
+	}
+	return rows[1:] // header
+}
+
+func parseCoord(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic("geo: bad coordinate " + s)
+	}
+	return v
+}
+
+// normalise lowercases a location part and strips PIN codes and filler
+// words, so "Pune 411001" and "Pune District" both become "pune"
+func normalise(s string) string {
+	s = pinPattern.ReplaceAllString(strings.ToLower(s), " ")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '.' || r == '(' || r == ')'
+	})
+
+	kept := words[:0]
+	for _, w := range words {
+		if w != "district" && w != "dist" {
+			kept = append(kept, w)
+		}
+	}
+	s = strings.Join(kept, " ")
+
+	if alias, ok := aliases[s]; ok {
+		return alias
+	}
+	return s
+}
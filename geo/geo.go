@@ -0,0 +1,161 @@
+// Package geo places listings on the map without calling out to a geocoding
+// service. Locations are resolved against an offline dataset of Indian
+// cities and industrial towns shipped in data/, with PIN codes mapped to
+// their postal district by their first three digits. Coordinates are
+// therefore approximate to the town or district, which is enough to rank
+// listings by transport distance.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vishwakarma-setu-backend/pricing"
+)
+
+// EarthRadiusKm is the mean radius used for great-circle distances
+const EarthRadiusKm = 6371.0
+
+var (
+	ErrInvalidPoint = errors.New("coordinates must be lat,lng with latitude in [-90, 90] and longitude in [-180, 180]")
+	ErrUnknownPlace = errors.New("unknown place")
+)
+
+// Point is a position in decimal degrees
+type Point struct {
+	Lat float64 `json:"latitude" example:"28.4089"`
+	Lng float64 `json:"longitude" example:"77.3178"`
+}
+
+// Valid reports whether the point lies within the coordinate ranges
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180 &&
+		!math.IsNaN(p.Lat) && !math.IsNaN(p.Lng)
+}
+
+// Place is a city or town from the dataset
+type Place struct {
+	City  string `json:"city"`
+	State string `json:"state"`
+	Point
+}
+
+var pinPattern = regexp.MustCompile(`\b[1-9][0-9]{2}\s?[0-9]{3}\b`)
+
+// Geocode resolves a free-text location such as "Faridabad, Haryana" or
+// "Plot 12, MIDC Chakan, Pune 410501". Comma-separated parts are matched
+// against city names first (using the state, when given, to tell apart
+// towns that share a name); failing that, a PIN code in the text places it
+// in its postal district.
+func Geocode(location string) (Place, bool) {
+	load()
+
+	state := ""
+	if st, ok := pricing.StateFromLocation(location); ok {
+		state = st.Name
+	}
+
+	for _, part := range strings.Split(location, ",") {
+		candidates := cities[normalise(part)]
+		if len(candidates) == 0 {
+			continue
+		}
+		for _, place := range candidates {
+			if place.State == state {
+				return place, true
+			}
+		}
+		return candidates[0], true
+	}
+
+	if pin := pinPattern.FindString(location); pin != "" {
+		return LookupPIN(pin)
+	}
+	return Place{}, false
+}
+
+// LookupPIN places a six-digit PIN code in its postal district
+func LookupPIN(pin string) (Place, bool) {
+	load()
+
+	pin = strings.ReplaceAll(strings.TrimSpace(pin), " ", "")
+	if len(pin) != 6 {
+		return Place{}, false
+	}
+	if _, err := strconv.Atoi(pin); err != nil {
+		return Place{}, false
+	}
+	place, ok := pinPrefixes[pin[:3]]
+	return place, ok
+}
+
+// Resolve turns a "lat,lng" pair, a city name or a PIN code into a point
+func Resolve(s string) (Point, error) {
+	s = strings.TrimSpace(s)
+	if lat, lng, ok := strings.Cut(s, ","); ok {
+		latV, errLat := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		lngV, errLng := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+		if errLat == nil && errLng == nil {
+			p := Point{Lat: latV, Lng: lngV}
+			if !p.Valid() {
+				return Point{}, ErrInvalidPoint
+			}
+			return p, nil
+		}
+	}
+
+	if place, ok := Geocode(s); ok {
+		return place.Point, nil
+	}
+	return Point{}, fmt.Errorf("%w %q", ErrUnknownPlace, s)
+}
+
+// DistanceKm is the great-circle (haversine) distance between two points
+func DistanceKm(a, b Point) float64 {
+	dLat := radians(b.Lat - a.Lat)
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// DistanceSQL returns a haversine expression for the distance in km from p
+// to the point stored in latColumn/lngColumn.
+func (p Point) DistanceSQL(latColumn, lngColumn string) (string, []interface{}) {
+	sql := fmt.Sprintf("%g * 2 * asin(least(1, sqrt(power(sin(radians(%s - ?) / 2), 2) + "+
+		"cos(radians(?)) * cos(radians(%s)) * power(sin(radians(%s - ?) / 2), 2))))",
+		EarthRadiusKm, latColumn, latColumn, lngColumn)
+	return sql, []interface{}{p.Lat, p.Lat, p.Lng}
+}
+
+// WithinSQL returns a condition matching points within radiusKm of p. A
+// bounding box on the raw columns comes first so an index on them can
+// narrow the rows before the exact distance is computed.
+func (p Point) WithinSQL(latColumn, lngColumn string, radiusKm float64) (string, []interface{}) {
+	distance, args := p.DistanceSQL(latColumn, lngColumn)
+
+	// One degree of latitude is ~111.2 km everywhere; a degree of longitude
+	// shrinks with the cosine of the latitude
+	dLat := radiusKm / (EarthRadiusKm * math.Pi / 180)
+	conds := []string{fmt.Sprintf("%s BETWEEN ? AND ?", latColumn)}
+	bounds := []interface{}{p.Lat - dLat, p.Lat + dLat}
+
+	if cos := math.Cos(radians(p.Lat)); cos > 0.01 {
+		if dLng := dLat / cos; dLng < 180 {
+			conds = append(conds, fmt.Sprintf("%s BETWEEN ? AND ?", lngColumn))
+			bounds = append(bounds, p.Lng-dLng, p.Lng+dLng)
+		}
+	}
+
+	conds = append(conds, distance+" <= ?")
+	args = append(bounds, append(args, radiusKm)...)
+	return strings.Join(conds, " AND "), args
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
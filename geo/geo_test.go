@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeocode(t *testing.T) {
+	tests := []struct {
+		location string
+		city     string
+		found    bool
+	}{
+		{"Faridabad, Haryana", "Faridabad", true},
+		{"Gurgaon", "Gurugram", true},
+		{"Plot 7, Sector 58, Noida, UP", "Noida", true},
+		{"Okhla Phase 2, New Delhi 110020", "New Delhi", true},
+		{"Pune District, MH", "Pune", true},
+		{"Aurangabad, Maharashtra", "Aurangabad", true},
+		{"Gat No. 12, Kharabwadi 410 501", "", false}, // 410 is not in the PIN table
+		{"Industrial Area, 141003", "Ludhiana", true},
+		{"Somewhere", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.location, func(t *testing.T) {
+			place, ok := Geocode(tc.location)
+			if ok != tc.found || place.City != tc.city {
+				t.Errorf("expected %q (%v), got %q (%v)", tc.city, tc.found, place.City, ok)
+			}
+		})
+	}
+}
+
+func TestLookupPIN(t *testing.T) {
+	place, ok := LookupPIN("122 002")
+	if !ok || place.City != "Gurugram" {
+		t.Errorf("expected Gurugram, got %q (%v)", place.City, ok)
+	}
+	if _, ok := LookupPIN("12200"); ok {
+		t.Error("expected a five-digit PIN to be rejected")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	p, err := Resolve("28.41, 77.32")
+	if err != nil || p.Lat != 28.41 || p.Lng != 77.32 {
+		t.Errorf("expected 28.41,77.32, got %+v (%v)", p, err)
+	}
+
+	p, err = Resolve("Faridabad")
+	if err != nil || math.Abs(p.Lat-28.4089) > 1e-9 {
+		t.Errorf("expected Faridabad, got %+v (%v)", p, err)
+	}
+
+	if _, err := Resolve("95,77"); err != ErrInvalidPoint {
+		t.Errorf("expected ErrInvalidPoint, got %v", err)
+	}
+	if _, err := Resolve("Atlantis"); err == nil {
+		t.Error("expected an unknown place to fail")
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	delhi, _ := Geocode("New Delhi")
+	mumbai, _ := Geocode("Mumbai")
+
+	// New Delhi to Mumbai is roughly 1,150 km as the crow flies
+	if d := DistanceKm(delhi.Point, mumbai.Point); d < 1100 || d > 1200 {
+		t.Errorf("expected ~1150 km, got %.1f", d)
+	}
+	if d := DistanceKm(delhi.Point, delhi.Point); d != 0 {
+		t.Errorf("expected 0, got %f", d)
+	}
+}
+
+func TestDataset(t *testing.T) {
+	load()
+	for key, places := range cities {
+		for _, p := range places {
+			// Everything in the dataset lies within India's extent
+			if p.Lat < 6 || p.Lat > 37 || p.Lng < 68 || p.Lng > 98 {
+				t.Errorf("%s (%s) at %+v is outside India", p.City, key, p.Point)
+			}
+		}
+	}
+	for alias, name := range aliases {
+		if _, ok := cities[name]; !ok {
+			t.Errorf("alias %q points at unknown town %q", alias, name)
+		}
+	}
+}
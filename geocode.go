@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/geo"
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+// runGeocode handles the `geocode` subcommand: it places listings that have
+// a location but no coordinates yet, e.g. those created before geocoding.
+func runGeocode() {
+	db := config.OpenDatabase()
+
+	var machines []models.Machine
+	if err := db.Select("id", "location").
+		Where("latitude IS NULL AND longitude IS NULL AND location <> ''").
+		Find(&machines).Error; err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	placed := 0
+	for _, m := range machines {
+		place, ok := geo.Geocode(m.Location)
+		if !ok {
+			fmt.Printf("  [ ] %s: could not place %q\n", m.ID, m.Location)
+			continue
+		}
+		if err := db.Model(&models.Machine{}).Where("id = ?", m.ID).
+			Updates(map[string]interface{}{"latitude": place.Lat, "longitude": place.Lng}).Error; err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		placed++
+	}
+	fmt.Printf("📍 Geocoded %d of %d listing(s)\n", placed, len(machines))
+}
@@ -0,0 +1,246 @@
+// Package imaging validates uploaded images and renders the variants the
+// API stores. Every variant is decoded and re-encoded from pixels, so
+// metadata in the upload (EXIF GPS coordinates, camera serials, comments)
+// never reaches storage.
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"strconv"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP uploads
+)
+
+// Formats variants are written in
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// MaxPixels bounds the decoded size of an upload, so a small file that
+// declares huge dimensions cannot exhaust memory
+const MaxPixels = 50_000_000
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG and WebP images are accepted")
+	ErrTooLarge        = errors.New("image dimensions too large")
+	ErrCorrupt         = errors.New("image could not be decoded")
+)
+
+// contentTypes are the sniffed types accepted as uploads
+var contentTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/webp": true}
+
+// Variant is a rendition scaled so its longest edge is at most MaxEdge;
+// smaller images are never upscaled
+type Variant struct {
+	Name    string
+	MaxEdge int
+}
+
+// Options controls how uploads are rendered
+type Options struct {
+	Variants    []Variant
+	JPEGQuality int
+	// WebP adds a lossless WebP copy of every variant
+	WebP bool
+}
+
+// Active is used by the upload handlers. Configure sets it at startup.
+var Active = Options{
+	Variants: []Variant{
+		{Name: "thumbnail", MaxEdge: 320},
+		{Name: "medium", MaxEdge: 1024},
+		{Name: "full", MaxEdge: 2048},
+	},
+	JPEGQuality: 85,
+}
+
+// Configure reads IMAGE_WEBP (default false) and IMAGE_JPEG_QUALITY
+// (1-100, default 85)
+func Configure() error {
+	if v := os.Getenv("IMAGE_WEBP"); v != "" {
+		webp, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid IMAGE_WEBP %q", v)
+		}
+		Active.WebP = webp
+	}
+	if v := os.Getenv("IMAGE_JPEG_QUALITY"); v != "" {
+		quality, err := strconv.Atoi(v)
+		if err != nil || quality < 1 || quality > 100 {
+			return fmt.Errorf("invalid IMAGE_JPEG_QUALITY %q", v)
+		}
+		Active.JPEGQuality = quality
+	}
+	return nil
+}
+
+// Source is a decoded upload, already rotated upright
+type Source struct {
+	ContentType string
+	Width       int
+	Height      int
+	// Hash is the hex SHA-256 of the uploaded bytes
+	Hash  string
+	image image.Image
+}
+
+// Sniff returns the content type of data from its leading bytes,
+// ignoring whatever the client claimed, or ErrUnsupportedType
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !contentTypes[contentType] {
+		return "", ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Decode sniffs, bounds-checks and decodes an upload, applying its EXIF
+// orientation so the stripped variants still display the right way up
+func Decode(data []byte) (*Source, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	sum := sha256.Sum256(data)
+	bounds := img.Bounds()
+	return &Source{
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Hash:        hex.EncodeToString(sum[:]),
+		image:       img,
+	}, nil
+}
+
+// Scaled returns the image fitted within maxEdge on its longest side, for
+// callers that embed it elsewhere rather than store a variant
+func (s *Source) Scaled(maxEdge int) image.Image {
+	return scale(s.image, maxEdge)
+}
+
+// Output is one encoded variant
+type Output struct {
+	Name        string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Ext is the file extension for the output's format
+func (o Output) Ext() string {
+	switch o.Format {
+	case FormatPNG:
+		return ".png"
+	case FormatWebP:
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// Render encodes every configured variant of src. Opaque images become
+// JPEG; images with transparency stay PNG so the alpha survives.
+func (o Options) Render(src *Source) ([]Output, error) {
+	format, contentType := FormatJPEG, "image/jpeg"
+	if !opaque(src.image) {
+		format, contentType = FormatPNG, "image/png"
+	}
+
+	var outputs []Output
+	for _, v := range o.Variants {
+		scaled := scale(src.image, v.MaxEdge)
+		width, height := scaled.Bounds().Dx(), scaled.Bounds().Dy()
+
+		var buf bytes.Buffer
+		var err error
+		if format == FormatPNG {
+			err = png.Encode(&buf, scaled)
+		} else {
+			err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: o.JPEGQuality})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s variant: %w", v.Name, err)
+		}
+		outputs = append(outputs, Output{Name: v.Name, Format: format, ContentType: contentType, Width: width, Height: height, Data: buf.Bytes()})
+
+		if o.WebP {
+			var webp bytes.Buffer
+			if err := EncodeWebP(&webp, scaled); err != nil {
+				return nil, fmt.Errorf("encoding %s webp variant: %w", v.Name, err)
+			}
+			outputs = append(outputs, Output{Name: v.Name, Format: FormatWebP, ContentType: "image/webp", Width: width, Height: height, Data: webp.Bytes()})
+		}
+	}
+	return outputs, nil
+}
+
+// scale fits img within maxEdge on its longest side, keeping the aspect
+// ratio. The result is always a fresh NRGBA image, which drops any
+// decoder-specific colour model along with the metadata.
+func scale(img image.Image, maxEdge int) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxEdge > 0 && (width > maxEdge || height > maxEdge) {
+		if width >= height {
+			height = max(1, height*maxEdge/width)
+			width = maxEdge
+		} else {
+			width = max(1, width*maxEdge/height)
+			height = maxEdge
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	}
+	return dst
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
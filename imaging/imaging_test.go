@@ -0,0 +1,184 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withExif inserts an APP1 segment holding an orientation tag and a fake
+// GPS payload right after the JPEG's SOI marker
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = append(tiff, 1, 0)                                     // one IFD0 entry
+	tiff = append(tiff, 0x12, 0x01, 3, 0, 1, 0, 0, 0, 0, 0, 0, 0) // orientation, SHORT, count 1
+	binary.LittleEndian.PutUint16(tiff[len(tiff)-4:], orientation)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 18.5204N 73.8567E"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestSniff(t *testing.T) {
+	if _, err := Sniff([]byte("MZ\x90\x00\x03 this program cannot be run in DOS mode")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected an executable to be rejected, got %v", err)
+	}
+	if _, err := Sniff([]byte("just some text")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected text to be rejected, got %v", err)
+	}
+	if ct, err := Sniff(encodeJPEG(t, 4, 4)); err != nil || ct != "image/jpeg" {
+		t.Errorf("expected image/jpeg, got %q %v", ct, err)
+	}
+}
+
+func TestDecode_OrientsAndStripsExif(t *testing.T) {
+	data := withExif(encodeJPEG(t, 40, 20), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("expected orientation 6, got %d", jpegOrientation(data))
+	}
+
+	src, err := Decode(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if src.Width != 20 || src.Height != 40 {
+		t.Errorf("expected the rotated image to be 20x40, got %dx%d", src.Width, src.Height)
+	}
+	if len(src.Hash) != 64 {
+		t.Errorf("expected a hex sha256, got %q", src.Hash)
+	}
+
+	outputs, err := Active.Render(src)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, out := range outputs {
+		if bytes.Contains(out.Data, []byte("Exif")) || bytes.Contains(out.Data, []byte("GPS")) {
+			t.Errorf("%s variant still carries metadata", out.Name)
+		}
+	}
+}
+
+func TestRender_Variants(t *testing.T) {
+	src, err := Decode(encodeJPEG(t, 3000, 1500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Active
+	opts.WebP = true
+	outputs, err := opts.Render(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]int{"thumbnail": {320, 160}, "medium": {1024, 512}, "full": {2048, 1024}}
+	if len(outputs) != 2*len(want) {
+		t.Fatalf("expected a jpeg and a webp per variant, got %d outputs", len(outputs))
+	}
+	for _, out := range outputs {
+		size := want[out.Name]
+		if out.Width != size[0] || out.Height != size[1] {
+			t.Errorf("%s/%s: expected %v, got %dx%d", out.Name, out.Format, size, out.Width, out.Height)
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(out.Data))
+		if err != nil || format != out.Format || cfg.Width != out.Width {
+			t.Errorf("%s/%s: output does not decode as declared (%s, %v)", out.Name, out.Format, format, err)
+		}
+	}
+
+	// Small images are not upscaled
+	small, _ := Decode(encodeJPEG(t, 100, 50))
+	outputs, _ = Active.Render(small)
+	for _, out := range outputs {
+		if out.Width != 100 || out.Height != 50 {
+			t.Errorf("%s: expected 100x50, got %dx%d", out.Name, out.Width, out.Height)
+		}
+	}
+}
+
+func TestRender_KeepsTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.SetNRGBA(2, 2, color.NRGBA{255, 0, 0, 128})
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	src, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, _ := Active.Render(src)
+	for _, out := range outputs {
+		if out.Format != FormatPNG || out.Ext() != ".png" {
+			t.Errorf("%s: expected png for a transparent image, got %s", out.Name, out.Format)
+		}
+	}
+}
+
+func TestDecode_RejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+
+	// Rewrite the IHDR dimensions (and its CRC) to claim 100000x100000
+	ihdr := data[8:]
+	binary.BigEndian.PutUint32(ihdr[8:], 100000)
+	binary.BigEndian.PutUint32(ihdr[12:], 100000)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+
+	if _, err := Decode(data); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestDecode_Corrupt(t *testing.T) {
+	data := encodeJPEG(t, 16, 16)
+	if _, err := Decode(data[:len(data)/2]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt for a truncated jpeg, got %v", err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func(prev Options) { Active = prev }(Active)
+
+	t.Setenv("IMAGE_WEBP", "true")
+	t.Setenv("IMAGE_JPEG_QUALITY", "70")
+	if err := Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if !Active.WebP || Active.JPEGQuality != 70 {
+		t.Errorf("unexpected options %+v", Active)
+	}
+
+	t.Setenv("IMAGE_JPEG_QUALITY", "0")
+	if err := Configure(); err == nil {
+		t.Error("expected an out-of-range quality to fail")
+	}
+}
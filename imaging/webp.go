@@ -0,0 +1,412 @@
+package imaging
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// EncodeWebP writes img as a lossless WebP (VP8L) image.
+//
+// The encoder is deliberately small: it applies the subtract-green and a
+// single gradient predictor transform, then prefix-codes the residuals
+// without backward references or a colour cache. That compresses photos
+// and diagrams reasonably, but not as well as libwebp.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return errors.New("webp: dimensions must be between 1 and 16384")
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	argb := make([]uint32, width*height)
+	alphaUsed := false
+	for i := range argb {
+		p := nrgba.Pix[i*4 : i*4+4]
+		argb[i] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+		if p[3] != 0xff {
+			alphaUsed = true
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8) // VP8L signature
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if alphaUsed {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Transforms are undone by the decoder in reverse, so subtract green
+	// is applied first and the predictor sees the decorrelated pixels
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	subtractGreen(argb)
+
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(predictorBlockBits-2, 3)
+	blocksX := (width + 1<<predictorBlockBits - 1) >> predictorBlockBits
+	blocksY := (height + 1<<predictorBlockBits - 1) >> predictorBlockBits
+	modes := make([]uint32, blocksX*blocksY)
+	for i := range modes {
+		modes[i] = predictorGradient << 8 // the mode is read from the green channel
+	}
+	writeImage(bw, modes, false)
+	argb = predictResiduals(argb, width, height)
+
+	bw.write(0, 1) // no more transforms
+	writeImage(bw, argb, true)
+
+	data := bw.bytes()
+	chunk := len(data)
+	padded := chunk + chunk&1
+
+	header := make([]byte, 20)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+padded))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(chunk))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padded != chunk {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	predictorBlockBits = 9  // one predictor per 512x512 block
+	predictorGradient  = 12 // ClampAddSubtractFull(L, T, TL)
+
+	maxCodeLength        = 15
+	maxCodeLengthCodeLen = 7
+)
+
+// codeLengthOrder is the order code-length code lengths are written in
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p>>16)&0xff - g) & 0xff
+		b := (p&0xff - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// predictResiduals replaces each pixel with its difference from the
+// gradient prediction. The first pixel is predicted as opaque black, the
+// rest of the top row from the left and the left column from above.
+func predictResiduals(argb []uint32, width, height int) []uint32 {
+	out := make([]uint32, len(argb))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			var pred uint32
+			switch {
+			case x == 0 && y == 0:
+				pred = 0xff000000
+			case y == 0:
+				pred = argb[i-1]
+			case x == 0:
+				pred = argb[i-width]
+			default:
+				pred = clampAddSubtract(argb[i-1], argb[i-width], argb[i-width-1])
+			}
+			out[i] = subPixels(argb[i], pred)
+		}
+	}
+	return out
+}
+
+func clampAddSubtract(l, t, tl uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := int(l>>shift&0xff) + int(t>>shift&0xff) - int(tl>>shift&0xff)
+		out |= uint32(min(max(v, 0), 255)) << shift
+	}
+	return out
+}
+
+// subPixels subtracts per channel, modulo 256
+func subPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		out |= ((a>>shift&0xff - b>>shift&0xff) & 0xff) << shift
+	}
+	return out
+}
+
+// writeImage writes an entropy-coded image: no colour cache, one group of
+// five prefix codes (green, red, blue, alpha, distance) and literal pixels.
+// Only the main image carries the meta prefix bit.
+func writeImage(bw *bitWriter, argb []uint32, main bool) {
+	bw.write(0, 1) // no colour cache
+	if main {
+		bw.write(0, 1) // a single prefix code group
+	}
+
+	var green [280]int
+	var red, blue, alpha [256]int
+	for _, p := range argb {
+		green[p>>8&0xff]++
+		red[p>>16&0xff]++
+		blue[p&0xff]++
+		alpha[p>>24]++
+	}
+
+	codes := [4]prefixCode{
+		writeCode(bw, green[:]),
+		writeCode(bw, red[:]),
+		writeCode(bw, blue[:]),
+		writeCode(bw, alpha[:]),
+	}
+	writeCode(bw, make([]int, 40)) // distances are never used
+
+	for _, p := range argb {
+		codes[0].put(bw, int(p>>8&0xff))
+		codes[1].put(bw, int(p>>16&0xff))
+		codes[2].put(bw, int(p&0xff))
+		codes[3].put(bw, int(p>>24))
+	}
+}
+
+// prefixCode holds canonical codes, already bit-reversed for the
+// LSB-first writer
+type prefixCode struct {
+	lengths []int
+	codes   []uint32
+}
+
+func (c prefixCode) put(bw *bitWriter, symbol int) {
+	if n := c.lengths[symbol]; n > 0 {
+		bw.write(c.codes[symbol], n)
+	}
+}
+
+// writeCode builds a prefix code for the histogram and writes it. With at
+// most one symbol in use the "simple" form is used and symbols cost no
+// bits.
+func writeCode(bw *bitWriter, histogram []int) prefixCode {
+	used := 0
+	symbol := 0
+	for s, n := range histogram {
+		if n > 0 {
+			used++
+			symbol = s
+		}
+	}
+	if used <= 1 {
+		bw.write(1, 1) // simple code
+		bw.write(0, 1) // one symbol
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+		return prefixCode{lengths: make([]int, len(histogram)), codes: make([]uint32, len(histogram))}
+	}
+
+	lengths := codeLengths(histogram, maxCodeLength)
+
+	// The code lengths are themselves prefix-coded; only the literal
+	// length symbols 0-15 are used
+	lengthHistogram := make([]int, 19)
+	for _, n := range lengths {
+		lengthHistogram[n]++
+	}
+	ensureTwoSymbols(lengthHistogram)
+	lengthLengths := codeLengths(lengthHistogram, maxCodeLengthCodeLen)
+
+	count := 4
+	for i, s := range codeLengthOrder {
+		if lengthLengths[s] > 0 {
+			count = max(count, i+1)
+		}
+	}
+	bw.write(0, 1) // normal code
+	bw.write(uint32(count-4), 4)
+	for _, s := range codeLengthOrder[:count] {
+		bw.write(uint32(lengthLengths[s]), 3)
+	}
+	bw.write(0, 1) // lengths for the whole alphabet follow
+
+	lengthCode := canonical(lengthLengths)
+	for _, n := range lengths {
+		lengthCode.put(bw, n)
+	}
+	return canonical(lengths)
+}
+
+// ensureTwoSymbols gives a histogram a second symbol if it has one, since
+// a normal code needs at least two
+func ensureTwoSymbols(histogram []int) {
+	used := 0
+	for _, n := range histogram {
+		if n > 0 {
+			used++
+		}
+	}
+	for s := 0; used < 2; s++ {
+		if histogram[s] == 0 {
+			histogram[s] = 1
+			used++
+		}
+	}
+}
+
+// codeLengths returns Huffman code lengths no longer than limit. When the
+// optimal tree is too deep, rare symbols are given a larger floor count
+// and the tree rebuilt, which flattens it.
+func codeLengths(histogram []int, limit int) []int {
+	for floor := 1; ; floor *= 2 {
+		counts := make([]int, len(histogram))
+		for s, n := range histogram {
+			if n > 0 {
+				counts[s] = max(n, floor)
+			}
+		}
+		lengths := huffmanLengths(counts)
+		longest := 0
+		for _, n := range lengths {
+			longest = max(longest, n)
+		}
+		if longest <= limit {
+			return lengths
+		}
+	}
+}
+
+type node struct {
+	count       int
+	symbol      int // leaves only
+	left, right *node
+}
+
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].symbol < h[j].symbol
+}
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x any)   { *h = append(*h, x.(*node)) }
+func (h *nodeHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// huffmanLengths computes optimal code lengths for at least two used symbols
+func huffmanLengths(counts []int) []int {
+	h := &nodeHeap{}
+	for s, n := range counts {
+		if n > 0 {
+			*h = append(*h, &node{count: n, symbol: s})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a := heap.Pop(h).(*node)
+		b := heap.Pop(h).(*node)
+		heap.Push(h, &node{count: a.count + b.count, symbol: min(a.symbol, b.symbol), left: a, right: b})
+	}
+
+	lengths := make([]int, len(counts))
+	var walk func(n *node, depth int)
+	walk = func(n *node, depth int) {
+		if n.left == nil {
+			lengths[n.symbol] = depth
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(heap.Pop(h).(*node), 0)
+	return lengths
+}
+
+// canonical assigns codes in order of length, then symbol, as the decoder
+// does
+func canonical(lengths []int) prefixCode {
+	var perLength [maxCodeLength + 1]int
+	for _, n := range lengths {
+		if n > 0 {
+			perLength[n]++
+		}
+	}
+	var next [maxCodeLength + 2]uint32
+	code := uint32(0)
+	for n := 1; n <= maxCodeLength; n++ {
+		code = (code + uint32(perLength[n-1])) << 1
+		next[n] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for s, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		codes[s] = reverseBits(next[n], n)
+		next[n]++
+	}
+	return prefixCode{lengths: lengths, codes: codes}
+}
+
+func reverseBits(v uint32, n int) uint32 {
+	var out uint32
+	for i := 0; i < n; i++ {
+		out = out<<1 | v&1
+		v >>= 1
+	}
+	return out
+}
+
+// bitWriter packs values least significant bit first
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits int
+}
+
+func (b *bitWriter) write(v uint32, n int) {
+	b.acc |= uint64(v&(1<<n-1)) << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
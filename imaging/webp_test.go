@@ -0,0 +1,84 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// TestEncodeWebP_RoundTrip decodes the encoder's output with the x/image
+// decoder and expects every pixel back
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	rng.Read(noise.Pix)
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 600, 9)) // spans two predictor blocks
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 600; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y * 20), uint8(x + y), 255})
+		}
+	}
+
+	flat := image.NewNRGBA(image.Rect(0, 0, 5, 4))
+	for i := range flat.Pix {
+		flat.Pix[i] = 200
+	}
+
+	single := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	single.SetNRGBA(0, 0, color.NRGBA{1, 2, 3, 4})
+
+	for name, img := range map[string]*image.NRGBA{"noise": noise, "gradient": gradient, "flat": flat, "single": single} {
+		var buf bytes.Buffer
+		if err := EncodeWebP(&buf, img); err != nil {
+			t.Fatalf("%s: encode: %v", name, err)
+		}
+		if ct, err := Sniff(buf.Bytes()); err != nil || ct != "image/webp" {
+			t.Errorf("%s: expected image/webp, got %q %v", name, ct, err)
+		}
+
+		decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if decoded.Bounds() != img.Bounds() {
+			t.Fatalf("%s: expected bounds %v, got %v", name, img.Bounds(), decoded.Bounds())
+		}
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				want := img.NRGBAAt(x, y)
+				got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+				if got != want {
+					t.Fatalf("%s: pixel (%d,%d) = %v, want %v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCodeLengths_Limited(t *testing.T) {
+	// Fibonacci counts give the deepest possible tree
+	histogram := make([]int, 30)
+	a, b := 1, 1
+	for i := range histogram {
+		histogram[i] = a
+		a, b = b, a+b
+	}
+	lengths := codeLengths(histogram, 7)
+
+	kraft := 0.0
+	for _, n := range lengths {
+		if n > 7 || n == 0 {
+			t.Fatalf("expected lengths between 1 and 7, got %v", lengths)
+		}
+		kraft += 1 / float64(int(1)<<n)
+	}
+	if kraft != 1 {
+		t.Errorf("expected a complete code, kraft sum %v", kraft)
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/vishwakarma-setu-backend/auth"
+	"github.com/vishwakarma-setu-backend/certificate"
 	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/files"
+	"github.com/vishwakarma-setu-backend/imaging"
+	"github.com/vishwakarma-setu-backend/payments"
 	"github.com/vishwakarma-setu-backend/routes"
+	"github.com/vishwakarma-setu-backend/signing"
+	"github.com/vishwakarma-setu-backend/storage"
 
 	_ "github.com/vishwakarma-setu-backend/docs" // Import generated docs
 )
@@ -24,11 +32,57 @@ func main() {
 	// Load env
 	_ = godotenv.Load()
 
+	// Schema management: `./main migrate up|down|status`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// Backfill listing coordinates: `./main geocode`
+	if len(os.Args) > 1 && os.Args[1] == "geocode" {
+		runGeocode()
+		return
+	}
+
 	e := echo.New()
 
 	// Connect to the database
 	config.ConnectDatabase()
 
+	// Load token verification keys; refuse to start without one
+	if err := auth.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Load the key inspection reports are signed with
+	if err := signing.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Select the payment provider
+	if err := payments.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Select where uploads are stored and how images are rendered
+	if err := storage.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+	if err := imaging.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Delete uploads nothing refers to
+	if err := files.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+	go files.RunSweeper(context.Background(), config.DB)
+
+	// Where inspection certificates send readers to verify them
+	if err := certificate.Configure(); err != nil {
+		e.Logger.Fatal(err)
+	}
+
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
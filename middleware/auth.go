@@ -1,27 +1,18 @@
 package middleware
 
 import (
-	"os"
-
-	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/auth"
 )
 
-// JWTMiddlewareConfig returns the Echo JWT middleware configuration
+// JWTMiddleware verifies bearer tokens with auth.Active, which main sets up
+// with auth.Configure before routes are registered. Verified tokens are
+// stored under "user" with *jwt.MapClaims claims.
 func JWTMiddleware() echo.MiddlewareFunc {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// Fallback or panic in production
-		secret = "your-secret-key"
-	}
-
-	config := echojwt.Config{
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(jwt.MapClaims)
+	return echojwt.WithConfig(echojwt.Config{
+		ParseTokenFunc: func(c echo.Context, raw string) (interface{}, error) {
+			return auth.Active.Parse(raw)
 		},
-		SigningKey: []byte(secret),
-	}
-
-	return echojwt.WithConfig(config)
+	})
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/auth"
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/models"
+	"github.com/vishwakarma-setu-backend/policy"
+	"gorm.io/gorm"
+)
+
+// Organization resolves the organization a request acts for, taken from
+// the X-Organization-ID header or else the token's org claim, and checks
+// the caller is a member of it. The membership is stored for
+// policy.SubjectFrom. Requests naming no organization act for the user
+// alone. It must follow JWTMiddleware.
+func Organization() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			orgID := c.Request().Header.Get(policy.OrganizationHeader)
+			if orgID == "" {
+				if token, ok := c.Get("user").(*jwt.Token); ok {
+					if claims, ok := token.Claims.(*jwt.MapClaims); ok {
+						orgID = auth.Active.Organization(*claims)
+					}
+				}
+			}
+			if orgID == "" {
+				return next(c)
+			}
+
+			id, err := uuid.Parse(orgID)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid organization ID"})
+			}
+			user, err := policy.SubjectFrom(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+			}
+
+			var member models.OrganizationMember
+			err = config.DB.Where("organization_id = ? AND user_id = ?", id, user.ID).First(&member).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return policy.Deny(c, "You are not a member of this organization")
+			}
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check membership"})
+			}
+
+			c.Set(policy.OrganizationKey, &policy.Membership{OrgID: id, Role: member.Role})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/policy"
+)
+
+// RequirePermission rejects callers whose role lacks any of perms before
+// the handler runs. It must follow JWTMiddleware. Ownership still has to be
+// checked by the handler, which is the only place that loads the resource.
+func RequirePermission(perms ...policy.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := policy.SubjectFrom(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+			}
+			for _, p := range perms {
+				if !user.Can(p) {
+					return policy.Forbid(c, p, "")
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/vishwakarma-setu-backend/config"
+	"github.com/vishwakarma-setu-backend/migrations"
+)
+
+const migrateUsage = "usage: migrate up | migrate down [steps] | migrate status"
+
+// runMigrate handles the `migrate` subcommand: up, down [steps], status.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	db := config.OpenDatabase()
+
+	switch args[0] {
+	case "up":
+		count, err := migrations.Up(db)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("🔄 Applied %d migration(s)\n", count)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("❌ Invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		count, err := migrations.Down(db, steps)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("🔄 Rolled back %d migration(s)\n", count)
+
+	case "status":
+		statuses, err := migrations.Status(db)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, s := range statuses {
+			if s.Applied {
+				fmt.Printf("  [x] %04d_%s (applied %s)\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("  [ ] %04d_%s\n", s.Version, s.Name)
+			}
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
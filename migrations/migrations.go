@@ -0,0 +1,282 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration files live in sql/ and are named NNNN_description.up.sql and
+// NNNN_description.down.sql. Every version must ship both directions.
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// advisoryLockID serialises migration runs across replicas.
+const advisoryLockID = 7283164001
+
+// Migration is a single numbered schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes whether a migration has been applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// SchemaMigration is a row in the schema_migrations tracking table.
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Load returns all embedded migrations sorted by version.
+func Load() ([]Migration, error) {
+	return load(files, "sql")
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		name, direction := match[2], match[3]
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// Up applies every pending migration in order and returns how many ran.
+func Up(db *gorm.DB) (int, error) {
+	all, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureTable(db); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range all {
+		applied := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockID).Error; err != nil {
+				return err
+			}
+
+			// Another replica may have applied it while we waited for the lock
+			var existing int64
+			if err := tx.Model(&SchemaMigration{}).Where("version = ?", m.Version).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing > 0 {
+				return nil
+			}
+
+			if err := tx.Exec(m.Up).Error; err != nil {
+				return err
+			}
+			applied = true
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if applied {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Down rolls back the most recent `steps` applied migrations.
+func Down(db *gorm.DB, steps int) (int, error) {
+	all, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureTable(db); err != nil {
+		return 0, err
+	}
+
+	byVersion := map[int]Migration{}
+	for _, m := range all {
+		byVersion[m.Version] = m
+	}
+
+	count := 0
+	for count < steps {
+		done := false
+		var rolledBack SchemaMigration
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockID).Error; err != nil {
+				return err
+			}
+
+			result := tx.Order("version desc").Limit(1).Find(&rolledBack)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				done = true
+				return nil
+			}
+
+			m, ok := byVersion[rolledBack.Version]
+			if !ok {
+				return fmt.Errorf("no down migration shipped for applied version %04d", rolledBack.Version)
+			}
+
+			if err := tx.Exec(m.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("rollback of %04d_%s failed: %w", rolledBack.Version, rolledBack.Name, err)
+		}
+		if done {
+			break
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status lists every known migration alongside whether it has been applied.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range all {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+func ensureTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       varchar(255) NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`).Error
+}
+
+// appliedVersions reads the tracking table without creating it, so that
+// read-only callers (status, startup check) never modify the schema.
+func appliedVersions(db *gorm.DB) (map[int]SchemaMigration, error) {
+	applied := map[int]SchemaMigration{}
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
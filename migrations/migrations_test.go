@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	all, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load embedded migrations: %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatal("expected at least one migration")
+	}
+
+	// Versions must be contiguous so `migrate down` never skips a gap
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Errorf("expected version %d at position %d, got %d", i+1, i, m.Version)
+		}
+	}
+}
+
+func TestLoad_SortsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"sql/0002_second.down.sql": {Data: []byte("SELECT -2;")},
+		"sql/0001_first.up.sql":    {Data: []byte("SELECT 1;")},
+		"sql/0001_first.down.sql":  {Data: []byte("SELECT -1;")},
+	}
+
+	all, err := load(fsys, "sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(all))
+	}
+	if all[0].Name != "first" || all[1].Name != "second" {
+		t.Errorf("unexpected order: %s, %s", all[0].Name, all[1].Name)
+	}
+	if all[1].Down != "SELECT -2;" {
+		t.Errorf("unexpected down body: %q", all[1].Down)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"Missing Down", fstest.MapFS{
+			"sql/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+		}},
+		{"Bad File Name", fstest.MapFS{
+			"sql/first.sql": {Data: []byte("SELECT 1;")},
+		}},
+		{"Conflicting Names", fstest.MapFS{
+			"sql/0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+			"sql/0001_other.down.sql": {Data: []byte("SELECT -1;")},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := load(tc.fsys, "sql"); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+-- Baseline for databases that were previously managed by AutoMigrate:
+-- IF NOT EXISTS lets this apply cleanly on top of an existing schema.
+CREATE TABLE IF NOT EXISTS machines (
+    id                     uuid PRIMARY KEY,
+    seller_id              bigint NOT NULL,
+    title                  varchar(100) NOT NULL,
+    description            text NOT NULL,
+    manufacturer           varchar(100) NOT NULL,
+    model_number           varchar(100) NOT NULL,
+    year_of_manufacture    int NOT NULL,
+    category               varchar(50),
+    location               varchar(100),
+    status                 varchar(50) DEFAULT 'pending_inspection',
+    listing_type           varchar(50) NOT NULL,
+    price_for_sale         decimal(10,2) DEFAULT 0,
+    rental_price_per_day   decimal(10,2) DEFAULT 0,
+    rental_price_per_month decimal(10,2) DEFAULT 0,
+    security_deposit       decimal(10,2) DEFAULT 0,
+    specs                  jsonb,
+    created_at             timestamptz,
+    updated_at             timestamptz,
+    deleted_at             timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_machines_category ON machines (category);
+CREATE INDEX IF NOT EXISTS idx_machines_location ON machines (location);
+CREATE INDEX IF NOT EXISTS idx_machines_listing_type ON machines (listing_type);
+CREATE INDEX IF NOT EXISTS idx_machines_specs ON machines USING gin (specs);
+CREATE INDEX IF NOT EXISTS idx_machines_deleted_at ON machines (deleted_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rentals (
+    id               uuid PRIMARY KEY,
+    machine_id       uuid NOT NULL,
+    renter_id        bigint NOT NULL,
+    start_date       timestamptz NOT NULL,
+    end_date         timestamptz NOT NULL,
+    total_amount     decimal(10,2) NOT NULL,
+    security_deposit decimal(10,2) NOT NULL,
+    platform_fee     decimal(10,2) DEFAULT 0,
+    status           varchar(50) DEFAULT 'pending',
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz,
+    CONSTRAINT fk_rentals_machine FOREIGN KEY (machine_id) REFERENCES machines (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rentals_deleted_at ON rentals (deleted_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS inspection_reports (
+    id              uuid PRIMARY KEY,
+    machine_id      uuid NOT NULL,
+    inspector_id    bigint NOT NULL,
+    report_type     varchar(50) DEFAULT 'listing',
+    inspection_date timestamptz,
+    verdict         varchar(50),
+    summary         text,
+    report_data     jsonb,
+    media_urls      jsonb,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_inspection_reports_machine_id ON inspection_reports (machine_id);
+CREATE INDEX IF NOT EXISTS idx_inspection_reports_deleted_at ON inspection_reports (deleted_at);
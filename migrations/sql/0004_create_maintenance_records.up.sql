@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS maintenance_records (
+    id           uuid PRIMARY KEY,
+    machine_id   uuid NOT NULL,
+    service_date timestamptz,
+    type         varchar(50),
+    description  text,
+    cost         decimal(10,2),
+    technician   varchar(100),
+    document_url varchar(255),
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_records_machine_id ON maintenance_records (machine_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_records_deleted_at ON maintenance_records (deleted_at);
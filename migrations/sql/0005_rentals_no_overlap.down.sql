@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rentals_machine_dates;
+ALTER TABLE rentals DROP CONSTRAINT IF EXISTS rentals_no_overlap;
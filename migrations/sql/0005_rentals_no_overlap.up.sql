@@ -0,0 +1,12 @@
+-- Prevent two approved/active rentals of the same machine from overlapping.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE rentals
+    ADD CONSTRAINT rentals_no_overlap
+    EXCLUDE USING gist (
+        machine_id WITH =,
+        tstzrange(start_date, end_date, '[]') WITH &&
+    )
+    WHERE (status IN ('approved', 'active') AND deleted_at IS NULL);
+
+CREATE INDEX IF NOT EXISTS idx_rentals_machine_dates ON rentals (machine_id, start_date, end_date);
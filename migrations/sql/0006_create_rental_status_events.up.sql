@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS rental_status_events (
+    id          uuid PRIMARY KEY,
+    rental_id   uuid NOT NULL REFERENCES rentals (id),
+    actor_id    bigint NOT NULL,
+    actor_role  varchar(50),
+    from_status varchar(50),
+    to_status   varchar(50) NOT NULL,
+    reason      text,
+    created_at  timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_rental_status_events_rental_id ON rental_status_events (rental_id);
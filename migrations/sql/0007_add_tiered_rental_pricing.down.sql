@@ -0,0 +1,6 @@
+ALTER TABLE rentals
+    DROP COLUMN IF EXISTS price_breakdown;
+
+ALTER TABLE machines
+    DROP COLUMN IF EXISTS min_rental_days,
+    DROP COLUMN IF EXISTS rental_price_per_week;
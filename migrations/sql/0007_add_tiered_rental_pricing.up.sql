@@ -0,0 +1,6 @@
+ALTER TABLE machines
+    ADD COLUMN IF NOT EXISTS rental_price_per_week decimal(10,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS min_rental_days int DEFAULT 0;
+
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS price_breakdown jsonb;
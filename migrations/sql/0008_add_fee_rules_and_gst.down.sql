@@ -0,0 +1,10 @@
+ALTER TABLE rentals
+    DROP COLUMN IF EXISTS renter_state,
+    DROP COLUMN IF EXISTS seller_state,
+    DROP COLUMN IF EXISTS igst,
+    DROP COLUMN IF EXISTS sgst,
+    DROP COLUMN IF EXISTS cgst,
+    DROP COLUMN IF EXISTS taxable_amount,
+    DROP COLUMN IF EXISTS gst_rate;
+
+DROP TABLE IF EXISTS platform_fee_rules;
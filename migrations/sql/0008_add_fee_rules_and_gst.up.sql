@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS platform_fee_rules (
+    id           uuid PRIMARY KEY,
+    name         varchar(100) NOT NULL,
+    category     varchar(50),
+    listing_type varchar(50),
+    kind         varchar(20) NOT NULL CHECK (kind IN ('percentage', 'flat')),
+    value        decimal(10,2) NOT NULL,
+    min_fee      decimal(10,2) DEFAULT 0,
+    max_fee      decimal(10,2) DEFAULT 0,
+    priority     int DEFAULT 0,
+    active       boolean DEFAULT true,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_platform_fee_rules_category ON platform_fee_rules (category);
+CREATE INDEX IF NOT EXISTS idx_platform_fee_rules_deleted_at ON platform_fee_rules (deleted_at);
+
+-- Preserve the previous hardcoded 5% commission as the catch-all rule
+INSERT INTO platform_fee_rules (id, name, kind, value, active, created_at, updated_at)
+VALUES (gen_random_uuid(), 'Default commission', 'percentage', 5, true, now(), now());
+
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS gst_rate decimal(5,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS taxable_amount decimal(12,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cgst decimal(10,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sgst decimal(10,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS igst decimal(10,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS seller_state varchar(100),
+    ADD COLUMN IF NOT EXISTS renter_state varchar(100);
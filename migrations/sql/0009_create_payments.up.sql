@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id              uuid PRIMARY KEY,
+    rental_id       uuid NOT NULL REFERENCES rentals (id),
+    payer_id        bigint NOT NULL,
+    purpose         varchar(20) NOT NULL CHECK (purpose IN ('rental', 'deposit')),
+    provider        varchar(50) NOT NULL,
+    provider_ref    varchar(255) NOT NULL,
+    client_secret   varchar(255),
+    capture_method  varchar(20) NOT NULL,
+    amount          decimal(12,2) NOT NULL,
+    captured_amount decimal(12,2) DEFAULT 0,
+    refunded_amount decimal(12,2) DEFAULT 0,
+    currency        varchar(3) DEFAULT 'INR',
+    status          varchar(30) DEFAULT 'requires_payment',
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments (provider_ref);
+CREATE INDEX IF NOT EXISTS idx_payments_rental_id ON payments (rental_id);
+CREATE INDEX IF NOT EXISTS idx_payments_status ON payments (status);
+CREATE INDEX IF NOT EXISTS idx_payments_deleted_at ON payments (deleted_at);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS damage_claim_items;
+DROP TABLE IF EXISTS damage_claims;
+
+ALTER TABLE rentals
+    DROP COLUMN IF EXISTS deposit_settled_at,
+    DROP COLUMN IF EXISTS deposit_refund;
+
+DROP INDEX IF EXISTS idx_inspection_reports_rental_id;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS rental_id;
@@ -0,0 +1,45 @@
+ALTER TABLE inspection_reports
+    ADD COLUMN IF NOT EXISTS rental_id uuid REFERENCES rentals (id);
+
+CREATE INDEX IF NOT EXISTS idx_inspection_reports_rental_id ON inspection_reports (rental_id);
+
+ALTER TABLE rentals
+    ADD COLUMN IF NOT EXISTS deposit_refund decimal(10,2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS deposit_settled_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS damage_claims (
+    id                  uuid PRIMARY KEY,
+    rental_id           uuid NOT NULL REFERENCES rentals (id),
+    claimant_id         bigint NOT NULL,
+    check_out_report_id uuid NOT NULL REFERENCES inspection_reports (id),
+    check_in_report_id  uuid NOT NULL REFERENCES inspection_reports (id),
+    notes               text,
+    status              varchar(20) DEFAULT 'submitted',
+    deposit_amount      decimal(10,2) NOT NULL,
+    claimed_amount      decimal(10,2) NOT NULL,
+    approved_amount     decimal(10,2) DEFAULT 0,
+    refund_amount       decimal(10,2) DEFAULT 0,
+    renter_response     text,
+    resolution_notes    text,
+    resolved_by         bigint,
+    settled_at          timestamptz,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    deleted_at          timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_damage_claims_rental_id ON damage_claims (rental_id);
+CREATE INDEX IF NOT EXISTS idx_damage_claims_deleted_at ON damage_claims (deleted_at);
+
+-- A rental's deposit can only be claimed against once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_damage_claims_one_per_rental ON damage_claims (rental_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS damage_claim_items (
+    id            uuid PRIMARY KEY,
+    claim_id      uuid NOT NULL REFERENCES damage_claims (id) ON DELETE CASCADE,
+    description   text NOT NULL,
+    amount        decimal(10,2) NOT NULL CHECK (amount > 0),
+    evidence_urls jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_damage_claim_items_claim_id ON damage_claim_items (claim_id);
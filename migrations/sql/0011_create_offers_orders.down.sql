@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS offer_rounds;
+DROP TABLE IF EXISTS offers;
@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS offers (
+    id         uuid PRIMARY KEY,
+    machine_id uuid NOT NULL REFERENCES machines (id),
+    buyer_id   bigint NOT NULL,
+    seller_id  bigint NOT NULL,
+    amount     decimal(12,2) NOT NULL,
+    status     varchar(20) DEFAULT 'pending',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_offers_machine_id ON offers (machine_id);
+CREATE INDEX IF NOT EXISTS idx_offers_buyer_id ON offers (buyer_id);
+CREATE INDEX IF NOT EXISTS idx_offers_seller_id ON offers (seller_id);
+CREATE INDEX IF NOT EXISTS idx_offers_deleted_at ON offers (deleted_at);
+
+-- One open negotiation per buyer and machine
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offers_one_open ON offers (machine_id, buyer_id)
+    WHERE status IN ('pending', 'countered') AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS offer_rounds (
+    id         uuid PRIMARY KEY,
+    offer_id   uuid NOT NULL REFERENCES offers (id) ON DELETE CASCADE,
+    actor_id   bigint NOT NULL,
+    party      varchar(20) NOT NULL,
+    action     varchar(20) NOT NULL,
+    amount     decimal(12,2),
+    message    text,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_offer_rounds_offer_id ON offer_rounds (offer_id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id                      uuid PRIMARY KEY,
+    offer_id                uuid NOT NULL REFERENCES offers (id),
+    machine_id              uuid NOT NULL REFERENCES machines (id),
+    buyer_id                bigint NOT NULL,
+    seller_id               bigint NOT NULL,
+    amount                  decimal(12,2) NOT NULL,
+    status                  varchar(20) DEFAULT 'accepted',
+    carrier                 varchar(100),
+    tracking_number         varchar(100),
+    previous_machine_status varchar(50),
+    paid_at                 timestamptz,
+    shipped_at              timestamptz,
+    delivered_at            timestamptz,
+    cancelled_at            timestamptz,
+    created_at              timestamptz,
+    updated_at              timestamptz,
+    deleted_at              timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_offer_id ON orders (offer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_machine_id ON orders (machine_id);
+CREATE INDEX IF NOT EXISTS idx_orders_buyer_id ON orders (buyer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_seller_id ON orders (seller_id);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+-- A machine can only be sold once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_one_per_machine ON orders (machine_id)
+    WHERE status <> 'cancelled' AND deleted_at IS NULL;
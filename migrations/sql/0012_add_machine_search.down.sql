@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_machines_search_vector;
+ALTER TABLE machines DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,13 @@
+-- Trigram similarity for typo-tolerant manufacturer matching
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE machines
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(manufacturer, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(model_number, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_machines_search_vector ON machines USING gin (search_vector);
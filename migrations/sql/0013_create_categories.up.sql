@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id          uuid PRIMARY KEY,
+    slug        varchar(60) NOT NULL,
+    name        varchar(50) NOT NULL,
+    description text,
+    fields      jsonb NOT NULL DEFAULT '[]',
+    active      boolean DEFAULT true,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name ON categories (lower(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+-- Standard categories and their spec fields
+INSERT INTO categories (id, slug, name, fields, active, created_at, updated_at) VALUES
+    (gen_random_uuid(), 'cnc-mill', 'CNC Mill', '[{"key":"axes","label":"Axes","type":"integer","required":true},{"key":"spindle_speed_rpm","label":"Spindle speed","type":"number","unit":"rpm","required":true},{"key":"control","label":"Control","type":"enum","values":["Fanuc","Siemens","Heidenhain","Mitsubishi","Haas","Mazak","Other"],"required":false},{"key":"travel_x_mm","label":"X travel","type":"number","unit":"mm","required":false},{"key":"travel_y_mm","label":"Y travel","type":"number","unit":"mm","required":false},{"key":"travel_z_mm","label":"Z travel","type":"number","unit":"mm","required":false},{"key":"tool_capacity","label":"Tool magazine capacity","type":"integer","required":false},{"key":"spindle_taper","label":"Spindle taper","type":"enum","values":["BT30","BT40","BT50","CAT40","CAT50","HSK63"],"required":false},{"key":"power_kw","label":"Spindle power","type":"number","unit":"kW","required":false}]'::jsonb, true, now(), now()),
+    (gen_random_uuid(), 'lathe', 'Lathe', '[{"key":"swing_mm","label":"Swing over bed","type":"number","unit":"mm","required":true},{"key":"distance_between_centres_mm","label":"Distance between centres","type":"number","unit":"mm","required":true},{"key":"spindle_speed_rpm","label":"Spindle speed","type":"number","unit":"rpm","required":false},{"key":"spindle_bore_mm","label":"Spindle bore","type":"number","unit":"mm","required":false},{"key":"axes","label":"Axes","type":"integer","required":false},{"key":"cnc","label":"CNC","type":"boolean","required":false},{"key":"control","label":"Control","type":"enum","values":["Fanuc","Siemens","Heidenhain","Mitsubishi","Haas","Mazak","Other"],"required":false},{"key":"bar_feeder","label":"Bar feeder","type":"boolean","required":false}]'::jsonb, true, now(), now()),
+    (gen_random_uuid(), 'press', 'Press', '[{"key":"capacity_tonnes","label":"Capacity","type":"number","unit":"t","required":true},{"key":"drive","label":"Drive","type":"enum","values":["Hydraulic","Mechanical","Pneumatic","Servo"],"required":true},{"key":"stroke_mm","label":"Stroke","type":"number","unit":"mm","required":false},{"key":"bed_length_mm","label":"Bed length","type":"number","unit":"mm","required":false},{"key":"bed_width_mm","label":"Bed width","type":"number","unit":"mm","required":false},{"key":"strokes_per_minute","label":"Strokes per minute","type":"number","unit":"spm","required":false}]'::jsonb, true, now(), now()),
+    (gen_random_uuid(), 'injection-moulding', 'Injection Moulding', '[{"key":"clamping_force_tonnes","label":"Clamping force","type":"number","unit":"t","required":true},{"key":"shot_weight_g","label":"Shot weight","type":"number","unit":"g","required":false},{"key":"screw_diameter_mm","label":"Screw diameter","type":"number","unit":"mm","required":false},{"key":"drive","label":"Drive","type":"enum","values":["Hydraulic","Electric","Hybrid"],"required":false}]'::jsonb, true, now(), now()),
+    (gen_random_uuid(), 'laser-cutter', 'Laser Cutter', '[{"key":"power_kw","label":"Laser power","type":"number","unit":"kW","required":true},{"key":"source","label":"Source","type":"enum","values":["Fiber","CO2"],"required":true},{"key":"bed_length_mm","label":"Bed length","type":"number","unit":"mm","required":false},{"key":"bed_width_mm","label":"Bed width","type":"number","unit":"mm","required":false},{"key":"max_thickness_mm","label":"Max mild steel thickness","type":"number","unit":"mm","required":false}]'::jsonb, true, now(), now());
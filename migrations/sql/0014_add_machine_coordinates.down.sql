@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_machines_lat_lng;
+ALTER TABLE machines
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
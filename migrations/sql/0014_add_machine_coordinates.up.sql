@@ -0,0 +1,7 @@
+-- Approximate coordinates geocoded from the listing's location. Existing
+-- listings are filled in by `./main geocode`.
+ALTER TABLE machines
+    ADD COLUMN IF NOT EXISTS latitude  double precision,
+    ADD COLUMN IF NOT EXISTS longitude double precision;
+
+CREATE INDEX IF NOT EXISTS idx_machines_lat_lng ON machines (latitude, longitude);
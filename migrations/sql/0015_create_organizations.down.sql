@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_rentals_organization_id;
+DROP INDEX IF EXISTS idx_machines_organization_id;
+ALTER TABLE rentals DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE machines DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id         uuid PRIMARY KEY,
+    name       varchar(150) NOT NULL,
+    gstin      varchar(15),
+    state      varchar(100),
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id              uuid PRIMARY KEY,
+    organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         bigint NOT NULL,
+    role            varchar(20) NOT NULL DEFAULT 'member',
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_org_members_org_user ON organization_members (organization_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members (user_id);
+
+-- Machines and rentals may belong to a company rather than the individual
+-- user who created them
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS organization_id uuid REFERENCES organizations (id);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS organization_id uuid REFERENCES organizations (id);
+
+CREATE INDEX IF NOT EXISTS idx_machines_organization_id ON machines (organization_id);
+CREATE INDEX IF NOT EXISTS idx_rentals_organization_id ON rentals (organization_id);
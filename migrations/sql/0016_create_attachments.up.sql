@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id           uuid PRIMARY KEY,
+    parent_type  varchar(20) NOT NULL,
+    parent_id    uuid NOT NULL,
+    uploader_id  bigint NOT NULL,
+    kind         varchar(20) NOT NULL DEFAULT 'other',
+    visibility   varchar(10) NOT NULL DEFAULT 'private',
+    filename     varchar(255) NOT NULL,
+    content_type varchar(100) NOT NULL,
+    size         bigint NOT NULL,
+    storage_key  varchar(512) NOT NULL,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+
+-- parent_id points at machines, maintenance_records, inspection_reports
+-- or rentals depending on parent_type, so it carries no foreign key
+CREATE INDEX IF NOT EXISTS idx_attachments_parent ON attachments (parent_type, parent_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_deleted_at ON attachments (deleted_at);
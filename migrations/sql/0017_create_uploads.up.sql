@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS uploads (
+    id            uuid PRIMARY KEY,
+    uploader_id   bigint NOT NULL,
+    hash          varchar(64) NOT NULL,
+    size          bigint NOT NULL,
+    content_type  varchar(100) NOT NULL,
+    base_key      varchar(100) NOT NULL,
+    files         jsonb NOT NULL,
+    referenced_by jsonb NOT NULL DEFAULT '[]',
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uploads_base_key ON uploads (base_key);
+CREATE INDEX IF NOT EXISTS idx_uploads_uploader_hash ON uploads (uploader_id, hash);
+-- The sweeper looks for unreferenced uploads by age
+CREATE INDEX IF NOT EXISTS idx_uploads_orphaned ON uploads (updated_at) WHERE referenced_by = '[]'::jsonb;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS machine_images (
+    id            uuid PRIMARY KEY,
+    machine_id    uuid NOT NULL REFERENCES machines (id),
+    url           varchar(512) NOT NULL,
+    thumbnail_url varchar(512) NOT NULL,
+    position      integer NOT NULL DEFAULT 0,
+    is_cover      boolean NOT NULL DEFAULT false,
+    caption       varchar(200),
+    alt_text      varchar(300),
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_machine_images_machine_position ON machine_images (machine_id, position);
+-- A listing has at most one cover photo
+CREATE UNIQUE INDEX IF NOT EXISTS idx_machine_images_cover ON machine_images (machine_id) WHERE is_cover;
@@ -0,0 +1,9 @@
+ALTER TABLE machines DROP COLUMN IF EXISTS condition_grade;
+ALTER TABLE machines DROP COLUMN IF EXISTS condition_score;
+
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS section_scores;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS condition_grade;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS condition_score;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS inspection_templates;
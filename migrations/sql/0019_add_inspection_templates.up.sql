@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS inspection_templates (
+    id          uuid PRIMARY KEY,
+    category_id uuid NOT NULL REFERENCES categories (id),
+    version     integer NOT NULL,
+    sections    jsonb NOT NULL DEFAULT '[]',
+    created_by  bigint NOT NULL,
+    created_at  timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inspection_templates_version ON inspection_templates (category_id, version);
+
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS template_id uuid REFERENCES inspection_templates (id);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS condition_score decimal(4,1);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS condition_grade varchar(1);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS section_scores jsonb;
+
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS condition_score decimal(4,1);
+ALTER TABLE machines ADD COLUMN IF NOT EXISTS condition_grade varchar(1);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS inspection_requests (
+    id              uuid PRIMARY KEY,
+    machine_id      uuid NOT NULL REFERENCES machines (id),
+    requested_by    bigint NOT NULL,
+    notes           text,
+    status          varchar(20) DEFAULT 'requested',
+    inspector_id    bigint,
+    scheduled_start timestamptz,
+    scheduled_end   timestamptz,
+    report_id       uuid REFERENCES inspection_reports (id),
+    cancel_reason   text,
+    started_at      timestamptz,
+    submitted_at    timestamptz,
+    cancelled_at    timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_inspection_requests_machine_id ON inspection_requests (machine_id);
+CREATE INDEX IF NOT EXISTS idx_inspection_requests_requested_by ON inspection_requests (requested_by);
+CREATE INDEX IF NOT EXISTS idx_inspection_requests_status ON inspection_requests (status);
+CREATE INDEX IF NOT EXISTS idx_inspection_requests_inspector_id ON inspection_requests (inspector_id);
+CREATE INDEX IF NOT EXISTS idx_inspection_requests_deleted_at ON inspection_requests (deleted_at);
+-- A machine has at most one open request
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inspection_requests_open ON inspection_requests (machine_id)
+    WHERE status <> 'submitted' AND status <> 'cancelled' AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_inspection_reports_verification_code;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS verification_code;
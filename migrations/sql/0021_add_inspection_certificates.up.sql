@@ -0,0 +1,4 @@
+-- Verification code printed on a report's certificate, assigned the first
+-- time the certificate is downloaded
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS verification_code varchar(14);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inspection_reports_verification_code ON inspection_reports (verification_code);
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS inspection_reports_immutable ON inspection_reports;
+DROP FUNCTION IF EXISTS inspection_reports_immutable();
+
+DROP INDEX IF EXISTS idx_inspection_reports_previous_id;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS signing_key_id;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS signature;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS content_hash;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS revision_note;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS revised_by;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS previous_hash;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS previous_id;
+ALTER TABLE inspection_reports DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,44 @@
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS revision integer NOT NULL DEFAULT 1;
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS previous_id uuid REFERENCES inspection_reports (id);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS previous_hash varchar(64);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS revised_by bigint;
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS revision_note text;
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS content_hash varchar(64);
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS signature text;
+ALTER TABLE inspection_reports ADD COLUMN IF NOT EXISTS signing_key_id varchar(32);
+-- A report is revised at most once; later corrections revise the revision
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inspection_reports_previous_id ON inspection_reports (previous_id);
+
+-- Signed reports are immutable: only bookkeeping columns (updated_at and
+-- the certificate's verification_code) may change, and rows may not be
+-- deleted. The signatures still expose changes made around this trigger.
+CREATE OR REPLACE FUNCTION inspection_reports_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        IF OLD.signature IS NOT NULL THEN
+            RAISE EXCEPTION 'inspection report % is signed and cannot be deleted', OLD.id;
+        END IF;
+        RETURN OLD;
+    END IF;
+    IF OLD.signature IS NOT NULL AND (
+        NEW.id, NEW.machine_id, NEW.inspector_id, NEW.report_type, NEW.rental_id,
+        NEW.inspection_date, NEW.verdict, NEW.summary, NEW.report_data, NEW.media_urls,
+        NEW.template_id, NEW.condition_score, NEW.condition_grade, NEW.section_scores,
+        NEW.revision, NEW.previous_id, NEW.previous_hash, NEW.revised_by, NEW.revision_note,
+        NEW.content_hash, NEW.signature, NEW.signing_key_id, NEW.deleted_at
+    ) IS DISTINCT FROM (
+        OLD.id, OLD.machine_id, OLD.inspector_id, OLD.report_type, OLD.rental_id,
+        OLD.inspection_date, OLD.verdict, OLD.summary, OLD.report_data, OLD.media_urls,
+        OLD.template_id, OLD.condition_score, OLD.condition_grade, OLD.section_scores,
+        OLD.revision, OLD.previous_id, OLD.previous_hash, OLD.revised_by, OLD.revision_note,
+        OLD.content_hash, OLD.signature, OLD.signing_key_id, OLD.deleted_at
+    ) THEN
+        RAISE EXCEPTION 'inspection report % is signed and cannot be changed; create a revision instead', OLD.id;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS inspection_reports_immutable ON inspection_reports;
+CREATE TRIGGER inspection_reports_immutable BEFORE UPDATE OR DELETE ON inspection_reports
+    FOR EACH ROW EXECUTE FUNCTION inspection_reports_immutable();
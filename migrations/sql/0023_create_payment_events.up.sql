@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS payment_events (
+    id         varchar(255) PRIMARY KEY,
+    payment_id uuid NOT NULL REFERENCES payments (id),
+    type       varchar(50) NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_events_payment_id ON payment_events (payment_id);
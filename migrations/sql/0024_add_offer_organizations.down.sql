@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_organization_id;
+DROP INDEX IF EXISTS idx_offers_organization_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE offers DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,12 @@
+-- Company machines are sold by the organization, so its members (not the
+-- member who listed the machine) answer offers and manage orders
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS organization_id uuid REFERENCES organizations (id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS organization_id uuid REFERENCES organizations (id);
+
+UPDATE offers SET organization_id = machines.organization_id
+FROM machines WHERE machines.id = offers.machine_id AND offers.organization_id IS NULL;
+UPDATE orders SET organization_id = machines.organization_id
+FROM machines WHERE machines.id = orders.machine_id AND orders.organization_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_offers_organization_id ON offers (organization_id);
+CREATE INDEX IF NOT EXISTS idx_orders_organization_id ON orders (organization_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_machines_model_number_trgm;
+DROP INDEX IF EXISTS idx_machines_title_trgm;
+DROP INDEX IF EXISTS idx_machines_manufacturer_trgm;
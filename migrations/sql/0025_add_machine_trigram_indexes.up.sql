@@ -0,0 +1,4 @@
+-- Trigram indexes behind the typo-tolerant `? <% column` search matches
+CREATE INDEX IF NOT EXISTS idx_machines_manufacturer_trgm ON machines USING gin (manufacturer gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_machines_title_trgm ON machines USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_machines_model_number_trgm ON machines USING gin (model_number gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_uploads_uploader_hash;
+CREATE INDEX IF NOT EXISTS idx_uploads_uploader_hash ON uploads (uploader_id, hash);
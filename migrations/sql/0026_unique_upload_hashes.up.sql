@@ -0,0 +1,13 @@
+-- Uploads are deduplicated per uploader by hash; make that a constraint so
+-- concurrent uploads of the same bytes cannot both be stored. Earlier
+-- duplicates keep their files but leave deduplication, as do presigned
+-- uploads, whose hash is empty until they are completed.
+UPDATE uploads u SET hash = ''
+WHERE u.hash <> '' AND EXISTS (
+    SELECT 1 FROM uploads o
+    WHERE o.uploader_id = u.uploader_id AND o.hash = u.hash
+      AND (o.created_at, o.id) < (u.created_at, u.id)
+);
+
+DROP INDEX IF EXISTS idx_uploads_uploader_hash;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uploads_uploader_hash ON uploads (uploader_id, hash) WHERE hash <> '';
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Records an attachment can belong to
+const (
+	AttachToMachine     = "machine"
+	AttachToMaintenance = "maintenance"
+	AttachToInspection  = "inspection"
+	AttachToRental      = "rental"
+)
+
+// What an attached document is
+const (
+	DocumentInvoice     = "invoice"
+	DocumentManual      = "manual"
+	DocumentCertificate = "certificate"
+	DocumentReport      = "report"
+	DocumentOther       = "other"
+)
+
+// IsDocumentKind reports whether kind is a known document kind
+func IsDocumentKind(kind string) bool {
+	switch kind {
+	case DocumentInvoice, DocumentManual, DocumentCertificate, DocumentReport, DocumentOther:
+		return true
+	}
+	return false
+}
+
+// Public attachments are visible to anyone who can see the record they
+// belong to; private ones only to its owner and parties. Rental
+// attachments are always private.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+// Attachment is a document (invoice, manual, certificate, ...) attached
+// to a machine, maintenance record, inspection report or rental.
+// ParentType and ParentID name the record.
+type Attachment struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ParentType string    `gorm:"type:varchar(20);not null;index:idx_attachments_parent,priority:1" json:"parent_type" example:"maintenance"`
+	ParentID   uuid.UUID `gorm:"type:uuid;not null;index:idx_attachments_parent,priority:2" json:"parent_id"`
+	UploaderID uint      `gorm:"not null" json:"uploader_id"`
+
+	Kind        string `gorm:"type:varchar(20);not null;default:'other'" json:"kind" example:"invoice"`
+	Visibility  string `gorm:"type:varchar(10);not null;default:'private'" json:"visibility" example:"private"`
+	Filename    string `gorm:"type:varchar(255);not null" json:"filename" example:"service-invoice-0423.pdf"`
+	ContentType string `gorm:"type:varchar(100);not null" json:"content_type" example:"application/pdf"`
+	Size        int64  `gorm:"not null" json:"size" example:"248113"`
+	StorageKey  string `gorm:"type:varchar(512);not null" json:"-"`
+
+	// Where to download it: the public URL, or a presigned one that
+	// expires at URLExpiresAt for private attachments
+	URL          string     `gorm:"-" json:"url"`
+	URLExpiresAt *time.Time `gorm:"-" json:"url_expires_at,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vishwakarma-setu-backend/specs"
+	"gorm.io/gorm"
+)
+
+// Category is a registered machine category and the spec fields its
+// listings must provide. Machine.Category holds the category Name. Slugs
+// and names (ignoring case) are unique among categories not deleted, as in
+// migration 0013.
+type Category struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;" json:"id"`
+	Slug        string       `gorm:"type:varchar(60);not null;uniqueIndex:idx_categories_slug,where:deleted_at IS NULL" json:"slug" example:"cnc-mill"`
+	Name        string       `gorm:"type:varchar(50);not null;uniqueIndex:idx_categories_name,expression:lower(name),where:deleted_at IS NULL" json:"name" example:"CNC Mill"`
+	Description string       `gorm:"type:text" json:"description"`
+	Fields      specs.Fields `gorm:"type:jsonb;not null;default:'[]'" json:"fields"`
+	Active      bool         `gorm:"default:true" json:"active"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return
+}
+
+// Schema returns the category's spec schema
+func (c *Category) Schema() *specs.Schema {
+	return &specs.Schema{Slug: c.Slug, Name: c.Name, Fields: c.Fields}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Damage claim statuses
+const (
+	ClaimSubmitted = "submitted" // awaiting the renter's response
+	ClaimAccepted  = "accepted"  // renter accepted; deposit settled
+	ClaimDisputed  = "disputed"  // renter disputed; awaiting admin
+	ClaimResolved  = "resolved"  // admin decided; deposit settled
+)
+
+// DamageClaim is an owner's claim against a rental's security deposit,
+// backed by the check-out and check-in inspection reports.
+type DamageClaim struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	RentalID         uuid.UUID `gorm:"type:uuid;not null;index" json:"rental_id"`
+	ClaimantID       uint      `gorm:"not null" json:"claimant_id"`
+	CheckOutReportID uuid.UUID `gorm:"type:uuid;not null" json:"check_out_report_id"`
+	CheckInReportID  uuid.UUID `gorm:"type:uuid;not null" json:"check_in_report_id"`
+	Notes            string    `gorm:"type:text" json:"notes"`
+
+	Status string `gorm:"type:varchar(20);default:'submitted'" json:"status"`
+
+	// Financials
+	DepositAmount   float64    `gorm:"type:decimal(10,2);not null" json:"deposit_amount"`
+	ClaimedAmount   float64    `gorm:"type:decimal(10,2);not null" json:"claimed_amount"` // sum of items
+	ApprovedAmount  float64    `gorm:"type:decimal(10,2);default:0" json:"approved_amount"`
+	RefundAmount    float64    `gorm:"type:decimal(10,2);default:0" json:"refund_amount"` // deposit returned to renter
+	RenterResponse  string     `gorm:"type:text" json:"renter_response"`
+	ResolutionNotes string     `gorm:"type:text" json:"resolution_notes"`
+	ResolvedBy      *uint      `json:"resolved_by"`
+	SettledAt       *time.Time `json:"settled_at"`
+
+	Items []DamageClaimItem `gorm:"foreignKey:ClaimID" json:"items"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (d *DamageClaim) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+// DamageClaimItem is one itemised deduction in a claim
+type DamageClaimItem struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	ClaimID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"claim_id"`
+	Description  string         `gorm:"type:text;not null" json:"description"`
+	Amount       float64        `gorm:"type:decimal(10,2);not null" json:"amount"`
+	EvidenceURLs datatypes.JSON `gorm:"type:jsonb" json:"evidence_urls" swaggertype:"array,string"`
+}
+
+func (i *DamageClaimItem) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New()
+	return
+}
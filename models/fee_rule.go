@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fee rule kinds
+const (
+	FeeKindPercentage = "percentage"
+	FeeKindFlat       = "flat"
+)
+
+// PlatformFeeRule configures the commission charged on a rental.
+// Empty Category or ListingType match any machine; the most specific
+// active rule wins, then the highest Priority.
+type PlatformFeeRule struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Category    string    `gorm:"type:varchar(50);index" json:"category"`      // e.g., "CNC Mill"; empty = any
+	ListingType string    `gorm:"type:varchar(50)" json:"listing_type"`        // rent, both; empty = any
+	Kind        string    `gorm:"type:varchar(20);not null" json:"kind"`       // percentage, flat
+	Value       float64   `gorm:"type:decimal(10,2);not null" json:"value"`    // percent (5 = 5%) or flat amount
+	MinFee      float64   `gorm:"type:decimal(10,2);default:0" json:"min_fee"` // 0 = no floor
+	MaxFee      float64   `gorm:"type:decimal(10,2);default:0" json:"max_fee"` // 0 = no cap
+	Priority    int       `gorm:"default:0" json:"priority"`
+	Active      bool      `gorm:"default:true" json:"active"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *PlatformFeeRule) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
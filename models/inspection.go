@@ -1,6 +1,10 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,11 +12,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// Inspection verdicts. A failed report never verifies a machine.
+const (
+	VerdictPass = "Pass"
+	VerdictFail = "Fail"
+)
+
+// ParseVerdict returns the canonical spelling of a verdict, matched
+// case-insensitively, and whether it is one
+func ParseVerdict(s string) (string, bool) {
+	for _, v := range []string{VerdictPass, VerdictFail} {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
 type InspectionReport struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
 	MachineID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"machine_id"`
 	InspectorID uint           `gorm:"not null" json:"inspector_id"`
 	ReportType  string         `gorm:"type:varchar(50);default:'listing'" json:"report_type"`
+	RentalID    *uuid.UUID     `gorm:"type:uuid;index" json:"rental_id,omitempty"` // set for check_out / check_in
 
 	InspectionDate time.Time      `json:"inspection_date"`
 	Verdict        string         `gorm:"type:varchar(50)" json:"verdict"`
@@ -24,12 +46,114 @@ type InspectionReport struct {
 	// FIX: Added swaggertype:"array,string"
 	MediaURLs      datatypes.JSON `gorm:"type:jsonb" json:"media_urls" swaggertype:"array,string"`
 
+	// Set when the machine's category has an inspection checklist: the
+	// template version ReportData was checked against and the resulting
+	// condition score (0-100), grade (A-F) and per-section scores
+	TemplateID     *uuid.UUID     `gorm:"type:uuid" json:"template_id,omitempty"`
+	ConditionScore *float64       `gorm:"type:decimal(4,1)" json:"condition_score,omitempty" example:"87.5"`
+	ConditionGrade string         `gorm:"type:varchar(1)" json:"condition_grade,omitempty" example:"B"`
+	SectionScores  datatypes.JSON `gorm:"type:jsonb" json:"section_scores,omitempty" swaggertype:"array,object"`
+
+	// Printed on the report's certificate; assigned on first download
+	VerificationCode *string      `gorm:"type:varchar(14);uniqueIndex" json:"verification_code,omitempty" example:"7KQ2-M9XD-4TRA"`
+
+	// Reports are signed when created and never edited: a correction is a
+	// new revision that points at the report it replaces and records that
+	// report's ContentHash, the SHA-256 of its SignedContent
+	Revision       int            `gorm:"not null;default:1" json:"revision" example:"1"`
+	PreviousID     *uuid.UUID     `gorm:"type:uuid;uniqueIndex" json:"previous_id,omitempty"`
+	PreviousHash   string         `gorm:"type:varchar(64)" json:"previous_hash,omitempty"`
+	RevisedBy      *uint          `json:"revised_by,omitempty"`
+	RevisionNote   string         `gorm:"type:text" json:"revision_note,omitempty"`
+	ContentHash    string         `gorm:"type:varchar(64)" json:"content_hash,omitempty"`
+	Signature      string         `gorm:"type:text" json:"signature,omitempty"` // base64 Ed25519
+	SigningKeyID   string         `gorm:"type:varchar(32)" json:"signing_key_id,omitempty"`
+
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (r *InspectionReport) BeforeCreate(tx *gorm.DB) (err error) {
-	r.ID = uuid.New()
+	// Signed reports are given their ID before signing
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
 	return
-}
\ No newline at end of file
+}
+// signedReport is the canonical form of a report that is hashed and
+// signed. Field order is fixed by the struct; JSON columns are re-encoded
+// so that Postgres normalising jsonb (key order, whitespace, number
+// spelling) does not change the content.
+type signedReport struct {
+	Format         string      `json:"format"`
+	ID             uuid.UUID   `json:"id"`
+	MachineID      uuid.UUID   `json:"machine_id"`
+	InspectorID    uint        `json:"inspector_id"`
+	ReportType     string      `json:"report_type"`
+	RentalID       *uuid.UUID  `json:"rental_id"`
+	InspectionDate string      `json:"inspection_date"`
+	Verdict        string      `json:"verdict"`
+	Summary        string      `json:"summary"`
+	ReportData     interface{} `json:"report_data"`
+	MediaURLs      interface{} `json:"media_urls"`
+	TemplateID     *uuid.UUID  `json:"template_id"`
+	ConditionScore *float64    `json:"condition_score"`
+	ConditionGrade string      `json:"condition_grade"`
+	SectionScores  interface{} `json:"section_scores"`
+	Revision       int         `json:"revision"`
+	PreviousID     *uuid.UUID  `json:"previous_id"`
+	PreviousHash   string      `json:"previous_hash"`
+	RevisedBy      *uint       `json:"revised_by"`
+	RevisionNote   string      `json:"revision_note"`
+}
+
+// signedFormat versions the canonical form
+const signedFormat = "inspection-report/v1"
+
+// SignedContent is the canonical encoding of everything a report's
+// signature covers. It leaves out bookkeeping (timestamps, the
+// certificate's verification code) and the signature itself.
+func (r *InspectionReport) SignedContent() []byte {
+	content := signedReport{
+		Format:      signedFormat,
+		ID:          r.ID,
+		MachineID:   r.MachineID,
+		InspectorID: r.InspectorID,
+		ReportType:  r.ReportType,
+		RentalID:    r.RentalID,
+		// Postgres keeps microseconds
+		InspectionDate: r.InspectionDate.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		Verdict:        r.Verdict,
+		Summary:        r.Summary,
+		ReportData:     canonicalJSON(r.ReportData),
+		MediaURLs:      canonicalJSON(r.MediaURLs),
+		TemplateID:     r.TemplateID,
+		ConditionScore: r.ConditionScore,
+		ConditionGrade: r.ConditionGrade,
+		SectionScores:  canonicalJSON(r.SectionScores),
+		Revision:       r.Revision,
+		PreviousID:     r.PreviousID,
+		PreviousHash:   r.PreviousHash,
+		RevisedBy:      r.RevisedBy,
+		RevisionNote:   r.RevisionNote,
+	}
+	b, _ := json.Marshal(content)
+	return b
+}
+
+// Hash is the hex SHA-256 of the report's SignedContent
+func (r *InspectionReport) Hash() string {
+	sum := sha256.Sum256(r.SignedContent())
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON decodes a JSON column so it re-encodes with sorted keys.
+// Empty and invalid columns count as null.
+func canonicalJSON(data datatypes.JSON) interface{} {
+	var v interface{}
+	if len(data) == 0 || json.Unmarshal(data, &v) != nil {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Inspection request statuses
+const (
+	InspectionRequested  = "requested"
+	InspectionScheduled  = "scheduled"
+	InspectionInProgress = "in_progress"
+	InspectionSubmitted  = "submitted"
+	InspectionCancelled  = "cancelled"
+)
+
+// PartyInspector is the inspector assigned to an inspection request. The
+// machine's owner acts as PartySeller.
+const PartyInspector = "inspector"
+
+var (
+	ErrUnknownInspectionStatus     = errors.New("unknown inspection request status")
+	ErrInvalidInspectionTransition = errors.New("invalid inspection request status transition")
+	ErrInspectionTransitionDenied  = errors.New("you are not allowed to make this status change")
+)
+
+// inspectionTransitions maps from -> to -> parties allowed to make the
+// change. Admins may perform any listed transition; scheduling (requested
+// -> scheduled, or rescheduling) is theirs alone.
+var inspectionTransitions = map[string]map[string][]string{
+	InspectionRequested: {
+		InspectionScheduled: {},
+		InspectionCancelled: {PartySeller},
+	},
+	InspectionScheduled: {
+		InspectionScheduled:  {},
+		InspectionInProgress: {PartyInspector},
+		InspectionSubmitted:  {PartyInspector},
+		InspectionCancelled:  {PartySeller},
+	},
+	InspectionInProgress: {
+		InspectionSubmitted: {PartyInspector},
+		InspectionCancelled: {},
+	},
+}
+
+// OpenInspectionStatuses are the statuses of a request still being worked
+// on. A machine has at most one open request.
+var OpenInspectionStatuses = []string{InspectionRequested, InspectionScheduled, InspectionInProgress}
+
+// IsInspectionStatus reports whether s is one of the known request statuses
+func IsInspectionStatus(s string) bool {
+	switch s {
+	case InspectionRequested, InspectionScheduled, InspectionInProgress, InspectionSubmitted, InspectionCancelled:
+		return true
+	}
+	return false
+}
+
+// CheckInspectionTransition validates moving an inspection request from one
+// status to another on behalf of a caller acting as the given parties.
+func CheckInspectionTransition(from, to string, parties []string) error {
+	if !IsInspectionStatus(to) {
+		return ErrUnknownInspectionStatus
+	}
+
+	allowed, ok := inspectionTransitions[from][to]
+	if !ok {
+		return ErrInvalidInspectionTransition
+	}
+
+	for _, party := range parties {
+		if party == PartyAdmin {
+			return nil
+		}
+		for _, a := range allowed {
+			if party == a {
+				return nil
+			}
+		}
+	}
+
+	return ErrInspectionTransitionDenied
+}
+
+// InspectionRequest is a seller's request to have a machine awaiting
+// inspection verified. An admin assigns it an inspector and a time slot,
+// and only that inspector may submit its report.
+type InspectionRequest struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	MachineID   uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_inspection_requests_open,where:status <> 'submitted' AND status <> 'cancelled' AND deleted_at IS NULL" json:"machine_id"`
+	Machine     Machine   `gorm:"foreignKey:MachineID" json:"machine,omitempty"`
+	RequestedBy uint      `gorm:"not null;index" json:"requested_by"`
+	Notes       string    `gorm:"type:text" json:"notes"`
+
+	Status string `gorm:"type:varchar(20);default:'requested';index" json:"status"`
+
+	// Set when an admin schedules the inspection
+	InspectorID    *uint      `gorm:"index" json:"inspector_id"`
+	ScheduledStart *time.Time `json:"scheduled_start"`
+	ScheduledEnd   *time.Time `json:"scheduled_end"`
+
+	// The submitted report
+	ReportID *uuid.UUID `gorm:"type:uuid" json:"report_id"`
+
+	CancelReason string `gorm:"type:text" json:"cancel_reason,omitempty"`
+
+	StartedAt   *time.Time `json:"started_at"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *InspectionRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckInspectionTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		parties  []string
+		expected error
+	}{
+		{"Admin Schedules", InspectionRequested, InspectionScheduled, []string{PartyAdmin}, nil},
+		{"Admin Reschedules", InspectionScheduled, InspectionScheduled, []string{PartyAdmin}, nil},
+		{"Seller Cancels Request", InspectionRequested, InspectionCancelled, []string{PartySeller}, nil},
+		{"Inspector Starts", InspectionScheduled, InspectionInProgress, []string{PartyInspector}, nil},
+		{"Inspector Submits", InspectionInProgress, InspectionSubmitted, []string{PartyInspector}, nil},
+		{"Admin Cancels In Progress", InspectionInProgress, InspectionCancelled, []string{PartyAdmin}, nil},
+		{"Seller Cannot Schedule", InspectionRequested, InspectionScheduled, []string{PartySeller}, ErrInspectionTransitionDenied},
+		{"Inspector Cannot Reschedule", InspectionScheduled, InspectionScheduled, []string{PartyInspector}, ErrInspectionTransitionDenied},
+		{"Seller Cannot Start", InspectionScheduled, InspectionInProgress, []string{PartySeller}, ErrInspectionTransitionDenied},
+		{"Seller Cannot Cancel In Progress", InspectionInProgress, InspectionCancelled, []string{PartySeller}, ErrInspectionTransitionDenied},
+		{"Cannot Start Unscheduled", InspectionRequested, InspectionInProgress, []string{PartyInspector}, ErrInvalidInspectionTransition},
+		{"Cannot Reopen Submitted", InspectionSubmitted, InspectionScheduled, []string{PartyAdmin}, ErrInvalidInspectionTransition},
+		{"Typo Status", InspectionScheduled, "started", []string{PartyInspector}, ErrUnknownInspectionStatus},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckInspectionTransition(tc.from, tc.to, tc.parties)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vishwakarma-setu-backend/checklist"
+	"gorm.io/gorm"
+)
+
+// InspectionTemplate is one version of a category's inspection checklist.
+// Templates are never edited: saving a category's checklist adds the next
+// version, so every report can be traced to the checklist it was scored
+// against.
+type InspectionTemplate struct {
+	ID         uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	CategoryID uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_inspection_templates_version,priority:1" json:"category_id"`
+	Version    int                `gorm:"not null;uniqueIndex:idx_inspection_templates_version,priority:2" json:"version" example:"3"`
+	Sections   checklist.Sections `gorm:"type:jsonb;not null;default:'[]'" json:"sections"`
+	CreatedBy  uint               `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func (t *InspectionTemplate) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.New()
+	return
+}
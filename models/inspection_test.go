@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSignedContent(t *testing.T) {
+	score := 87.5
+	report := InspectionReport{
+		ID:             uuid.New(),
+		MachineID:      uuid.New(),
+		InspectorID:    3,
+		ReportType:     "listing",
+		InspectionDate: time.Date(2026, 3, 2, 11, 40, 0, 123456789, time.FixedZone("IST", 19800)),
+		Verdict:        "Pass",
+		ReportData:     []byte(`{"spindle_runout_mm": 0.0040, "spindle_noise": "pass"}`),
+		MediaURLs:      []byte(`["/uploads/a.jpg"]`),
+		ConditionScore: &score,
+		Revision:       1,
+	}
+	hash := report.Hash()
+
+	// What Postgres hands back: jsonb re-spelled, microseconds in UTC
+	stored := report
+	stored.ReportData = []byte(`{"spindle_noise":"pass","spindle_runout_mm":0.004}`)
+	stored.MediaURLs = []byte(`[ "/uploads/a.jpg" ]`)
+	stored.InspectionDate = report.InspectionDate.UTC().Truncate(time.Microsecond)
+	stored.CreatedAt = time.Now()
+	code := "7KQ2-M9XD-4TRA"
+	stored.VerificationCode = &code
+	if stored.Hash() != hash {
+		t.Errorf("storage round trip changed the hash:\n%s\n%s", report.SignedContent(), stored.SignedContent())
+	}
+
+	// Any change to the content does
+	changes := map[string]func(r *InspectionReport){
+		"verdict":       func(r *InspectionReport) { r.Verdict = "Fail" },
+		"answers":       func(r *InspectionReport) { r.ReportData = []byte(`{"spindle_noise":"fail","spindle_runout_mm":0.004}`) },
+		"photos":        func(r *InspectionReport) { r.MediaURLs = []byte(`[]`) },
+		"score":         func(r *InspectionReport) { s := 95.0; r.ConditionScore = &s },
+		"date":          func(r *InspectionReport) { r.InspectionDate = r.InspectionDate.Add(time.Second) },
+		"inspector":     func(r *InspectionReport) { r.InspectorID = 4 },
+		"chain":         func(r *InspectionReport) { r.PreviousHash = "00" },
+		"revision":      func(r *InspectionReport) { r.Revision = 2 },
+		"revised by":    func(r *InspectionReport) { id := uint(9); r.RevisedBy = &id },
+		"revision note": func(r *InspectionReport) { r.RevisionNote = "re-measured" },
+	}
+	for name, change := range changes {
+		changed := report
+		change(&changed)
+		if changed.Hash() == hash {
+			t.Errorf("changing the %s kept the hash", name)
+		}
+	}
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"Pass", VerdictPass, true},
+		{" fail ", VerdictFail, true},
+		{"PASS", VerdictPass, true},
+		{"Good", "", false},
+		{"", "", false},
+	}
+	for _, tc := range tests {
+		if got, ok := ParseVerdict(tc.in); got != tc.want || ok != tc.ok {
+			t.Errorf("ParseVerdict(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxMachineImages caps a listing's gallery
+const MaxMachineImages = 20
+
+// MachineImage is one photo in a listing's gallery. URL is one of the
+// seller's uploads and ThumbnailURL its thumbnail variant. Images are shown
+// by Position; at most one per machine is the cover.
+type MachineImage struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	MachineID    uuid.UUID `gorm:"type:uuid;not null;index:idx_machine_images_machine_position,priority:1;uniqueIndex:idx_machine_images_cover,where:is_cover" json:"machine_id"`
+	URL          string    `gorm:"type:varchar(512);not null" json:"url" example:"/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-full.jpg"`
+	ThumbnailURL string    `gorm:"type:varchar(512);not null" json:"thumbnail_url" example:"/uploads/upload-3f2a1c9e-7d4b-4c1a-9f0e-2b8d6a5c4e3f-thumbnail.jpg"`
+	Position     int       `gorm:"not null;default:0;index:idx_machine_images_machine_position,priority:2" json:"position"`
+	IsCover      bool      `gorm:"not null;default:false" json:"is_cover"`
+	Caption      string    `gorm:"type:varchar(200)" json:"caption" example:"Spindle and tool changer"`
+	AltText      string    `gorm:"type:varchar(300)" json:"alt_text" example:"Front view of a Haas VF-2 vertical machining centre"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (i *MachineImage) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.New()
+	return
+}
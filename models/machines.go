@@ -11,6 +11,8 @@ import (
 type Machine struct {
 	ID                  uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
 	SellerID            uint           `gorm:"not null" json:"seller_id"`
+	// Set when the listing belongs to a company; any member can manage it
+	OrganizationID      *uuid.UUID     `gorm:"type:uuid;index" json:"organization_id"`
 	Title               string         `gorm:"type:varchar(100);not null" json:"title"`
 	Description         string         `gorm:"type:text;not null" json:"description"`
 	Manufacturer        string         `gorm:"type:varchar(100);not null" json:"manufacturer"`
@@ -20,17 +22,39 @@ type Machine struct {
 	// New Fields for Search
 	Category            string         `gorm:"type:varchar(50);index" json:"category"`   // e.g., "CNC Mill", "Lathe"
 	Location            string         `gorm:"type:varchar(100);index" json:"location"`  // e.g., "Faridabad, Haryana"
+	// Geocoded from Location unless the seller gives exact coordinates
+	Latitude            *float64       `gorm:"type:double precision;index:idx_machines_lat_lng,priority:1" json:"latitude" example:"28.4089"`
+	Longitude           *float64       `gorm:"type:double precision;index:idx_machines_lat_lng,priority:2" json:"longitude" example:"77.3178"`
 
 	Status              string         `gorm:"type:varchar(50);default:'pending_inspection'" json:"status"`
 	ListingType         string         `gorm:"type:varchar(50);not null;index" json:"listing_type"` // sale, rent, both
 
 	PriceForSale        float64        `gorm:"type:decimal(10,2);default:0" json:"price_for_sale"`
 	RentalPricePerDay   float64        `gorm:"type:decimal(10,2);default:0" json:"rental_price_per_day"`
+	RentalPricePerWeek  float64        `gorm:"type:decimal(10,2);default:0" json:"rental_price_per_week"`
 	RentalPricePerMonth float64        `gorm:"type:decimal(10,2);default:0" json:"rental_price_per_month"`
+	MinRentalDays       int            `gorm:"type:int;default:0" json:"min_rental_days"` // 0 = no minimum
 	SecurityDeposit     float64        `gorm:"type:decimal(10,2);default:0" json:"security_deposit"`
 
 	// UPDATED: Added swaggertype:"object" to fix Swagger generation
 	Specs               datatypes.JSON `gorm:"type:jsonb;index:,type:gin" json:"specs" swaggertype:"object"`
+
+	// Full-text search document, maintained by Postgres. Title and manufacturer
+	// weigh most, then model number and category, then the description.
+	SearchVector        string         `gorm:"type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(manufacturer, '')), 'A') || setweight(to_tsvector('english', coalesce(model_number, '')), 'B') || setweight(to_tsvector('english', coalesce(category, '')), 'B') || setweight(to_tsvector('english', coalesce(description, '')), 'C')) STORED;index:,type:gin;->:false;<-:false" json:"-"`
+	// Search relevance, only set on keyword search results
+	Relevance           float64        `gorm:"->;-:migration" json:"relevance,omitempty"`
+	// Kilometres from the search point, only set on results of a "near" search
+	DistanceKm          *float64       `gorm:"->;-:migration" json:"distance_km,omitempty"`
+
+	// Condition from the latest scored inspection report
+	ConditionScore      *float64       `gorm:"type:decimal(4,1)" json:"condition_score" example:"87.5"`
+	ConditionGrade      string         `gorm:"type:varchar(1)" json:"condition_grade" example:"B"`
+
+	// Photo gallery, ordered by position; only loaded on a single listing
+	Images              []MachineImage `gorm:"foreignKey:MachineID" json:"images,omitempty"`
+	// Thumbnail of the cover photo, only set on listing results
+	CoverThumbnailURL   *string        `gorm:"->;-:migration" json:"cover_thumbnail_url,omitempty"`
 	
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
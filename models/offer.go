@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Offer statuses. An open offer is waiting on one side: pending waits on
+// the seller, countered waits on the buyer.
+const (
+	OfferPending   = "pending"
+	OfferCountered = "countered"
+	OfferAccepted  = "accepted"
+	OfferRejected  = "rejected"
+	OfferWithdrawn = "withdrawn"
+)
+
+// Offer actions
+const (
+	OfferActionAccept   = "accept"
+	OfferActionReject   = "reject"
+	OfferActionCounter  = "counter"
+	OfferActionWithdraw = "withdraw"
+)
+
+// Parties to a sale
+const (
+	PartyBuyer  = "buyer"
+	PartySeller = "seller"
+)
+
+// OpenOfferStatuses are the statuses in which an offer can still be answered
+var OpenOfferStatuses = []string{OfferPending, OfferCountered}
+
+var (
+	ErrUnknownOfferAction = errors.New("action must be accept, reject, counter or withdraw")
+	ErrOfferClosed        = errors.New("offer is no longer open")
+	ErrNotYourTurn        = errors.New("offer is waiting on the other party")
+	ErrInvalidOfferAmount = errors.New("amount must be greater than zero")
+)
+
+// Offer is a buyer's negotiation to purchase a machine listed for sale.
+// Amount is the price currently on the table.
+type Offer struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	MachineID uuid.UUID `gorm:"type:uuid;not null;index" json:"machine_id"`
+	Machine   Machine   `gorm:"foreignKey:MachineID" json:"machine,omitempty"`
+	BuyerID   uint      `gorm:"not null;index" json:"buyer_id"`
+	SellerID  uint      `gorm:"not null;index" json:"seller_id"`
+	// Set when the machine belongs to a company; its members answer for the seller
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
+
+	Amount float64 `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Status string  `gorm:"type:varchar(20);default:'pending'" json:"status"`
+
+	Rounds []OfferRound `gorm:"foreignKey:OfferID" json:"rounds,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (o *Offer) BeforeCreate(tx *gorm.DB) (err error) {
+	o.ID = uuid.New()
+	return
+}
+
+// IsOpen reports whether the offer can still be answered
+func (o *Offer) IsOpen() bool {
+	return o.Status == OfferPending || o.Status == OfferCountered
+}
+
+// AwaitingParty is the side that must respond next, or "" once closed
+func (o *Offer) AwaitingParty() string {
+	switch o.Status {
+	case OfferPending:
+		return PartySeller
+	case OfferCountered:
+		return PartyBuyer
+	}
+	return ""
+}
+
+// Respond applies party's action to the offer. Accepting, rejecting and
+// countering are only allowed on your turn; the buyer may withdraw at any
+// time while the offer is open.
+func (o *Offer) Respond(party, action string, amount float64) error {
+	if !o.IsOpen() {
+		return ErrOfferClosed
+	}
+
+	if action == OfferActionWithdraw {
+		if party != PartyBuyer {
+			return ErrUnknownOfferAction
+		}
+		o.Status = OfferWithdrawn
+		return nil
+	}
+
+	if party != o.AwaitingParty() {
+		return ErrNotYourTurn
+	}
+
+	switch action {
+	case OfferActionAccept:
+		o.Status = OfferAccepted
+	case OfferActionReject:
+		o.Status = OfferRejected
+	case OfferActionCounter:
+		if amount <= 0 {
+			return ErrInvalidOfferAmount
+		}
+		o.Amount = amount
+		if party == PartySeller {
+			o.Status = OfferCountered
+		} else {
+			o.Status = OfferPending
+		}
+	default:
+		return ErrUnknownOfferAction
+	}
+	return nil
+}
+
+// OfferRound records one move in a negotiation
+type OfferRound struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OfferID   uuid.UUID `gorm:"type:uuid;not null;index" json:"offer_id"`
+	ActorID   uint      `gorm:"not null" json:"actor_id"`
+	Party     string    `gorm:"type:varchar(20);not null" json:"party"`  // buyer, seller
+	Action    string    `gorm:"type:varchar(20);not null" json:"action"` // offer, counter, accept, reject, withdraw
+	Amount    float64   `gorm:"type:decimal(12,2)" json:"amount"`
+	Message   string    `gorm:"type:text" json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *OfferRound) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
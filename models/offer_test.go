@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOfferRespond(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		party, action  string
+		amount         float64
+		expected       error
+		expectedStatus string
+	}{
+		{"Seller Accepts", OfferPending, PartySeller, OfferActionAccept, 0, nil, OfferAccepted},
+		{"Seller Counters", OfferPending, PartySeller, OfferActionCounter, 95000, nil, OfferCountered},
+		{"Buyer Counters Back", OfferCountered, PartyBuyer, OfferActionCounter, 90000, nil, OfferPending},
+		{"Buyer Accepts Counter", OfferCountered, PartyBuyer, OfferActionAccept, 0, nil, OfferAccepted},
+		{"Buyer Withdraws Out Of Turn", OfferPending, PartyBuyer, OfferActionWithdraw, 0, nil, OfferWithdrawn},
+		{"Buyer Cannot Accept Own Offer", OfferPending, PartyBuyer, OfferActionAccept, 0, ErrNotYourTurn, OfferPending},
+		{"Seller Cannot Withdraw", OfferPending, PartySeller, OfferActionWithdraw, 0, ErrUnknownOfferAction, OfferPending},
+		{"Counter Needs Amount", OfferPending, PartySeller, OfferActionCounter, 0, ErrInvalidOfferAmount, OfferPending},
+		{"Closed Offer", OfferRejected, PartyBuyer, OfferActionCounter, 1000, ErrOfferClosed, OfferRejected},
+		{"Unknown Action", OfferPending, PartySeller, "haggle", 0, ErrUnknownOfferAction, OfferPending},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			offer := Offer{Status: tc.status, Amount: 100000}
+			err := offer.Respond(tc.party, tc.action, tc.amount)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+			if offer.Status != tc.expectedStatus {
+				t.Errorf("expected status %s, got %s", tc.expectedStatus, offer.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MachineSold is the status of a machine whose sale has been agreed.
+// Sold machines are hidden from listings and cannot be rented.
+const MachineSold = "sold"
+
+// Order statuses
+const (
+	OrderAccepted  = "accepted"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+)
+
+var (
+	ErrUnknownOrderStatus     = errors.New("unknown order status")
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+	ErrOrderTransitionDenied  = errors.New("you are not allowed to make this status change")
+)
+
+// orderTransitions maps from -> to -> parties allowed to make the change.
+// Admins may perform any listed transition.
+var orderTransitions = map[string]map[string][]string{
+	OrderAccepted: {
+		OrderPaid:      {PartySeller}, // seller confirms receipt of payment
+		OrderCancelled: {PartyBuyer, PartySeller},
+	},
+	OrderPaid: {
+		OrderShipped: {PartySeller},
+	},
+	OrderShipped: {
+		OrderDelivered: {PartyBuyer},
+	},
+}
+
+// IsOrderStatus reports whether s is one of the known order statuses
+func IsOrderStatus(s string) bool {
+	switch s {
+	case OrderAccepted, OrderPaid, OrderShipped, OrderDelivered, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+// CheckOrderTransition validates moving an order from one status to another
+// on behalf of a caller acting as the given parties.
+func CheckOrderTransition(from, to string, parties []string) error {
+	if !IsOrderStatus(to) {
+		return ErrUnknownOrderStatus
+	}
+
+	allowed, ok := orderTransitions[from][to]
+	if !ok {
+		return ErrInvalidOrderTransition
+	}
+
+	for _, party := range parties {
+		if party == PartyAdmin {
+			return nil
+		}
+		for _, a := range allowed {
+			if party == a {
+				return nil
+			}
+		}
+	}
+
+	return ErrOrderTransitionDenied
+}
+
+// Order is an agreed sale of a machine, created when an offer is accepted
+type Order struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OfferID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"offer_id"`
+	MachineID uuid.UUID `gorm:"type:uuid;not null;index" json:"machine_id"`
+	Machine   Machine   `gorm:"foreignKey:MachineID" json:"machine,omitempty"`
+	BuyerID   uint      `gorm:"not null;index" json:"buyer_id"`
+	SellerID  uint      `gorm:"not null;index" json:"seller_id"`
+	// Set when a company sold the machine; its members act for the seller
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
+
+	Amount float64 `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Status string  `gorm:"type:varchar(20);default:'accepted'" json:"status"`
+
+	// Shipping details, set when the order is shipped
+	Carrier        string `gorm:"type:varchar(100)" json:"carrier"`
+	TrackingNumber string `gorm:"type:varchar(100)" json:"tracking_number"`
+
+	// Machine status before the sale, restored if the order is cancelled
+	PreviousMachineStatus string `gorm:"type:varchar(50)" json:"-"`
+
+	PaidAt      *time.Time `json:"paid_at"`
+	ShippedAt   *time.Time `json:"shipped_at"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (o *Order) BeforeCreate(tx *gorm.DB) (err error) {
+	o.ID = uuid.New()
+	return
+}
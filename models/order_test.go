@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckOrderTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		parties  []string
+		expected error
+	}{
+		{"Seller Confirms Payment", OrderAccepted, OrderPaid, []string{PartySeller}, nil},
+		{"Buyer Cancels", OrderAccepted, OrderCancelled, []string{PartyBuyer}, nil},
+		{"Seller Ships", OrderPaid, OrderShipped, []string{PartySeller}, nil},
+		{"Buyer Confirms Delivery", OrderShipped, OrderDelivered, []string{PartyBuyer}, nil},
+		{"Admin Overrides Party", OrderShipped, OrderDelivered, []string{PartyAdmin}, nil},
+		{"Buyer Cannot Mark Paid", OrderAccepted, OrderPaid, []string{PartyBuyer}, ErrOrderTransitionDenied},
+		{"Seller Cannot Confirm Delivery", OrderShipped, OrderDelivered, []string{PartySeller}, ErrOrderTransitionDenied},
+		{"Cannot Cancel After Payment", OrderPaid, OrderCancelled, []string{PartyBuyer}, ErrInvalidOrderTransition},
+		{"Skip To Shipped", OrderAccepted, OrderShipped, []string{PartySeller}, ErrInvalidOrderTransition},
+		{"Typo Status", OrderAccepted, "payed", []string{PartySeller}, ErrUnknownOrderStatus},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckOrderTransition(tc.from, tc.to, tc.parties)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Member roles within an organization. Every member acts for the company
+// on its machines and rentals; owners and admins also manage membership,
+// and only owners can appoint or remove other owners.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// IsOrgRole reports whether role is a known member role
+func IsOrgRole(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin || role == OrgRoleMember
+}
+
+// Organization is a company that owns machines and rents them. Users act
+// for it by sending its ID in the X-Organization-ID header (or an org
+// claim in their token).
+type Organization struct {
+	ID    uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Name  string    `gorm:"type:varchar(150);not null" json:"name" example:"Shakti Precision Tools Pvt Ltd"`
+	GSTIN string    `gorm:"type:varchar(15)" json:"gstin" example:"06AABCS1429B1Z5"`
+	State string    `gorm:"type:varchar(100)" json:"state" example:"Haryana"`
+
+	Members []OrganizationMember `gorm:"foreignKey:OrganizationID" json:"members,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) (err error) {
+	o.ID = uuid.New()
+	return
+}
+
+// OrganizationMember links a user to an organization with a member role
+type OrganizationMember struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_org_members_org_user,priority:1" json:"organization_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_org_members_org_user,priority:2;index" json:"user_id"`
+	Role           string    `gorm:"type:varchar(20);not null;default:'member'" json:"role" example:"member"`
+
+	// Only set when listing a user's own memberships
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.New()
+	return
+}
+
+// CanManageMembers reports whether the member may add and remove others
+func (m *OrganizationMember) CanManageMembers() bool {
+	return m.Role == OrgRoleOwner || m.Role == OrgRoleAdmin
+}
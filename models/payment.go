@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment purposes
+const (
+	PaymentForRental  = "rental"  // rental charge incl. platform fee and GST
+	PaymentForDeposit = "deposit" // security deposit, held until settlement
+)
+
+// Payment statuses
+const (
+	PaymentRequiresPayment   = "requires_payment"
+	PaymentAuthorized        = "authorized" // funds held, not yet captured
+	PaymentCaptured          = "captured"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+	PaymentCancelled         = "cancelled" // hold released or intent voided
+	PaymentFailed            = "failed"
+)
+
+// Payment is a payment intent with the provider, tied to a rental
+type Payment struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	RentalID uuid.UUID `gorm:"type:uuid;not null;index" json:"rental_id"`
+	PayerID  uint      `gorm:"not null" json:"payer_id"`
+	Purpose  string    `gorm:"type:varchar(20);not null" json:"purpose"` // rental, deposit
+
+	Provider      string `gorm:"type:varchar(50);not null" json:"provider"`
+	ProviderRef   string `gorm:"type:varchar(255);not null;uniqueIndex" json:"provider_ref"`
+	ClientSecret  string `gorm:"type:varchar(255)" json:"client_secret,omitempty"`
+	CaptureMethod string `gorm:"type:varchar(20);not null" json:"capture_method"` // automatic, manual
+
+	Amount         float64 `gorm:"type:decimal(12,2);not null" json:"amount"`
+	CapturedAmount float64 `gorm:"type:decimal(12,2);default:0" json:"captured_amount"`
+	RefundedAmount float64 `gorm:"type:decimal(12,2);default:0" json:"refunded_amount"`
+	Currency       string  `gorm:"type:varchar(3);default:'INR'" json:"currency"`
+
+	Status string `gorm:"type:varchar(30);default:'requires_payment';index" json:"status"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (p *Payment) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
+
+// PaymentEvent records a webhook event that has been applied, so that
+// deliveries the provider retries are applied only once
+type PaymentEvent struct {
+	ID        string    `gorm:"type:varchar(255);primary_key" json:"id"` // the provider's event ID
+	PaymentID uuid.UUID `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Type      string    `gorm:"type:varchar(50);not null" json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
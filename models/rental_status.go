@@ -0,0 +1,101 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Rental statuses
+const (
+	RentalPending   = "pending"
+	RentalApproved  = "approved"
+	RentalActive    = "active"
+	RentalCompleted = "completed"
+	RentalRejected  = "rejected"
+	RentalCancelled = "cancelled"
+)
+
+// Parties to a rental, used to decide who may make a transition
+const (
+	PartyOwner  = "owner"
+	PartyRenter = "renter"
+	PartyAdmin  = "admin"
+)
+
+var (
+	ErrUnknownRentalStatus     = errors.New("unknown rental status")
+	ErrInvalidRentalTransition = errors.New("invalid rental status transition")
+	ErrRentalTransitionDenied  = errors.New("you are not allowed to make this status change")
+)
+
+// rentalTransitions maps from -> to -> parties allowed to make the change.
+// Admins may perform any listed transition.
+var rentalTransitions = map[string]map[string][]string{
+	RentalPending: {
+		RentalApproved:  {PartyOwner},
+		RentalRejected:  {PartyOwner},
+		RentalCancelled: {PartyRenter},
+	},
+	RentalApproved: {
+		RentalActive:    {PartyOwner},
+		RentalCancelled: {PartyRenter, PartyOwner},
+	},
+	RentalActive: {
+		RentalCompleted: {PartyOwner},
+	},
+}
+
+// IsRentalStatus reports whether s is one of the known rental statuses
+func IsRentalStatus(s string) bool {
+	switch s {
+	case RentalPending, RentalApproved, RentalActive, RentalCompleted, RentalRejected, RentalCancelled:
+		return true
+	}
+	return false
+}
+
+// CheckRentalTransition validates moving a rental from one status to another
+// on behalf of a caller acting as the given parties.
+func CheckRentalTransition(from, to string, parties []string) error {
+	if !IsRentalStatus(to) {
+		return ErrUnknownRentalStatus
+	}
+
+	allowed, ok := rentalTransitions[from][to]
+	if !ok {
+		return ErrInvalidRentalTransition
+	}
+
+	for _, party := range parties {
+		if party == PartyAdmin {
+			return nil
+		}
+		for _, a := range allowed {
+			if party == a {
+				return nil
+			}
+		}
+	}
+
+	return ErrRentalTransitionDenied
+}
+
+// RentalStatusEvent is an audit record of a single rental status change
+type RentalStatusEvent struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	RentalID   uuid.UUID `gorm:"type:uuid;not null;index" json:"rental_id"`
+	ActorID    uint      `gorm:"not null" json:"actor_id"`
+	ActorRole  string    `gorm:"type:varchar(50)" json:"actor_role"`
+	FromStatus string    `gorm:"type:varchar(50)" json:"from_status"` // empty for the initial request
+	ToStatus   string    `gorm:"type:varchar(50);not null" json:"to_status"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (e *RentalStatusEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return
+}
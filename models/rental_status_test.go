@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRentalTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		parties  []string
+		expected error
+	}{
+		{"Owner Approves", RentalPending, RentalApproved, []string{PartyOwner}, nil},
+		{"Owner Rejects", RentalPending, RentalRejected, []string{PartyOwner}, nil},
+		{"Renter Cancels Pending", RentalPending, RentalCancelled, []string{PartyRenter}, nil},
+		{"Renter Cancels Approved", RentalApproved, RentalCancelled, []string{PartyRenter}, nil},
+		{"Owner Activates", RentalApproved, RentalActive, []string{PartyOwner}, nil},
+		{"Owner Completes", RentalActive, RentalCompleted, []string{PartyOwner}, nil},
+		{"Admin Overrides Party", RentalPending, RentalApproved, []string{PartyAdmin}, nil},
+		{"Renter Cannot Approve", RentalPending, RentalApproved, []string{PartyRenter}, ErrRentalTransitionDenied},
+		{"Owner Cannot Cancel Pending", RentalPending, RentalCancelled, []string{PartyOwner}, ErrRentalTransitionDenied},
+		{"Skip To Completed", RentalPending, RentalCompleted, []string{PartyOwner}, ErrInvalidRentalTransition},
+		{"Reopen Completed", RentalCompleted, RentalActive, []string{PartyAdmin}, ErrInvalidRentalTransition},
+		{"Typo Status", RentalPending, "aproved", []string{PartyOwner}, ErrUnknownRentalStatus},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckRentalTransition(tc.from, tc.to, tc.parties)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
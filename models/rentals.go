@@ -4,13 +4,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// BlockingRentalStatuses reserve the machine for the rental's dates.
+// Keep in sync with the rentals_no_overlap exclusion constraint.
+var BlockingRentalStatuses = []string{RentalApproved, RentalActive}
+
 type Rental struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
 	MachineID uuid.UUID `gorm:"type:uuid;not null" json:"machine_id"`
 	RenterID  uint      `gorm:"not null" json:"renter_id"` // Matches Auth Service ID type
+	// Set when the rental was booked on behalf of a company
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id"`
 
 	StartDate time.Time `gorm:"not null" json:"start_date"`
 	EndDate   time.Time `gorm:"not null" json:"end_date"`
@@ -20,6 +27,22 @@ type Rental struct {
 	SecurityDeposit float64 `gorm:"type:decimal(10,2);not null" json:"security_deposit"`
 	PlatformFee     float64 `gorm:"type:decimal(10,2);default:0" json:"platform_fee"` // e.g. 5%
 
+	// Deposit settlement, recorded once the deposit is released or a claim settles
+	DepositRefund    float64    `gorm:"type:decimal(10,2);default:0" json:"deposit_refund"`
+	DepositSettledAt *time.Time `json:"deposit_settled_at"`
+
+	// GST on TotalAmount + PlatformFee: CGST+SGST within a state, IGST across states
+	GSTRate       float64 `gorm:"type:decimal(5,2);default:0" json:"gst_rate"`
+	TaxableAmount float64 `gorm:"type:decimal(12,2);default:0" json:"taxable_amount"`
+	CGST          float64 `gorm:"type:decimal(10,2);default:0" json:"cgst"`
+	SGST          float64 `gorm:"type:decimal(10,2);default:0" json:"sgst"`
+	IGST          float64 `gorm:"type:decimal(10,2);default:0" json:"igst"`
+	SellerState   string  `gorm:"type:varchar(100)" json:"seller_state"`
+	RenterState   string  `gorm:"type:varchar(100)" json:"renter_state"`
+
+	// Tier line items (month/week/day) that make up TotalAmount
+	PriceBreakdown datatypes.JSON `gorm:"type:jsonb" json:"price_breakdown" swaggertype:"array,object"`
+
 	// Status Flow: pending -> approved -> active -> completed (or rejected/cancelled)
 	Status string `gorm:"type:varchar(50);default:'pending'" json:"status"`
 
@@ -31,6 +54,11 @@ type Rental struct {
 	Machine Machine `gorm:"foreignKey:MachineID" json:"machine,omitempty"`
 }
 
+// ChargeAmount is what the renter pays for the rental itself, excluding the deposit
+func (r *Rental) ChargeAmount() float64 {
+	return r.TotalAmount + r.PlatformFee + r.CGST + r.SGST + r.IGST
+}
+
 func (r *Rental) BeforeCreate(tx *gorm.DB) (err error) {
 	r.ID = uuid.New()
 	return
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Upload records a file a user put in storage, so it can be checked
+// against what they later submit and deleted once nothing uses it.
+//
+// Every object stored for the upload starts with BaseKey ("upload-<uuid>"),
+// and Files lists them. ReferencedBy holds the records pointing at the
+// upload ("inspection:<id>", "maintenance:<id>", ...); an upload whose
+// list has been empty for the grace period is swept. Hash is unique per
+// uploader; it is empty while a presigned upload awaits completion.
+type Upload struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	UploaderID   uint           `gorm:"not null;uniqueIndex:idx_uploads_uploader_hash,priority:1,where:hash <> ''" json:"uploader_id"`
+	Hash         string         `gorm:"type:varchar(64);not null;uniqueIndex:idx_uploads_uploader_hash,priority:2,where:hash <> ''" json:"hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Size         int64          `gorm:"not null" json:"size" example:"482113"`
+	ContentType  string         `gorm:"type:varchar(100);not null" json:"content_type" example:"image/jpeg"`
+	BaseKey      string         `gorm:"type:varchar(100);not null;uniqueIndex" json:"-"`
+	Files        datatypes.JSON `gorm:"type:jsonb;not null" json:"-"`
+	ReferencedBy datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"referenced_by" swaggertype:"array,string"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// UploadFile is one stored object of an upload
+type UploadFile struct {
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	ContentType string `json:"content_type"`
+	Key         string `json:"key"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int    `json:"size"`
+}
+
+func (u *Upload) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
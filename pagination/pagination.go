@@ -0,0 +1,261 @@
+// Package pagination pages list endpoints with keyset cursors. A cursor
+// records the sort keys of the last row served, and the next page starts
+// strictly after it, so rows inserted meanwhile never shift or repeat
+// results the way OFFSET paging does. Cursors are opaque to clients.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params are the paging query parameters shared by every list endpoint
+type Params struct {
+	Limit        int
+	Cursor       string
+	IncludeTotal bool
+}
+
+// FromQuery reads limit, cursor and include_total. A missing or
+// non-positive limit falls back to DefaultLimit; larger ones are capped.
+func FromQuery(values url.Values) Params {
+	limit, _ := strconv.Atoi(values.Get("limit"))
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	includeTotal, _ := strconv.ParseBool(values.Get("include_total"))
+	return Params{Limit: limit, Cursor: values.Get("cursor"), IncludeTotal: includeTotal}
+}
+
+// Key is one column of a keyset ordering. Expr may be any SQL expression
+// (with Args for its placeholders) but must never be NULL; wrap nullable
+// columns in coalesce. Type is the Postgres type cursor values are cast
+// back to, and Value reads the key from a fetched row.
+type Key[T any] struct {
+	Expr  string
+	Args  []interface{}
+	Type  string
+	Desc  bool
+	Value func(*T) interface{}
+}
+
+// Order is a keyset ordering. The last key must be unique (normally the
+// primary key) so that every row has a distinct position.
+type Order[T any] []Key[T]
+
+// Page is the envelope every list endpoint returns. NextCursor is null on
+// the last page; Total is only counted when include_total=true.
+type Page[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+	Limit      int     `json:"limit"`
+	Total      *int64  `json:"total,omitempty"`
+}
+
+// Paginate orders query by order, resumes after params.Cursor and fetches
+// one page. Callers apply filters (and any Select) but no Order.
+func Paginate[T any](query *gorm.DB, params Params, order Order[T]) (*Page[T], error) {
+	page := &Page[T]{Data: []T{}, Limit: params.Limit}
+
+	query = query.Model(new(T))
+
+	if params.IncludeTotal {
+		// Count the filtered rows as a subquery, leaving any Preload or
+		// Select on query untouched
+		var total int64
+		counter := query.Session(&gorm.Session{NewDB: true}).Table("(?) AS matches", query)
+		if err := counter.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	if params.Cursor != "" {
+		values, err := decodeCursor(params.Cursor, order.signature(), len(order))
+		if err != nil {
+			return nil, err
+		}
+		sql, args := order.after(values)
+		query = query.Where(sql, args...)
+	}
+
+	orderSQL, orderArgs := order.orderBy()
+	query = query.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: orderSQL, Vars: orderArgs, WithoutParentheses: true}})
+
+	// Fetch one extra row to learn whether another page follows
+	if err := query.Limit(params.Limit + 1).Find(&page.Data).Error; err != nil {
+		return nil, err
+	}
+
+	if len(page.Data) > params.Limit {
+		page.Data = page.Data[:params.Limit]
+		page.HasMore = true
+		next := encodeCursor(order.signature(), order.values(&page.Data[len(page.Data)-1]))
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// orderBy renders the ORDER BY list
+func (o Order[T]) orderBy() (string, []interface{}) {
+	parts := make([]string, len(o))
+	var args []interface{}
+	for i, k := range o {
+		parts[i] = k.Expr + direction(k.Desc)
+		args = append(args, k.Args...)
+	}
+	return strings.Join(parts, ", "), args
+}
+
+// after renders the condition for rows strictly after the cursor values.
+// When every key runs the same way a row comparison suffices (and can use
+// a composite index); mixed directions expand to
+// (a > x) OR (a = x AND b > y) OR ...
+func (o Order[T]) after(values []string) (string, []interface{}) {
+	uniform := true
+	for _, k := range o {
+		uniform = uniform && k.Desc == o[0].Desc
+	}
+
+	if uniform {
+		exprs := make([]string, len(o))
+		params := make([]string, len(o))
+		var args []interface{}
+		for i, k := range o {
+			exprs[i] = k.Expr
+			params[i] = k.placeholder()
+			args = append(args, k.Args...)
+		}
+		for _, v := range values {
+			args = append(args, v)
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), comparison(o[0].Desc), strings.Join(params, ", ")), args
+	}
+
+	var terms []string
+	var args []interface{}
+	for i, k := range o {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, o[j].Expr+" = "+o[j].placeholder())
+			args = append(args, o[j].Args...)
+			args = append(args, values[j])
+		}
+		conds = append(conds, k.Expr+" "+comparison(k.Desc)+" "+k.placeholder())
+		args = append(args, k.Args...)
+		args = append(args, values[i])
+		terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// values reads the cursor values of a row
+func (o Order[T]) values(row *T) []string {
+	values := make([]string, len(o))
+	for i, k := range o {
+		values[i] = formatValue(k.Value(row))
+	}
+	return values
+}
+
+// signature identifies the ordering, including the arguments bound into its
+// expressions, so a cursor taken from a list sorted one way (or by distance
+// from one place, or relevance to one search) is refused by another
+func (o Order[T]) signature() string {
+	h := fnv.New32a()
+	for _, k := range o {
+		fmt.Fprintf(h, "%s|%s|%t|%#v;", k.Expr, k.Type, k.Desc, k.Args)
+	}
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// placeholder binds a cursor value, which always travels as text and is
+// cast by Postgres to the key's type
+func (k Key[T]) placeholder() string {
+	return "CAST(CAST(? AS text) AS " + k.Type + ")"
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
+
+// formatValue renders a key value in a form Postgres parses back exactly
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return formatFloat(v, 64)
+	case float32:
+		return formatFloat(float64(v), 32)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatFloat(f float64, bits int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'f', -1, bits)
+}
+
+// cursor is the payload behind the opaque cursor string
+type cursor struct {
+	Order  string   `json:"o"`
+	Values []string `json:"v"`
+}
+
+func encodeCursor(signature string, values []string) string {
+	raw, _ := json.Marshal(cursor{Order: signature, Values: values})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, signature string, keys int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Order != signature || len(c.Values) != keys {
+		return nil, ErrInvalidCursor
+	}
+	return c.Values, nil
+}
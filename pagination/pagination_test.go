@@ -0,0 +1,112 @@
+package pagination
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type row struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Score     float64
+}
+
+var byID = Key[row]{Expr: "id", Type: "uuid", Value: func(r *row) interface{} { return r.ID }}
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected Params
+	}{
+		{"", Params{Limit: DefaultLimit}},
+		{"limit=5&cursor=abc&include_total=true", Params{Limit: 5, Cursor: "abc", IncludeTotal: true}},
+		{"limit=0", Params{Limit: DefaultLimit}},
+		{"limit=ten", Params{Limit: DefaultLimit}},
+		{"limit=5000", Params{Limit: MaxLimit}},
+	}
+
+	for _, tc := range tests {
+		values, _ := url.ParseQuery(tc.query)
+		if got := FromQuery(values); got != tc.expected {
+			t.Errorf("%q: expected %+v, got %+v", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := Key[row]{Expr: "created_at", Type: "timestamptz", Desc: true, Value: func(r *row) interface{} { return r.CreatedAt }}
+	order := Order[row]{created, byID}
+
+	r := row{ID: uuid.New(), CreatedAt: time.Date(2025, 3, 1, 10, 30, 0, 123456000, time.FixedZone("IST", 19800))}
+	cursor := encodeCursor(order.signature(), order.values(&r))
+
+	values, err := decodeCursor(cursor, order.signature(), len(order))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"2025-03-01T05:00:00.123456Z", r.ID.String()}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+
+	// Cursors from another ordering, or tampered with, are refused
+	other := Order[row]{{Expr: "created_at", Type: "timestamptz", Value: created.Value}, byID}
+	if _, err := decodeCursor(cursor, other.signature(), len(other)); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor for another order, got %v", err)
+	}
+	near := func(lat, lng float64) Order[row] {
+		return Order[row]{{Expr: "distance(?, ?)", Args: []interface{}{lat, lng}, Type: "double precision", Value: created.Value}, byID}
+	}
+	delhi, mumbai := near(28.61, 77.21), near(19.08, 72.88)
+	if _, err := decodeCursor(encodeCursor(delhi.signature(), delhi.values(&r)), mumbai.signature(), len(mumbai)); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor for an order bound to other arguments, got %v", err)
+	}
+	if _, err := decodeCursor("not a cursor!", order.signature(), len(order)); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor for garbage, got %v", err)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	score := Key[row]{Expr: "score(?)", Args: []interface{}{"q"}, Type: "real", Desc: true, Value: func(r *row) interface{} { return r.Score }}
+
+	// Same direction throughout: a row comparison
+	sql, args := Order[row]{score, {Expr: "id", Type: "uuid", Desc: true}}.after([]string{"0.5", "x"})
+	if sql != "(score(?), id) < (CAST(CAST(? AS text) AS real), CAST(CAST(? AS text) AS uuid))" {
+		t.Errorf("unexpected sql %s", sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"q", "0.5", "x"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	// Mixed directions expand key by key
+	sql, args = Order[row]{score, byID}.after([]string{"0.5", "x"})
+	expected := "((score(?) < CAST(CAST(? AS text) AS real)) OR (score(?) = CAST(CAST(? AS text) AS real) AND id > CAST(CAST(? AS text) AS uuid)))"
+	if sql != expected {
+		t.Errorf("expected %s, got %s", expected, sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"q", "0.5", "q", "0.5", "x"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{float64(float32(0.1)), "0.10000000149011612"},
+		{2500000.5, "2500000.5"},
+		{math.Inf(1), "Infinity"},
+		{42, "42"},
+	}
+	for _, tc := range tests {
+		if got := formatValue(tc.value); got != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, got)
+		}
+	}
+}
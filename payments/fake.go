@@ -0,0 +1,180 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider is an in-process PaymentProvider for tests and local
+// development. It keeps intents in memory and signs webhooks the same way
+// a real gateway would, so the full webhook path can be exercised.
+type FakeProvider struct {
+	secret string
+
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+}
+
+type fakeIntent struct {
+	amount        int64
+	captureMethod string
+	status        string
+	captured      int64
+	refunded      int64
+}
+
+// Fake intent statuses
+const (
+	fakeRequiresPayment = "requires_payment"
+	fakeAuthorized      = "authorized"
+	fakeCaptured        = "captured"
+	fakeCancelled       = "cancelled"
+	fakeFailed          = "failed"
+)
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{secret: secret, intents: map[string]*fakeIntent{}}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ref := "fake_pi_" + uuid.New().String()
+	f.intents[ref] = &fakeIntent{
+		amount:        ToPaise(req.Amount),
+		captureMethod: req.CaptureMethod,
+		status:        fakeRequiresPayment,
+	}
+
+	return &Intent{
+		ProviderRef:  ref,
+		ClientSecret: "fake_secret_" + uuid.New().String(),
+		Status:       fakeRequiresPayment,
+	}, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, providerRef string, amount float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[providerRef]
+	if !ok {
+		return ErrUnknownIntent
+	}
+	if intent.status != fakeAuthorized || ToPaise(amount) > intent.amount {
+		return ErrInvalidState
+	}
+
+	intent.status = fakeCaptured
+	intent.captured = ToPaise(amount)
+	return nil
+}
+
+func (f *FakeProvider) Cancel(ctx context.Context, providerRef string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[providerRef]
+	if !ok {
+		return ErrUnknownIntent
+	}
+	if intent.status == fakeCaptured {
+		return ErrInvalidState
+	}
+
+	intent.status = fakeCancelled
+	return nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, providerRef string, amount float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[providerRef]
+	if !ok {
+		return ErrUnknownIntent
+	}
+	if intent.status != fakeCaptured || intent.refunded+ToPaise(amount) > intent.captured {
+		return ErrInvalidState
+	}
+
+	intent.refunded += ToPaise(amount)
+	return nil
+}
+
+func (f *FakeProvider) ParseWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !VerifySignature(f.secret, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Complete simulates the payer finishing checkout. Manual-capture intents
+// become authorised (funds held); automatic ones are captured in full.
+// It returns the signed webhook the provider would send.
+func (f *FakeProvider) Complete(providerRef string) (payload []byte, signature string, err error) {
+	f.mu.Lock()
+	intent, ok := f.intents[providerRef]
+	if !ok {
+		f.mu.Unlock()
+		return nil, "", ErrUnknownIntent
+	}
+	if intent.status != fakeRequiresPayment {
+		f.mu.Unlock()
+		return nil, "", ErrInvalidState
+	}
+
+	eventType := EventCaptured
+	if intent.captureMethod == CaptureManual {
+		intent.status = fakeAuthorized
+		eventType = EventAuthorized
+	} else {
+		intent.status = fakeCaptured
+		intent.captured = intent.amount
+	}
+	amount := float64(intent.amount) / 100
+	f.mu.Unlock()
+
+	return f.SignedEvent(eventType, providerRef, amount)
+}
+
+// Fail simulates a declined payment and returns the signed webhook
+func (f *FakeProvider) Fail(providerRef string) (payload []byte, signature string, err error) {
+	f.mu.Lock()
+	intent, ok := f.intents[providerRef]
+	if !ok {
+		f.mu.Unlock()
+		return nil, "", ErrUnknownIntent
+	}
+	intent.status = fakeFailed
+	f.mu.Unlock()
+
+	return f.SignedEvent(EventFailed, providerRef, 0)
+}
+
+// SignedEvent builds a webhook body and its signature
+func (f *FakeProvider) SignedEvent(eventType, providerRef string, amount float64) ([]byte, string, error) {
+	payload, err := json.Marshal(WebhookEvent{
+		ID:          "evt_" + uuid.New().String(),
+		Type:        eventType,
+		ProviderRef: providerRef,
+		Amount:      amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(f.secret, payload), nil
+}
@@ -0,0 +1,132 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Capture methods for a payment intent
+const (
+	CaptureAutomatic = "automatic" // charge immediately once the payer authorises
+	CaptureManual    = "manual"    // hold the funds until captured or released
+)
+
+// Webhook event types, normalised across providers
+const (
+	EventAuthorized = "payment.authorized"
+	EventCaptured   = "payment.captured"
+	EventFailed     = "payment.failed"
+	EventRefunded   = "payment.refunded"
+	EventCancelled  = "payment.cancelled"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of a webhook body
+const SignatureHeader = "X-Payment-Signature"
+
+var (
+	ErrNoProvider       = errors.New("no payment provider configured: set PAYMENT_PROVIDER")
+	ErrNoWebhookSecret  = errors.New("no webhook secret configured: set PAYMENT_WEBHOOK_SECRET")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownIntent    = errors.New("unknown payment intent")
+	ErrInvalidState     = errors.New("payment intent is not in a valid state for this operation")
+)
+
+// IntentRequest describes money to collect from a payer
+type IntentRequest struct {
+	Amount        float64 // rupees
+	Currency      string
+	CaptureMethod string
+	Description   string
+	Metadata      map[string]string
+}
+
+// Intent is the provider's view of a payment intent
+type Intent struct {
+	ProviderRef  string
+	ClientSecret string // handed to the frontend to complete payment
+	Status       string
+}
+
+// WebhookEvent is a verified, provider-independent webhook notification
+type WebhookEvent struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"`
+	ProviderRef string  `json:"provider_ref"`
+	Amount      float64 `json:"amount"` // for refunds: total refunded so far
+}
+
+// PaymentProvider is implemented by each payment gateway integration.
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture collects up to the authorised amount of a manual intent
+	Capture(ctx context.Context, providerRef string, amount float64) error
+	// Cancel voids an intent, releasing any held funds
+	Cancel(ctx context.Context, providerRef string) error
+	Refund(ctx context.Context, providerRef string, amount float64) error
+	// ParseWebhook verifies the signature and decodes the event
+	ParseWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// Active is the provider used by the API. Configure sets it at startup;
+// tests may replace it directly.
+var Active PaymentProvider
+
+// Configure selects the provider from PAYMENT_PROVIDER and the secret its
+// webhooks are signed with from PAYMENT_WEBHOOK_SECRET. Both are required:
+// the in-process "fake" provider lets anyone complete their own payments,
+// so it is only used when asked for by name, for local development.
+func Configure() error {
+	provider := os.Getenv("PAYMENT_PROVIDER")
+	if provider == "" {
+		return ErrNoProvider
+	}
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		return ErrNoWebhookSecret
+	}
+
+	switch provider {
+	case "fake":
+		Active = NewFakeProvider(secret)
+		return nil
+	default:
+		return fmt.Errorf("unsupported PAYMENT_PROVIDER %q", provider)
+	}
+}
+
+// Simulated reports whether the active provider is the fake one, whose
+// checkout can be completed through the API
+func Simulated() bool {
+	_, ok := Active.(*FakeProvider)
+	return ok
+}
+
+// Sign returns the hex HMAC-SHA256 of payload under secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a hex HMAC-SHA256 signature in constant time
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ToPaise converts rupees to the integer minor unit providers expect
+func ToPaise(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
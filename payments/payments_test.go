@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"type":"payment.captured"}`)
+	sig := Sign("secret", payload)
+
+	if !VerifySignature("secret", payload, sig) {
+		t.Error("expected valid signature")
+	}
+	if VerifySignature("other-secret", payload, sig) {
+		t.Error("expected signature under a different secret to fail")
+	}
+	if VerifySignature("secret", []byte(`{"type":"payment.failed"}`), sig) {
+		t.Error("expected tampered payload to fail")
+	}
+	if VerifySignature("secret", payload, "not-hex") {
+		t.Error("expected malformed signature to fail")
+	}
+}
+
+func TestFakeProvider_DepositHold(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeProvider("secret")
+
+	intent, err := fake.CreateIntent(ctx, IntentRequest{Amount: 5000, CaptureMethod: CaptureManual})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Cannot capture before the payer authorises
+	if err := fake.Capture(ctx, intent.ProviderRef, 5000); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState, got %v", err)
+	}
+
+	payload, sig, err := fake.Complete(intent.ProviderRef)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event, err := fake.ParseWebhook(payload, sig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Type != EventAuthorized || event.ProviderRef != intent.ProviderRef {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// Partial capture, then refund no more than was captured
+	if err := fake.Capture(ctx, intent.ProviderRef, 2000); err != nil {
+		t.Fatalf("unexpected capture error: %v", err)
+	}
+	if err := fake.Refund(ctx, intent.ProviderRef, 2500); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for over-refund, got %v", err)
+	}
+	if err := fake.Refund(ctx, intent.ProviderRef, 2000); err != nil {
+		t.Errorf("unexpected refund error: %v", err)
+	}
+}
+
+func TestFakeProvider_RejectsBadSignature(t *testing.T) {
+	fake := NewFakeProvider("secret")
+	payload, _, _ := fake.SignedEvent(EventCaptured, "fake_pi_1", 100)
+
+	if _, err := fake.ParseWebhook(payload, Sign("wrong", payload)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func() { Active = nil }()
+	t.Setenv("PAYMENT_PROVIDER", "")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "secret")
+	if err := Configure(); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("expected ErrNoProvider, got %v", err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "fake")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "")
+	if err := Configure(); !errors.Is(err, ErrNoWebhookSecret) {
+		t.Errorf("expected ErrNoWebhookSecret, got %v", err)
+	}
+
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "secret")
+	if err := Configure(); err != nil || !Simulated() {
+		t.Errorf("expected the fake provider, got %v", err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "paypal")
+	if err := Configure(); err == nil {
+		t.Error("expected an unknown provider to be rejected")
+	}
+}
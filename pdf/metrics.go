@@ -0,0 +1,96 @@
+package pdf
+
+import "strings"
+
+// Advance widths of the printable ASCII characters (32-126) in thousandths
+// of the font size, from the Adobe font metrics of the standard fonts
+var widths = [][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// winAnsiExtras maps the typographic characters WinAnsi places in 0x80-0x9f
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi returns the WinAnsi code of r, or '?' when it has none
+func winAnsi(r rune) byte {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return ' '
+	case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	}
+	if c, ok := winAnsiExtras[r]; ok {
+		return c
+	}
+	return '?'
+}
+
+// Width is the width of s in points when set in font at size
+func Width(font Font, size float64, s string) float64 {
+	total := 0
+	for _, r := range s {
+		c := winAnsi(r)
+		switch {
+		case c >= 0x20 && c <= 0x7e:
+			total += widths[font][c-0x20]
+		case c == 0x97:
+			total += 1000
+		default:
+			total += 556 // close enough for the few other characters
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than maxWidth, at spaces where
+// possible. Words longer than a line are split. Line breaks in s are kept.
+func Wrap(font Font, size float64, s string, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if Width(font, size, candidate) <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Split words that do not fit on a line of their own
+			for Width(font, size, word) > maxWidth {
+				runes := []rune(word)
+				n := len(runes) - 1
+				for n > 1 && Width(font, size, string(runes[:n])) > maxWidth {
+					n--
+				}
+				lines = append(lines, string(runes[:n]))
+				word = string(runes[n:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,268 @@
+// Package pdf writes simple PDF documents: A4 pages of text in the standard
+// Helvetica fonts, filled and stroked rectangles, lines and JPEG images.
+//
+// It covers what the API renders (certificates and similar one-off
+// documents) and nothing more. Text is limited to the WinAnsi character set
+// of the standard fonts, so no fonts need embedding; other characters are
+// replaced with "?".
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page size in points (1/72 inch)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts every PDF reader provides
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+// Color is an RGB colour
+type Color struct{ R, G, B uint8 }
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+)
+
+// Document is a PDF being built. Add pages and images, then write it out.
+type Document struct {
+	Title   string
+	Created time.Time
+	pages   []*Page
+	images  []*Image
+}
+
+// New starts an empty document
+func New(title string) *Document {
+	return &Document{Title: title, Created: time.Now()}
+}
+
+// AddPage appends a blank A4 page
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Image is a JPEG embedded once and drawn on any number of pages
+type Image struct {
+	Width, Height int
+	data          []byte
+	id            int
+}
+
+// AddImage embeds img as a JPEG of the given quality. Transparent areas
+// are flattened onto white.
+func (d *Document) AddImage(img image.Image, quality int) (*Image, error) {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	im := &Image{Width: bounds.Dx(), Height: bounds.Dy(), data: buf.Bytes(), id: len(d.images)}
+	d.images = append(d.images, im)
+	return im, nil
+}
+
+// Page is a content stream. Coordinates are in points from the top left
+// corner; text is positioned by its baseline.
+type Page struct {
+	content bytes.Buffer
+	images  []*Image
+}
+
+func (p *Page) op(format string, args ...interface{}) {
+	fmt.Fprintf(&p.content, format+"\n", args...)
+}
+
+// Text draws s at (x, y) in the given font, size and colour
+func (p *Page) Text(x, y float64, font Font, size float64, c Color, s string) {
+	p.op("BT /F%d %s Tf %s rg %s %s Td (%s) Tj ET", font+1, num(size), rgb(c), num(x), num(PageHeight-y), escape(s))
+}
+
+// Rect fills a rectangle
+func (p *Page) Rect(x, y, w, h float64, c Color) {
+	p.op("%s rg %s %s %s %s re f", rgb(c), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// StrokeRect outlines a rectangle
+func (p *Page) StrokeRect(x, y, w, h, width float64, c Color) {
+	p.op("%s RG %s w %s %s %s %s re S", rgb(c), num(width), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line draws a straight line
+func (p *Page) Line(x1, y1, x2, y2, width float64, c Color) {
+	p.op("%s RG %s w %s %s m %s %s l S", rgb(c), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Image draws an embedded image into the box at (x, y), scaled to w by h
+func (p *Page) Image(im *Image, x, y, w, h float64) {
+	p.images = append(p.images, im)
+	p.op("q %s 0 0 %s %s %s cm /Im%d Do Q", num(w), num(h), num(x), num(PageHeight-y-h), im.id)
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo renders the document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &writer{}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Objects: 1 catalog, 2 page tree, 3 info, fonts, images, then a page
+	// and its content stream per page
+	const catalog, pageTree, info = 1, 2, 3
+	firstFont := 4
+	firstImage := firstFont + len(fontNames)
+	firstPage := firstImage + len(d.images)
+
+	out.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pageTree)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	out.object(pageTree, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	out.object(info, "<< /Title (%s) /Producer (Vishwakarma Setu) /CreationDate (D:%s) >>",
+		escape(d.Title), d.Created.UTC().Format("20060102150405Z"))
+
+	for i, name := range fontNames {
+		out.object(firstFont+i, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+	}
+
+	for i, im := range d.images {
+		out.stream(firstImage+i, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode",
+			im.Width, im.Height), im.data)
+	}
+
+	fonts := make([]string, len(fontNames))
+	for i := range fontNames {
+		fonts[i] = fmt.Sprintf("/F%d %d 0 R", i+1, firstFont+i)
+	}
+	for i, p := range d.pages {
+		var xobjects []string
+		seen := map[int]bool{}
+		for _, im := range p.images {
+			if !seen[im.id] {
+				seen[im.id] = true
+				xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", im.id, firstImage+im.id))
+			}
+		}
+		pageObj := firstPage + 2*i
+		out.object(pageObj, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> /XObject << %s >> >> /Contents %d 0 R >>",
+			pageTree, num(PageWidth), num(PageHeight), strings.Join(fonts, " "), strings.Join(xobjects, " "), pageObj+1)
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(p.content.Bytes())
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		out.stream(pageObj+1, "/Filter /FlateDecode", compressed.Bytes())
+	}
+
+	xref := out.buf.Len()
+	count := firstPage + 2*len(d.pages)
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", count)
+	for i := 1; i < count; i++ {
+		out.printf("%010d 00000 n \n", out.offsets[i])
+	}
+	out.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", count, catalog, info, xref)
+
+	n, err := w.Write(out.buf.Bytes())
+	return int64(n), err
+}
+
+// writer tracks object offsets for the cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+func (w *writer) object(id int, format string, args ...interface{}) {
+	w.mark(id)
+	w.printf("%d 0 obj\n"+format+"\nendobj\n", append([]interface{}{id}, args...)...)
+}
+
+func (w *writer) stream(id int, dict string, data []byte) {
+	w.mark(id)
+	w.printf("%d 0 obj\n<< %s /Length %d >>\nstream\n", id, dict, len(data))
+	w.buf.Write(data)
+	w.printf("\nendstream\nendobj\n")
+}
+
+func (w *writer) mark(id int) {
+	if w.offsets == nil {
+		w.offsets = map[int]int{}
+	}
+	w.offsets[id] = w.buf.Len()
+}
+
+// num formats a coordinate without needless digits
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func rgb(c Color) string {
+	return num(float64(c.R)/255) + " " + num(float64(c.G)/255) + " " + num(float64(c.B)/255)
+}
+
+// escape encodes s as the body of a WinAnsi string literal
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c := winAnsi(r)
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 0x20 || c > 0x7e {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
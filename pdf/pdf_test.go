@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	doc := New("Test (certificate)")
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.NRGBA{200, 0, 0, 255})
+	im, err := doc.AddImage(img, 80)
+	if err != nil {
+		t.Fatalf("AddImage: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		page := doc.AddPage()
+		page.Text(40, 60, HelveticaBold, 18, Black, "Hello (world)")
+		page.Rect(40, 80, 100, 20, Color{230, 240, 250})
+		page.Image(im, 40, 120, 40, 30)
+	}
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+
+	// Every cross-reference entry points at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	lines := strings.Split(string(out[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		offset, _ := strconv.Atoi(lines[2+i][:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i, out[offset:offset+10])
+		}
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) || !bytes.Contains(out, []byte("/Title (Test \\(certificate\\))")) {
+		t.Error("expected two pages and the escaped title")
+	}
+
+	// Page content is compressed; the text survives escaped
+	start := bytes.Index(out, []byte("/FlateDecode"))
+	stream := out[bytes.Index(out[start:], []byte("stream\n"))+start+len("stream\n"):]
+	zr, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("content stream: %v", err)
+	}
+	content, _ := io.ReadAll(zr)
+	for _, want := range []string{"/F2 18 Tf", "(Hello \\(world\\)) Tj", "re f", "/Im0 Do"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in the page content:\n%s", want, content)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	cases := map[string]string{
+		"plain":       "plain",
+		`a\b`:         `a\\b`,
+		"Café – 5€":   `Caf\351 \226 5\200`,
+		"₹ 1,000":     "? 1,000",
+		"line\nbreak": "line break",
+	}
+	for in, want := range cases {
+		if got := escape(in); got != want {
+			t.Errorf("escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	// H e l l o = 722 + 556 + 222 + 222 + 556
+	if got := Width(Helvetica, 10, "Hello"); got != 22.78 {
+		t.Errorf("expected 22.78, got %v", got)
+	}
+	if Width(HelveticaBold, 10, "Hello") <= Width(Helvetica, 10, "Hello") {
+		t.Error("expected bold text to be wider")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap(Helvetica, 10, "The spindle runs quietly\nand true", 80)
+	want := []string{"The spindle runs", "quietly", "and true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	for _, line := range Wrap(Helvetica, 10, strings.Repeat("x", 40), 50) {
+		if Width(Helvetica, 10, line) > 50 {
+			t.Errorf("line %q is wider than 50pt", line)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package policy decides what an authenticated user may do. Roles carried in
+// the JWT are mapped onto named permissions, and handlers and routes ask for
+// permissions rather than comparing role strings. A permission covers the
+// caller's own resources; its ":any" form (see Any) extends it to everyone
+// else's, which is how admins oversee the marketplace.
+package policy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/vishwakarma-setu-backend/auth"
+)
+
+// Permission names an action, as "resource:verb"
+type Permission string
+
+const (
+	MachineCreate     Permission = "machine:create"
+	MachineUpdate     Permission = "machine:update"
+	MachineDelete     Permission = "machine:delete"
+	MaintenanceCreate Permission = "maintenance:create"
+	InspectionRequest Permission = "inspection:request" // ask for one's machines to be inspected
+	InspectionSubmit  Permission = "inspection:submit"
+	InspectionAssign  Permission = "inspection:assign" // schedule inspectors
+	FileUpload        Permission = "file:upload"
+
+	RentalCreate  Permission = "rental:create"
+	RentalApprove Permission = "rental:approve" // act as owner on rentals of one's machines
+	PaymentPay    Permission = "payment:pay"
+	PaymentManage Permission = "payment:manage" // capture, release and refund by hand
+	ClaimCreate   Permission = "claim:create"
+	ClaimResolve  Permission = "claim:resolve"
+	DepositSettle Permission = "deposit:settle"
+
+	OfferCreate Permission = "offer:create"
+	OrderManage Permission = "order:manage" // act on offers and orders one is party to
+
+	OrganizationManage Permission = "organization:manage" // create companies, manage their members
+
+	CategoryManage Permission = "category:manage"
+	FeeRuleManage  Permission = "fee_rule:manage"
+)
+
+// all grants every permission
+const all Permission = "*"
+
+// Roles carried in the JWT role claim
+const (
+	RoleBuyer     = "buyer"
+	RoleSeller    = "seller"
+	RoleInspector = "inspector"
+	RoleAdmin     = "admin"
+)
+
+var buyer = []Permission{RentalCreate, PaymentPay, OfferCreate, OrderManage, OrganizationManage, FileUpload}
+
+// roles maps each role onto the permissions it holds. Sellers can do
+// everything buyers can, since a seller renting or buying equipment
+// themselves is routine.
+var roles = map[string][]Permission{
+	RoleBuyer: buyer,
+	RoleSeller: append([]Permission{
+		MachineCreate, MachineUpdate, MachineDelete, MaintenanceCreate,
+		InspectionRequest, RentalApprove, ClaimCreate, DepositSettle,
+	}, buyer...),
+	RoleInspector: {InspectionSubmit, FileUpload},
+	RoleAdmin:     {all},
+}
+
+// Any is the permission to perform p on resources belonging to others
+func Any(p Permission) Permission {
+	return p + ":any"
+}
+
+// Can reports whether role holds permission p. Unknown roles hold nothing.
+func Can(role string, p Permission) bool {
+	for _, granted := range roles[role] {
+		if granted == all || granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists the permissions a role holds
+func Permissions(role string) []Permission {
+	return append([]Permission(nil), roles[role]...)
+}
+
+const (
+	// OrganizationHeader names the organization a request acts for
+	OrganizationHeader = "X-Organization-ID"
+	// OrganizationKey is the context key under which the organization
+	// middleware stores the caller's verified *Membership
+	OrganizationKey = "organization"
+)
+
+// Membership is the organization a request acts for and the caller's
+// member role in it
+type Membership struct {
+	OrgID uuid.UUID
+	Role  string
+}
+
+// Subject is the authenticated caller. Org is set when they act for an
+// organization.
+type Subject struct {
+	ID   uint
+	Role string
+	Org  *Membership
+}
+
+// OrgID is the organization the subject acts for, or nil
+func (s *Subject) OrgID() *uuid.UUID {
+	if s.Org == nil {
+		return nil
+	}
+	id := s.Org.OrgID
+	return &id
+}
+
+// Owner is who a resource belongs to: the user who created it, or the
+// organization it was created for
+type Owner struct {
+	UserID uint
+	OrgID  *uuid.UUID
+}
+
+// Owns reports whether the subject owns a resource. An organization's
+// resources belong to whoever acts for it, so any member can manage them
+// and the creator loses access once they leave.
+func (s *Subject) Owns(o Owner) bool {
+	if o.OrgID != nil {
+		return s.Org != nil && s.Org.OrgID == *o.OrgID
+	}
+	return s.ID == o.UserID
+}
+
+// Can reports whether the subject's role holds permission p
+func (s *Subject) Can(p Permission) bool {
+	return Can(s.Role, p)
+}
+
+// Allows reports whether the subject may perform p on a resource: they
+// need p and must either own the resource or hold Any(p).
+func (s *Subject) Allows(p Permission, o Owner) bool {
+	if !s.Can(p) {
+		return false
+	}
+	return s.Owns(o) || s.Can(Any(p))
+}
+
+// SubjectFrom reads the caller from the JWT the auth middleware stored on
+// the context, using the configured claim names
+func SubjectFrom(c echo.Context) (*Subject, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Missing token")
+	}
+	claims, ok := token.Claims.(*jwt.MapClaims)
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
+	}
+
+	id, role, err := auth.Active.Subject(*claims)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims: "+err.Error())
+	}
+	subject := &Subject{ID: id, Role: role}
+	if m, ok := c.Get(OrganizationKey).(*Membership); ok {
+		subject.Org = m
+	}
+	return subject, nil
+}
+
+// Denial is the body of every 403. Permission names what was missing when
+// the caller's role falls short; it is empty when the role is fine but the
+// caller has no part in the resource.
+type Denial struct {
+	Error      string     `json:"error"`
+	Permission Permission `json:"permission,omitempty"`
+}
+
+// Forbid answers 403 for a permission the caller lacks, either outright or
+// for a resource they do not own. reason may be empty, in which case one
+// is derived from the permission.
+func Forbid(c echo.Context, p Permission, reason string) error {
+	if reason == "" {
+		reason = "Your role does not allow " + describe(p)
+	}
+	return c.JSON(http.StatusForbidden, Denial{Error: reason, Permission: p})
+}
+
+// Deny answers 403 with a reason, for callers whose role is fine but who
+// are not a party to the resource or not allowed this particular step
+func Deny(c echo.Context, reason string) error {
+	return c.JSON(http.StatusForbidden, Denial{Error: reason})
+}
+
+// describe turns "rental:approve" into "rental approve" and
+// "machine:update:any" into "machine update on others' resources"
+func describe(p Permission) string {
+	s, others := strings.CutSuffix(string(p), ":any")
+	s = strings.NewReplacer(":", " ", "_", " ").Replace(s)
+	if others {
+		s += " on others' resources"
+	}
+	return s
+}
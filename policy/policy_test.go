@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role string
+		perm Permission
+		want bool
+	}{
+		{RoleSeller, MachineCreate, true},
+		{RoleBuyer, MachineCreate, false},
+		{RoleSeller, RentalCreate, true}, // sellers rent too
+		{RoleBuyer, RentalApprove, false},
+		{RoleInspector, InspectionSubmit, true},
+		{RoleSeller, InspectionSubmit, false},
+		{RoleAdmin, InspectionSubmit, true},
+		{RoleSeller, InspectionRequest, true},
+		{RoleSeller, InspectionAssign, false},
+		{RoleInspector, InspectionAssign, false},
+		{RoleAdmin, Any(MachineUpdate), true},
+		{RoleSeller, Any(MachineUpdate), false},
+		{"superuser", FileUpload, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.role+" "+string(tc.perm), func(t *testing.T) {
+			if got := Can(tc.role, tc.perm); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	owner := &Subject{ID: 1, Role: RoleSeller}
+	other := &Subject{ID: 2, Role: RoleSeller}
+	admin := &Subject{ID: 3, Role: RoleAdmin}
+	buyer := &Subject{ID: 1, Role: RoleBuyer}
+
+	mine := Owner{UserID: 1}
+	if !owner.Allows(MachineUpdate, mine) {
+		t.Error("expected the owner to be allowed")
+	}
+	if other.Allows(MachineUpdate, mine) {
+		t.Error("expected another seller to be refused")
+	}
+	if !admin.Allows(MachineUpdate, mine) {
+		t.Error("expected an admin to be allowed on any machine")
+	}
+	if buyer.Allows(MachineUpdate, mine) {
+		t.Error("expected a role without the permission to be refused even as owner")
+	}
+}
+
+func TestAllows_Organization(t *testing.T) {
+	org := uuid.New()
+	fleet := Owner{UserID: 1, OrgID: &org}
+
+	colleague := &Subject{ID: 2, Role: RoleSeller, Org: &Membership{OrgID: org, Role: "member"}}
+	if !colleague.Allows(MachineUpdate, fleet) {
+		t.Error("expected any member acting for the organization to be allowed")
+	}
+
+	creator := &Subject{ID: 1, Role: RoleSeller}
+	if creator.Allows(MachineUpdate, fleet) {
+		t.Error("expected the creator to need to act for the organization")
+	}
+
+	outsider := &Subject{ID: 3, Role: RoleSeller, Org: &Membership{OrgID: uuid.New(), Role: "owner"}}
+	if outsider.Allows(MachineUpdate, fleet) {
+		t.Error("expected a member of another organization to be refused")
+	}
+	if !outsider.Allows(MachineUpdate, Owner{UserID: 3}) {
+		t.Error("expected personal resources to stay accessible while acting for an organization")
+	}
+}
+
+func TestSubjectFrom(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	if _, err := SubjectFrom(c); err == nil {
+		t.Error("expected an error without a token")
+	}
+
+	claims := jwt.MapClaims{"user_id": float64(7), "role": RoleInspector}
+	c.Set("user", &jwt.Token{Claims: &claims})
+	user, err := SubjectFrom(c)
+	if err != nil || user.ID != 7 || user.Role != RoleInspector {
+		t.Errorf("expected inspector 7, got %+v (%v)", user, err)
+	}
+
+	c.Set("user", &jwt.Token{Claims: &jwt.MapClaims{"user_id": float64(7)}})
+	if _, err := SubjectFrom(c); err == nil {
+		t.Error("expected an error without a role")
+	}
+}
+
+func TestForbid(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	if err := Forbid(c, Any(RentalApprove), ""); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+
+	var resp Denial
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Permission != "rental:approve:any" || resp.Error != "Your role does not allow rental approve on others' resources" {
+		t.Errorf("unexpected denial %+v", resp)
+	}
+}
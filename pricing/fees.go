@@ -0,0 +1,63 @@
+package pricing
+
+import (
+	"math"
+
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+// DefaultFeeRule applies when no configured rule matches a machine
+var DefaultFeeRule = models.PlatformFeeRule{
+	Name:   "Default commission",
+	Kind:   models.FeeKindPercentage,
+	Value:  5,
+	Active: true,
+}
+
+// MatchFeeRule picks the rule for a machine: rules that name both its
+// category and listing type beat rules naming one, which beat catch-all
+// rules. Ties go to the higher Priority.
+func MatchFeeRule(rules []models.PlatformFeeRule, machine models.Machine) models.PlatformFeeRule {
+	best, bestScore := DefaultFeeRule, -1
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+
+		score := 0
+		if rule.Category != "" {
+			if rule.Category != machine.Category {
+				continue
+			}
+			score += 2
+		}
+		if rule.ListingType != "" {
+			if rule.ListingType != machine.ListingType {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore || (score == bestScore && rule.Priority > best.Priority) {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// PlatformFee applies a fee rule to a rental subtotal, honouring min/max caps
+func PlatformFee(rule models.PlatformFeeRule, subtotal float64) float64 {
+	fee := rule.Value
+	if rule.Kind == models.FeeKindPercentage {
+		fee = subtotal * rule.Value / 100
+	}
+
+	if rule.MinFee > 0 {
+		fee = math.Max(fee, rule.MinFee)
+	}
+	if rule.MaxFee > 0 {
+		fee = math.Min(fee, rule.MaxFee)
+	}
+
+	return round(fee)
+}
@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestMatchFeeRule(t *testing.T) {
+	rules := []models.PlatformFeeRule{
+		{Name: "Catch-all", Kind: models.FeeKindPercentage, Value: 4, Active: true},
+		{Name: "Rentals", ListingType: "rent", Kind: models.FeeKindPercentage, Value: 6, Active: true},
+		{Name: "CNC", Category: "CNC Mill", Kind: models.FeeKindPercentage, Value: 3, Active: true},
+		{Name: "CNC Rentals", Category: "CNC Mill", ListingType: "rent", Kind: models.FeeKindFlat, Value: 2500, Active: true},
+		{Name: "Disabled", Category: "Lathe", ListingType: "rent", Kind: models.FeeKindFlat, Value: 1, Active: false},
+	}
+
+	tests := []struct {
+		name     string
+		machine  models.Machine
+		expected string
+	}{
+		{"Category And Type", models.Machine{Category: "CNC Mill", ListingType: "rent"}, "CNC Rentals"},
+		{"Category Only", models.Machine{Category: "CNC Mill", ListingType: "both"}, "CNC"},
+		{"Type Only", models.Machine{Category: "Lathe", ListingType: "rent"}, "Rentals"},
+		{"Catch-all", models.Machine{Category: "Press", ListingType: "both"}, "Catch-all"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rule := MatchFeeRule(rules, tc.machine); rule.Name != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, rule.Name)
+			}
+		})
+	}
+
+	if rule := MatchFeeRule(nil, models.Machine{}); rule.Name != DefaultFeeRule.Name {
+		t.Errorf("expected default rule, got %q", rule.Name)
+	}
+}
+
+func TestPlatformFee(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     models.PlatformFeeRule
+		subtotal float64
+		expected float64
+	}{
+		{"Percentage", models.PlatformFeeRule{Kind: models.FeeKindPercentage, Value: 5}, 10000, 500},
+		{"Flat", models.PlatformFeeRule{Kind: models.FeeKindFlat, Value: 750}, 10000, 750},
+		{"Min Cap", models.PlatformFeeRule{Kind: models.FeeKindPercentage, Value: 5, MinFee: 1000}, 10000, 1000},
+		{"Max Cap", models.PlatformFeeRule{Kind: models.FeeKindPercentage, Value: 5, MaxFee: 300}, 10000, 300},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if fee := PlatformFee(tc.rule, tc.subtotal); fee != tc.expected {
+				t.Errorf("expected %.2f, got %.2f", tc.expected, fee)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package pricing
+
+import (
+	"strings"
+)
+
+// DefaultGSTRate is the GST percentage on machinery rental (SAC 9973)
+const DefaultGSTRate = 18.0
+
+// State is an Indian state or union territory with its GST state code
+type State struct {
+	Name string `json:"name" example:"Haryana"`
+	Code string `json:"code" example:"06"`
+}
+
+// TaxBreakdown is the GST applied to a rental. Intra-state supplies split the
+// rate into CGST and SGST; inter-state supplies charge IGST.
+type TaxBreakdown struct {
+	TaxableValue float64 `json:"taxable_value"`
+	Rate         float64 `json:"rate" example:"18"`
+	SellerState  string  `json:"seller_state" example:"Haryana"`
+	RenterState  string  `json:"renter_state" example:"Delhi"`
+	CGST         float64 `json:"cgst"`
+	SGST         float64 `json:"sgst"`
+	IGST         float64 `json:"igst"`
+	Total        float64 `json:"total"`
+}
+
+var states = []State{
+	{"Jammu and Kashmir", "01"},
+	{"Himachal Pradesh", "02"},
+	{"Punjab", "03"},
+	{"Chandigarh", "04"},
+	{"Uttarakhand", "05"},
+	{"Haryana", "06"},
+	{"Delhi", "07"},
+	{"Rajasthan", "08"},
+	{"Uttar Pradesh", "09"},
+	{"Bihar", "10"},
+	{"Sikkim", "11"},
+	{"Arunachal Pradesh", "12"},
+	{"Nagaland", "13"},
+	{"Manipur", "14"},
+	{"Mizoram", "15"},
+	{"Tripura", "16"},
+	{"Meghalaya", "17"},
+	{"Assam", "18"},
+	{"West Bengal", "19"},
+	{"Jharkhand", "20"},
+	{"Odisha", "21"},
+	{"Chhattisgarh", "22"},
+	{"Madhya Pradesh", "23"},
+	{"Gujarat", "24"},
+	{"Dadra and Nagar Haveli and Daman and Diu", "26"},
+	{"Maharashtra", "27"},
+	{"Karnataka", "29"},
+	{"Goa", "30"},
+	{"Lakshadweep", "31"},
+	{"Kerala", "32"},
+	{"Tamil Nadu", "33"},
+	{"Puducherry", "34"},
+	{"Andaman and Nicobar Islands", "35"},
+	{"Telangana", "36"},
+	{"Andhra Pradesh", "37"},
+	{"Ladakh", "38"},
+}
+
+// Common alternate spellings and abbreviations
+var stateAliases = map[string]string{
+	"j&k":          "Jammu and Kashmir",
+	"jk":           "Jammu and Kashmir",
+	"hp":           "Himachal Pradesh",
+	"pb":           "Punjab",
+	"uk":           "Uttarakhand",
+	"uttaranchal":  "Uttarakhand",
+	"hr":           "Haryana",
+	"new delhi":    "Delhi",
+	"nct of delhi": "Delhi",
+	"dl":           "Delhi",
+	"rj":           "Rajasthan",
+	"up":           "Uttar Pradesh",
+	"wb":           "West Bengal",
+	"orissa":       "Odisha",
+	"cg":           "Chhattisgarh",
+	"mp":           "Madhya Pradesh",
+	"gj":           "Gujarat",
+	"mh":           "Maharashtra",
+	"ka":           "Karnataka",
+	"tn":           "Tamil Nadu",
+	"pondicherry":  "Puducherry",
+	"ts":           "Telangana",
+	"ap":           "Andhra Pradesh",
+}
+
+// LookupState resolves a state name, abbreviation or GST code
+func LookupState(s string) (State, bool) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if key == "" {
+		return State{}, false
+	}
+	if alias, ok := stateAliases[key]; ok {
+		key = strings.ToLower(alias)
+	}
+	for _, st := range states {
+		if strings.ToLower(st.Name) == key || st.Code == key {
+			return st, true
+		}
+	}
+	return State{}, false
+}
+
+// StateFromLocation extracts the state from a free-text location such as
+// "Faridabad, Haryana" by checking comma-separated parts from the end.
+func StateFromLocation(location string) (State, bool) {
+	parts := strings.Split(location, ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		// Drop PIN codes like "Haryana 121001"
+		part := strings.TrimSpace(strings.TrimRight(parts[i], " 0123456789"))
+		if st, ok := LookupState(part); ok {
+			return st, true
+		}
+	}
+	return State{}, false
+}
+
+// CalculateGST taxes the taxable value at rate percent. An unknown renter
+// state is treated as the seller's state (intra-state supply).
+func CalculateGST(taxable, rate float64, sellerState, renterState State) TaxBreakdown {
+	if renterState.Code == "" {
+		renterState = sellerState
+	}
+
+	tax := TaxBreakdown{
+		TaxableValue: round(taxable),
+		Rate:         rate,
+		SellerState:  sellerState.Name,
+		RenterState:  renterState.Name,
+	}
+
+	total := round(taxable * rate / 100)
+	if sellerState.Code == renterState.Code {
+		tax.CGST = round(total / 2)
+		tax.SGST = round(total - tax.CGST)
+	} else {
+		tax.IGST = total
+	}
+	tax.Total = round(tax.CGST + tax.SGST + tax.IGST)
+
+	return tax
+}
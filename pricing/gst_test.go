@@ -0,0 +1,47 @@
+package pricing
+
+import "testing"
+
+func TestStateFromLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+		found    bool
+	}{
+		{"Faridabad, Haryana", "Haryana", true},
+		{"Pune, MH", "Maharashtra", true},
+		{"Okhla Phase 2, New Delhi 110020", "Delhi", true},
+		{"Bhubaneswar, Orissa", "Odisha", true},
+		{"Somewhere", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.location, func(t *testing.T) {
+			st, ok := StateFromLocation(tc.location)
+			if ok != tc.found || st.Name != tc.expected {
+				t.Errorf("expected %q (%v), got %q (%v)", tc.expected, tc.found, st.Name, ok)
+			}
+		})
+	}
+}
+
+func TestCalculateGST(t *testing.T) {
+	haryana, _ := LookupState("Haryana")
+	delhi, _ := LookupState("Delhi")
+
+	intra := CalculateGST(10000, 18, haryana, haryana)
+	if intra.CGST != 900 || intra.SGST != 900 || intra.IGST != 0 {
+		t.Errorf("unexpected intra-state split: %+v", intra)
+	}
+
+	inter := CalculateGST(10000, 18, haryana, delhi)
+	if inter.IGST != 1800 || inter.CGST != 0 || inter.SGST != 0 {
+		t.Errorf("unexpected inter-state split: %+v", inter)
+	}
+
+	// Unknown renter state falls back to the seller's state
+	fallback := CalculateGST(10000, 18, haryana, State{})
+	if fallback.RenterState != "Haryana" || fallback.CGST != 900 {
+		t.Errorf("unexpected fallback: %+v", fallback)
+	}
+}
@@ -0,0 +1,185 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+const (
+	DaysPerWeek  = 7
+	DaysPerMonth = 30
+)
+
+// Tier names used in line items
+const (
+	TierMonth = "month"
+	TierWeek  = "week"
+	TierDay   = "day"
+)
+
+var (
+	ErrInvalidDates  = errors.New("end date cannot be before start date")
+	ErrNoRentalPrice = errors.New("machine has no rental price for this duration")
+	ErrBelowMinimum  = errors.New("rental is shorter than the minimum duration")
+	ErrUnknownState  = errors.New("unknown state")
+)
+
+// LineItem is one pricing tier applied to part of a rental
+type LineItem struct {
+	Tier   string  `json:"tier" example:"month"`
+	Units  int     `json:"units" example:"1"`
+	Rate   float64 `json:"rate" example:"120000"`
+	Amount float64 `json:"amount" example:"120000"`
+}
+
+// QuoteOptions carries the configuration a quote depends on
+type QuoteOptions struct {
+	FeeRules    []models.PlatformFeeRule // active rules; DefaultFeeRule if none match
+	RenterState string                   // place of supply; defaults to the seller's state
+	GSTRate     float64                  // percent; DefaultGSTRate if zero
+}
+
+// Quote is the full price of renting a machine for a date range
+type Quote struct {
+	MachineID       string       `json:"machine_id"`
+	StartDate       string       `json:"start_date" example:"2025-01-01"`
+	EndDate         string       `json:"end_date" example:"2025-02-05"`
+	Days            int          `json:"days" example:"35"`
+	Breakdown       []LineItem   `json:"breakdown"`
+	Subtotal        float64      `json:"subtotal"`
+	PlatformFee     float64      `json:"platform_fee"`
+	PlatformFeeRule string       `json:"platform_fee_rule" example:"Default commission"`
+	Tax             TaxBreakdown `json:"tax"`
+	SecurityDeposit float64      `json:"security_deposit"`
+	AmountDue       float64      `json:"amount_due"` // subtotal + fee + tax + deposit
+}
+
+// RentalDays counts billable days the same way rentals always have:
+// whole days between start and end, with a minimum of one.
+func RentalDays(start, end time.Time) int {
+	days := int(math.Ceil(end.Sub(start).Hours() / 24))
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
+
+// QuoteRental prices a rental of the machine between start and end.
+// GST is charged on the subtotal plus platform fee; the refundable
+// security deposit is not taxed.
+func QuoteRental(machine models.Machine, start, end time.Time, opts QuoteOptions) (*Quote, error) {
+	if end.Before(start) {
+		return nil, ErrInvalidDates
+	}
+
+	days := RentalDays(start, end)
+	if machine.MinRentalDays > 0 && days < machine.MinRentalDays {
+		return nil, fmt.Errorf("%w (%d days)", ErrBelowMinimum, machine.MinRentalDays)
+	}
+
+	breakdown, err := Breakdown(days, machine.RentalPricePerMonth, machine.RentalPricePerWeek, machine.RentalPricePerDay)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal := 0.0
+	for _, item := range breakdown {
+		subtotal += item.Amount
+	}
+	subtotal = round(subtotal)
+
+	feeRule := MatchFeeRule(opts.FeeRules, machine)
+	platformFee := PlatformFee(feeRule, subtotal)
+
+	var renterState State
+	if opts.RenterState != "" {
+		st, ok := LookupState(opts.RenterState)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownState, opts.RenterState)
+		}
+		renterState = st
+	}
+	sellerState, _ := StateFromLocation(machine.Location)
+
+	gstRate := opts.GSTRate
+	if gstRate == 0 {
+		gstRate = DefaultGSTRate
+	}
+	tax := CalculateGST(subtotal+platformFee, gstRate, sellerState, renterState)
+
+	return &Quote{
+		MachineID:       machine.ID.String(),
+		StartDate:       start.Format("2006-01-02"),
+		EndDate:         end.Format("2006-01-02"),
+		Days:            days,
+		Breakdown:       breakdown,
+		Subtotal:        subtotal,
+		PlatformFee:     platformFee,
+		PlatformFeeRule: feeRule.Name,
+		Tax:             tax,
+		SecurityDeposit: machine.SecurityDeposit,
+		AmountDue:       round(subtotal + platformFee + tax.Total + machine.SecurityDeposit),
+	}, nil
+}
+
+// Breakdown splits a number of days into monthly, weekly and daily charges.
+// Whole months and weeks are billed at their rates and the remainder at the
+// daily rate, but a remainder is never billed above the next tier up, so a
+// 29-day rental never costs more than a month. A rate of 0 means the tier is
+// not offered.
+func Breakdown(days int, monthly, weekly, daily float64) ([]LineItem, error) {
+	months, weeks, remaining := 0, 0, days
+
+	if monthly > 0 {
+		months, remaining = remaining/DaysPerMonth, remaining%DaysPerMonth
+	}
+	if weekly > 0 {
+		weeks, remaining = remaining/DaysPerWeek, remaining%DaysPerWeek
+	}
+
+	// Round leftover days up to a week when that is cheaper or days are not priced
+	if weekly > 0 && remaining > 0 && cost(daily, remaining) > weekly {
+		weeks, remaining = weeks+1, 0
+	}
+	// Same for the whole sub-month tail against one month
+	if monthly > 0 && (weeks > 0 || remaining > 0) && cost(weekly, weeks)+cost(daily, remaining) > monthly {
+		months, weeks, remaining = months+1, 0, 0
+	}
+
+	if math.IsInf(cost(weekly, weeks)+cost(daily, remaining), 1) || (months == 0 && weeks == 0 && remaining == 0) {
+		return nil, ErrNoRentalPrice
+	}
+
+	var items []LineItem
+	if months > 0 {
+		items = append(items, LineItem{Tier: TierMonth, Units: months, Rate: monthly, Amount: round(monthly * float64(months))})
+	}
+	if weeks > 0 {
+		items = append(items, LineItem{Tier: TierWeek, Units: weeks, Rate: weekly, Amount: round(weekly * float64(weeks))})
+	}
+	if remaining > 0 {
+		items = append(items, LineItem{Tier: TierDay, Units: remaining, Rate: daily, Amount: round(daily * float64(remaining))})
+	}
+
+	return items, nil
+}
+
+// cost prices units at rate, treating an unset rate as unavailable
+func cost(rate float64, units int) float64 {
+	if units == 0 {
+		return 0
+	}
+	if rate <= 0 {
+		return math.Inf(1)
+	}
+	return rate * float64(units)
+}
+
+// round rounds to whole paise
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
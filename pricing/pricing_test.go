@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vishwakarma-setu-backend/models"
+)
+
+func TestBreakdown(t *testing.T) {
+	tests := []struct {
+		name                   string
+		days                   int
+		monthly, weekly, daily float64
+		expectedTotal          float64
+		expectedTiers          []string
+	}{
+		{"Daily Only", 4, 0, 0, 1000, 4000, []string{TierDay}},
+		{"Month Plus Days", 33, 20000, 0, 1000, 23000, []string{TierMonth, TierDay}},
+		{"Month Week Days", 40, 20000, 6000, 1000, 29000, []string{TierMonth, TierWeek, TierDay}},
+		{"Days Capped At Week", 6, 0, 5000, 1000, 5000, []string{TierWeek}},
+		{"Tail Capped At Month", 29, 20000, 6000, 1000, 20000, []string{TierMonth}},
+		{"Monthly Only Rounds Up", 35, 20000, 0, 0, 40000, []string{TierMonth}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := Breakdown(tc.days, tc.monthly, tc.weekly, tc.daily)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			total := 0.0
+			var tiers []string
+			for _, item := range items {
+				total += item.Amount
+				tiers = append(tiers, item.Tier)
+			}
+			if total != tc.expectedTotal {
+				t.Errorf("expected total %.2f, got %.2f", tc.expectedTotal, total)
+			}
+			if len(tiers) != len(tc.expectedTiers) {
+				t.Fatalf("expected tiers %v, got %v", tc.expectedTiers, tiers)
+			}
+			for i := range tiers {
+				if tiers[i] != tc.expectedTiers[i] {
+					t.Errorf("expected tiers %v, got %v", tc.expectedTiers, tiers)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakdown_NoPrice(t *testing.T) {
+	if _, err := Breakdown(3, 0, 0, 0); !errors.Is(err, ErrNoRentalPrice) {
+		t.Errorf("expected ErrNoRentalPrice, got %v", err)
+	}
+}
+
+func TestQuoteRental(t *testing.T) {
+	machine := models.Machine{
+		Location:            "Faridabad, Haryana",
+		RentalPricePerDay:   1000,
+		RentalPricePerMonth: 20000,
+		SecurityDeposit:     5000,
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 32)
+
+	quote, err := QuoteRental(machine, start, end, QuoteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Days != 32 {
+		t.Errorf("expected 32 days, got %d", quote.Days)
+	}
+	if quote.Subtotal != 22000 {
+		t.Errorf("expected subtotal 22000, got %.2f", quote.Subtotal)
+	}
+	if quote.PlatformFee != 1100 {
+		t.Errorf("expected platform fee 1100, got %.2f", quote.PlatformFee)
+	}
+	// 18% GST on 23100, split evenly for an intra-state rental
+	if quote.Tax.CGST != 2079 || quote.Tax.SGST != 2079 || quote.Tax.IGST != 0 {
+		t.Errorf("unexpected tax split: %+v", quote.Tax)
+	}
+	if quote.AmountDue != 32258 {
+		t.Errorf("expected amount due 32258, got %.2f", quote.AmountDue)
+	}
+}
+
+func TestQuoteRental_BelowMinimum(t *testing.T) {
+	machine := models.Machine{RentalPricePerDay: 1000, MinRentalDays: 7}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := QuoteRental(machine, start, start.AddDate(0, 0, 3), QuoteOptions{}); !errors.Is(err, ErrBelowMinimum) {
+		t.Errorf("expected ErrBelowMinimum, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package qr encodes short texts, such as verification URLs, as QR codes.
+//
+// The encoder is deliberately small: it writes byte mode at error
+// correction level M in versions 1 to 10, which holds up to 213 bytes.
+// That covers URLs and codes; anything longer is refused.
+package qr
+
+import "errors"
+
+// ErrTooLong is returned for texts that do not fit in a version 10 symbol
+var ErrTooLong = errors.New("qr: text too long")
+
+// MaxVersion is the largest symbol the encoder produces
+const MaxVersion = 10
+
+// Code is an encoded symbol. Callers should draw a light quiet zone of at
+// least four modules around it.
+type Code struct {
+	Version int
+	Size    int // modules per side
+	Mask    int
+	modules []bool
+	// function marks the modules of patterns rather than data
+	function []bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// blockSpec describes a version's error correction blocks at level M: the
+// EC codewords per block, then a count and data length per block group
+type blockSpec struct {
+	ecPerBlock     int
+	blocks1, data1 int
+	blocks2, data2 int
+}
+
+var specs = [MaxVersion + 1]blockSpec{
+	1:  {10, 1, 16, 0, 0},
+	2:  {16, 1, 28, 0, 0},
+	3:  {26, 1, 44, 0, 0},
+	4:  {18, 2, 32, 0, 0},
+	5:  {24, 2, 43, 0, 0},
+	6:  {16, 4, 27, 0, 0},
+	7:  {18, 4, 31, 0, 0},
+	8:  {22, 2, 38, 2, 39},
+	9:  {22, 3, 36, 2, 37},
+	10: {26, 4, 43, 1, 44},
+}
+
+// alignment lists the centre coordinates of the alignment patterns
+var alignment = [MaxVersion + 1][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+func (s blockSpec) dataCodewords() int {
+	return s.blocks1*s.data1 + s.blocks2*s.data2
+}
+
+// Encode returns the smallest symbol holding text
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*specs[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := interleave(version, dataCodewords(version, data))
+
+	c := newCode(version)
+	c.placeData(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask) // masking twice undoes it
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+	c.Mask = best
+	return c, nil
+}
+
+// countBits is the width of the byte mode character count
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// dataCodewords encodes data in byte mode and pads it to the version's
+// capacity
+func dataCodewords(version int, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * specs[version].dataCodewords()
+	bits.append(0, min(4, capacity-len(bits))) // terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// interleave splits data into blocks, adds each block's error correction
+// and interleaves the codewords in transmission order
+func interleave(version int, data []byte) []byte {
+	spec := specs[version]
+	var blocks, ecs [][]byte
+	for i := 0; i < spec.blocks1+spec.blocks2; i++ {
+		n := spec.data1
+		if i >= spec.blocks1 {
+			n = spec.data2
+		}
+		blocks = append(blocks, data[:n])
+		ecs = append(ecs, reedSolomon(data[:n], spec.ecPerBlock))
+		data = data[n:]
+	}
+
+	var out []byte
+	for i := 0; i < max(spec.data1, spec.data2); i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecPerBlock; i++ {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// bitBuffer collects bits most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
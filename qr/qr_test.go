@@ -0,0 +1,139 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, from the worked example of the standard
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	for mask, want := range map[int]int{
+		0: 0b101010000010010,
+		3: 0b101101101001011,
+		5: 0b100000011001110,
+		7: 0b100101010100000,
+	} {
+		if got := formatBits(mask); got != want {
+			t.Errorf("mask %d: expected %015b, got %015b", mask, want, got)
+		}
+	}
+	if got := versionBits(7); got != 0x07c94 {
+		t.Errorf("expected version 7 word 0x07c94, got %#x", got)
+	}
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	for v := 1; v <= MaxVersion; v++ {
+		c := newCode(v)
+		data := 0
+		for _, f := range c.function {
+			if !f {
+				data++
+			}
+		}
+		// Data modules hold every codeword plus 0 or 7 remainder bits
+		codewords := specs[v].dataCodewords() + (specs[v].blocks1+specs[v].blocks2)*specs[v].ecPerBlock
+		remainder := 0
+		if v >= 2 && v <= 6 {
+			remainder = 7
+		}
+		if data != codewords*8+remainder {
+			t.Errorf("version %d: %d data modules for %d codewords", v, data, codewords)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, text := range []string{
+		"https://vishwakarmasetu.in/api/certificates/7KQ2-M9XD-4TRA",
+		strings.Repeat("x", 150), // several block groups
+	} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		if c.Size != 17+4*c.Version {
+			t.Errorf("unexpected size %d for version %d", c.Size, c.Version)
+		}
+
+		// Finder in the top left corner, with its light separator
+		for _, m := range []struct {
+			x, y int
+			dark bool
+		}{{0, 0, true}, {1, 1, false}, {3, 3, true}, {7, 7, false}, {8, c.Size - 8, true}} {
+			if c.Dark(m.x, m.y) != m.dark {
+				t.Errorf("module (%d,%d) should be dark=%v", m.x, m.y, m.dark)
+			}
+		}
+
+		// The format information names the chosen mask
+		format := 0
+		for i := 14; i >= 9; i-- {
+			format = format<<1 | bit(c.Dark(14-i, 8))
+		}
+		format = format<<1 | bit(c.Dark(7, 8))
+		format = format<<1 | bit(c.Dark(8, 8))
+		format = format<<1 | bit(c.Dark(8, 7))
+		for i := 5; i >= 0; i-- {
+			format = format<<1 | bit(c.Dark(8, i))
+		}
+		if format != formatBits(c.Mask) {
+			t.Errorf("format bits %015b do not match mask %d", format, c.Mask)
+		}
+
+		// Unmasking and reading the zigzag gives back the codewords
+		want := interleave(c.Version, dataCodewords(c.Version, []byte(text)))
+		if got := readCodewords(c, len(want)); !bytes.Equal(got, want) {
+			t.Errorf("version %d: codewords do not round-trip", c.Version)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", 214)); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+// readCodewords reads n codewords back out of a symbol
+func readCodewords(c *Code, n int) []byte {
+	ref := newCode(c.Version)
+	out := make([]byte, n)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if ref.function[y*c.Size+x] || i >= n*8 {
+					continue
+				}
+				if c.Dark(x, y) != masked(c.Mask, x, y) {
+					out[i/8] |= 0x80 >> (i % 8)
+				}
+				i++
+			}
+		}
+	}
+	return out
+}
+
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
@@ -5,14 +5,20 @@ import (
 	swagger "github.com/swaggo/echo-swagger"
 	"github.com/vishwakarma-setu-backend/controllers"
 	"github.com/vishwakarma-setu-backend/middleware"
+	"github.com/vishwakarma-setu-backend/payments"
+	"github.com/vishwakarma-setu-backend/policy"
+	"github.com/vishwakarma-setu-backend/storage"
 )
 
 func RegisterRoutes(e *echo.Echo) {
 	// Swagger Documentation Route
 	e.GET("/swagger/*", swagger.WrapHandler)
 
-	// 1. Static File Serving
-	e.Static("/uploads", "./uploads")
+	// 1. Static File Serving (local storage only; S3 serves its own files)
+	if local, ok := storage.Active.(*storage.Local); ok {
+		e.GET(local.PublicPath()+"/*", echo.WrapHandler(local.PublicHandler()))
+		e.Any(storage.SignedPath+"*", echo.WrapHandler(local.Handler()))
+	}
 
 	// API Group
 	api := e.Group("/api")
@@ -31,34 +37,127 @@ func RegisterRoutes(e *echo.Echo) {
 	// Public Machine Routes
 	api.GET("/machines", controllers.GetAllListings)
 	api.GET("/machines/:id", controllers.GetListingByID)
+	api.GET("/machines/:id/availability", controllers.GetMachineAvailability)
+
+	// Public Category Registry
+	api.GET("/categories", controllers.GetCategories)
+	api.GET("/categories/:slug/schema", controllers.GetCategorySchema)
+	api.GET("/categories/:slug/inspection-template", controllers.GetInspectionTemplate)
+
+	// Public Rental Quote (no booking is created)
+	api.POST("/rentals/quote", controllers.QuoteRental)
+
+	// Payment Provider Webhook (signature-verified)
+	api.POST("/payments/webhook", controllers.PaymentWebhook)
 
 	// Public Inspection Route (Buyers need to see the report)
 	api.GET("/machines/:machine_id/inspection", controllers.GetMachineInspection)
+	api.GET("/machines/:machine_id/inspection/certificate.pdf", controllers.GetInspectionCertificate)
+	api.GET("/certificates/:code", controllers.VerifyCertificate)
+	api.GET("/inspections/:id/verify", controllers.VerifyInspectionReport)
 
 	// Public Maintenance Route
 	api.GET("/machines/:machine_id/maintenance", controllers.GetMaintenanceHistory)
 
 	// Protected Routes (Auth Required)
 	protected := api.Group("")
-	protected.Use(middleware.JWTMiddleware())
+	protected.Use(middleware.JWTMiddleware(), middleware.Organization())
+
+	// Role checks happen here; handlers add the ownership checks
+	can := middleware.RequirePermission
 
 	// Utility
-	protected.POST("/upload", controllers.UploadImage)
+	protected.POST("/upload", controllers.UploadImage, can(policy.FileUpload))
+	protected.POST("/upload/presign", controllers.PresignUpload, can(policy.FileUpload))
+	protected.POST("/upload/:id/complete", controllers.CompleteUpload, can(policy.FileUpload))
+
+	// Document Attachments
+	protected.POST("/attachments", controllers.CreateAttachment, can(policy.FileUpload))
+	protected.GET("/attachments", controllers.GetAttachments)
+	protected.GET("/attachments/:id", controllers.GetAttachment)
+	protected.DELETE("/attachments/:id", controllers.DeleteAttachment)
 
 	// Machine Management
-	protected.POST("/machines", controllers.CreateListing)
-	protected.PUT("/machines/:id", controllers.UpdateListing)
-	protected.DELETE("/machines/:id", controllers.DeleteListing)
+	protected.POST("/machines", controllers.CreateListing, can(policy.MachineCreate))
+	protected.PUT("/machines/:id", controllers.UpdateListing, can(policy.MachineUpdate))
+	protected.DELETE("/machines/:id", controllers.DeleteListing, can(policy.MachineDelete))
+
+	// Listing photo gallery
+	protected.POST("/machines/:id/images", controllers.AddMachineImage, can(policy.MachineUpdate))
+	protected.PUT("/machines/:id/images/order", controllers.ReorderMachineImages, can(policy.MachineUpdate))
+	protected.PUT("/machines/:id/images/:image_id", controllers.UpdateMachineImage, can(policy.MachineUpdate))
+	protected.DELETE("/machines/:id/images/:image_id", controllers.DeleteMachineImage, can(policy.MachineUpdate))
 
 	// Rental Management
-	protected.POST("/rentals", controllers.CreateRentalRequest)
+	protected.POST("/rentals", controllers.CreateRentalRequest, can(policy.RentalCreate))
 	protected.GET("/rentals/my", controllers.GetMyRentals)
 	protected.GET("/rentals/manage", controllers.GetOwnerRentals)
 	protected.PUT("/rentals/:id/status", controllers.UpdateRentalStatus)
+	protected.GET("/rentals/:id/history", controllers.GetRentalHistory)
+
+	// Payments & Deposits
+	protected.POST("/rentals/:id/payments", controllers.CreateRentalPayments, can(policy.PaymentPay))
+	protected.GET("/rentals/:id/payments", controllers.GetRentalPayments)
+	protected.POST("/payments/:id/capture", controllers.CapturePayment, can(policy.PaymentManage))
+	protected.POST("/payments/:id/release", controllers.ReleasePayment, can(policy.PaymentManage))
+	protected.POST("/payments/:id/refund", controllers.RefundPayment, can(policy.PaymentManage))
+	if payments.Simulated() {
+		// Local development only: lets payers complete their own checkout
+		protected.POST("/payments/:id/simulate", controllers.SimulatePayment, can(policy.PaymentPay))
+	}
+
+	// Deposit Settlement & Damage Claims
+	protected.POST("/rentals/:id/claims", controllers.CreateDamageClaim, can(policy.ClaimCreate))
+	protected.GET("/rentals/:id/claims", controllers.GetRentalClaims)
+	protected.PUT("/claims/:id/respond", controllers.RespondToClaim)
+	protected.PUT("/claims/:id/resolve", controllers.ResolveClaim, can(policy.ClaimResolve))
+	protected.POST("/rentals/:id/deposit/settle", controllers.SettleRentalDeposit, can(policy.DepositSettle))
+
+	// Sales: Offers & Orders
+	protected.POST("/machines/:id/offers", controllers.CreateOffer, can(policy.OfferCreate))
+	protected.GET("/offers/my", controllers.GetMyOffers)
+	protected.GET("/offers/manage", controllers.GetReceivedOffers)
+	protected.GET("/offers/:id", controllers.GetOffer)
+	protected.PUT("/offers/:id/respond", controllers.RespondToOffer)
+	protected.GET("/orders/my", controllers.GetMyOrders)
+	protected.GET("/orders/manage", controllers.GetSellerOrders)
+	protected.PUT("/orders/:id/status", controllers.UpdateOrderStatus)
+
+	// Organizations
+	protected.POST("/organizations", controllers.CreateOrganization, can(policy.OrganizationManage))
+	protected.GET("/organizations/my", controllers.GetMyOrganizations)
+	protected.GET("/organizations/:id", controllers.GetOrganization)
+	protected.POST("/organizations/:id/members", controllers.AddOrganizationMember, can(policy.OrganizationManage))
+	protected.DELETE("/organizations/:id/members/:user_id", controllers.RemoveOrganizationMember)
 
 	// Inspection Management
-	protected.POST("/inspections", controllers.CreateInspectionReport) // Inspector submits report
+	protected.POST("/inspection-requests", controllers.CreateInspectionRequest, can(policy.InspectionRequest))
+	protected.GET("/inspection-requests/my", controllers.GetMyInspectionRequests)
+	protected.GET("/inspection-requests/queue", controllers.GetInspectorQueue, can(policy.InspectionSubmit))
+	protected.GET("/inspection-requests/:id", controllers.GetInspectionRequest)
+	protected.PUT("/inspection-requests/:id/status", controllers.UpdateInspectionRequestStatus)
+	protected.POST("/inspections", controllers.CreateInspectionReport, can(policy.InspectionSubmit))
+	protected.POST("/inspections/:id/revisions", controllers.ReviseInspectionReport, can(policy.InspectionSubmit))
 
 	// Protected Maintenance Route
-	protected.POST("/maintenance", controllers.AddMaintenanceRecord)
+	protected.POST("/maintenance", controllers.AddMaintenanceRecord, can(policy.MaintenanceCreate))
+
+	// Admin: Platform Fee Rules
+	fees := protected.Group("/admin/fee-rules", can(policy.FeeRuleManage))
+	fees.GET("", controllers.GetFeeRules)
+	fees.POST("", controllers.CreateFeeRule)
+	fees.PUT("/:id", controllers.UpdateFeeRule)
+	fees.DELETE("/:id", controllers.DeleteFeeRule)
+
+	// Admin: Inspection Scheduling
+	inspections := protected.Group("/admin/inspection-requests", can(policy.InspectionAssign))
+	inspections.GET("", controllers.GetInspectionRequests)
+	inspections.PUT("/:id/schedule", controllers.ScheduleInspection)
+
+	// Admin: Category Registry
+	categories := protected.Group("/admin/categories", can(policy.CategoryManage))
+	categories.POST("", controllers.CreateCategory)
+	categories.PUT("/:slug", controllers.UpdateCategory)
+	categories.DELETE("/:slug", controllers.DeleteCategory)
+	categories.PUT("/:slug/inspection-template", controllers.SaveInspectionTemplate)
 }
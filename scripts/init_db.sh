@@ -19,5 +19,4 @@ done
 
 echo "✅ Postgres is up and running!"
 
-# (Optional) Run migrations or other setup here if needed
-# The app will likely handle AutoMigrate, so we just exit successfully.
\ No newline at end of file
+# Migrations are applied by `./main migrate up` after this script exits.
\ No newline at end of file
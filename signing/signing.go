@@ -0,0 +1,155 @@
+// Package signing signs records the platform vouches for, such as
+// inspection reports, with the server's Ed25519 key. Every signature names
+// the key that made it, so keys can be rotated: retired public keys stay
+// configured for verification while a new key signs.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Algorithm names the signature scheme in API responses
+const Algorithm = "Ed25519"
+
+var (
+	ErrNoKey = errors.New("no signing key configured: set INSPECTION_SIGNING_KEY or INSPECTION_SIGNING_KEY_FILE")
+	// ErrUnknownKey means the signature names a key that is not configured
+	ErrUnknownKey   = errors.New("signed with an unknown key")
+	ErrBadSignature = errors.New("signature does not match")
+)
+
+// Signer signs with one private key and verifies with it and any retired
+// public keys
+type Signer struct {
+	keyID   string
+	private ed25519.PrivateKey
+	public  map[string]ed25519.PublicKey
+}
+
+// NewSigner signs with private. retired are public keys of earlier signing
+// keys, still trusted for verification.
+func NewSigner(private ed25519.PrivateKey, retired ...ed25519.PublicKey) *Signer {
+	pub := private.Public().(ed25519.PublicKey)
+	s := &Signer{keyID: KeyID(pub), private: private, public: map[string]ed25519.PublicKey{KeyID(pub): pub}}
+	for _, key := range retired {
+		s.public[KeyID(key)] = key
+	}
+	return s
+}
+
+// KeyID identifies a public key: the first 8 bytes of its SHA-256, in hex
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyID is the ID of the key new signatures are made with
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign signs message, returning the key ID and the signature
+func (s *Signer) Sign(message []byte) (string, []byte) {
+	return s.keyID, ed25519.Sign(s.private, message)
+}
+
+// PublicKey returns the public key with the given ID
+func (s *Signer) PublicKey(keyID string) (ed25519.PublicKey, bool) {
+	key, ok := s.public[keyID]
+	return key, ok
+}
+
+// Verify checks that signature is keyID's signature of message
+func (s *Signer) Verify(keyID string, message, signature []byte) error {
+	key, ok := s.public[keyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if !ed25519.Verify(key, message, signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ParsePrivateKey reads a private key as a PKCS#8 PEM block (as written by
+// "openssl genpkey -algorithm ed25519") or as a base64 32-byte seed
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("PEM key is %T, not Ed25519", key)
+		}
+		return private, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("signing key must be a PEM Ed25519 key or a base64 32-byte seed")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey reads a base64 32-byte Ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key %q", s)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Active signs inspection reports. Configure sets it at startup; tests
+// may replace it directly.
+var Active *Signer
+
+// Configure builds Active from INSPECTION_SIGNING_KEY (a base64 seed) or
+// INSPECTION_SIGNING_KEY_FILE (a PEM file), plus INSPECTION_RETIRED_KEYS,
+// comma-separated base64 public keys of earlier signing keys. Like token
+// verification keys, a signing key is required.
+func Configure() error {
+	inline, file := os.Getenv("INSPECTION_SIGNING_KEY"), os.Getenv("INSPECTION_SIGNING_KEY_FILE")
+	var data []byte
+	switch {
+	case inline != "" && file != "":
+		return errors.New("set only one of INSPECTION_SIGNING_KEY and INSPECTION_SIGNING_KEY_FILE")
+	case inline != "":
+		data = []byte(inline)
+	case file != "":
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return fmt.Errorf("reading INSPECTION_SIGNING_KEY_FILE: %w", err)
+		}
+	default:
+		return ErrNoKey
+	}
+	private, err := ParsePrivateKey(data)
+	if err != nil {
+		return err
+	}
+
+	var retired []ed25519.PublicKey
+	for _, s := range strings.Split(os.Getenv("INSPECTION_RETIRED_KEYS"), ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		key, err := ParsePublicKey(s)
+		if err != nil {
+			return fmt.Errorf("INSPECTION_RETIRED_KEYS: %w", err)
+		}
+		retired = append(retired, key)
+	}
+	Active = NewSigner(private, retired...)
+	return nil
+}
@@ -0,0 +1,99 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func seed(b byte) []byte {
+	s := make([]byte, ed25519.SeedSize)
+	s[0] = b
+	return s
+}
+
+func TestSignAndVerify(t *testing.T) {
+	old := ed25519.NewKeyFromSeed(seed(1))
+	current := ed25519.NewKeyFromSeed(seed(2))
+	s := NewSigner(current, old.Public().(ed25519.PublicKey))
+
+	keyID, sig := s.Sign([]byte("report"))
+	if keyID != s.KeyID() || len(keyID) != 16 {
+		t.Fatalf("unexpected key ID %q", keyID)
+	}
+	if err := s.Verify(keyID, []byte("report"), sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := s.Verify(keyID, []byte("repor7"), sig); err != ErrBadSignature {
+		t.Errorf("expected ErrBadSignature for changed content, got %v", err)
+	}
+
+	// Signatures by a retired key still verify; unknown keys do not
+	retired := KeyID(old.Public().(ed25519.PublicKey))
+	if err := s.Verify(retired, []byte("report"), ed25519.Sign(old, []byte("report"))); err != nil {
+		t.Errorf("expected a retired key's signature to verify, got %v", err)
+	}
+	if err := s.Verify("0000000000000000", []byte("report"), sig); err != ErrUnknownKey {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	if key, ok := s.PublicKey(keyID); !ok || !key.Equal(current.Public()) {
+		t.Error("expected the current public key")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func() { Active = nil }()
+	t.Setenv("INSPECTION_SIGNING_KEY", "")
+	t.Setenv("INSPECTION_SIGNING_KEY_FILE", "")
+	if err := Configure(); err != ErrNoKey {
+		t.Fatalf("expected ErrNoKey, got %v", err)
+	}
+
+	// A base64 seed
+	t.Setenv("INSPECTION_SIGNING_KEY", base64.StdEncoding.EncodeToString(seed(3)))
+	if err := Configure(); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	want := KeyID(ed25519.NewKeyFromSeed(seed(3)).Public().(ed25519.PublicKey))
+	if Active.KeyID() != want {
+		t.Errorf("key ID %q, want %q", Active.KeyID(), want)
+	}
+	t.Setenv("INSPECTION_SIGNING_KEY", "c2hvcnQ=")
+	if err := Configure(); err == nil {
+		t.Error("expected a short seed to be rejected")
+	}
+	t.Setenv("INSPECTION_SIGNING_KEY", "not a key")
+	if err := Configure(); err == nil {
+		t.Error("expected a key that is not base64 to be rejected")
+	}
+
+	// A PEM file, as openssl writes it, plus a retired key
+	der, err := x509.MarshalPKCS8PrivateKey(ed25519.NewKeyFromSeed(seed(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "signing.pem")
+	os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	retired := ed25519.NewKeyFromSeed(seed(5)).Public().(ed25519.PublicKey)
+	t.Setenv("INSPECTION_SIGNING_KEY", "")
+	t.Setenv("INSPECTION_SIGNING_KEY_FILE", file)
+	t.Setenv("INSPECTION_RETIRED_KEYS", base64.StdEncoding.EncodeToString(retired))
+	if err := Configure(); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if Active.KeyID() != KeyID(ed25519.NewKeyFromSeed(seed(4)).Public().(ed25519.PublicKey)) {
+		t.Error("expected the PEM key to sign")
+	}
+	if _, ok := Active.PublicKey(KeyID(retired)); !ok {
+		t.Error("expected the retired key to be trusted")
+	}
+
+	t.Setenv("INSPECTION_SIGNING_KEY", base64.StdEncoding.EncodeToString(seed(3)))
+	if err := Configure(); err == nil {
+		t.Error("expected setting both a key and a key file to be rejected")
+	}
+}
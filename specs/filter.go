@@ -0,0 +1,173 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamPrefix marks query parameters that filter on specs
+const ParamPrefix = "specs."
+
+// Comparison operators, written as specs.<key>[op]=value; eq is the default
+const (
+	OpEq  = "eq"
+	OpNe  = "ne"
+	OpGt  = "gt"
+	OpGte = "gte"
+	OpLt  = "lt"
+	OpLte = "lte"
+	OpIn  = "in" // comma separated values
+)
+
+var (
+	ErrUnknownField    = errors.New("unknown spec field")
+	ErrInvalidOperator = errors.New("invalid operator")
+	ErrInvalidValue    = errors.New("invalid value")
+)
+
+var sqlComparisons = map[string]string{OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<="}
+
+// Filter is one typed predicate on a spec field
+type Filter struct {
+	Field  *Field
+	Op     string
+	Values []interface{} // typed: float64, int64, bool or string
+}
+
+// HasFilters reports whether any query parameter filters on specs
+func HasFilters(params url.Values) bool {
+	for name := range params {
+		if strings.HasPrefix(name, ParamPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilters reads specs.<key>[op]=value parameters and types their
+// values against the schema. Parameters are returned in a stable order.
+func ParseFilters(schema *Schema, params url.Values) ([]Filter, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if strings.HasPrefix(name, ParamPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var filters []Filter
+	for _, name := range names {
+		key, op := strings.TrimPrefix(name, ParamPrefix), OpEq
+		if i := strings.IndexByte(key, '['); i >= 0 && strings.HasSuffix(key, "]") {
+			key, op = key[:i], key[i+1:len(key)-1]
+		}
+
+		field, ok := schema.Field(key)
+		if !ok {
+			return nil, fmt.Errorf("%w %q for %s", ErrUnknownField, key, schema.Name)
+		}
+
+		for _, raw := range params[name] {
+			filter, err := parseFilter(field, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+func parseFilter(field *Field, op, raw string) (Filter, error) {
+	switch field.Type {
+	case TypeNumber, TypeInteger:
+		if op != OpEq && op != OpNe && op != OpIn && sqlComparisons[op] == "" {
+			return Filter{}, fmt.Errorf("%w %q for %s", ErrInvalidOperator, op, field.Key)
+		}
+	default:
+		if op != OpEq && op != OpNe && op != OpIn {
+			return Filter{}, fmt.Errorf("%w %q for %s (%s fields support eq, ne and in)", ErrInvalidOperator, op, field.Key, field.Type)
+		}
+	}
+
+	parts := []string{raw}
+	if op == OpIn {
+		parts = strings.Split(raw, ",")
+	}
+
+	filter := Filter{Field: field, Op: op}
+	for _, part := range parts {
+		v, err := field.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return Filter{}, err
+		}
+		filter.Values = append(filter.Values, v)
+	}
+	return filter, nil
+}
+
+// Parse converts a query string value to the field's type
+func (f *Field) Parse(raw string) (interface{}, error) {
+	switch f.Type {
+	case TypeNumber:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s: %q is not a number", ErrInvalidValue, f.Key, raw)
+		}
+		return v, nil
+	case TypeInteger:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s: %q is not a whole number", ErrInvalidValue, f.Key, raw)
+		}
+		return v, nil
+	case TypeBoolean:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s: %q is not true or false", ErrInvalidValue, f.Key, raw)
+		}
+		return v, nil
+	case TypeEnum:
+		v, ok := f.canonicalValue(raw)
+		if !ok {
+			return nil, fmt.Errorf("%w for %s: %q is not one of %s", ErrInvalidValue, f.Key, raw, strings.Join(f.Values, ", "))
+		}
+		return v, nil
+	}
+	if raw == "" {
+		return nil, fmt.Errorf("%w for %s: empty value", ErrInvalidValue, f.Key)
+	}
+	return raw, nil
+}
+
+// SQL compiles the filter to a parameterised predicate on the JSONB column.
+// Equality uses containment so it can use the GIN index; range comparisons
+// only consider values that are stored as JSON numbers.
+func (f Filter) SQL(column string) (string, []interface{}) {
+	if cmp, ok := sqlComparisons[f.Op]; ok {
+		expr := fmt.Sprintf("CASE WHEN jsonb_typeof(%[1]s -> ?::text) = 'number' THEN (%[1]s ->> ?::text)::numeric END %[2]s ?", column, cmp)
+		return expr, []interface{}{f.Field.Key, f.Field.Key, f.Values[0]}
+	}
+
+	clauses := make([]string, len(f.Values))
+	args := make([]interface{}, len(f.Values))
+	for i, v := range f.Values {
+		doc, _ := json.Marshal(map[string]interface{}{f.Field.Key: v})
+		clauses[i] = column + " @> ?::jsonb"
+		args[i] = string(doc)
+	}
+
+	expr := strings.Join(clauses, " OR ")
+	if len(clauses) > 1 {
+		expr = "(" + expr + ")"
+	}
+	if f.Op == OpNe {
+		expr = "NOT " + expr
+	}
+	return expr, args
+}
@@ -0,0 +1,135 @@
+package specs
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+var testMill = Schema{
+	Slug: "cnc-mill",
+	Name: "CNC Mill",
+	Fields: []Field{
+		{Key: "axes", Type: TypeInteger, Required: true},
+		{Key: "spindle_speed_rpm", Type: TypeNumber, Unit: "rpm", Required: true},
+		{Key: "control", Type: TypeEnum, Values: []string{"Fanuc", "Siemens", "Haas"}},
+		{Key: "coolant", Type: TypeBoolean},
+		{Key: "notes", Type: TypeString},
+	},
+}
+
+func TestParseFilters(t *testing.T) {
+	mill := &testMill
+
+	params := url.Values{
+		"specs.axes":                   {"5"},
+		"specs.spindle_speed_rpm[gte]": {"8000"},
+		"specs.control[in]":            {"fanuc,Siemens"},
+		"category":                     {"CNC Mill"},
+	}
+
+	filters, err := ParseFilters(mill, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filters) != 3 {
+		t.Fatalf("expected 3 filters, got %d", len(filters))
+	}
+
+	// Sorted by parameter name
+	expected := []struct {
+		key    string
+		op     string
+		values []interface{}
+	}{
+		{"axes", OpEq, []interface{}{int64(5)}},
+		{"control", OpIn, []interface{}{"Fanuc", "Siemens"}},
+		{"spindle_speed_rpm", OpGte, []interface{}{8000.0}},
+	}
+	for i, e := range expected {
+		f := filters[i]
+		if f.Field.Key != e.key || f.Op != e.op || !reflect.DeepEqual(f.Values, e.values) {
+			t.Errorf("filter %d: expected %s %s %v, got %s %s %v", i, e.key, e.op, e.values, f.Field.Key, f.Op, f.Values)
+		}
+	}
+}
+
+func TestParseFilters_Errors(t *testing.T) {
+	mill := &testMill
+
+	tests := []struct {
+		name     string
+		param    string
+		value    string
+		expected error
+	}{
+		{"Unknown Key", "specs.RPM", "8000", ErrUnknownField},
+		{"Unknown Operator", "specs.axes[like]", "5", ErrInvalidOperator},
+		{"Range On Enum", "specs.control[gt]", "Fanuc", ErrInvalidOperator},
+		{"Not A Number", "specs.spindle_speed_rpm[gte]", "fast", ErrInvalidValue},
+		{"Fractional Integer", "specs.axes", "4.5", ErrInvalidValue},
+		{"Enum Not Allowed", "specs.control", "Okuma", ErrInvalidValue},
+		{"Not A Boolean", "specs.coolant", "maybe", ErrInvalidValue},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFilters(mill, url.Values{tc.param: {tc.value}})
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestFilterSQL(t *testing.T) {
+	mill := &testMill
+	axes, _ := mill.Field("axes")
+	rpm, _ := mill.Field("spindle_speed_rpm")
+	control, _ := mill.Field("control")
+
+	tests := []struct {
+		name   string
+		filter Filter
+		sql    string
+		args   []interface{}
+	}{
+		{
+			"Equality Uses Containment",
+			Filter{Field: axes, Op: OpEq, Values: []interface{}{int64(5)}},
+			"specs @> ?::jsonb",
+			[]interface{}{`{"axes":5}`},
+		},
+		{
+			"Range Is Typed",
+			Filter{Field: rpm, Op: OpGte, Values: []interface{}{8000.0}},
+			"CASE WHEN jsonb_typeof(specs -> ?::text) = 'number' THEN (specs ->> ?::text)::numeric END >= ?",
+			[]interface{}{"spindle_speed_rpm", "spindle_speed_rpm", 8000.0},
+		},
+		{
+			"Negated In",
+			Filter{Field: control, Op: OpNe, Values: []interface{}{"Fanuc"}},
+			"NOT specs @> ?::jsonb",
+			[]interface{}{`{"control":"Fanuc"}`},
+		},
+		{
+			"In Is Disjunction",
+			Filter{Field: control, Op: OpIn, Values: []interface{}{"Fanuc", "Haas"}},
+			"(specs @> ?::jsonb OR specs @> ?::jsonb)",
+			[]interface{}{`{"control":"Fanuc"}`, `{"control":"Haas"}`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := tc.filter.SQL("specs")
+			if sql != tc.sql {
+				t.Errorf("expected SQL %q, got %q", tc.sql, sql)
+			}
+			if !reflect.DeepEqual(args, tc.args) {
+				t.Errorf("expected args %v, got %v", tc.args, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package specs describes the technical specifications expected for each
+// machine category, validates listings against them and compiles typed
+// filters over Machine.Specs. Schemas are stored in the category registry.
+package specs
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Field types
+const (
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeString  = "string"
+	TypeEnum    = "enum"
+	TypeBoolean = "boolean"
+)
+
+// Field is one key in a category's specs
+type Field struct {
+	Key      string   `json:"key" example:"spindle_speed_rpm"`
+	Label    string   `json:"label" example:"Spindle speed"`
+	Type     string   `json:"type" example:"number"`
+	Unit     string   `json:"unit,omitempty" example:"rpm"`
+	Values   []string `json:"values,omitempty"` // allowed values for enums
+	Required bool     `json:"required"`
+}
+
+// Schema lists the spec fields of a machine category
+type Schema struct {
+	Slug   string `json:"slug" example:"cnc-mill"`
+	Name   string `json:"name" example:"CNC Mill"`
+	Fields Fields `json:"fields"`
+}
+
+// Field looks up a field by key
+func (s *Schema) Field(key string) (*Field, bool) {
+	for i := range s.Fields {
+		if s.Fields[i].Key == key {
+			return &s.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// canonicalValue returns the allowed enum value matching v, ignoring case
+func (f *Field) canonicalValue(v string) (string, bool) {
+	for _, allowed := range f.Values {
+		if strings.EqualFold(allowed, v) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// Fields is a list of spec fields stored as a JSON column
+type Fields []Field
+
+// Value implements driver.Valuer
+func (f Fields) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (f *Fields) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case nil:
+		*f = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into specs.Fields", value)
+}
@@ -0,0 +1,132 @@
+package specs
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/vishwakarma-setu-backend/validation"
+)
+
+var (
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	keyPattern  = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// Validate checks decoded JSON specs against the schema. Required fields
+// must be present, unknown keys are rejected and values must match their
+// type. It returns the specs with enum values in their canonical spelling.
+func (s *Schema) Validate(values map[string]interface{}) (map[string]interface{}, error) {
+	verr := validation.New("specs")
+	clean := make(map[string]interface{}, len(values))
+
+	for key, v := range values {
+		field, ok := s.Field(key)
+		if !ok {
+			verr.Add(key, fmt.Sprintf("is not a %s spec", s.Name))
+			continue
+		}
+		if v == nil {
+			continue // null is the same as leaving it out
+		}
+
+		norm, msg := field.check(v)
+		if msg != "" {
+			verr.Add(key, msg)
+			continue
+		}
+		clean[key] = norm
+	}
+
+	for _, field := range s.Fields {
+		if _, ok := clean[field.Key]; !ok && field.Required {
+			if _, reported := verr.Fields[field.Key]; !reported {
+				verr.Add(field.Key, "is required")
+			}
+		}
+	}
+
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+// check validates a decoded JSON value and returns it normalised
+func (f *Field) check(v interface{}) (interface{}, string) {
+	switch f.Type {
+	case TypeNumber:
+		if n, ok := v.(float64); ok {
+			return n, ""
+		}
+		return nil, "must be a number" + f.unitHint()
+	case TypeInteger:
+		if n, ok := v.(float64); ok && n == math.Trunc(n) {
+			return n, ""
+		}
+		return nil, "must be a whole number" + f.unitHint()
+	case TypeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, ""
+		}
+		return nil, "must be true or false"
+	case TypeEnum:
+		if str, ok := v.(string); ok {
+			if canonical, ok := f.canonicalValue(str); ok {
+				return canonical, ""
+			}
+		}
+		return nil, "must be one of " + strings.Join(f.Values, ", ")
+	}
+	if str, ok := v.(string); ok && strings.TrimSpace(str) != "" {
+		return str, ""
+	}
+	return nil, "must be a non-empty string"
+}
+
+func (f *Field) unitHint() string {
+	if f.Unit == "" {
+		return ""
+	}
+	return " (in " + f.Unit + ")"
+}
+
+// ValidateSchema checks a schema definition before it is saved
+func ValidateSchema(s *Schema) error {
+	verr := validation.New("specs")
+
+	if !slugPattern.MatchString(s.Slug) {
+		verr.Add("slug", "must be lowercase letters, digits and dashes")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		verr.Add("name", "is required")
+	}
+
+	seen := map[string]bool{}
+	for i, field := range s.Fields {
+		name := fmt.Sprintf("fields[%d]", i)
+		switch {
+		case !keyPattern.MatchString(field.Key):
+			verr.Add(name+".key", "must be snake_case, e.g. spindle_speed_rpm")
+		case seen[field.Key]:
+			verr.Add(name+".key", fmt.Sprintf("%q is defined twice", field.Key))
+		}
+		seen[field.Key] = true
+
+		switch field.Type {
+		case TypeNumber, TypeInteger, TypeString, TypeBoolean:
+			if len(field.Values) > 0 {
+				verr.Add(name+".values", "only enum fields have allowed values")
+			}
+		case TypeEnum:
+			if len(field.Values) == 0 {
+				verr.Add(name+".values", "enum fields need at least one allowed value")
+			}
+		default:
+			verr.Add(name+".type", "must be number, integer, string, enum or boolean")
+		}
+	}
+
+	return verr.OrNil()
+}
@@ -0,0 +1,77 @@
+package specs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vishwakarma-setu-backend/validation"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	clean, err := testMill.Validate(map[string]interface{}{
+		"axes":              5.0,
+		"spindle_speed_rpm": 12000.0,
+		"control":           "fanuc",
+		"notes":             nil,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{"axes": 5.0, "spindle_speed_rpm": 12000.0, "control": "Fanuc"}
+	if !reflect.DeepEqual(clean, expected) {
+		t.Errorf("expected %v, got %v", expected, clean)
+	}
+}
+
+func TestSchemaValidate_FieldErrors(t *testing.T) {
+	_, err := testMill.Validate(map[string]interface{}{
+		"axes":    4.5,
+		"RPM":     8000.0,
+		"control": "Okuma",
+		"coolant": "yes",
+	})
+
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a validation.Error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"axes":              "must be a whole number",
+		"RPM":               "is not a CNC Mill spec",
+		"control":           "must be one of Fanuc, Siemens, Haas",
+		"coolant":           "must be true or false",
+		"spindle_speed_rpm": "is required",
+	}
+	if !reflect.DeepEqual(verr.Fields, expected) {
+		t.Errorf("expected %v, got %v", expected, verr.Fields)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	if err := ValidateSchema(&testMill); err != nil {
+		t.Fatalf("expected valid schema, got %v", err)
+	}
+
+	bad := Schema{
+		Slug: "CNC Mill",
+		Fields: []Field{
+			{Key: "axes", Type: TypeInteger},
+			{Key: "axes", Type: TypeNumber},
+			{Key: "Spindle Speed", Type: "float"},
+			{Key: "control", Type: TypeEnum},
+		},
+	}
+
+	var verr *validation.Error
+	if !errors.As(ValidateSchema(&bad), &verr) {
+		t.Fatal("expected a validation.Error")
+	}
+	for _, field := range []string{"slug", "name", "fields[1].key", "fields[2].key", "fields[2].type", "fields[3].values"} {
+		if _, ok := verr.Fields[field]; !ok {
+			t.Errorf("expected an error for %s, got %v", field, verr.Fields)
+		}
+	}
+}
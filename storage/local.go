@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedPath is where Local.Handler must be mounted; presigned URLs of the
+// local backend point under it
+const SignedPath = "/storage/"
+
+// maxSignedPut caps a presigned local upload, since the signature cannot
+// carry a size limit
+const maxSignedPut = 50 << 20
+
+// Local keeps objects as files under Dir, publicly served at BaseURL.
+// Presigned URLs are HMAC-signed and served by Handler.
+type Local struct {
+	Dir     string
+	BaseURL string
+	secret  []byte
+}
+
+// NewLocal returns a local backend. Without a signing secret a random one
+// is generated, so presigned URLs stop working when the server restarts.
+func NewLocal(dir, baseURL, secret string) *Local {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &Local{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/"), secret: key}
+}
+
+func (l *Local) Name() string { return BackendLocal }
+
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file and renames it into place, so readers
+// never see a partial object
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("expected %d bytes, got %d", size, written)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.BaseURL + "/" + key
+}
+
+func (l *Local) KeyFor(url string) (string, bool) {
+	return keyAfter(l.BaseURL+"/", url)
+}
+
+func (l *Local) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return l.presign(http.MethodGet, key, expires)
+}
+
+func (l *Local) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return l.presign(http.MethodPut, key, expires)
+}
+
+func (l *Local) presign(method, key string, expires time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if err := checkExpiry(expires); err != nil {
+		return "", err
+	}
+	deadline := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{"expires": {deadline}, "signature": {l.sign(method, key, deadline)}}
+	return SignedPath + key + "?" + query.Encode(), nil
+}
+
+func (l *Local) sign(method, key, deadline string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s", method, key, deadline)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a presigned request's signature and deadline
+func (l *Local) verify(method, key string, query url.Values) bool {
+	deadline := query.Get("expires")
+	unix, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	expected := l.sign(method, key, deadline)
+	return hmac.Equal([]byte(expected), []byte(query.Get("signature")))
+}
+
+// PublicPath is the path URL serves objects under: BaseURL's path when it
+// is an absolute URL, such as a CDN in front of this server
+func (l *Local) PublicPath() string {
+	if u, err := url.Parse(l.BaseURL); err == nil && u.Host != "" {
+		return strings.TrimSuffix(u.Path, "/")
+	}
+	return l.BaseURL
+}
+
+// PublicHandler serves objects under PublicPath, except private ones
+func (l *Local) PublicHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, l.PublicPath()+"/")
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !ValidKey(key) || IsPrivate(key) {
+			http.NotFound(w, r)
+			return
+		}
+		l.serve(w, r, key)
+	})
+}
+
+// Handler serves presigned GETs and PUTs under SignedPath
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, SignedPath)
+		if r.Method != http.MethodGet && r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !ValidKey(key) || !l.verify(r.Method, key, r.URL.Query()) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			if r.ContentLength > maxSignedPut {
+				http.Error(w, "object too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			body := http.MaxBytesReader(w, r.Body, maxSignedPut)
+			if err := l.Put(r.Context(), key, body, r.ContentLength, r.Header.Get("Content-Type")); err != nil {
+				http.Error(w, "could not store object", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		l.serve(w, r, key)
+	})
+}
+
+// serve writes a stored object; key must already be validated
+func (l *Local) serve(w http.ResponseWriter, r *http.Request, key string) {
+	path, _ := l.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if ctype := mime.TypeByExtension(filepath.Ext(key)); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+}
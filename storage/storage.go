@@ -0,0 +1,156 @@
+// Package storage keeps uploaded files in an object store. The local
+// backend writes to a directory on disk, which suits a single server; the
+// S3 backend talks to any S3-compatible service (AWS S3, MinIO, R2, ...)
+// so that every replica sees the same files.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Backends selectable with STORAGE_BACKEND
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// MaxPresignExpiry is the longest a presigned URL may stay valid
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// PrivatePrefix marks keys that must only be read through presigned URLs.
+// The local backend never serves them publicly; on S3 the bucket policy
+// should only grant public reads outside it.
+const PrivatePrefix = "private/"
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage is implemented by each backend. Keys are slash-separated paths
+// such as "upload-<uuid>.jpg"; see ValidKey.
+type Storage interface {
+	Name() string
+	// Put stores size bytes read from r under key, replacing any object
+	// already there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// URL is where clients fetch a publicly readable object
+	URL(key string) string
+	// KeyFor reverses URL: the key behind a URL this backend produced, or
+	// false for any other URL
+	KeyFor(url string) (string, bool)
+	// PresignGet returns a URL that downloads key until it expires
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+	// PresignPut returns a URL the client can PUT the object's bytes to
+	// until it expires
+	PresignPut(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// Active is the backend used by the API. Configure sets it at startup;
+// tests may replace it directly.
+var Active Storage = NewLocal("./uploads", "/uploads", "")
+
+// Configure selects the backend from STORAGE_BACKEND (default "local").
+//
+// Local: UPLOAD_DIR (default ./uploads), UPLOAD_BASE_URL (default /uploads)
+// and STORAGE_SIGNING_SECRET for presigned URLs.
+//
+// S3: S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID,
+// S3_SECRET_ACCESS_KEY, and optionally S3_PUBLIC_URL (e.g. a CDN in front
+// of the bucket) and S3_PATH_STYLE.
+func Configure() error {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", BackendLocal:
+		dir := envOr("UPLOAD_DIR", "./uploads")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating upload directory: %w", err)
+		}
+		Active = NewLocal(dir, envOr("UPLOAD_BASE_URL", "/uploads"), os.Getenv("STORAGE_SIGNING_SECRET"))
+		return nil
+	case BackendS3:
+		cfg := S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          envOr("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		}
+		// MinIO and most self-hosted stores only speak path-style
+		cfg.PathStyle = cfg.Endpoint != ""
+		if v := os.Getenv("S3_PATH_STYLE"); v != "" {
+			pathStyle, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid S3_PATH_STYLE %q", v)
+			}
+			cfg.PathStyle = pathStyle
+		}
+		s3, err := NewS3(cfg)
+		if err != nil {
+			return err
+		}
+		Active = s3
+		return nil
+	default:
+		return fmt.Errorf("unsupported STORAGE_BACKEND %q", backend)
+	}
+}
+
+// ValidKey reports whether key is safe to use on every backend: relative,
+// no "." or ".." segments, and only letters, digits and -_./
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 512 || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for _, r := range key {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '-' || r == '_' || r == '.' || r == '/'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// keyAfter returns the valid key that follows prefix in url
+func keyAfter(prefix, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, prefix)
+	if !ok || !ValidKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// IsPrivate reports whether key lives under PrivatePrefix
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+func checkExpiry(expires time.Duration) error {
+	if expires <= 0 || expires > MaxPresignExpiry {
+		return fmt.Errorf("presigned URLs must expire within %s", MaxPresignExpiry)
+	}
+	return nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
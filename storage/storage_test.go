@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidKey(t *testing.T) {
+	valid := []string{"upload-1.jpg", "machines/42/photo.png", "a_b-c.d"}
+	invalid := []string{"", "/abs.jpg", "dir/", "../etc/passwd", "a/./b", "a//b", "space name.jpg", "q?x=1"}
+	for _, key := range valid {
+		if !ValidKey(key) {
+			t.Errorf("expected %q to be valid", key)
+		}
+	}
+	for _, key := range invalid {
+		if ValidKey(key) {
+			t.Errorf("expected %q to be invalid", key)
+		}
+	}
+}
+
+func TestLocal_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	l := NewLocal(t.TempDir(), "/uploads/", "secret")
+
+	if err := l.Put(ctx, "a/b.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	rc, err := l.Get(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "hello" {
+		t.Errorf("expected hello, got %q", data)
+	}
+	if got := l.URL("a/b.txt"); got != "/uploads/a/b.txt" {
+		t.Errorf("unexpected url %s", got)
+	}
+	if key, ok := l.KeyFor("/uploads/a/b.txt"); !ok || key != "a/b.txt" {
+		t.Errorf("expected KeyFor to reverse URL, got %q %v", key, ok)
+	}
+	for _, foreign := range []string{"https://evil.example.com/uploads/a.jpg", "/uploads/../secret", "/other/a.jpg"} {
+		if _, ok := l.KeyFor(foreign); ok {
+			t.Errorf("expected %q not to map to a key", foreign)
+		}
+	}
+
+	if err := l.Put(ctx, "short.txt", strings.NewReader("hi"), 5, ""); err == nil {
+		t.Error("expected a size mismatch to fail")
+	}
+	if _, err := l.Get(ctx, "short.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("a failed put must not leave an object, got %v", err)
+	}
+
+	if err := l.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := l.Delete(ctx, "a/b.txt"); err != nil {
+		t.Errorf("deleting a missing key should succeed, got %v", err)
+	}
+	if _, err := l.Get(ctx, "../outside"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestLocal_PresignedRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	l := NewLocal(t.TempDir(), "/uploads", "secret")
+	srv := httptest.NewServer(l.Handler())
+	defer srv.Close()
+
+	putURL, err := l.PresignPut(ctx, "doc.pdf", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+putURL, bytes.NewReader([]byte("%PDF-1.4")))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected presigned put to succeed, got %d", resp.StatusCode)
+	}
+
+	getURL, _ := l.PresignGet(ctx, "doc.pdf", time.Minute)
+	resp, err = http.Get(srv.URL + getURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "%PDF-1.4" {
+		t.Errorf("expected the uploaded bytes, got %d %q", resp.StatusCode, body)
+	}
+
+	// A GET signature does not authorise a PUT, nor survive tampering
+	req, _ = http.NewRequest(http.MethodPut, srv.URL+getURL, strings.NewReader("overwrite"))
+	resp, _ = http.DefaultClient.Do(req)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a put with a get signature, got %d", resp.StatusCode)
+	}
+	resp, _ = http.Get(srv.URL + strings.Replace(getURL, "doc.pdf", "other.pdf", 1))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for another key, got %d", resp.StatusCode)
+	}
+}
+
+func TestLocal_PresignExpiry(t *testing.T) {
+	l := NewLocal(t.TempDir(), "/uploads", "secret")
+	if _, err := l.PresignGet(context.Background(), "a.jpg", 0); err == nil {
+		t.Error("expected a zero expiry to be rejected")
+	}
+	if _, err := l.PresignGet(context.Background(), "a.jpg", MaxPresignExpiry+time.Hour); err == nil {
+		t.Error("expected an expiry beyond the maximum to be rejected")
+	}
+
+	expired := SignedPath + "a.jpg?expires=1&signature=" + l.sign(http.MethodGet, "a.jpg", "1")
+	rec := httptest.NewRecorder()
+	l.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, expired, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an expired url, got %d", rec.Code)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer func(prev Storage) { Active = prev }(Active)
+
+	t.Setenv("STORAGE_BACKEND", "s3")
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_BUCKET", "uploads")
+	t.Setenv("S3_ACCESS_KEY_ID", "minio")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minio123")
+	if err := Configure(); err != nil {
+		t.Fatalf("configure s3: %v", err)
+	}
+	if Active.Name() != BackendS3 || Active.URL("a.jpg") != "http://localhost:9000/uploads/a.jpg" {
+		t.Errorf("expected a path-style s3 backend, got %s %s", Active.Name(), Active.URL("a.jpg"))
+	}
+
+	t.Setenv("S3_BUCKET", "")
+	if err := Configure(); err == nil {
+		t.Error("expected a missing bucket to fail")
+	}
+
+	t.Setenv("STORAGE_BACKEND", "ftp")
+	if err := Configure(); err == nil {
+		t.Error("expected an unknown backend to fail")
+	}
+}
+
+func TestLocal_PublicHandler(t *testing.T) {
+	ctx := context.Background()
+	l := NewLocal(t.TempDir(), "https://cdn.example.com/files", "secret")
+	if l.PublicPath() != "/files" {
+		t.Fatalf("expected /files, got %s", l.PublicPath())
+	}
+	l.Put(ctx, "photo.jpg", strings.NewReader("jpeg"), 4, "image/jpeg")
+	l.Put(ctx, PrivatePrefix+"invoice.pdf", strings.NewReader("%PDF"), 4, "application/pdf")
+
+	rec := httptest.NewRecorder()
+	l.PublicHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/photo.jpg", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "jpeg" {
+		t.Errorf("expected the public object, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	l.PublicHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/private/invoice.pdf", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected private objects to be hidden, got %d", rec.Code)
+	}
+
+	// ... but a presigned URL reaches them
+	signed, _ := l.PresignGet(ctx, PrivatePrefix+"invoice.pdf", time.Minute)
+	rec = httptest.NewRecorder()
+	l.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signed, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the presigned read to succeed, got %d", rec.Code)
+	}
+}
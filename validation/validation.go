@@ -0,0 +1,58 @@
+// Package validation reports what is wrong with submitted data, field by
+// field, so handlers can return every problem at once.
+package validation
+
+import (
+	"sort"
+	"strings"
+)
+
+// Error maps field names to what is wrong with them. Subject names what
+// was validated, such as "specs" or "checklist".
+type Error struct {
+	Subject string
+	Fields  map[string]string
+}
+
+// New returns an empty Error for subject; add problems with Add and
+// return it with OrNil
+func New(subject string) *Error {
+	return &Error{Subject: subject}
+}
+
+// Field returns an Error with a single problem
+func Field(subject, field, msg string) *Error {
+	e := New(subject)
+	e.Add(field, msg)
+	return e
+}
+
+func (e *Error) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + e.Fields[k]
+	}
+	return "invalid " + e.Subject + ": " + strings.Join(parts, "; ")
+}
+
+// Add records a problem with field, replacing any earlier one
+func (e *Error) Add(field, msg string) {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	e.Fields[field] = msg
+}
+
+// OrNil returns e if it holds any problems, and nil otherwise
+func (e *Error) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
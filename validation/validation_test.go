@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	e := New("specs")
+	if e.OrNil() != nil {
+		t.Fatal("expected no error without problems")
+	}
+
+	e.Add("b", "is required")
+	e.Add("a", "must be a number")
+	if got := e.Error(); got != "invalid specs: a: must be a number; b: is required" {
+		t.Errorf("unexpected message %q", got)
+	}
+
+	var verr *Error
+	if !errors.As(fmt.Errorf("saving: %w", e.OrNil()), &verr) || len(verr.Fields) != 2 {
+		t.Errorf("expected the error to unwrap, got %v", verr)
+	}
+}